@@ -7,6 +7,9 @@ import (
 	_ "github.com/khorzhenwin/go-cafe/backend/docs"
 	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
 	"github.com/khorzhenwin/go-cafe/backend/internal/db"
+	"github.com/khorzhenwin/go-cafe/backend/internal/discovery"
+	"github.com/khorzhenwin/go-cafe/backend/internal/mail"
+	"github.com/khorzhenwin/go-cafe/backend/internal/photo"
 	"github.com/khorzhenwin/go-cafe/backend/internal/server"
 )
 
@@ -23,6 +26,53 @@ func (app *application) run() error {
 		log.Fatal(err)
 	}
 
+	mailCfg, err := appconfig.LoadMailConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	mailer, err := mail.NewFromConfig(mailCfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cacheCfg, err := appconfig.LoadCacheConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	discoveryCfg, err := appconfig.LoadDiscoveryConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	staticMapCfg, err := appconfig.LoadStaticMapConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	staticMaps, err := discovery.NewStaticMapRendererFromConfig(staticMapCfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	photoCfg, err := appconfig.LoadPhotoConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	photoStore, err := photo.NewBlobStoreFromConfig(photoCfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	screeningCfg, err := appconfig.LoadScreeningConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	auditCfg, err := appconfig.LoadAuditConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	conn, err := db.NewAWSClient(cloudDbCfg)
 	if err != nil {
 		log.Fatal(err)
@@ -34,8 +84,13 @@ func (app *application) run() error {
 		Address:      app.config.ADDRESS,
 		WriteTimeout: app.config.writeTimeout,
 		ReadTimeout:  app.config.readTimeout,
+		Cache:        *cacheCfg,
+		Discovery:    *discoveryCfg,
+		Photos:       *photoCfg,
+		Screening:    *screeningCfg,
+		Audit:        *auditCfg,
 	}
-	handler := server.New(conn, authCfg, srvCfg)
+	handler := server.NewWithDependencies(conn, authCfg, srvCfg, server.Dependencies{Mailer: mailer, StaticMaps: staticMaps, PhotoStore: photoStore})
 	srv := server.NewServer(handler, srvCfg)
 
 	log.Println("Starting server on", app.config.ADDRESS)
@@ -0,0 +1,169 @@
+package apitoken
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+)
+
+type Handler struct {
+	Service *Service
+}
+
+type CreateTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 = never expires
+}
+
+type TokenSummary struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+type CreateTokenResponse struct {
+	TokenSummary
+	Token string `json:"token"` // shown once
+}
+
+// RegisterRoutes registers /me/tokens. Managing tokens requires a login session; personal access tokens
+// cannot manage tokens.
+func RegisterRoutes(r chi.Router, service *Service, authMiddleware func(http.Handler) http.Handler) {
+	h := &Handler{Service: service}
+	r.Route("/me/tokens", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Get("/", h.ListHandler)
+		r.Post("/", h.CreateHandler)
+		r.Delete("/{id}", h.RevokeHandler)
+	})
+}
+
+func summarize(t *models.PersonalAccessToken) TokenSummary {
+	return TokenSummary{
+		ID:          t.ID,
+		Name:        t.Name,
+		TokenPrefix: t.TokenPrefix,
+		Scopes:      ScopeList(t),
+		CreatedAt:   t.CreatedAt,
+		ExpiresAt:   t.ExpiresAt,
+		LastUsedAt:  t.LastUsedAt,
+	}
+}
+
+// ListHandler godoc
+// @Summary List my API tokens
+// @Description Returns the authenticated user's active (unrevoked, unexpired) personal access tokens. Token values are never returned.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} TokenSummary
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /me/tokens/ [get]
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	tokens, err := h.Service.List(userID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve tokens", http.StatusInternalServerError)
+		return
+	}
+	out := make([]TokenSummary, 0, len(tokens))
+	for i := range tokens {
+		out = append(out, summarize(&tokens[i]))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// CreateHandler godoc
+// @Summary Create an API token
+// @Description Issues a named personal access token limited to the given scopes (cafes:read, cafes:write, ratings:read, ratings:write). The token value is returned only in this response; send it as "Authorization: Bearer <token>".
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateTokenRequest true "Token name, scopes and optional lifetime"
+// @Success 201 {object} CreateTokenResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /me/tokens/ [post]
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	token, raw, err := h.Service.Create(userID, req.Name, req.Scopes, req.ExpiresInDays)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNameRequired), errors.Is(err, ErrInvalidScope), errors.Is(err, ErrInvalidExpiry):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, ErrTooManyTokens):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(CreateTokenResponse{TokenSummary: summarize(token), Token: raw})
+}
+
+// RevokeHandler godoc
+// @Summary Revoke an API token
+// @Description Revokes one of the authenticated user's personal access tokens immediately.
+// @Tags me
+// @Security BearerAuth
+// @Param id path int true "Token ID"
+// @Success 204 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /me/tokens/{id} [delete]
+func (h *Handler) RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.Service.Revoke(userID, uint(id)); err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package apitoken
+
+import (
+	"errors"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type Storage interface {
+	Create(token *models.PersonalAccessToken) error
+	GetByHash(hash string) (*models.PersonalAccessToken, error)
+	ListActiveByUserID(userID uint, now time.Time) ([]models.PersonalAccessToken, error)
+	CountActiveByUserID(userID uint, now time.Time) (int64, error)
+	Revoke(id, userID uint, at time.Time) error
+	RevokeAllByUserID(userID uint, at time.Time) error
+	TouchLastUsed(id uint, at, staleBefore time.Time) error
+}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(t *models.PersonalAccessToken) error {
+	return r.db.Create(t).Error
+}
+
+func (r *Repository) GetByHash(hash string) (*models.PersonalAccessToken, error) {
+	var t models.PersonalAccessToken
+	err := r.db.Where("token_hash = ?", hash).First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &t, err
+}
+
+func (r *Repository) activeByUser(userID uint, now time.Time) *gorm.DB {
+	return r.db.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now)
+}
+
+func (r *Repository) ListActiveByUserID(userID uint, now time.Time) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := r.activeByUser(userID, now).Order("created_at DESC, id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *Repository) CountActiveByUserID(userID uint, now time.Time) (int64, error) {
+	var n int64
+	err := r.activeByUser(userID, now).Count(&n).Error
+	return n, err
+}
+
+// Revoke revokes one of the user's tokens; gorm.ErrRecordNotFound when it is not theirs or already revoked.
+func (r *Repository) Revoke(id, userID uint, at time.Time) error {
+	res := r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *Repository) RevokeAllByUserID(userID uint, at time.Time) error {
+	return r.db.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+// TouchLastUsed records use unless it was already recorded after staleBefore, so busy tokens do not write on every request.
+func (r *Repository) TouchLastUsed(id uint, at, staleBefore time.Time) error {
+	return r.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, staleBefore).
+		Update("last_used_at", at).Error
+}
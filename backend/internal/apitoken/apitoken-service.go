@@ -0,0 +1,145 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxActiveTokens = 50
+	MaxExpiryDays   = 365
+	maxNameLength   = 100
+	// lastUsedResolution bounds how often last_used_at is written for a busy token.
+	lastUsedResolution = time.Minute
+	// displayPrefixLength covers the type prefix plus a few random characters to tell tokens apart in lists.
+	displayPrefixLength = len(auth.PersonalTokenPrefix) + 6
+)
+
+// RoleLookup is implemented by user service.
+type RoleLookup interface {
+	GetRole(userID uint) (string, error)
+}
+
+type Service struct {
+	store Storage
+	roles RoleLookup
+	now   func() time.Time
+}
+
+func NewService(store Storage, roles RoleLookup) *Service {
+	return &Service{store: store, roles: roles, now: time.Now}
+}
+
+// Create issues a token for userID and returns its record with the plaintext, which is never stored
+// and cannot be shown again. expiresInDays of 0 means the token does not expire.
+func (s *Service) Create(userID uint, name string, scopes []string, expiresInDays int) (*models.PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxNameLength {
+		return nil, "", ErrNameRequired
+	}
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if expiresInDays < 0 || expiresInDays > MaxExpiryDays {
+		return nil, "", ErrInvalidExpiry
+	}
+	now := s.now()
+	active, err := s.store.CountActiveByUserID(userID, now)
+	if err != nil {
+		return nil, "", err
+	}
+	if active >= MaxActiveTokens {
+		return nil, "", ErrTooManyTokens
+	}
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	raw := auth.PersonalTokenPrefix + secret
+	token := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   auth.HashOpaqueToken(raw),
+		TokenPrefix: raw[:displayPrefixLength],
+		Scopes:      strings.Join(normalized, " "),
+	}
+	if expiresInDays > 0 {
+		expiresAt := now.Add(time.Duration(expiresInDays) * 24 * time.Hour)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := s.store.Create(token); err != nil {
+		return nil, "", err
+	}
+	return token, raw, nil
+}
+
+func (s *Service) List(userID uint) ([]models.PersonalAccessToken, error) {
+	return s.store.ListActiveByUserID(userID, s.now())
+}
+
+func (s *Service) Revoke(userID, id uint) error {
+	err := s.store.Revoke(id, userID, s.now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTokenNotFound
+	}
+	return err
+}
+
+// RevokeAllForUser revokes every token of the user, e.g. after a password reset.
+func (s *Service) RevokeAllForUser(userID uint) error {
+	return s.store.RevokeAllByUserID(userID, s.now())
+}
+
+// AuthenticateToken resolves a presented token to its owner and scopes (implements auth.TokenAuthenticator).
+func (s *Service) AuthenticateToken(ctx context.Context, rawToken string) (*auth.TokenPrincipal, error) {
+	token, err := s.store.GetByHash(auth.HashOpaqueToken(rawToken))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if token == nil || token.RevokedAt != nil || (token.ExpiresAt != nil && !token.ExpiresAt.After(now)) {
+		return nil, auth.ErrInvalidToken
+	}
+	role, err := s.roles.GetRole(token.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.TouchLastUsed(token.ID, now, now.Add(-lastUsedResolution)); err != nil {
+		return nil, err
+	}
+	return &auth.TokenPrincipal{UserID: token.UserID, Role: role, Scopes: ScopeList(token)}, nil
+}
+
+// ScopeList returns the token's scopes as a slice.
+func ScopeList(token *models.PersonalAccessToken) []string {
+	return strings.Fields(token.Scopes)
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	var out []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !auth.IsValidScope(scope) {
+			return nil, ErrInvalidScope
+		}
+		if !slices.Contains(out, scope) {
+			out = append(out, scope)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrInvalidScope
+	}
+	slices.Sort(out)
+	return out, nil
+}
@@ -0,0 +1,152 @@
+package apitoken
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeRoles map[uint]string
+
+func (f fakeRoles) GetRole(userID uint) (string, error) {
+	role, ok := f[userID]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return role, nil
+}
+
+func newTestService(t *testing.T) (*Service, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.PersonalAccessToken{}))
+	return NewService(NewRepository(db), fakeRoles{1: auth.RoleUser, 2: auth.RoleAdmin}), db
+}
+
+func TestService_CreateAndAuthenticate(t *testing.T) {
+	svc, db := newTestService(t)
+
+	token, raw, err := svc.Create(1, "  importer ", []string{auth.ScopeCafesWrite, auth.ScopeCafesRead, auth.ScopeCafesWrite}, 30)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, auth.PersonalTokenPrefix))
+	assert.True(t, strings.HasPrefix(raw, token.TokenPrefix))
+	assert.Equal(t, "importer", token.Name)
+	assert.Equal(t, []string{auth.ScopeCafesRead, auth.ScopeCafesWrite}, ScopeList(token))
+	require.NotNil(t, token.ExpiresAt)
+
+	var stored models.PersonalAccessToken
+	require.NoError(t, db.First(&stored, token.ID).Error)
+	assert.Equal(t, auth.HashOpaqueToken(raw), stored.TokenHash)
+	assert.Nil(t, stored.LastUsedAt)
+
+	principal, err := svc.AuthenticateToken(context.Background(), raw)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), principal.UserID)
+	assert.Equal(t, auth.RoleUser, principal.Role)
+	assert.Equal(t, []string{auth.ScopeCafesRead, auth.ScopeCafesWrite}, principal.Scopes)
+	require.NoError(t, db.First(&stored, token.ID).Error)
+	require.NotNil(t, stored.LastUsedAt)
+
+	_, err = svc.AuthenticateToken(context.Background(), raw+"x")
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestService_CreateValidation(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	_, _, err := svc.Create(1, " ", []string{auth.ScopeCafesRead}, 0)
+	assert.ErrorIs(t, err, ErrNameRequired)
+	_, _, err = svc.Create(1, "x", nil, 0)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	_, _, err = svc.Create(1, "x", []string{"users:admin"}, 0)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	_, _, err = svc.Create(1, "x", []string{auth.ScopeCafesRead}, MaxExpiryDays+1)
+	assert.ErrorIs(t, err, ErrInvalidExpiry)
+
+	for i := 0; i < MaxActiveTokens; i++ {
+		_, _, err = svc.Create(1, "bulk", []string{auth.ScopeCafesRead}, 0)
+		require.NoError(t, err)
+	}
+	_, _, err = svc.Create(1, "one too many", []string{auth.ScopeCafesRead}, 0)
+	assert.ErrorIs(t, err, ErrTooManyTokens)
+}
+
+func TestService_RevokeExpireAndList(t *testing.T) {
+	svc, _ := newTestService(t)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	keep, _, err := svc.Create(1, "keep", []string{auth.ScopeRatingsRead}, 0)
+	require.NoError(t, err)
+	revoked, revokedRaw, err := svc.Create(1, "revoke me", []string{auth.ScopeRatingsRead}, 0)
+	require.NoError(t, err)
+	_, expiringRaw, err := svc.Create(1, "short", []string{auth.ScopeRatingsRead}, 1)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.Revoke(2, revoked.ID), ErrTokenNotFound, "other users cannot revoke it")
+	require.NoError(t, svc.Revoke(1, revoked.ID))
+	assert.ErrorIs(t, svc.Revoke(1, revoked.ID), ErrTokenNotFound)
+	_, err = svc.AuthenticateToken(context.Background(), revokedRaw)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	now = now.Add(25 * time.Hour)
+	_, err = svc.AuthenticateToken(context.Background(), expiringRaw)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	tokens, err := svc.List(1)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, keep.ID, tokens[0].ID)
+
+	require.NoError(t, svc.RevokeAllForUser(1))
+	tokens, err = svc.List(1)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
+func TestService_AuthenticateThrottlesLastUsedWrites(t *testing.T) {
+	svc, db := newTestService(t)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	token, raw, err := svc.Create(2, "admin script", []string{auth.ScopeCafesRead}, 0)
+	require.NoError(t, err)
+
+	lastUsed := func() time.Time {
+		var stored models.PersonalAccessToken
+		require.NoError(t, db.First(&stored, token.ID).Error)
+		require.NotNil(t, stored.LastUsedAt)
+		return stored.LastUsedAt.UTC()
+	}
+	_, err = svc.AuthenticateToken(context.Background(), raw)
+	require.NoError(t, err)
+	first := lastUsed()
+
+	now = now.Add(30 * time.Second)
+	_, err = svc.AuthenticateToken(context.Background(), raw)
+	require.NoError(t, err)
+	assert.Equal(t, first, lastUsed())
+
+	now = now.Add(time.Minute)
+	principal, err := svc.AuthenticateToken(context.Background(), raw)
+	require.NoError(t, err)
+	assert.Equal(t, now, lastUsed())
+	assert.Equal(t, auth.RoleAdmin, principal.Role)
+}
+
+func TestService_AuthenticateDeletedUser(t *testing.T) {
+	svc, _ := newTestService(t)
+	_, raw, err := svc.Create(99, "orphan", []string{auth.ScopeCafesRead}, 0)
+	require.NoError(t, err)
+
+	_, err = svc.AuthenticateToken(context.Background(), raw)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
@@ -0,0 +1,9 @@
+package apitoken
+
+import "errors"
+
+var ErrTokenNotFound = errors.New("token not found")
+var ErrNameRequired = errors.New("name is required")
+var ErrInvalidScope = errors.New("scopes must be a non-empty list of known scopes")
+var ErrInvalidExpiry = errors.New("expires_in_days must be between 0 and 365")
+var ErrTooManyTokens = errors.New("too many active tokens")
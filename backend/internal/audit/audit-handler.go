@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	Service   *Service
+	Restorers map[string]Restorer // keyed by entity type
+}
+
+// RegisterRoutes registers the admin-only audit trail and restore routes. restorers maps each entity type that can
+// be restored to its service.
+func RegisterRoutes(r chi.Router, service *Service, restorers map[string]Restorer, authMiddleware func(http.Handler) http.Handler) {
+	h := &Handler{Service: service, Restorers: restorers}
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware, auth.RequireRole(auth.RoleAdmin))
+		r.Get("/admin/audit-events/", h.ListHandler)
+		r.Post("/admin/restore/{entityType}/{id}", h.RestoreHandler)
+	})
+}
+
+// ListHandler godoc
+// @Summary List audit events
+// @Description Returns the audit trail of updates, deletes and restores of users, cafe listings and ratings, newest first, with each entity's JSON before and after the change. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param entity_type query string false "user, cafe_listing or rating"
+// @Param entity_id query int false "Only events for this entity (use with entity_type)"
+// @Param actor_id query int false "Only events by this user"
+// @Param action query string false "update, delete or restore"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.AuditEvent}
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /admin/audit-events/ [get]
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	filter := ListFilter{
+		EntityType: r.URL.Query().Get("entity_type"),
+		Action:     r.URL.Query().Get("action"),
+	}
+	if raw := r.URL.Query().Get("entity_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil {
+			http.Error(w, "Invalid entity_id", http.StatusBadRequest)
+			return
+		}
+		filter.EntityID = uint(id)
+	}
+	if raw := r.URL.Query().Get("actor_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 0)
+		if err != nil {
+			http.Error(w, "Invalid actor_id", http.StatusBadRequest)
+			return
+		}
+		filter.ActorID = uint(id)
+	}
+	events, err := h.Service.List(filter, page)
+	if err != nil {
+		if errors.Is(err, ErrInvalidEntityType) || errors.Is(err, ErrInvalidAction) || errors.Is(err, pagination.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to retrieve audit events", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(events)
+}
+
+// RestoreHandler godoc
+// @Summary Restore deleted entity
+// @Description Restores a soft-deleted user, cafe listing or rating together with everything deleted with it (a user's listings and ratings, a listing's ratings), if it was deleted within the restore window. Admin only.
+// @Tags admin
+// @Security BearerAuth
+// @Param entityType path string true "user, cafe_listing or rating"
+// @Param id path int true "Entity ID"
+// @Success 204 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 410 {string} string
+// @Failure 500 {string} string
+// @Router /admin/restore/{entityType}/{id} [post]
+func (h *Handler) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	restorer, ok := h.Restorers[chi.URLParam(r, "entityType")]
+	if !ok {
+		http.Error(w, ErrInvalidEntityType.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.Service.Restore(restorer, uint(id), adminID); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			http.Error(w, "Not found", http.StatusNotFound)
+		case errors.Is(err, ErrNotDeleted), errors.Is(err, ErrRestoreConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, ErrRestoreWindowPassed):
+			http.Error(w, err.Error(), http.StatusGone)
+		default:
+			http.Error(w, "Failed to restore", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
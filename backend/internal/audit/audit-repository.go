@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// Storage has no update or delete: the audit trail is append-only, and in Postgres a trigger rejects both.
+type Storage interface {
+	// Create writes event through tx, the transaction making the change it describes.
+	Create(tx *gorm.DB, event *models.AuditEvent) error
+	List(filter ListFilter, page pagination.Request) (pagination.Page[models.AuditEvent], error)
+}
+
+// ListFilter narrows the audit trail; zero fields match every event.
+type ListFilter struct {
+	EntityType string
+	EntityID   uint
+	ActorID    uint
+	Action     string
+}
+
+// newestOrder lists the latest events first; ids are assigned in insert order.
+var newestOrder = pagination.Order[models.AuditEvent]{Name: "newest", Keys: []pagination.Key[models.AuditEvent]{
+	{Column: "gocafe_audit_events.id", Desc: true, Value: func(e models.AuditEvent) any { return e.ID }},
+}}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(tx *gorm.DB, event *models.AuditEvent) error {
+	return tx.Create(event).Error
+}
+
+func (r *Repository) List(filter ListFilter, page pagination.Request) (pagination.Page[models.AuditEvent], error) {
+	q := r.db.Model(&models.AuditEvent{})
+	if filter.EntityType != "" {
+		q = q.Where("gocafe_audit_events.entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		q = q.Where("gocafe_audit_events.entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != 0 {
+		q = q.Where("gocafe_audit_events.actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		q = q.Where("gocafe_audit_events.action = ?", filter.Action)
+	}
+	return pagination.Find(q, newestOrder, page)
+}
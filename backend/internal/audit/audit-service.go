@@ -0,0 +1,119 @@
+// Package audit keeps the append-only trail of changes to users, cafe listings and ratings, and restores
+// soft-deleted ones for admins within the restore window.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+const (
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+
+	EntityUser        = "user"
+	EntityCafeListing = "cafe_listing"
+	EntityRating      = "rating"
+)
+
+// DefaultRestoreWindow is used when NewService is given no restore window.
+const DefaultRestoreWindow = 30 * 24 * time.Hour
+
+var (
+	// Actions lists every action an event may record.
+	Actions = []string{ActionUpdate, ActionDelete, ActionRestore}
+	// EntityTypes lists every kind of entity the trail covers.
+	EntityTypes = []string{EntityUser, EntityCafeListing, EntityRating}
+)
+
+// Change is one change to record. Before and After are stored as JSON; nil leaves that side empty.
+type Change struct {
+	ActorID    uint
+	Action     string
+	EntityType string
+	EntityID   uint
+	Before     any
+	After      any
+}
+
+// Recorder appends changes to the audit trail (implemented by *Service). tx is the transaction making the change, so
+// the event commits or rolls back with it; an error from Record should roll the change back.
+type Recorder interface {
+	Record(tx *gorm.DB, change Change) error
+}
+
+// Restorer brings back one kind of soft-deleted entity together with whatever was deleted with it. Entities deleted
+// before deletedSince are out of the restore window. Implemented by the user, cafe listing and rating services.
+type Restorer interface {
+	Restore(id, actorID uint, deletedSince time.Time) error
+}
+
+type Service struct {
+	store         Storage
+	restoreWindow time.Duration
+	now           func() time.Time
+}
+
+func NewService(store Storage, restoreWindow time.Duration) *Service {
+	if restoreWindow <= 0 {
+		restoreWindow = DefaultRestoreWindow
+	}
+	return &Service{store: store, restoreWindow: restoreWindow, now: time.Now}
+}
+
+func (s *Service) Record(tx *gorm.DB, change Change) error {
+	event := &models.AuditEvent{
+		ActorID:    change.ActorID,
+		Action:     change.Action,
+		EntityType: change.EntityType,
+		EntityID:   change.EntityID,
+	}
+	var err error
+	if event.Before, err = snapshot(change.Before); err == nil {
+		event.After, err = snapshot(change.After)
+	}
+	if err == nil {
+		err = s.store.Create(tx, event)
+	}
+	if err != nil {
+		return fmt.Errorf("record audit event %s %s %d: %w", change.Action, change.EntityType, change.EntityID, err)
+	}
+	return nil
+}
+
+// List returns the audit trail, newest event first.
+func (s *Service) List(filter ListFilter, page pagination.Request) (pagination.Page[models.AuditEvent], error) {
+	filter.EntityType = strings.ToLower(strings.TrimSpace(filter.EntityType))
+	if filter.EntityType != "" && !slices.Contains(EntityTypes, filter.EntityType) {
+		return pagination.Page[models.AuditEvent]{}, ErrInvalidEntityType
+	}
+	filter.Action = strings.ToLower(strings.TrimSpace(filter.Action))
+	if filter.Action != "" && !slices.Contains(Actions, filter.Action) {
+		return pagination.Page[models.AuditEvent]{}, ErrInvalidAction
+	}
+	return s.store.List(filter, page)
+}
+
+// Restore undeletes entity id through restorer, as long as it was deleted within the restore window.
+func (s *Service) Restore(restorer Restorer, id, actorID uint) error {
+	return restorer.Restore(id, actorID, s.now().Add(-s.restoreWindow))
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return data, nil
+}
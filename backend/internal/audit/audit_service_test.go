@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockStorage struct {
+	created   []*models.AuditEvent
+	createErr error
+	filter    ListFilter
+}
+
+func (m *mockStorage) Create(tx *gorm.DB, event *models.AuditEvent) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.created = append(m.created, event)
+	return nil
+}
+
+func (m *mockStorage) List(filter ListFilter, page pagination.Request) (pagination.Page[models.AuditEvent], error) {
+	m.filter = filter
+	return pagination.Page[models.AuditEvent]{}, nil
+}
+
+type restorerFunc func(id, actorID uint, deletedSince time.Time) error
+
+func (f restorerFunc) Restore(id, actorID uint, deletedSince time.Time) error {
+	return f(id, actorID, deletedSince)
+}
+
+func TestService_Record_Snapshots(t *testing.T) {
+	m := &mockStorage{}
+	svc := NewService(m, 0)
+
+	require.NoError(t, svc.Record(nil, Change{ActorID: 2, Action: ActionDelete, EntityType: EntityCafeListing, EntityID: 9, Before: &models.Cafe{ID: 9, Name: "Gone"}, After: (*models.Cafe)(nil)}))
+	require.Len(t, m.created, 1)
+	event := m.created[0]
+	assert.Equal(t, uint(2), event.ActorID)
+	assert.Equal(t, ActionDelete, event.Action)
+	assert.Equal(t, EntityCafeListing, event.EntityType)
+	assert.Equal(t, uint(9), event.EntityID)
+	assert.Contains(t, string(event.Before), `"name":"Gone"`)
+	assert.Nil(t, event.After, "a typed nil pointer records no snapshot")
+}
+
+func TestService_Record_ReturnsWriteError(t *testing.T) {
+	writeErr := errors.New("connection reset")
+	svc := NewService(&mockStorage{createErr: writeErr}, 0)
+
+	err := svc.Record(nil, Change{ActorID: 2, Action: ActionUpdate, EntityType: EntityRating, EntityID: 4})
+	assert.ErrorIs(t, err, writeErr, "the caller rolls its change back on a failed audit write")
+}
+
+func TestService_List_ValidatesFilters(t *testing.T) {
+	m := &mockStorage{}
+	svc := NewService(m, 0)
+
+	_, err := svc.List(ListFilter{EntityType: "photo"}, pagination.Request{})
+	assert.ErrorIs(t, err, ErrInvalidEntityType)
+	_, err = svc.List(ListFilter{Action: "create"}, pagination.Request{})
+	assert.ErrorIs(t, err, ErrInvalidAction)
+
+	_, err = svc.List(ListFilter{EntityType: " Rating ", Action: "DELETE", EntityID: 3}, pagination.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, ListFilter{EntityType: EntityRating, Action: ActionDelete, EntityID: 3}, m.filter)
+}
+
+func TestService_Restore_PassesWindowStart(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	svc := NewService(&mockStorage{}, 7*24*time.Hour)
+	svc.now = func() time.Time { return now }
+
+	var since time.Time
+	err := svc.Restore(restorerFunc(func(id, actorID uint, deletedSince time.Time) error {
+		assert.Equal(t, uint(4), id)
+		assert.Equal(t, uint(1), actorID)
+		since = deletedSince
+		return nil
+	}), 4, 1)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-7*24*time.Hour), since)
+
+	assert.Equal(t, DefaultRestoreWindow, NewService(&mockStorage{}, 0).restoreWindow)
+}
@@ -0,0 +1,9 @@
+package audit
+
+import "errors"
+
+var ErrInvalidEntityType = errors.New("entity_type must be user, cafe_listing or rating")
+var ErrInvalidAction = errors.New("action must be update, delete or restore")
+var ErrNotDeleted = errors.New("entity is not deleted")
+var ErrRestoreWindowPassed = errors.New("entity was deleted too long ago to restore")
+var ErrRestoreConflict = errors.New("entity cannot be restored")
@@ -5,8 +5,31 @@ import "context"
 type contextKey string
 
 const UserIDKey contextKey = "user_id"
+const SessionIDKey contextKey = "session_id"
+const RoleKey contextKey = "role"
+const ScopesKey contextKey = "scopes"
+
+// requiredScopeKey holds the scope declared by RequireScope for the current route.
+const requiredScopeKey contextKey = "required_scope"
 
 func UserIDFromContext(ctx context.Context) (uint, bool) {
 	id, ok := ctx.Value(UserIDKey).(uint)
 	return id, ok
 }
+
+func SessionIDFromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(SessionIDKey).(uint)
+	return id, ok
+}
+
+func RoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(RoleKey).(string)
+	return role, ok
+}
+
+// ScopesFromContext returns the scopes of a personal access token; ok is false for JWT sessions,
+// which are not scope-limited.
+func ScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(ScopesKey).([]string)
+	return scopes, ok
+}
@@ -0,0 +1,14 @@
+package auth
+
+import "errors"
+
+// ErrInvalidToken is returned by single-use token flows (password reset, email verification)
+// when a token is unknown, expired, or already used.
+var ErrInvalidToken = errors.New("token is invalid or has expired")
+
+// ErrUnknownProvider is returned when an OIDC provider name is not configured.
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+// ErrExternalEmailNotVerified is returned when an OIDC identity cannot be linked because the provider
+// did not vouch for the email address.
+var ErrExternalEmailNotVerified = errors.New("identity provider did not verify the email address")
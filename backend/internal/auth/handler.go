@@ -1,13 +1,19 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"net/netip"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/khorzhenwin/go-cafe/backend/internal/config"
 )
 
@@ -21,10 +27,48 @@ type RegisterCreator interface {
 	CreateWithPassword(email, name, password string) (id uint, err error)
 }
 
+// RoleLookup is implemented by user service so access tokens carry the user's current role.
+type RoleLookup interface {
+	GetRole(userID uint) (string, error)
+}
+
+// SessionManager is implemented by session service for refresh token issuance, rotation and revocation.
+type SessionManager interface {
+	CreateSession(userID uint, userAgent, ipAddress string, expiresAt time.Time) (sessionID uint, refreshToken string, err error)
+	RotateSession(refreshToken string, expiresAt time.Time) (sessionID, userID uint, newRefreshToken string, err error)
+	RevokeSession(sessionID uint) error
+	RevokeAllForUser(userID uint) error
+}
+
+// PasswordResetter is implemented by password reset service.
+type PasswordResetter interface {
+	RequestReset(ctx context.Context, email string) error
+	ResetPassword(token, newPassword string) error
+}
+
+// EmailVerifier is implemented by email verification service.
+type EmailVerifier interface {
+	SendVerification(ctx context.Context, userID uint) error
+	VerifyEmail(token string) error
+}
+
+// LoginGuard is implemented by login guard service to throttle password guessing per email and per IP.
+type LoginGuard interface {
+	Check(ctx context.Context, email, ip string) (retryAfter time.Duration, err error)
+	RecordFailure(ctx context.Context, email, ip string) (retryAfter time.Duration, err error)
+	RecordSuccess(ctx context.Context, email, ip string) error
+}
+
 type Handler struct {
-	AuthCfg *config.AuthConfig
-	Finder  LoginFinder
-	Creator RegisterCreator
+	AuthCfg  *config.AuthConfig
+	Finder   LoginFinder
+	Creator  RegisterCreator
+	Roles    RoleLookup
+	Sessions SessionManager
+	Resets   PasswordResetter
+	Verifier EmailVerifier
+	Guard    LoginGuard
+	OIDC     OIDCLogin
 }
 
 type LoginRequest struct {
@@ -38,21 +82,59 @@ type RegisterRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
 type TokenResponse struct {
-	Token     string `json:"token"`
-	ExpiresAt string `json:"expires_at"`
+	Token                 string `json:"token"`
+	ExpiresAt             string `json:"expires_at"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresAt string `json:"refresh_token_expires_at"`
 }
 
-func RegisterRoutes(r chi.Router, h *Handler) {
+// RegisterRoutes registers auth routes. authMiddleware is required for logout endpoints.
+func RegisterRoutes(r chi.Router, h *Handler, authMiddleware func(http.Handler) http.Handler) {
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/login", h.LoginHandler)
 		r.Post("/register", h.RegisterHandler)
+		r.Post("/refresh", h.RefreshHandler)
+		r.Post("/password/forgot", h.ForgotPasswordHandler)
+		r.Post("/password/reset", h.ResetPasswordHandler)
+		r.Post("/verify-email", h.VerifyEmailHandler)
+		r.Get("/oidc/providers", h.OIDCProvidersHandler)
+		r.Get("/oidc/{provider}/start", h.OIDCStartHandler)
+		r.Get("/oidc/{provider}/callback", h.OIDCCallbackHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(authMiddleware)
+			r.Post("/logout", h.LogoutHandler)
+			r.Post("/logout-all", h.LogoutAllHandler)
+			r.Post("/verify-email/resend", h.ResendVerificationHandler)
+		})
 	})
 }
 
 // LoginHandler godoc
 // @Summary Login user
-// @Description Authenticates a user and returns a JWT token.
+// @Description Authenticates a user and returns a short-lived access token and a rotating refresh token.
+// @Description Repeated failures lock the email or client IP temporarily; locked attempts get 429 with Retry-After.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -60,6 +142,7 @@ func RegisterRoutes(r chi.Router, h *Handler) {
 // @Success 200 {object} TokenResponse
 // @Failure 400 {string} string
 // @Failure 401 {string} string
+// @Failure 429 {string} string
 // @Failure 500 {string} string
 // @Router /auth/login [post]
 func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
@@ -73,26 +156,40 @@ func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "email and password required", http.StatusBadRequest)
 		return
 	}
+	ip := clientIP(r, h.AuthCfg.TrustedProxies)
+	if h.Guard != nil {
+		// Checked before bcrypt so a locked key costs no hashing work.
+		wait, err := h.Guard.Check(r.Context(), req.Email, ip)
+		if err != nil {
+			http.Error(w, "Failed to process login", http.StatusInternalServerError)
+			return
+		}
+		if wait > 0 {
+			tooManyAttempts(w, wait)
+			return
+		}
+	}
 	id, passwordHash, err := h.Finder.GetByEmailForAuth(req.Email)
-	if err != nil || passwordHash == "" {
-		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+	if err != nil || passwordHash == "" || !CheckPassword(req.Password, passwordHash) {
+		h.loginFailed(w, r, req.Email, ip)
 		return
 	}
-	if !CheckPassword(req.Password, passwordHash) {
-		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
-		return
+	if h.Guard != nil {
+		if err := h.Guard.RecordSuccess(r.Context(), req.Email, ip); err != nil {
+			log.Printf("reset login failures: %v", err)
+		}
 	}
-	token, expiresAt, err := h.issueToken(id)
+	resp, err := h.issueTokens(r, id)
 	if err != nil {
 		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
 		return
 	}
-	_ = json.NewEncoder(w).Encode(TokenResponse{Token: token, ExpiresAt: expiresAt.Format(time.RFC3339)})
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 // RegisterHandler godoc
 // @Summary Register user
-// @Description Creates a new user account and returns a JWT token.
+// @Description Creates a new user account, emails a verification link, and returns a short-lived access token and a rotating refresh token.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -117,26 +214,329 @@ func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	token, expiresAt, err := h.issueToken(id)
+	if h.Verifier != nil {
+		// The account exists either way; the user can ask for a new link via /auth/verify-email/resend.
+		if err := h.Verifier.SendVerification(r.Context(), id); err != nil {
+			log.Printf("send verification for user %d: %v", id, err)
+		}
+	}
+	resp, err := h.issueTokens(r, id)
 	if err != nil {
 		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(TokenResponse{Token: token, ExpiresAt: expiresAt.Format(time.RFC3339)})
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// RefreshHandler godoc
+// @Summary Refresh tokens
+// @Description Exchanges a refresh token for a new access token and a new refresh token. Reusing an old refresh token revokes its session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body RefreshRequest true "Refresh payload"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /auth/refresh [post]
+func (h *Handler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		http.Error(w, "refresh_token required", http.StatusBadRequest)
+		return
+	}
+	refreshExpiresAt := time.Now().Add(h.AuthCfg.RefreshExpiry)
+	sessionID, userID, refreshToken, err := h.Sessions.RotateSession(req.RefreshToken, refreshExpiresAt)
+	if err != nil {
+		http.Error(w, "Invalid or expired refresh token", http.StatusUnauthorized)
+		return
+	}
+	role, err := h.lookupRole(userID)
+	if err != nil {
+		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
+		return
+	}
+	token, expiresAt, err := signAccessToken(h.AuthCfg, userID, sessionID, role)
+	if err != nil {
+		http.Error(w, "Failed to issue token", http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(TokenResponse{
+		Token:                 token,
+		ExpiresAt:             expiresAt.Format(time.RFC3339),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// LogoutHandler godoc
+// @Summary Logout
+// @Description Revokes the session of the presented access token, invalidating its refresh token.
+// @Tags auth
+// @Security BearerAuth
+// @Success 204 {string} string
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /auth/logout [post]
+func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID, ok := SessionIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.Sessions.RevokeSession(sessionID); err != nil {
+		http.Error(w, "Failed to logout", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAllHandler godoc
+// @Summary Logout everywhere
+// @Description Revokes every session of the authenticated user.
+// @Tags auth
+// @Security BearerAuth
+// @Success 204 {string} string
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /auth/logout-all [post]
+func (h *Handler) LogoutAllHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.Sessions.RevokeAllForUser(userID); err != nil {
+		http.Error(w, "Failed to logout", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPasswordHandler godoc
+// @Summary Request password reset
+// @Description Emails a single-use reset link if the address is registered. The response is identical either way.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordRequest true "Forgot password payload"
+// @Success 202 {object} MessageResponse
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /auth/password/forgot [post]
+func (h *Handler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Email) == "" {
+		http.Error(w, "email required", http.StatusBadRequest)
+		return
+	}
+	if err := h.Resets.RequestReset(r.Context(), req.Email); err != nil {
+		http.Error(w, "Failed to request password reset", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(MessageResponse{
+		Message: "If an account exists for that email, a password reset link has been sent.",
+	})
+}
+
+// ResetPasswordHandler godoc
+// @Summary Reset password
+// @Description Sets a new password using a reset token. The token is single-use and all sessions of the user are revoked.
+// @Tags auth
+// @Accept json
+// @Param body body ResetPasswordRequest true "Reset password payload"
+// @Success 204 {string} string
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /auth/password/reset [post]
+func (h *Handler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Token) == "" || req.NewPassword == "" {
+		http.Error(w, "token and new_password required", http.StatusBadRequest)
+		return
+	}
+	if err := h.Resets.ResetPassword(req.Token, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrWeakPassword):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *Handler) issueToken(userID uint) (string, time.Time, error) {
-	expiresAt := time.Now().Add(h.AuthCfg.JWTExpiry)
-	claims := jwt.RegisteredClaims{
-		Subject:   strconv.FormatUint(uint64(userID), 10),
-		ExpiresAt: jwt.NewNumericDate(expiresAt),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+// VerifyEmailHandler godoc
+// @Summary Verify email
+// @Description Confirms the account email using the single-use token from the verification link.
+// @Tags auth
+// @Accept json
+// @Param body body VerifyEmailRequest true "Verify email payload"
+// @Success 204 {string} string
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /auth/verify-email [post]
+func (h *Handler) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Token) == "" {
+		http.Error(w, "token required", http.StatusBadRequest)
+		return
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(h.AuthCfg.JWTSecret)
+	if err := h.Verifier.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to verify email", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerificationHandler godoc
+// @Summary Resend verification email
+// @Description Emails a new verification link to the authenticated user's address, invalidating earlier links. No-op when already verified.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} MessageResponse
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /auth/verify-email/resend [post]
+func (h *Handler) ResendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.Verifier.SendVerification(r.Context(), userID); err != nil {
+		http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(MessageResponse{Message: "If your email is not verified yet, a new verification link has been sent."})
+}
+
+// loginFailed counts the failure and answers 401, or 429 when this failure triggered a lockout.
+func (h *Handler) loginFailed(w http.ResponseWriter, r *http.Request, email, ip string) {
+	if h.Guard != nil {
+		wait, err := h.Guard.RecordFailure(r.Context(), email, ip)
+		if err != nil {
+			log.Printf("record login failure: %v", err)
+		}
+		if wait > 0 {
+			tooManyAttempts(w, wait)
+			return
+		}
+	}
+	http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+}
+
+// tooManyAttempts writes 429 with Retry-After rounded up to whole seconds.
+func tooManyAttempts(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(w, "Too many failed login attempts. Try again later.", http.StatusTooManyRequests)
+}
+
+// issueTokens starts a new session for the user and returns the access/refresh token pair.
+func (h *Handler) issueTokens(r *http.Request, userID uint) (TokenResponse, error) {
+	refreshExpiresAt := time.Now().Add(h.AuthCfg.RefreshExpiry)
+	sessionID, refreshToken, err := h.Sessions.CreateSession(userID, r.UserAgent(), clientIP(r, h.AuthCfg.TrustedProxies), refreshExpiresAt)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	role, err := h.lookupRole(userID)
 	if err != nil {
-		return "", time.Time{}, err
+		return TokenResponse{}, err
+	}
+	token, expiresAt, err := signAccessToken(h.AuthCfg, userID, sessionID, role)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return TokenResponse{
+		Token:                 token,
+		ExpiresAt:             expiresAt.Format(time.RFC3339),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// lookupRole returns the user's current role, defaulting to RoleUser when no lookup is wired.
+func (h *Handler) lookupRole(userID uint) (string, error) {
+	if h.Roles == nil {
+		return RoleUser, nil
+	}
+	role, err := h.Roles.GetRole(userID)
+	if err != nil {
+		return "", err
+	}
+	if role == "" {
+		role = RoleUser
+	}
+	return role, nil
+}
+
+// clientIP returns the remote address host. X-Forwarded-For is only read when that peer is a trusted proxy; its
+// hops are then walked from the right, and the first one that is not itself a trusted proxy is the client. Anything
+// left of that hop was written by the client and is ignored, so it cannot pick the IP its login failures count against.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, trusted) {
+		return host
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if _, err := netip.ParseAddr(hop); err != nil {
+			return host
+		}
+		if !isTrustedProxy(hop, trusted) {
+			return hop
+		}
+		host = hop
+	}
+	return host
+}
+
+func isTrustedProxy(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
 	}
-	return signed, expiresAt, nil
+	return false
 }
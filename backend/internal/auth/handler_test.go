@@ -0,0 +1,287 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSessionManager struct {
+	rotateErr    error
+	revoked      []uint
+	revokedUsers []uint
+}
+
+func (s *stubSessionManager) CreateSession(userID uint, userAgent, ipAddress string, expiresAt time.Time) (uint, string, error) {
+	return 11, "refresh-1", nil
+}
+
+func (s *stubSessionManager) RotateSession(refreshToken string, expiresAt time.Time) (uint, uint, string, error) {
+	if s.rotateErr != nil {
+		return 0, 0, "", s.rotateErr
+	}
+	return 11, 7, "refresh-2", nil
+}
+
+func (s *stubSessionManager) RevokeSession(sessionID uint) error {
+	s.revoked = append(s.revoked, sessionID)
+	return nil
+}
+
+func (s *stubSessionManager) RevokeAllForUser(userID uint) error {
+	s.revokedUsers = append(s.revokedUsers, userID)
+	return nil
+}
+
+type stubCreator struct{}
+
+func (stubCreator) CreateWithPassword(email, name, password string) (uint, error) { return 7, nil }
+
+func testHandler(sessions *stubSessionManager) *Handler {
+	return &Handler{
+		AuthCfg:  &config.AuthConfig{JWTSecret: []byte("test-secret"), JWTExpiry: time.Minute, RefreshExpiry: time.Hour},
+		Creator:  stubCreator{},
+		Sessions: sessions,
+	}
+}
+
+func TestRegisterHandler_ReturnsTokenPair(t *testing.T) {
+	h := testHandler(&stubSessionManager{})
+	body, _ := json.Marshal(RegisterRequest{Email: "a@b.com", Password: "secret123"})
+	rec := httptest.NewRecorder()
+	h.RegisterHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(body)))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var resp TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.Token)
+	assert.Equal(t, "refresh-1", resp.RefreshToken)
+	assert.NotEmpty(t, resp.RefreshTokenExpiresAt)
+}
+
+func TestRefreshHandler(t *testing.T) {
+	h := testHandler(&stubSessionManager{})
+	body, _ := json.Marshal(RefreshRequest{RefreshToken: "refresh-1"})
+	rec := httptest.NewRecorder()
+	h.RefreshHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "refresh-2", resp.RefreshToken)
+}
+
+func TestRefreshHandler_Rejected(t *testing.T) {
+	h := testHandler(&stubSessionManager{rotateErr: errors.New("reused")})
+	body, _ := json.Marshal(RefreshRequest{RefreshToken: "refresh-1"})
+	rec := httptest.NewRecorder()
+	h.RefreshHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRefreshHandler_MissingToken(t *testing.T) {
+	h := testHandler(&stubSessionManager{})
+	rec := httptest.NewRecorder()
+	h.RefreshHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader([]byte(`{}`))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestLogoutHandlers(t *testing.T) {
+	sessions := &stubSessionManager{}
+	h := testHandler(sessions)
+	mw := Middleware(h.AuthCfg, nil, nil)
+	token, _, err := signAccessToken(h.AuthCfg, 7, 11, RoleUser)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	mw(http.HandlerFunc(h.LogoutHandler)).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []uint{11}, sessions.revoked)
+
+	req = httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	mw(http.HandlerFunc(h.LogoutAllHandler)).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []uint{7}, sessions.revokedUsers)
+}
+
+type stubResetter struct {
+	requested []string
+	resetErr  error
+}
+
+func (s *stubResetter) RequestReset(ctx context.Context, email string) error {
+	s.requested = append(s.requested, email)
+	return nil
+}
+
+func (s *stubResetter) ResetPassword(token, newPassword string) error { return s.resetErr }
+
+func TestForgotPasswordHandler_AlwaysAccepted(t *testing.T) {
+	resets := &stubResetter{}
+	h := testHandler(&stubSessionManager{})
+	h.Resets = resets
+	body, _ := json.Marshal(ForgotPasswordRequest{Email: "nobody@example.com"})
+	rec := httptest.NewRecorder()
+	h.ForgotPasswordHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewReader(body)))
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, []string{"nobody@example.com"}, resets.requested)
+}
+
+func TestResetPasswordHandler_StatusMapping(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusNoContent},
+		{ErrInvalidToken, http.StatusBadRequest},
+		{ErrWeakPassword, http.StatusBadRequest},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		h := testHandler(&stubSessionManager{})
+		h.Resets = &stubResetter{resetErr: tc.err}
+		body, _ := json.Marshal(ResetPasswordRequest{Token: "tok", NewPassword: "new-password"})
+		rec := httptest.NewRecorder()
+		h.ResetPasswordHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewReader(body)))
+		assert.Equal(t, tc.want, rec.Code)
+	}
+}
+
+type stubVerifier struct {
+	sent      []uint
+	verifyErr error
+}
+
+func (s *stubVerifier) SendVerification(ctx context.Context, userID uint) error {
+	s.sent = append(s.sent, userID)
+	return nil
+}
+
+func (s *stubVerifier) VerifyEmail(token string) error { return s.verifyErr }
+
+func TestRegisterHandler_SendsVerification(t *testing.T) {
+	verifier := &stubVerifier{}
+	h := testHandler(&stubSessionManager{})
+	h.Verifier = verifier
+	body, _ := json.Marshal(RegisterRequest{Email: "a@b.com", Password: "secret123"})
+	rec := httptest.NewRecorder()
+	h.RegisterHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(body)))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, []uint{7}, verifier.sent)
+}
+
+func TestVerifyEmailHandler_StatusMapping(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusNoContent},
+		{ErrInvalidToken, http.StatusBadRequest},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		h := testHandler(&stubSessionManager{})
+		h.Verifier = &stubVerifier{verifyErr: tc.err}
+		body, _ := json.Marshal(VerifyEmailRequest{Token: "tok"})
+		rec := httptest.NewRecorder()
+		h.VerifyEmailHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/verify-email", bytes.NewReader(body)))
+		assert.Equal(t, tc.want, rec.Code)
+	}
+}
+
+type stubFinder struct {
+	hash string
+}
+
+func (s stubFinder) GetByEmailForAuth(email string) (uint, string, error) { return 7, s.hash, nil }
+
+type stubGuard struct {
+	checkWait   time.Duration
+	failureWait time.Duration
+	failures    int
+	successes   int
+}
+
+func (s *stubGuard) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	return s.checkWait, nil
+}
+
+func (s *stubGuard) RecordFailure(ctx context.Context, email, ip string) (time.Duration, error) {
+	s.failures++
+	return s.failureWait, nil
+}
+
+func (s *stubGuard) RecordSuccess(ctx context.Context, email, ip string) error {
+	s.successes++
+	return nil
+}
+
+func TestLoginHandler_Guard(t *testing.T) {
+	hash, err := HashPassword("secret123")
+	require.NoError(t, err)
+	login := func(guard *stubGuard, password string) *httptest.ResponseRecorder {
+		h := testHandler(&stubSessionManager{})
+		h.Finder = stubFinder{hash: hash}
+		h.Guard = guard
+		body, _ := json.Marshal(LoginRequest{Email: "a@b.com", Password: password})
+		rec := httptest.NewRecorder()
+		h.LoginHandler(rec, httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body)))
+		return rec
+	}
+
+	guard := &stubGuard{}
+	assert.Equal(t, http.StatusUnauthorized, login(guard, "wrong").Code)
+	assert.Equal(t, 1, guard.failures)
+	assert.Equal(t, http.StatusOK, login(guard, "secret123").Code)
+	assert.Equal(t, 1, guard.successes)
+
+	rec := login(&stubGuard{failureWait: 1500 * time.Millisecond}, "wrong")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+	// Locked keys are rejected before the password is checked, even if it is correct.
+	locked := &stubGuard{checkWait: time.Minute}
+	rec = login(locked, "secret123")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Zero(t, locked.failures)
+	assert.Zero(t, locked.successes)
+}
+
+func TestClientIP_TrustsForwardedForOnlyFromProxies(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	request := func(remoteAddr string, forwarded ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+		r.RemoteAddr = remoteAddr
+		for _, value := range forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		return r
+	}
+
+	// A direct client cannot choose its IP through the header.
+	assert.Equal(t, "203.0.113.9", clientIP(request("203.0.113.9:5000", "198.51.100.1"), trusted))
+	assert.Equal(t, "203.0.113.9", clientIP(request("203.0.113.9:5000", "198.51.100.1"), nil))
+	// Behind trusted proxies the nearest untrusted hop wins; hops the client prepended are ignored.
+	assert.Equal(t, "203.0.113.9", clientIP(request("10.0.0.2:5000", "198.51.100.1, 203.0.113.9"), trusted))
+	assert.Equal(t, "203.0.113.9", clientIP(request("10.0.0.2:5000", "198.51.100.1", "203.0.113.9, 10.0.0.5"), trusted))
+	// A proxy that forwarded nothing, or something that is not an IP, leaves the peer address.
+	assert.Equal(t, "10.0.0.2", clientIP(request("10.0.0.2:5000"), trusted))
+	assert.Equal(t, "10.0.0.2", clientIP(request("10.0.0.2:5000", "not-an-ip"), trusted))
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+)
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	if alg == config.JWTAlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// newAccessToken builds an unsigned access token and the key to sign it with: the active asymmetric key
+// (with its kid in the header) when keys are configured, else HS256 with the shared secret.
+func newAccessToken(cfg *config.AuthConfig, claims Claims) (*jwt.Token, any, error) {
+	if len(cfg.JWTKeys) == 0 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims), cfg.JWTSecret, nil
+	}
+	for _, k := range cfg.JWTKeys {
+		if k.ID == cfg.JWTActiveKeyID && k.Signer != nil {
+			token := jwt.NewWithClaims(signingMethod(k.Algorithm), claims)
+			token.Header["kid"] = k.ID
+			return token, k.Signer, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("active JWT key %q not found", cfg.JWTActiveKeyID)
+}
+
+// parseAccessToken verifies an access token. Algorithms are pinned: with asymmetric keys configured only
+// their algorithms are accepted and each token must use the algorithm of the key named by its kid, so
+// retired keys still verify tokens issued before a rotation. Issuer and audience are checked when configured.
+func parseAccessToken(cfg *config.AuthConfig, tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if cfg.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWTAudience))
+	}
+	var keyFunc jwt.Keyfunc
+	if len(cfg.JWTKeys) == 0 {
+		opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+		keyFunc = func(*jwt.Token) (any, error) { return cfg.JWTSecret, nil }
+	} else {
+		opts = append(opts, jwt.WithValidMethods([]string{config.JWTAlgorithmRS256, config.JWTAlgorithmEdDSA}))
+		keyFunc = func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			for _, k := range cfg.JWTKeys {
+				if k.ID == kid {
+					if token.Method.Alg() != k.Algorithm {
+						return nil, fmt.Errorf("key %q does not sign %s", kid, token.Method.Alg())
+					}
+					return k.Public, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+	}
+	if _, err := jwt.ParseWithClaims(tokenStr, claims, keyFunc, opts...); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWKSHandler serves the public keys that verify access tokens at /.well-known/jwks.json (outside the API
+// base path, so not in Swagger). Retired keys stay listed until removed from config; the set is empty for HS256.
+func JWKSHandler(cfg *config.AuthConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keys := make([]JWK, 0, len(cfg.JWTKeys))
+		for _, k := range cfg.JWTKeys {
+			jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+			switch pub := k.Public.(type) {
+			case *rsa.PublicKey:
+				jwk.KeyType = "RSA"
+				jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+				jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+			case ed25519.PublicKey:
+				jwk.KeyType = "OKP"
+				jwk.Curve = "Ed25519"
+				jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+			default:
+				continue
+			}
+			keys = append(keys, jwk)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(JWKSResponse{Keys: keys})
+	}
+}
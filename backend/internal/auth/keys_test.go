@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKSHandler_PublishesAllKeys(t *testing.T) {
+	rsaKey, edKey := rsaTestKey(t, "rsa-1"), ed25519TestKey(t, "ed-1")
+	retired := config.JWTKey{ID: "rsa-0", Algorithm: rsaKey.Algorithm, Public: rsaKey.Public}
+	cfg := &config.AuthConfig{JWTKeys: []config.JWTKey{rsaKey, edKey, retired}, JWTActiveKeyID: edKey.ID}
+
+	rec := httptest.NewRecorder()
+	JWKSHandler(cfg)(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), `"d"`)
+
+	var set JWKSResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 3)
+	assert.Equal(t, JWK{KeyType: "RSA", KeyID: "rsa-1", Use: "sig", Algorithm: "RS256", N: set.Keys[0].N, E: "AQAB"}, set.Keys[0])
+	assert.NotEmpty(t, set.Keys[0].N)
+	assert.Equal(t, "OKP", set.Keys[1].KeyType)
+	assert.Equal(t, "Ed25519", set.Keys[1].Curve)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(edKey.Public.(ed25519.PublicKey)), set.Keys[1].X)
+	assert.Equal(t, "rsa-0", set.Keys[2].KeyID)
+}
+
+func TestJWKSHandler_EmptyForHS256(t *testing.T) {
+	rec := httptest.NewRecorder()
+	JWKSHandler(&config.AuthConfig{JWTSecret: []byte("s")})(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	assert.JSONEq(t, `{"keys":[]}`, rec.Body.String())
+}
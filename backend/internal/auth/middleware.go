@@ -2,15 +2,22 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/khorzhenwin/go-cafe/backend/internal/config"
 )
 
-func Middleware(cfg *config.AuthConfig) func(next http.Handler) http.Handler {
+// SessionValidator is implemented by session service to reject access tokens whose session was revoked.
+type SessionValidator interface {
+	IsSessionActive(sessionID uint) (bool, error)
+}
+
+// Middleware verifies the bearer access token. When sessions is non-nil, tokens must reference an active session.
+// When tokens is non-nil, personal access tokens are accepted too, limited to routes wrapped in RequireScope.
+func Middleware(cfg *config.AuthConfig, sessions SessionValidator, tokens TokenAuthenticator) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			auth := r.Header.Get("Authorization")
@@ -24,11 +31,12 @@ func Middleware(cfg *config.AuthConfig) func(next http.Handler) http.Handler {
 				return
 			}
 			tokenStr := strings.TrimPrefix(auth, prefix)
-			claims := &jwt.RegisteredClaims{}
-			token, err := jwt.ParseWithClaims(tokenStr, claims, func(*jwt.Token) (interface{}, error) {
-				return cfg.JWTSecret, nil
-			})
-			if err != nil || !token.Valid {
+			if strings.HasPrefix(tokenStr, PersonalTokenPrefix) {
+				servePersonalToken(w, r, next, tokens, tokenStr)
+				return
+			}
+			claims, err := parseAccessToken(cfg, tokenStr)
+			if err != nil {
 				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 				return
 			}
@@ -42,9 +50,75 @@ func Middleware(cfg *config.AuthConfig) func(next http.Handler) http.Handler {
 				http.Error(w, "Invalid token", http.StatusUnauthorized)
 				return
 			}
+			if sessions != nil {
+				if claims.SessionID == 0 {
+					http.Error(w, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+				active, err := sessions.IsSessionActive(claims.SessionID)
+				if err != nil {
+					http.Error(w, "Failed to verify session", http.StatusInternalServerError)
+					return
+				}
+				if !active {
+					http.Error(w, "Session has been revoked", http.StatusUnauthorized)
+					return
+				}
+			}
 			userID := uint(userIDNum)
+			role := claims.Role
+			if role == "" {
+				role = RoleUser
+			}
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, RoleKey, role)
+			if claims.SessionID != 0 {
+				ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// Optional runs authMiddleware only when the request carries an Authorization header, so public routes can tell
+// who is asking without requiring it. A header that is present must still be valid.
+func Optional(authMiddleware func(http.Handler) http.Handler) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authed := authMiddleware(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			authed.ServeHTTP(w, r)
+		})
+	}
+}
+
+func servePersonalToken(w http.ResponseWriter, r *http.Request, next http.Handler, tokens TokenAuthenticator, tokenStr string) {
+	if tokens == nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+	principal, err := tokens.AuthenticateToken(r.Context(), tokenStr)
+	if errors.Is(err, ErrInvalidToken) {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+		return
+	}
+	if status, msg := checkTokenScope(r, principal); status != 0 {
+		http.Error(w, msg, status)
+		return
+	}
+	role := principal.Role
+	if role == "" {
+		role = RoleUser
+	}
+	ctx := context.WithValue(r.Context(), UserIDKey, principal.UserID)
+	ctx = context.WithValue(ctx, RoleKey, role)
+	ctx = context.WithValue(ctx, ScopesKey, principal.Scopes)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -14,7 +17,7 @@ import (
 
 func TestMiddleware_NoAuthHeader(t *testing.T) {
 	cfg := &config.AuthConfig{JWTSecret: []byte("secret"), JWTExpiry: time.Hour}
-	mw := Middleware(cfg)
+	mw := Middleware(cfg, nil, nil)
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec := httptest.NewRecorder()
@@ -25,7 +28,7 @@ func TestMiddleware_NoAuthHeader(t *testing.T) {
 
 func TestMiddleware_InvalidPrefix(t *testing.T) {
 	cfg := &config.AuthConfig{JWTSecret: []byte("secret"), JWTExpiry: time.Hour}
-	mw := Middleware(cfg)
+	mw := Middleware(cfg, nil, nil)
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("Authorization", "Basic xyz")
@@ -36,7 +39,7 @@ func TestMiddleware_InvalidPrefix(t *testing.T) {
 
 func TestMiddleware_InvalidToken(t *testing.T) {
 	cfg := &config.AuthConfig{JWTSecret: []byte("secret"), JWTExpiry: time.Hour}
-	mw := Middleware(cfg)
+	mw := Middleware(cfg, nil, nil)
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("Authorization", "Bearer invalid.jwt.here")
@@ -45,10 +48,30 @@ func TestMiddleware_InvalidToken(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, rec.Code)
 }
 
+func TestOptional(t *testing.T) {
+	cfg := &config.AuthConfig{JWTSecret: []byte("secret"), JWTExpiry: time.Hour}
+	mw := Optional(Middleware(cfg, nil, nil))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := UserIDFromContext(r.Context())
+		assert.False(t, ok)
+		w.WriteHeader(200)
+	})
+
+	rec := httptest.NewRecorder()
+	mw(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer invalid.jwt.here")
+	rec = httptest.NewRecorder()
+	mw(next).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
 func TestMiddleware_ValidToken(t *testing.T) {
 	secret := []byte("test-secret")
 	cfg := &config.AuthConfig{JWTSecret: secret, JWTExpiry: time.Hour}
-	mw := Middleware(cfg)
+	mw := Middleware(cfg, nil, nil)
 	var capturedID uint
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		id, ok := UserIDFromContext(r.Context())
@@ -71,3 +94,145 @@ func TestMiddleware_ValidToken(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	assert.Equal(t, uint(7), capturedID)
 }
+
+type stubSessionValidator struct {
+	active map[uint]bool
+}
+
+func (s stubSessionValidator) IsSessionActive(sessionID uint) (bool, error) {
+	return s.active[sessionID], nil
+}
+
+func TestMiddleware_SessionChecks(t *testing.T) {
+	cfg := &config.AuthConfig{JWTSecret: []byte("test-secret"), JWTExpiry: time.Hour}
+	validator := stubSessionValidator{active: map[uint]bool{1: true, 2: false}}
+	mw := Middleware(cfg, validator, nil)
+
+	cases := []struct {
+		name      string
+		sessionID uint
+		want      int
+	}{
+		{name: "active session", sessionID: 1, want: http.StatusOK},
+		{name: "revoked session", sessionID: 2, want: http.StatusUnauthorized},
+		{name: "missing sid", sessionID: 0, want: http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var capturedSID uint
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedSID, _ = SessionIDFromContext(r.Context())
+				w.WriteHeader(200)
+			})
+			tokenStr, _, err := signAccessToken(cfg, 7, tc.sessionID, RoleUser)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tokenStr)
+			rec := httptest.NewRecorder()
+			mw(next).ServeHTTP(rec, req)
+			assert.Equal(t, tc.want, rec.Code)
+			if tc.want == http.StatusOK {
+				assert.Equal(t, tc.sessionID, capturedSID)
+			}
+		})
+	}
+}
+
+func rsaTestKey(t *testing.T, id string) config.JWTKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return config.JWTKey{ID: id, Algorithm: config.JWTAlgorithmRS256, Signer: k, Public: &k.PublicKey}
+}
+
+func ed25519TestKey(t *testing.T, id string) config.JWTKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return config.JWTKey{ID: id, Algorithm: config.JWTAlgorithmEdDSA, Signer: priv, Public: pub}
+}
+
+func serveWithToken(cfg *config.AuthConfig, tokenStr string) int {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenStr)
+	rec := httptest.NewRecorder()
+	Middleware(cfg, nil, nil)(next).ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestMiddleware_AsymmetricKeysSurviveRotation(t *testing.T) {
+	oldKey, newKey := rsaTestKey(t, "2026-04"), ed25519TestKey(t, "2026-10")
+	cfg := &config.AuthConfig{
+		JWTSecret: []byte("test-secret"), JWTExpiry: time.Hour,
+		JWTKeys: []config.JWTKey{oldKey}, JWTActiveKeyID: oldKey.ID,
+		JWTIssuer: "go-cafe", JWTAudience: "go-cafe-api",
+	}
+	before, _, err := signAccessToken(cfg, 7, 0, RoleUser)
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(before, &Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+	assert.Equal(t, oldKey.ID, parsed.Header["kid"])
+
+	// Rotate: the new key signs, the old key stays listed for verification.
+	cfg.JWTKeys = []config.JWTKey{newKey, {ID: oldKey.ID, Algorithm: oldKey.Algorithm, Public: oldKey.Public}}
+	cfg.JWTActiveKeyID = newKey.ID
+	after, _, err := signAccessToken(cfg, 7, 0, RoleUser)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serveWithToken(cfg, before))
+	assert.Equal(t, http.StatusOK, serveWithToken(cfg, after))
+
+	// Once the old key is dropped its tokens stop verifying.
+	cfg.JWTKeys = []config.JWTKey{newKey}
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(cfg, before))
+	assert.Equal(t, http.StatusOK, serveWithToken(cfg, after))
+}
+
+func TestMiddleware_PinsAlgorithmsAndValidatesIssuerAudience(t *testing.T) {
+	key := rsaTestKey(t, "k1")
+	cfg := &config.AuthConfig{
+		JWTSecret: []byte("test-secret"), JWTExpiry: time.Hour,
+		JWTKeys: []config.JWTKey{key}, JWTActiveKeyID: key.ID,
+		JWTIssuer: "go-cafe", JWTAudience: "go-cafe-api",
+	}
+	claims := func(iss, aud string) Claims {
+		return Claims{RegisteredClaims: jwt.RegisteredClaims{
+			Issuer: iss, Audience: jwt.ClaimStrings{aud}, Subject: "7",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}}
+	}
+	sign := func(method jwt.SigningMethod, kid string, c Claims, key any) string {
+		token := jwt.NewWithClaims(method, c)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+
+	assert.Equal(t, http.StatusOK, serveWithToken(cfg, sign(jwt.SigningMethodRS256, "k1", claims("go-cafe", "go-cafe-api"), key.Signer)))
+
+	// HS256 signed with the shared secret is refused once asymmetric keys are configured.
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(cfg, sign(jwt.SigningMethodHS256, "k1", claims("go-cafe", "go-cafe-api"), cfg.JWTSecret)))
+	// The kid's key type decides the algorithm: PS256 with the same RSA key is refused, as is a token without kid.
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(cfg, sign(jwt.SigningMethodPS256, "k1", claims("go-cafe", "go-cafe-api"), key.Signer)))
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(cfg, sign(jwt.SigningMethodRS256, "", claims("go-cafe", "go-cafe-api"), key.Signer)))
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(cfg, sign(jwt.SigningMethodRS256, "k1", claims("someone-else", "go-cafe-api"), key.Signer)))
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(cfg, sign(jwt.SigningMethodRS256, "k1", claims("go-cafe", "other-api"), key.Signer)))
+	none := sign(jwt.SigningMethodNone, "k1", claims("go-cafe", "go-cafe-api"), jwt.UnsafeAllowNoneSignatureType)
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(cfg, none))
+}
+
+func TestMiddleware_HS256RejectsOtherAlgorithms(t *testing.T) {
+	cfg := &config.AuthConfig{JWTSecret: []byte("test-secret"), JWTExpiry: time.Hour}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.RegisteredClaims{
+		Subject:   "7",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	tokenStr, err := token.SignedString(cfg.JWTSecret)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(cfg, tokenStr))
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcStateCookie   = "gocafe_oidc_state"
+	oidcStateAudience = "oidc-state"
+	oidcStateTTL      = 10 * time.Minute
+)
+
+// OIDCLogin is implemented by oidc service.
+type OIDCLogin interface {
+	Providers() []string
+	AuthCodeURL(ctx context.Context, provider, state, nonce, verifier string) (string, error)
+	Authenticate(ctx context.Context, provider, code, nonce, verifier string) (userID uint, err error)
+}
+
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// oidcStateClaims carry the per-login secrets between start and callback in a signed, short-lived cookie.
+type oidcStateClaims struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// OIDCProvidersHandler godoc
+// @Summary List OIDC providers
+// @Description Returns the names of configured external identity providers for sign-in buttons.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} OIDCProvidersResponse
+// @Router /auth/oidc/providers [get]
+func (h *Handler) OIDCProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	providers := []string{}
+	if h.OIDC != nil {
+		providers = append(providers, h.OIDC.Providers()...)
+	}
+	_ = json.NewEncoder(w).Encode(OIDCProvidersResponse{Providers: providers})
+}
+
+// OIDCStartHandler godoc
+// @Summary Start OIDC sign-in
+// @Description Redirects the browser to the identity provider. Open this URL directly in the browser (not via fetch).
+// @Tags auth
+// @Param provider path string true "Provider name"
+// @Success 302 {string} string
+// @Failure 404 {string} string
+// @Failure 502 {string} string
+// @Router /auth/oidc/{provider}/start [get]
+func (h *Handler) OIDCStartHandler(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	if h.OIDC == nil {
+		http.Error(w, ErrUnknownProvider.Error(), http.StatusNotFound)
+		return
+	}
+	var secrets [3]string
+	for i := range secrets {
+		v, err := GenerateOpaqueToken()
+		if err != nil {
+			http.Error(w, "Failed to start sign-in", http.StatusInternalServerError)
+			return
+		}
+		secrets[i] = v
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+	authURL, err := h.OIDC.AuthCodeURL(r.Context(), provider, state, nonce, verifier)
+	if err != nil {
+		if errors.Is(err, ErrUnknownProvider) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("oidc start %s: %v", provider, err)
+		http.Error(w, "Identity provider unavailable", http.StatusBadGateway)
+		return
+	}
+	now := time.Now()
+	cookieValue, err := jwt.NewWithClaims(jwt.SigningMethodHS256, oidcStateClaims{
+		Provider: provider,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{oidcStateAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(oidcStateTTL)),
+		},
+	}).SignedString(h.AuthCfg.JWTSecret)
+	if err != nil {
+		http.Error(w, "Failed to start sign-in", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, h.oidcStateCookie(r, cookieValue, int(oidcStateTTL.Seconds())))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallbackHandler godoc
+// @Summary Complete OIDC sign-in
+// @Description Provider redirect target. Links the identity to a user (by verified email on first sign-in, creating a password-less account if needed) and redirects to OIDC_SUCCESS_URL with token, expires_at, refresh_token and refresh_token_expires_at in the URL fragment, or error on failure.
+// @Tags auth
+// @Param provider path string true "Provider name"
+// @Param code query string false "Authorization code"
+// @Param state query string true "State"
+// @Success 302 {string} string
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *Handler) OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	cookie, err := r.Cookie(oidcStateCookie)
+	// The state cookie is single-use whatever the outcome.
+	http.SetCookie(w, h.oidcStateCookie(r, "", -1))
+	if err != nil || h.OIDC == nil {
+		h.redirectOIDCResult(w, r, url.Values{"error": {"invalid_state"}})
+		return
+	}
+	var claims oidcStateClaims
+	_, err = jwt.ParseWithClaims(cookie.Value, &claims, func(t *jwt.Token) (any, error) {
+		return h.AuthCfg.JWTSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(oidcStateAudience), jwt.WithExpirationRequired())
+	query := r.URL.Query()
+	if err != nil || claims.Provider != provider || claims.State == "" || claims.State != query.Get("state") {
+		h.redirectOIDCResult(w, r, url.Values{"error": {"invalid_state"}})
+		return
+	}
+	if providerErr := query.Get("error"); providerErr != "" {
+		h.redirectOIDCResult(w, r, url.Values{"error": {"access_denied"}})
+		return
+	}
+	userID, err := h.OIDC.Authenticate(r.Context(), provider, query.Get("code"), claims.Nonce, claims.Verifier)
+	if err != nil {
+		code := "login_failed"
+		if errors.Is(err, ErrExternalEmailNotVerified) {
+			code = "email_not_verified"
+		} else {
+			log.Printf("oidc callback %s: %v", provider, err)
+		}
+		h.redirectOIDCResult(w, r, url.Values{"error": {code}})
+		return
+	}
+	resp, err := h.issueTokens(r, userID)
+	if err != nil {
+		h.redirectOIDCResult(w, r, url.Values{"error": {"login_failed"}})
+		return
+	}
+	h.redirectOIDCResult(w, r, url.Values{
+		"token":                    {resp.Token},
+		"expires_at":               {resp.ExpiresAt},
+		"refresh_token":            {resp.RefreshToken},
+		"refresh_token_expires_at": {resp.RefreshTokenExpiresAt},
+	})
+}
+
+// redirectOIDCResult sends the browser to the frontend with the result in the fragment, which is never sent to servers.
+func (h *Handler) redirectOIDCResult(w http.ResponseWriter, r *http.Request, values url.Values) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, h.AuthCfg.OIDCSuccessURL+"#"+values.Encode(), http.StatusFound)
+}
+
+// oidcStateCookie scopes the cookie to this provider's /start and /callback paths.
+func (h *Handler) oidcStateCookie(r *http.Request, value string, maxAge int) *http.Cookie {
+	path := r.URL.Path
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		path = path[:i]
+	}
+	return &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.AuthCfg.OIDCCallbackBaseURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -1,9 +1,18 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 const bcryptCost = 12
 
+// MinPasswordLength is the minimum length accepted for new passwords.
+const MinPasswordLength = 8
+
+var ErrWeakPassword = errors.New("password must be at least 8 characters")
+
 func HashPassword(password string) (string, error) {
 	b, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
 	if err != nil {
@@ -16,3 +25,11 @@ func CheckPassword(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// ValidatePasswordStrength enforces the password policy for newly chosen passwords.
+func ValidatePasswordStrength(password string) error {
+	if len(password) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
@@ -27,3 +27,8 @@ func TestHashPassword_DifferentEachTime(t *testing.T) {
 	assert.True(t, CheckPassword("same", h1))
 	assert.True(t, CheckPassword("same", h2))
 }
+
+func TestValidatePasswordStrength(t *testing.T) {
+	assert.ErrorIs(t, ValidatePasswordStrength("short"), ErrWeakPassword)
+	assert.NoError(t, ValidatePasswordStrength("long-enough"))
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// CanModerate reports whether role may work the moderation queue and see hidden content.
+func CanModerate(role string) bool {
+	return role == RoleModerator || role == RoleAdmin
+}
+
+// CanViewHidden reports whether the caller in ctx may see moderator-hidden content owned by ownerID: the owner
+// themselves, or a moderator.
+func CanViewHidden(ctx context.Context, ownerID uint) bool {
+	if userID, ok := UserIDFromContext(ctx); ok && userID == ownerID {
+		return true
+	}
+	role, _ := RoleFromContext(ctx)
+	return CanModerate(role)
+}
+
+// RequireRole allows the request through only when the authenticated role is one of roles.
+// It must run after Middleware so the role is present in the request context.
+func RequireRole(roles ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := RoleFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireRole(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
+	guard := RequireRole(RoleAdmin)
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		want int
+	}{
+		{name: "no role", ctx: context.Background(), want: http.StatusUnauthorized},
+		{name: "user", ctx: context.WithValue(context.Background(), RoleKey, RoleUser), want: http.StatusForbidden},
+		{name: "admin", ctx: context.WithValue(context.Background(), RoleKey, RoleAdmin), want: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(tc.ctx)
+			rec := httptest.NewRecorder()
+			guard(next).ServeHTTP(rec, req)
+			assert.Equal(t, tc.want, rec.Code)
+		})
+	}
+}
+
+func TestMiddleware_RoleFromClaims(t *testing.T) {
+	cfg := &config.AuthConfig{JWTSecret: []byte("test-secret"), JWTExpiry: time.Hour}
+	chain := Middleware(cfg, nil, nil)(RequireRole(RoleModerator, RoleAdmin)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) }),
+	))
+
+	for role, want := range map[string]int{RoleModerator: http.StatusOK, RoleUser: http.StatusForbidden, "": http.StatusForbidden} {
+		token, _, err := signAccessToken(cfg, 1, 1, role)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		chain.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Code, "role %q", role)
+	}
+}
+
+func TestIsValidRole(t *testing.T) {
+	assert.True(t, IsValidRole(RoleAdmin))
+	assert.False(t, IsValidRole("superuser"))
+}
+
+func TestCanViewHidden(t *testing.T) {
+	owner := context.WithValue(context.WithValue(context.Background(), UserIDKey, uint(7)), RoleKey, RoleUser)
+	other := context.WithValue(context.WithValue(context.Background(), UserIDKey, uint(8)), RoleKey, RoleUser)
+	moderator := context.WithValue(context.WithValue(context.Background(), UserIDKey, uint(9)), RoleKey, RoleModerator)
+
+	assert.True(t, CanViewHidden(owner, 7))
+	assert.False(t, CanViewHidden(other, 7))
+	assert.True(t, CanViewHidden(moderator, 7))
+	assert.False(t, CanViewHidden(context.Background(), 7))
+}
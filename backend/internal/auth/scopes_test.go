@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubTokenAuthenticator map[string]*TokenPrincipal
+
+func (s stubTokenAuthenticator) AuthenticateToken(_ context.Context, raw string) (*TokenPrincipal, error) {
+	if raw == PersonalTokenPrefix+"broken" {
+		return nil, errors.New("db down")
+	}
+	if p, ok := s[raw]; ok {
+		return p, nil
+	}
+	return nil, ErrInvalidToken
+}
+
+func TestMiddleware_PersonalAccessTokenScopes(t *testing.T) {
+	cfg := &config.AuthConfig{JWTSecret: []byte("test-secret"), JWTExpiry: time.Hour}
+	tokens := stubTokenAuthenticator{
+		PersonalTokenPrefix + "reader": {UserID: 7, Scopes: []string{ScopeCafesRead}},
+	}
+	mw := Middleware(cfg, nil, tokens)
+	var gotScopes []string
+	var gotUser uint
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotScopes, _ = ScopesFromContext(r.Context())
+		gotUser, _ = UserIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(handler http.Handler, bearer string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(WithScope(ScopeCafesRead, mw)(next), PersonalTokenPrefix+"reader"))
+	assert.Equal(t, []string{ScopeCafesRead}, gotScopes)
+	assert.Equal(t, uint(7), gotUser)
+
+	assert.Equal(t, http.StatusForbidden, serve(WithScope(ScopeCafesWrite, mw)(next), PersonalTokenPrefix+"reader"))
+	assert.Equal(t, http.StatusForbidden, serve(mw(next), PersonalTokenPrefix+"reader"), "routes without a scope refuse tokens")
+	assert.Equal(t, http.StatusUnauthorized, serve(WithScope(ScopeCafesRead, mw)(next), PersonalTokenPrefix+"unknown"))
+	assert.Equal(t, http.StatusInternalServerError, serve(WithScope(ScopeCafesRead, mw)(next), PersonalTokenPrefix+"broken"))
+	assert.Equal(t, http.StatusUnauthorized, serve(WithScope(ScopeCafesRead, Middleware(cfg, nil, nil))(next), PersonalTokenPrefix+"reader"))
+
+	// JWT sessions are not limited by scopes.
+	jwtToken, _, err := signAccessToken(cfg, 7, 0, RoleUser)
+	assert.NoError(t, err)
+	gotScopes = nil
+	assert.Equal(t, http.StatusOK, serve(WithScope(ScopeCafesWrite, mw)(next), jwtToken))
+	assert.Nil(t, gotScopes)
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+)
+
+// Claims are the access token claims. SessionID links the token to a revocable server-side session.
+type Claims struct {
+	SessionID uint   `json:"sid,omitempty"`
+	Role      string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func signAccessToken(cfg *config.AuthConfig, userID, sessionID uint, role string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(cfg.JWTExpiry)
+	claims := Claims{
+		SessionID: sessionID,
+		Role:      role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.JWTIssuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	if cfg.JWTAudience != "" {
+		claims.Audience = jwt.ClaimStrings{cfg.JWTAudience}
+	}
+	token, key, err := newAccessToken(cfg, claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
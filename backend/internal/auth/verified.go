@@ -0,0 +1,32 @@
+package auth
+
+import "net/http"
+
+// VerificationChecker is implemented by user service.
+type VerificationChecker interface {
+	IsEmailVerified(userID uint) (bool, error)
+}
+
+// RequireVerifiedEmail rejects requests from users who have not verified their email with 403.
+// It must run after Middleware so the user ID is present in the request context.
+func RequireVerifiedEmail(checker VerificationChecker) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			verified, err := checker.IsEmailVerified(userID)
+			if err != nil {
+				http.Error(w, "Failed to check email verification", http.StatusInternalServerError)
+				return
+			}
+			if !verified {
+				http.Error(w, "Email verification required", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubVerificationChecker struct {
+	verified bool
+	err      error
+}
+
+func (s stubVerificationChecker) IsEmailVerified(userID uint) (bool, error) { return s.verified, s.err }
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
+	withUser := context.WithValue(context.Background(), UserIDKey, uint(1))
+
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		checker stubVerificationChecker
+		want    int
+	}{
+		{name: "no user", ctx: context.Background(), want: http.StatusUnauthorized},
+		{name: "unverified", ctx: withUser, want: http.StatusForbidden},
+		{name: "verified", ctx: withUser, checker: stubVerificationChecker{verified: true}, want: http.StatusOK},
+		{name: "lookup error", ctx: withUser, checker: stubVerificationChecker{err: errors.New("db down")}, want: http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(tc.ctx)
+			rec := httptest.NewRecorder()
+			RequireVerifiedEmail(tc.checker)(next).ServeHTTP(rec, req)
+			assert.Equal(t, tc.want, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_PutGetDelete(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "photos/2026/ab.jpg", []byte("jpeg"), "image/jpeg"))
+	body, err := store.Get(ctx, "photos/2026/ab.jpg")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "jpeg", string(data))
+
+	require.NoError(t, store.Delete(ctx, "photos/2026/ab.jpg"))
+	require.NoError(t, store.Delete(ctx, "photos/2026/ab.jpg"))
+	_, err = store.Get(ctx, "photos/2026/ab.jpg")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalStore_RejectsKeysOutsideRoot(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, key := range []string{"", "../escape.jpg", "/etc/passwd", "photos/../../escape.jpg"} {
+		assert.ErrorIs(t, store.Put(context.Background(), key, []byte("x"), "image/jpeg"), ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package blob
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_S3Store runs against the bucket configured by PHOTO_S3_*, e.g. the MinIO service from
+// docker compose --profile minio.
+func TestIntegration_S3Store(t *testing.T) {
+	_ = godotenv.Load()
+	cfg, err := appconfig.LoadPhotoConfig()
+	if err != nil || cfg.Store != appconfig.PhotoStoreS3 {
+		t.Skipf("skip integration: PHOTO_STORE is not s3 (err: %v)", err)
+		return
+	}
+	store, err := NewS3Store(S3Config{
+		Endpoint:        cfg.S3Endpoint,
+		Region:          cfg.S3Region,
+		Bucket:          cfg.S3Bucket,
+		AccessKeyID:     cfg.S3AccessKeyID,
+		SecretAccessKey: cfg.S3SecretAccessKey,
+		PathStyle:       cfg.S3PathStyle,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	key := "integration/" + strconv.FormatInt(time.Now().UnixNano(), 36) + " test+photo.jpg"
+	data := []byte("not really a jpeg")
+
+	require.NoError(t, store.Put(ctx, key, data, "image/jpeg"))
+	body, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	got, err := io.ReadAll(body)
+	require.NoError(t, body.Close())
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, store.Delete(ctx, key), "deleting a missing key succeeds")
+}
@@ -0,0 +1,6 @@
+package blob
+
+import "errors"
+
+var ErrNotFound = errors.New("blob not found")
+var ErrInvalidKey = errors.New("invalid blob key")
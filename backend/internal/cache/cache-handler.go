@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+)
+
+type Handler struct {
+	Caches []*Cache
+}
+
+// RegisterRoutes registers the admin-only cache metrics route. Nil caches (disabled by config) are skipped.
+func RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler, caches ...*Cache) {
+	h := &Handler{}
+	for _, c := range caches {
+		if c != nil {
+			h.Caches = append(h.Caches, c)
+		}
+	}
+	r.With(authMiddleware, auth.RequireRole(auth.RoleAdmin)).Get("/admin/cache/stats", h.StatsHandler)
+}
+
+// StatsHandler godoc
+// @Summary Cache metrics
+// @Description Returns hit, miss, coalesced, stale and error counters for each upstream response cache since start-up. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} Stats
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Router /admin/cache/stats [get]
+func (h *Handler) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats := make([]Stats, 0, len(h.Caches))
+	for _, c := range h.Caches {
+		stats = append(stats, c.Stats())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(stats)
+}
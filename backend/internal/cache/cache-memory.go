@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+)
+
+// DefaultMaxEntries is the MemoryStore capacity when none is configured.
+const DefaultMaxEntries = 1000
+
+// MemoryStore is an in-process LRU Storage for single-instance deployments. Entries are lost on restart.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is most recently used; values are *models.CacheEntry
+	entries    map[string]*list.Element
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryStore{maxEntries: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (m *MemoryStore) Get(ctx context.Context, key string) (*models.CacheEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	m.order.MoveToFront(el)
+	entry := *el.Value.(*models.CacheEntry)
+	return &entry, nil
+}
+
+func (m *MemoryStore) Set(ctx context.Context, entry models.CacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[entry.Key]; ok {
+		el.Value = &entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+	m.entries[entry.Key] = m.order.PushFront(&entry)
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*models.CacheEntry).Key)
+	}
+	return nil
+}
+
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sweepEvery is how many writes pass between deletions of entries past their stale window.
+const sweepEvery = 256
+
+// Storage holds cache entries. Get returns nil when the key is absent.
+type Storage interface {
+	Get(ctx context.Context, key string) (*models.CacheEntry, error)
+	Set(ctx context.Context, entry models.CacheEntry) error
+}
+
+// Repository is the Postgres-backed Storage, shared by every API instance.
+type Repository struct {
+	db     *gorm.DB
+	writes atomic.Int64
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Get(ctx context.Context, key string) (*models.CacheEntry, error) {
+	var entry models.CacheEntry
+	err := r.db.WithContext(ctx).Where("key = ? AND stale_until > ?", key, time.Now()).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *Repository) Set(ctx context.Context, entry models.CacheEntry) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires_at", "stale_until", "updated_at"}),
+	}).Create(&entry).Error
+	if err != nil {
+		return err
+	}
+	if r.writes.Add(1)%sweepEvery == 0 {
+		return r.db.WithContext(ctx).Where("stale_until <= ?", time.Now()).Delete(&models.CacheEntry{}).Error
+	}
+	return nil
+}
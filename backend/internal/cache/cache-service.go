@@ -0,0 +1,132 @@
+// Package cache is a read-through cache for upstream API responses: fresh entries are served until their TTL,
+// concurrent misses for one key share a single upstream call, and expired entries are served stale when the
+// upstream fails.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"golang.org/x/sync/singleflight"
+)
+
+// Config sets how long entries are fresh (TTL) and how much longer they may be served when the upstream fails (StaleTTL).
+type Config struct {
+	TTL      time.Duration
+	StaleTTL time.Duration
+}
+
+// Stats are the counters of one cache since start-up.
+type Stats struct {
+	Name        string `json:"name"`
+	Hits        int64  `json:"hits"`
+	Misses      int64  `json:"misses"`
+	Coalesced   int64  `json:"coalesced"`    // misses whose upstream request was shared with concurrent callers
+	StaleServed int64  `json:"stale_served"` // upstream failures answered from an expired entry
+	LoadErrors  int64  `json:"load_errors"`  // upstream failures, whether or not a stale entry covered them
+	StoreErrors int64  `json:"store_errors"` // storage reads and writes that failed; the request still went upstream
+}
+
+// Cache is one named cache over a Storage. Keys are prefixed with the name, so caches can share a store.
+// A nil *Cache is valid and loads straight from the upstream.
+type Cache struct {
+	name  string
+	store Storage
+	cfg   Config
+	group singleflight.Group
+	now   func() time.Time
+
+	hits, misses, coalesced, staleServed, loadErrors, storeErrors atomic.Int64
+}
+
+func New(name string, store Storage, cfg Config) *Cache {
+	return &Cache{name: name, store: store, cfg: cfg, now: time.Now}
+}
+
+func (c *Cache) Name() string {
+	return c.name
+}
+
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Name:        c.name,
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Coalesced:   c.coalesced.Load(),
+		StaleServed: c.staleServed.Load(),
+		LoadErrors:  c.loadErrors.Load(),
+		StoreErrors: c.storeErrors.Load(),
+	}
+}
+
+// Load returns the cached value for key, calling load on a miss and caching its result. Values round-trip
+// through JSON, so T must marshal losslessly. Errors from load are never cached.
+func Load[T any](ctx context.Context, c *Cache, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if c == nil {
+		return load(ctx)
+	}
+	key = c.name + ":" + key
+
+	entry, err := c.store.Get(ctx, key)
+	if err != nil {
+		c.storeErrors.Add(1)
+		entry = nil
+	}
+	now := c.now()
+	if entry != nil && now.Before(entry.ExpiresAt) {
+		var value T
+		if json.Unmarshal(entry.Value, &value) == nil {
+			c.hits.Add(1)
+			return value, nil
+		}
+	}
+	c.misses.Add(1)
+
+	// The shared call must not be cancelled by whichever caller happened to start it.
+	raw, err, shared := c.group.Do(key, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		stored := c.now()
+		if err := c.store.Set(ctx, models.CacheEntry{
+			Key:        key,
+			Value:      raw,
+			ExpiresAt:  stored.Add(c.cfg.TTL),
+			StaleUntil: stored.Add(c.cfg.TTL + c.cfg.StaleTTL),
+		}); err != nil {
+			c.storeErrors.Add(1)
+		}
+		return raw, nil
+	})
+	if shared {
+		c.coalesced.Add(1)
+	}
+	if err != nil {
+		c.loadErrors.Add(1)
+		if entry != nil && now.Before(entry.StaleUntil) {
+			var value T
+			if json.Unmarshal(entry.Value, &value) == nil {
+				c.staleServed.Add(1)
+				return value, nil
+			}
+		}
+		return zero, err
+	}
+
+	// Each caller decodes its own copy, so callers sharing a load cannot alias each other's results.
+	var value T
+	if err := json.Unmarshal(raw.([]byte), &value); err != nil {
+		return zero, err
+	}
+	return value, nil
+}
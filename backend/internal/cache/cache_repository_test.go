@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func newCacheTestRepo(t *testing.T) (*Repository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "gocafe_"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.CacheEntry{}))
+	return NewRepository(db), db
+}
+
+func TestRepository_SetUpsertsAndGetSkipsPastStaleWindow(t *testing.T) {
+	repo, db := newCacheTestRepo(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, repo.Set(ctx, models.CacheEntry{Key: "places:a", Value: []byte(`"v1"`), ExpiresAt: now.Add(time.Minute), StaleUntil: now.Add(time.Hour)}))
+	require.NoError(t, repo.Set(ctx, models.CacheEntry{Key: "places:a", Value: []byte(`"v2"`), ExpiresAt: now.Add(time.Minute), StaleUntil: now.Add(time.Hour)}))
+	require.NoError(t, repo.Set(ctx, models.CacheEntry{Key: "places:gone", Value: []byte(`"old"`), ExpiresAt: now.Add(-2 * time.Hour), StaleUntil: now.Add(-time.Hour)}))
+
+	entry, err := repo.Get(ctx, "places:a")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, `"v2"`, string(entry.Value))
+
+	var count int64
+	require.NoError(t, db.Model(&models.CacheEntry{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	entry, err = repo.Get(ctx, "places:gone")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	entry, err = repo.Get(ctx, "places:missing")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
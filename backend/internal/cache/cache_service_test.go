@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct{ at time.Time }
+
+func (c *clock) now() time.Time { return c.at }
+
+func newTestCache(t *testing.T) (*Cache, *clock) {
+	t.Helper()
+	clk := &clock{at: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	c := New("places", NewMemoryStore(10), Config{TTL: time.Minute, StaleTTL: time.Hour})
+	c.now = clk.now
+	return c, clk
+}
+
+func TestLoad_HitsUntilTTLThenReloads(t *testing.T) {
+	c, clk := newTestCache(t)
+	calls := 0
+	load := func(ctx context.Context) ([]string, error) {
+		calls++
+		return []string{"Apiary", "Nylon"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := Load(context.Background(), c, "sg", load)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Apiary", "Nylon"}, got)
+	}
+	assert.Equal(t, 1, calls)
+
+	clk.at = clk.at.Add(2 * time.Minute)
+	_, err := Load(context.Background(), c, "sg", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, Stats{Name: "places", Hits: 2, Misses: 2}, c.Stats())
+}
+
+func TestLoad_ServesStaleOnUpstreamErrorWithinStaleWindow(t *testing.T) {
+	c, clk := newTestCache(t)
+	_, err := Load(context.Background(), c, "sg", func(ctx context.Context) (string, error) { return "fresh", nil })
+	require.NoError(t, err)
+
+	down := func(ctx context.Context) (string, error) { return "", errors.New("geoapify 503") }
+	clk.at = clk.at.Add(30 * time.Minute)
+	got, err := Load(context.Background(), c, "sg", down)
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", got)
+
+	clk.at = clk.at.Add(2 * time.Hour)
+	_, err = Load(context.Background(), c, "sg", down)
+	assert.EqualError(t, err, "geoapify 503")
+
+	// Errors are never cached: the next call goes upstream again.
+	got, err = Load(context.Background(), c, "sg", func(ctx context.Context) (string, error) { return "back", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "back", got)
+
+	stats := c.Stats()
+	assert.Equal(t, int64(1), stats.StaleServed)
+	assert.Equal(t, int64(2), stats.LoadErrors)
+}
+
+func TestLoad_CoalescesConcurrentMisses(t *testing.T) {
+	c, _ := newTestCache(t)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = Load(context.Background(), c, "k", load)
+		}(i)
+	}
+	// Let every caller reach the shared call before the upstream answers.
+	require.Eventually(t, func() bool { return c.Stats().Misses == 5 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, []int{42, 42, 42, 42, 42}, results)
+}
+
+func TestLoad_NilCacheLoadsDirectly(t *testing.T) {
+	calls := 0
+	for i := 0; i < 2; i++ {
+		got, err := Load(context.Background(), nil, "k", func(ctx context.Context) (string, error) {
+			calls++
+			return "v", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "v", got)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := New("lru", NewMemoryStore(2), Config{TTL: time.Hour})
+	calls := map[string]int{}
+	load := func(key string) func(ctx context.Context) (string, error) {
+		return func(ctx context.Context) (string, error) {
+			calls[key]++
+			return key, nil
+		}
+	}
+	ctx := context.Background()
+	_, _ = Load(ctx, c, "a", load("a"))
+	_, _ = Load(ctx, c, "b", load("b"))
+	_, _ = Load(ctx, c, "a", load("a")) // a is now most recently used
+	_, _ = Load(ctx, c, "c", load("c")) // evicts b
+	_, _ = Load(ctx, c, "a", load("a"))
+	_, _ = Load(ctx, c, "b", load("b"))
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 1}, calls)
+}
@@ -0,0 +1,26 @@
+package cafelisting
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/cache"
+)
+
+// CachedAutocompleteProvider caches suggestions per normalised text and limit, so retyping a prefix is free.
+type CachedAutocompleteProvider struct {
+	next  AddressAutocompleteProvider
+	cache *cache.Cache
+}
+
+func NewCachedAutocompleteProvider(next AddressAutocompleteProvider, c *cache.Cache) *CachedAutocompleteProvider {
+	return &CachedAutocompleteProvider{next: next, cache: c}
+}
+
+func (p *CachedAutocompleteProvider) Autocomplete(ctx context.Context, text string, limit int) ([]AddressSuggestion, error) {
+	key := strings.ToLower(strings.Join(strings.Fields(text), " ")) + "|" + strconv.Itoa(limit)
+	return cache.Load(ctx, p.cache, key, func(ctx context.Context) ([]AddressSuggestion, error) {
+		return p.next.Autocomplete(ctx, text, limit)
+	})
+}
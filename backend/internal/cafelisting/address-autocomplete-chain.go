@@ -0,0 +1,30 @@
+package cafelisting
+
+import (
+	"context"
+	"errors"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
+)
+
+// AutocompleteChain asks providers in order and moves to the next only when one is rate limited, failing, or
+// unreachable (see upstream.Retryable).
+type AutocompleteChain struct {
+	providers []AddressAutocompleteProvider
+}
+
+func NewAutocompleteChain(providers ...AddressAutocompleteProvider) *AutocompleteChain {
+	return &AutocompleteChain{providers: providers}
+}
+
+func (c *AutocompleteChain) Autocomplete(ctx context.Context, text string, limit int) ([]AddressSuggestion, error) {
+	err := errors.New("no address autocomplete provider is configured")
+	for _, provider := range c.providers {
+		var suggestions []AddressSuggestion
+		suggestions, err = provider.Autocomplete(ctx, text, limit)
+		if err == nil || !upstream.Retryable(ctx, err) {
+			return suggestions, err
+		}
+	}
+	return nil, err
+}
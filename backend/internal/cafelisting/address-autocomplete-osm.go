@@ -0,0 +1,128 @@
+package cafelisting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
+)
+
+type nominatimPlace struct {
+	Lat         string           `json:"lat"`
+	Lon         string           `json:"lon"`
+	Name        string           `json:"name"`
+	DisplayName string           `json:"display_name"`
+	Address     nominatimAddress `json:"address"`
+}
+
+type nominatimAddress struct {
+	HouseNumber string `json:"house_number"`
+	Road        string `json:"road"`
+	City        string `json:"city"`
+	Town        string `json:"town"`
+	Village     string `json:"village"`
+	Postcode    string `json:"postcode"`
+	Country     string `json:"country"`
+}
+
+// NominatimClient suggests addresses from an OpenStreetMap Nominatim instance. The public server's usage policy
+// forbids as-you-type autocomplete, so point baseURL at a self-hosted instance.
+type NominatimClient struct {
+	baseURL    string
+	userAgent  string
+	httpClient *upstream.Client
+}
+
+func NewNominatimClient(baseURL, userAgent string) *NominatimClient {
+	return &NominatimClient{
+		baseURL:    strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		userAgent:  strings.TrimSpace(userAgent),
+		httpClient: upstream.Shared(upstream.Nominatim, upstream.DefaultPolicy),
+	}
+}
+
+func (c *NominatimClient) Autocomplete(ctx context.Context, text string, limit int) ([]AddressSuggestion, error) {
+	query := strings.TrimSpace(text)
+	if query == "" {
+		return []AddressSuggestion{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultAutocompleteLimit
+	}
+	if limit > maxAutocompleteLimit {
+		limit = maxAutocompleteLimit
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "jsonv2")
+	params.Set("addressdetails", "1")
+	params.Set("limit", strconv.Itoa(limit))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstream.NewStatusError("nominatim autocomplete", resp)
+	}
+
+	var payload []nominatimPlace
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]AddressSuggestion, 0, len(payload))
+	for _, place := range payload {
+		suggestions = append(suggestions, normalizeNominatimPlace(place))
+	}
+	return suggestions, nil
+}
+
+func normalizeNominatimPlace(place nominatimPlace) AddressSuggestion {
+	lat, _ := strconv.ParseFloat(place.Lat, 64)
+	lon, _ := strconv.ParseFloat(place.Lon, 64)
+	address := place.Address
+	city := address.City
+	if city == "" {
+		city = address.Town
+	}
+	if city == "" {
+		city = address.Village
+	}
+
+	line1 := strings.Join(strings.Fields(address.HouseNumber+" "+address.Road), " ")
+	if place.Name != "" {
+		line1 = place.Name
+	}
+	var line2Parts []string
+	for _, part := range []string{city, address.Postcode, address.Country} {
+		if part != "" {
+			line2Parts = append(line2Parts, part)
+		}
+	}
+
+	return AddressSuggestion{
+		Name:         place.Name,
+		Formatted:    place.DisplayName,
+		AddressLine1: line1,
+		AddressLine2: strings.Join(line2Parts, ", "),
+		City:         city,
+		Postcode:     address.Postcode,
+		Country:      address.Country,
+		Lat:          lat,
+		Lon:          lon,
+	}
+}
@@ -4,13 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
-	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
 )
 
 const (
@@ -45,16 +45,14 @@ type geoapifyResponse struct {
 type GeoapifyClient struct {
 	apiKey     string
 	baseURL    string
-	httpClient *http.Client
+	httpClient *upstream.Client
 }
 
 func NewGeoapifyClient(apiKey string) *GeoapifyClient {
 	return &GeoapifyClient{
-		apiKey:  strings.TrimSpace(apiKey),
-		baseURL: "https://api.geoapify.com/v1/geocode/autocomplete",
-		httpClient: &http.Client{
-			Timeout: 8 * time.Second,
-		},
+		apiKey:     strings.TrimSpace(apiKey),
+		baseURL:    "https://api.geoapify.com/v1/geocode/autocomplete",
+		httpClient: upstream.Shared(upstream.Geoapify, upstream.DefaultPolicy),
 	}
 }
 
@@ -99,8 +97,7 @@ func (c *GeoapifyClient) Autocomplete(ctx context.Context, text string, limit in
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return nil, fmt.Errorf("geoapify request failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, upstream.NewStatusError("geoapify autocomplete", resp)
 	}
 
 	var payload geoapifyResponse
@@ -0,0 +1,80 @@
+package cafelisting
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	AmenityWiFi                 = "wifi"
+	AmenityOutlets              = "outlets"
+	AmenityLaptopFriendly       = "laptop_friendly"
+	AmenityOatMilk              = "oat_milk"
+	AmenityVeganOptions         = "vegan_options"
+	AmenityOutdoorSeating       = "outdoor_seating"
+	AmenityPetFriendly          = "pet_friendly"
+	AmenityWheelchairAccessible = "wheelchair_accessible"
+	AmenityTakeaway             = "takeaway"
+	AmenityAirConditioning      = "air_conditioning"
+)
+
+// Amenities lists every amenity a listing may have, in display order.
+var Amenities = []string{
+	AmenityWiFi,
+	AmenityOutlets,
+	AmenityLaptopFriendly,
+	AmenityOatMilk,
+	AmenityVeganOptions,
+	AmenityOutdoorSeating,
+	AmenityPetFriendly,
+	AmenityWheelchairAccessible,
+	AmenityTakeaway,
+	AmenityAirConditioning,
+}
+
+const (
+	MinPriceLevel = 1
+	MaxPriceLevel = 4
+)
+
+// normalizeAmenities lower-cases, de-duplicates and sorts amenities, rejecting any outside Amenities.
+// An empty set is nil, so it is stored as NULL.
+func normalizeAmenities(input []string) ([]string, error) {
+	var out []string
+	for _, raw := range input {
+		amenity := strings.ToLower(strings.TrimSpace(raw))
+		if !slices.Contains(Amenities, amenity) {
+			return nil, ErrInvalidAmenity
+		}
+		if !slices.Contains(out, amenity) {
+			out = append(out, amenity)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+func validPriceLevel(level *int) bool {
+	return level == nil || (*level >= MinPriceLevel && *level <= MaxPriceLevel)
+}
+
+// parseAmenitiesQuery parses "wifi,outlets".
+func parseAmenitiesQuery(raw string) ([]string, error) {
+	return normalizeAmenities(strings.Split(raw, ","))
+}
+
+// parsePriceLevelsQuery parses "1,2".
+func parsePriceLevelsQuery(raw string) ([]int, error) {
+	var levels []int
+	for _, part := range strings.Split(raw, ",") {
+		level, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || !validPriceLevel(&level) {
+			return nil, ErrInvalidPriceLevel
+		}
+		if !slices.Contains(levels, level) {
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
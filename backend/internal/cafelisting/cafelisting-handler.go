@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
 	"gorm.io/gorm"
 )
 
@@ -18,11 +22,14 @@ type Handler struct {
 	Autocomplete AddressAutocompleteProvider
 }
 
-// RegisterRoutes registers cafe listing routes. Pass authMiddleware for protected routes (required for create/update/delete and /me).
+// RegisterRoutes registers cafe listing routes. Pass authMiddleware for protected routes (required for create/update/delete and /me);
+// personal access tokens need cafes:read or cafes:write for them.
+// writeMiddleware runs after authMiddleware on create/update routes (e.g. email verification).
 func RegisterRoutes(
 	r chi.Router,
 	service *Service,
 	authMiddleware func(http.Handler) http.Handler,
+	writeMiddleware func(http.Handler) http.Handler,
 	autocompleteProvider AddressAutocompleteProvider,
 ) {
 	if autocompleteProvider == nil {
@@ -32,25 +39,25 @@ func RegisterRoutes(
 		Service:      service,
 		Autocomplete: autocompleteProvider,
 	}
+	// Protected routes also accept personal access tokens carrying the matching cafes scope.
+	readAuth := auth.WithScope(auth.ScopeCafesRead, authMiddleware)
+	writeAuth := auth.WithScope(auth.ScopeCafesWrite, authMiddleware)
 	// Authenticated "me" routes - user ID from JWT context
-	r.Group(func(r chi.Router) {
-		r.Use(authMiddleware)
-		r.Get("/me/cafes", h.ListMyHandler)
-		r.Post("/me/cafes", h.CreateMyHandler)
-	})
+	r.With(readAuth).Get("/me/cafes", h.ListMyHandler)
+	r.With(writeAuth, writeMiddleware).Post("/me/cafes", h.CreateMyHandler)
 	// Legacy user-scoped routes - require auth and path userId must match JWT
 	r.Route("/users/{userId}/cafes", func(r chi.Router) {
-		r.Use(authMiddleware)
-		r.Get("/", h.ListByUserHandler)
-		r.Post("/", h.CreateHandler)
+		r.With(readAuth).Get("/", h.ListByUserHandler)
+		r.With(writeAuth, writeMiddleware).Post("/", h.CreateHandler)
 	})
 	r.Route("/cafes", func(r chi.Router) {
 		r.Get("/", h.ListDiscoveryHandler)
 		r.Get("/autocomplete", h.AddressAutocompleteHandler)
-		r.Get("/{id}", h.GetByIDHandler)
+		// An optional login lets owners and moderators open a hidden listing.
+		r.With(auth.Optional(readAuth)).Get("/{id}", h.GetByIDHandler)
 		r.Group(func(r chi.Router) {
-			r.Use(authMiddleware)
-			r.Put("/{id}", h.UpdateHandler)
+			r.Use(writeAuth)
+			r.With(writeMiddleware).Put("/{id}", h.UpdateHandler)
 			r.Delete("/{id}", h.DeleteHandler)
 		})
 	})
@@ -58,35 +65,43 @@ func RegisterRoutes(
 
 // ListDiscoveryHandler godoc
 // @Summary Discover cafes
-// @Description Returns public community-submitted cafes for discovery surfaces.
+// @Description Returns public community-submitted cafes for discovery surfaces, one page at a time.
 // @Tags cafes
 // @Produce json
-// @Param query query string false "Search query"
+// @Param query query string false "Search query (web search syntax: quoted phrases, or, -exclusions)"
 // @Param city query string false "City filter"
-// @Param sort query string false "Sort: rating_desc|newest|name_asc"
-// @Param limit query int false "Result limit (1-60)"
-// @Success 200 {array} models.CafeListing
+// @Param near query string false "Origin as lat,lon; only cafes with coordinates are returned and each carries distance_m"
+// @Param radius_m query number false "With near, keep cafes within this many meters (max 50000)"
+// @Param bbox query string false "Bounding box as minLon,minLat,maxLon,maxLat (minLon > maxLon crosses the antimeridian)"
+// @Param amenities query string false "Comma-separated amenities the cafe must all have, e.g. wifi,outlets"
+// @Param price_level query string false "Comma-separated price levels (1-4) to include, e.g. 1,2"
+// @Param open_now query bool false "Only cafes whose opening hours say they are open now (cafes without hours are excluded)"
+// @Param sort query string false "Sort: relevance|distance|rating_desc|coffee_desc|ambience_desc|service_desc|value_desc|work_desc|newest|name_asc (relevance is the default when query is set; distance requires near)"
+// @Param limit query int false "Page size (1-60, default 18)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.Cafe}
 // @Failure 400 {string} string
 // @Failure 500 {string} string
 // @Router /cafes [get]
 func (h *Handler) ListDiscoveryHandler(w http.ResponseWriter, r *http.Request) {
-	limit := 18
-	if limitStr := strings.TrimSpace(r.URL.Query().Get("limit")); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil {
-			http.Error(w, "Invalid limit", http.StatusBadRequest)
-			return
-		}
-		limit = parsed
+	page, err := pagination.FromQuery(r.URL.Query(), DefaultDiscoveryLimit, MaxDiscoveryLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := discoveryFilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	listings, err := h.Service.ListDiscovery(
-		r.URL.Query().Get("query"),
-		r.URL.Query().Get("city"),
-		r.URL.Query().Get("sort"),
-		limit,
-	)
+	listings, err := h.Service.ListDiscovery(filter, page)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) || errors.Is(err, ErrInvalidRadius) || errors.Is(err, ErrDistanceSortRequiresNear) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to retrieve cafes", http.StatusInternalServerError)
 		return
 	}
@@ -95,17 +110,72 @@ func (h *Handler) ListDiscoveryHandler(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(listings)
 }
 
+func discoveryFilterFromQuery(values url.Values) (DiscoveryFilter, error) {
+	filter := DiscoveryFilter{
+		Query: values.Get("query"),
+		City:  values.Get("city"),
+		Sort:  values.Get("sort"),
+	}
+	if raw := strings.TrimSpace(values.Get("near")); raw != "" {
+		near, err := ParseGeoPoint(raw)
+		if err != nil {
+			return DiscoveryFilter{}, err
+		}
+		filter.Near = near
+	}
+	if raw := strings.TrimSpace(values.Get("radius_m")); raw != "" {
+		radius, err := strconv.ParseFloat(raw, 64)
+		if err != nil || !(radius > 0) {
+			return DiscoveryFilter{}, ErrInvalidRadius
+		}
+		filter.RadiusM = radius
+	}
+	if raw := strings.TrimSpace(values.Get("bbox")); raw != "" {
+		box, err := ParseBBox(raw)
+		if err != nil {
+			return DiscoveryFilter{}, err
+		}
+		filter.BBox = box
+	}
+	if raw := strings.TrimSpace(values.Get("amenities")); raw != "" {
+		amenities, err := parseAmenitiesQuery(raw)
+		if err != nil {
+			return DiscoveryFilter{}, err
+		}
+		filter.Amenities = amenities
+	}
+	if raw := strings.TrimSpace(values.Get("price_level")); raw != "" {
+		levels, err := parsePriceLevelsQuery(raw)
+		if err != nil {
+			return DiscoveryFilter{}, err
+		}
+		filter.PriceLevels = levels
+	}
+	if raw := strings.TrimSpace(values.Get("open_now")); raw != "" {
+		openNow, err := strconv.ParseBool(raw)
+		if err != nil {
+			return DiscoveryFilter{}, ErrInvalidOpenNow
+		}
+		if openNow {
+			now := time.Now()
+			filter.OpenAt = &now
+		}
+	}
+	return filter, nil
+}
+
 // AddressAutocompleteHandler godoc
 // @Summary Address autocomplete
-// @Description Returns autocomplete suggestions from Geoapify.
+// @Description Returns autocomplete suggestions from the configured providers (Geoapify, OpenStreetMap Nominatim).
 // @Tags cafes
 // @Produce json
 // @Param text query string true "Partial address or place text"
 // @Param limit query int false "Max suggestions (1-10)"
 // @Success 200 {object} AddressAutocompleteResponse
 // @Failure 400 {string} string
-// @Failure 503 {string} string
 // @Failure 502 {string} string
+// @Failure 503 {string} string
+// @Failure 504 {string} string
 // @Router /cafes/autocomplete [get]
 func (h *Handler) AddressAutocompleteHandler(w http.ResponseWriter, r *http.Request) {
 	query := strings.TrimSpace(r.URL.Query().Get("text"))
@@ -131,7 +201,7 @@ func (h *Handler) AddressAutocompleteHandler(w http.ResponseWriter, r *http.Requ
 
 	results, err := h.Autocomplete.Autocomplete(r.Context(), query, limit)
 	if err != nil {
-		http.Error(w, "Failed to fetch address suggestions", http.StatusBadGateway)
+		upstream.WriteError(w, err)
 		return
 	}
 
@@ -141,11 +211,11 @@ func (h *Handler) AddressAutocompleteHandler(w http.ResponseWriter, r *http.Requ
 
 // GetByIDHandler godoc
 // @Summary Get cafe by ID
-// @Description Returns a cafe listing by ID.
+// @Description Returns a cafe by ID; a signed-in viewer also gets their visit_status, notes and saved_at for it. A cafe hidden by moderators is 404 except for the user who added it and moderators, who must send a bearer token. Ids of listings folded into another cafe answer 308 to that cafe.
 // @Tags cafes
 // @Produce json
 // @Param id path int true "Cafe ID"
-// @Success 200 {object} models.CafeListing
+// @Success 200 {object} models.Cafe
 // @Failure 400 {string} string
 // @Failure 404 {string} string
 // @Failure 500 {string} string
@@ -157,12 +227,13 @@ func (h *Handler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid ID", http.StatusBadRequest)
 		return
 	}
-	listing, err := h.Service.GetByID(uint(id))
+	viewerID, _ := auth.UserIDFromContext(r.Context())
+	listing, err := h.Service.GetForViewer(uint(id), viewerID)
 	if err != nil {
 		http.Error(w, "Failed to retrieve cafe listing", http.StatusInternalServerError)
 		return
 	}
-	if listing == nil {
+	if listing == nil || (listing.HiddenAt != nil && !auth.CanViewHidden(r.Context(), listing.UserID)) {
 		http.Error(w, "Cafe listing not found", http.StatusNotFound)
 		return
 	}
@@ -172,13 +243,15 @@ func (h *Handler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 
 // ListMyHandler godoc
 // @Summary List my cafes
-// @Description Returns cafe listings owned by the authenticated user.
+// @Description Returns the cafes the authenticated user has saved, with their visit_status and notes, one page at a time.
 // @Tags cafes
 // @Produce json
 // @Security BearerAuth
 // @Param status query string false "Filter by status (to_visit|visited)"
 // @Param sort query string false "Sort: updated_desc|created_desc|name_asc|name_desc|status_asc|status_desc"
-// @Success 200 {array} models.CafeListing
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.Cafe}
 // @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 500 {string} string
@@ -189,11 +262,16 @@ func (h *Handler) ListMyHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
 	visitStatus := r.URL.Query().Get("status")
 	sort := r.URL.Query().Get("sort")
-	listings, err := h.Service.GetByUserIDFiltered(userID, visitStatus, sort)
+	listings, err := h.Service.GetByUserIDFiltered(userID, visitStatus, sort, page)
 	if err != nil {
-		if errors.Is(err, ErrInvalidVisitStatus) {
+		if errors.Is(err, ErrInvalidVisitStatus) || errors.Is(err, pagination.ErrInvalidCursor) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -206,13 +284,13 @@ func (h *Handler) ListMyHandler(w http.ResponseWriter, r *http.Request) {
 
 // CreateMyHandler godoc
 // @Summary Create my cafe
-// @Description Creates a cafe listing for the authenticated user.
+// @Description Saves a cafe to the authenticated user's list. source_cafe_id, or else an external_place_id some cafe already has, saves that existing cafe; otherwise a new cafe is added. Saving a cafe again updates visit_status and notes.
 // @Tags cafes
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param body body models.CafeListing true "Create cafe payload"
-// @Success 201 {object} models.CafeListing
+// @Param body body models.Cafe true "Create cafe payload"
+// @Success 201 {object} models.Cafe
 // @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 500 {string} string
@@ -224,14 +302,15 @@ func (h *Handler) CreateMyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	var listing models.CafeListing
+	var listing models.Cafe
 	if err := json.NewDecoder(r.Body).Decode(&listing); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 	listing.UserID = userID
 	if err := h.Service.CreateListing(&listing); err != nil {
-		if errors.Is(err, ErrInvalidVisitStatus) || errors.Is(err, ErrInvalidCafeName) || errors.Is(err, ErrInvalidCoordinates) {
+		if errors.Is(err, ErrInvalidVisitStatus) || errors.Is(err, ErrInvalidCafeName) || errors.Is(err, ErrInvalidImageURL) || errors.Is(err, ErrInvalidCoordinates) ||
+			errors.Is(err, ErrInvalidAmenity) || errors.Is(err, ErrInvalidPriceLevel) || errors.Is(err, ErrInvalidOpeningHours) || errors.Is(err, ErrNotesTooLong) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -255,7 +334,9 @@ func (h *Handler) CreateMyHandler(w http.ResponseWriter, r *http.Request) {
 // @Param userId path int true "User ID"
 // @Param status query string false "Filter by status (to_visit|visited)"
 // @Param sort query string false "Sort: updated_desc|created_desc|name_asc|name_desc|status_asc|status_desc"
-// @Success 200 {array} models.CafeListing
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.Cafe}
 // @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 403 {string} string
@@ -277,11 +358,16 @@ func (h *Handler) ListByUserHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
 	visitStatus := r.URL.Query().Get("status")
 	sort := r.URL.Query().Get("sort")
-	listings, err := h.Service.GetByUserIDFiltered(userID, visitStatus, sort)
+	listings, err := h.Service.GetByUserIDFiltered(userID, visitStatus, sort, page)
 	if err != nil {
-		if errors.Is(err, ErrInvalidVisitStatus) {
+		if errors.Is(err, ErrInvalidVisitStatus) || errors.Is(err, pagination.ErrInvalidCursor) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -300,8 +386,8 @@ func (h *Handler) ListByUserHandler(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerAuth
 // @Param userId path int true "User ID"
-// @Param body body models.CafeListing true "Create cafe payload"
-// @Success 201 {object} models.CafeListing
+// @Param body body models.Cafe true "Create cafe payload"
+// @Success 201 {object} models.Cafe
 // @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 403 {string} string
@@ -324,14 +410,15 @@ func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	var listing models.CafeListing
+	var listing models.Cafe
 	if err := json.NewDecoder(r.Body).Decode(&listing); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 	listing.UserID = userID
 	if err := h.Service.CreateListing(&listing); err != nil {
-		if errors.Is(err, ErrInvalidVisitStatus) || errors.Is(err, ErrInvalidCafeName) || errors.Is(err, ErrInvalidCoordinates) {
+		if errors.Is(err, ErrInvalidVisitStatus) || errors.Is(err, ErrInvalidCafeName) || errors.Is(err, ErrInvalidImageURL) || errors.Is(err, ErrInvalidCoordinates) ||
+			errors.Is(err, ErrInvalidAmenity) || errors.Is(err, ErrInvalidPriceLevel) || errors.Is(err, ErrInvalidOpeningHours) || errors.Is(err, ErrNotesTooLong) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -348,13 +435,13 @@ func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 
 // UpdateHandler godoc
 // @Summary Update cafe
-// @Description Updates a cafe listing by ID.
+// @Description Updates a cafe. The user who added it edits its details; anyone who saved it changes their own visit_status and notes, and gets 403 if they change any other field (fields left out or sent back unchanged are accepted).
 // @Tags cafes
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Cafe ID"
-// @Param body body models.CafeListing true "Update cafe payload"
+// @Param body body models.Cafe true "Update cafe payload"
 // @Success 200 {object} map[string]string
 // @Failure 400 {string} string
 // @Failure 401 {string} string
@@ -374,7 +461,7 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid ID", http.StatusBadRequest)
 		return
 	}
-	var listing models.CafeListing
+	var listing models.Cafe
 	if err := json.NewDecoder(r.Body).Decode(&listing); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
@@ -384,7 +471,12 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
 		}
-		if errors.Is(err, ErrInvalidVisitStatus) || errors.Is(err, ErrInvalidCafeName) || errors.Is(err, ErrInvalidCoordinates) {
+		if errors.Is(err, ErrDetailsNotEditable) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrInvalidVisitStatus) || errors.Is(err, ErrInvalidCafeName) || errors.Is(err, ErrInvalidImageURL) || errors.Is(err, ErrInvalidCoordinates) ||
+			errors.Is(err, ErrInvalidAmenity) || errors.Is(err, ErrInvalidPriceLevel) || errors.Is(err, ErrInvalidOpeningHours) || errors.Is(err, ErrNotesTooLong) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -401,7 +493,7 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 // DeleteHandler godoc
 // @Summary Delete cafe
-// @Description Deletes a cafe listing by ID.
+// @Description Takes the cafe off the caller's list. The cafe itself is deleted only when the caller added it and no other user has saved or rated it.
 // @Tags cafes
 // @Security BearerAuth
 // @Param id path int true "Cafe ID"
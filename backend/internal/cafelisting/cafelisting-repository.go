@@ -1,21 +1,39 @@
 package cafelisting
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Storage interface {
-	Create(listing *models.CafeListing) error
-	GetByID(id uint) (*models.CafeListing, error)
-	ListDiscovery(filter DiscoveryFilter) ([]models.CafeListing, error)
-	GetByUserID(userID uint) ([]models.CafeListing, error)
-	GetByUserIDFiltered(userID uint, filter ListFilter) ([]models.CafeListing, error)
-	Update(id uint, updated models.CafeListing) error
-	Delete(id uint) error
+	// Save adds place to the user's list, creating cafe first when it has no ID yet.
+	Save(cafe *models.Cafe, place models.SavedPlace) error
+	GetByID(id uint) (*models.Cafe, error)
+	// GetForUser is GetByID with userID's saved place filled in.
+	GetForUser(id, userID uint) (*models.Cafe, error)
+	GetByExternalPlaceID(externalPlaceID string) (*models.Cafe, error)
+	ListDiscovery(filter DiscoveryFilter, page pagination.Request) (pagination.Page[models.Cafe], error)
+	GetByUserIDFiltered(userID uint, filter ListFilter, page pagination.Request) (pagination.Page[models.Cafe], error)
+	Update(id uint, updated models.Cafe) error
+	GetSavedPlace(userID, cafeID uint) (*models.SavedPlace, error)
+	UpdateSavedPlace(place models.SavedPlace) error
+	// Remove takes the cafe off the user's list, and deletes the cafe when the user added it and nobody else uses it.
+	Remove(id, userID uint) (cafeDeleted bool, err error)
+	GetWithDeleted(id uint) (*models.Cafe, error)
+	Restore(id uint, deletedAt time.Time) error
+	// ResolveRedirect returns the cafe a folded listing id now points to, or 0.
+	ResolveRedirect(id uint) (uint, error)
+	// Transaction runs fn with a Storage bound to tx, committing only if fn returns nil.
+	Transaction(fn func(tx *gorm.DB, store Storage) error) error
 }
 
 type ListFilter struct {
@@ -24,10 +42,78 @@ type ListFilter struct {
 }
 
 type DiscoveryFilter struct {
-	Query string
-	City  string
-	Sort  string
-	Limit int
+	Query   string
+	City    string
+	Sort    string    // empty means relevance when Query is set, popular otherwise
+	Near    *GeoPoint // restricts to listings with coordinates and selects distance_m
+	RadiusM float64   // with Near, keeps listings within this many meters; zero means no radius
+	BBox    *BBox
+	// Amenities keeps listings that have all of them.
+	Amenities   []string
+	PriceLevels []int
+	// OpenAt keeps listings whose opening hours say they are open at that instant.
+	OpenAt *time.Time
+}
+
+const (
+	DefaultDiscoveryLimit = 18
+	MaxDiscoveryLimit     = 60
+	// maxOpenNowScan bounds how many listings with opening hours one open_now page reads.
+	maxOpenNowScan = 1000
+)
+
+// Keyset orders for each supported sort; every order ends with the listing id so it is total.
+var (
+	listingID     = pagination.Key[models.Cafe]{Column: "gocafe_cafes.id", Value: func(c models.Cafe) any { return c.ID }}
+	listingIDDesc = pagination.Key[models.Cafe]{Column: "gocafe_cafes.id", Desc: true, Value: func(c models.Cafe) any { return c.ID }}
+	updatedDesc   = pagination.Key[models.Cafe]{Column: "gocafe_cafes.updated_at", Desc: true, Value: func(c models.Cafe) any { return c.UpdatedAt }}
+	createdDesc   = pagination.Key[models.Cafe]{Column: "gocafe_cafes.created_at", Desc: true, Value: func(c models.Cafe) any { return c.CreatedAt }}
+	nameAsc       = pagination.Key[models.Cafe]{Column: "gocafe_cafes.name", Value: func(c models.Cafe) any { return c.Name }}
+	nameDesc      = pagination.Key[models.Cafe]{Column: "gocafe_cafes.name", Desc: true, Value: func(c models.Cafe) any { return c.Name }}
+	// The owner orders page a user's saved places, so dates and status come from the saved place.
+	savedUpdatedDesc = pagination.Key[models.Cafe]{Column: "gocafe_saved_places.updated_at", Desc: true, Value: func(c models.Cafe) any { return derefTime(c.SavedUpdatedAt) }}
+	savedCreatedDesc = pagination.Key[models.Cafe]{Column: "gocafe_saved_places.created_at", Desc: true, Value: func(c models.Cafe) any { return derefTime(c.SavedAt) }}
+	statusAsc        = pagination.Key[models.Cafe]{Column: "gocafe_saved_places.visit_status", Value: func(c models.Cafe) any { return c.VisitStatus }}
+	statusDesc       = pagination.Key[models.Cafe]{Column: "gocafe_saved_places.visit_status", Desc: true, Value: func(c models.Cafe) any { return c.VisitStatus }}
+	reviewsDesc      = pagination.Key[models.Cafe]{Column: "COALESCE(stats.review_count, 0)", Desc: true, Value: func(c models.Cafe) any { return c.ReviewCount }}
+	ratingDesc       = pagination.Key[models.Cafe]{Column: "COALESCE(stats.avg_rating, 0)", Desc: true, Value: func(c models.Cafe) any { return c.AvgRating }}
+
+	discoveryOrders = map[string]pagination.Order[models.Cafe]{
+		"popular":     {Name: "popular", Keys: []pagination.Key[models.Cafe]{reviewsDesc, ratingDesc, updatedDesc, listingIDDesc}},
+		"newest":      {Name: "newest", Keys: []pagination.Key[models.Cafe]{createdDesc, listingIDDesc}},
+		"name_asc":    {Name: "name_asc", Keys: []pagination.Key[models.Cafe]{nameAsc, listingID}},
+		"rating_desc": {Name: "rating_desc", Keys: []pagination.Key[models.Cafe]{ratingDesc, reviewsDesc, updatedDesc, listingIDDesc}},
+		// Per-dimension sorts put listings nobody has scored on that dimension last.
+		"coffee_desc":   dimensionOrder("coffee_desc", "stats.avg_coffee", func(c models.Cafe) *float64 { return c.AvgCoffee }),
+		"ambience_desc": dimensionOrder("ambience_desc", "stats.avg_ambience", func(c models.Cafe) *float64 { return c.AvgAmbience }),
+		"service_desc":  dimensionOrder("service_desc", "stats.avg_service", func(c models.Cafe) *float64 { return c.AvgService }),
+		"value_desc":    dimensionOrder("value_desc", "stats.avg_value", func(c models.Cafe) *float64 { return c.AvgValue }),
+		"work_desc":     dimensionOrder("work_desc", "stats.avg_work", func(c models.Cafe) *float64 { return c.AvgWork }),
+	}
+	// relevanceOrder pages the ranked subquery built by ListDiscovery, so its columns are qualified by "ranked".
+	relevanceOrder = pagination.Order[models.Cafe]{Name: "relevance", Keys: []pagination.Key[models.Cafe]{
+		{Column: "ranked.relevance", Desc: true, Value: func(c models.Cafe) any { return c.Relevance }},
+		{Column: "ranked.id", Desc: true, Value: func(c models.Cafe) any { return c.ID }},
+	}}
+	ownerOrders = map[string]pagination.Order[models.Cafe]{
+		"updated_desc": {Name: "updated_desc", Keys: []pagination.Key[models.Cafe]{savedUpdatedDesc, listingIDDesc}},
+		"created_desc": {Name: "created_desc", Keys: []pagination.Key[models.Cafe]{savedCreatedDesc, listingIDDesc}},
+		"name_asc":     {Name: "name_asc", Keys: []pagination.Key[models.Cafe]{nameAsc, listingID}},
+		"name_desc":    {Name: "name_desc", Keys: []pagination.Key[models.Cafe]{nameDesc, listingIDDesc}},
+		"status_asc":   {Name: "status_asc", Keys: []pagination.Key[models.Cafe]{statusAsc, savedUpdatedDesc, listingIDDesc}},
+		"status_desc":  {Name: "status_desc", Keys: []pagination.Key[models.Cafe]{statusDesc, savedUpdatedDesc, listingIDDesc}},
+	}
+)
+
+// dimensionOrder sorts by one sub-score average, highest first, then like rating_desc.
+func dimensionOrder(name, column string, avg func(models.Cafe) *float64) pagination.Order[models.Cafe] {
+	key := pagination.Key[models.Cafe]{Column: "COALESCE(" + column + ", 0)", Desc: true, Value: func(c models.Cafe) any {
+		if v := avg(c); v != nil {
+			return *v
+		}
+		return float64(0)
+	}}
+	return pagination.Order[models.Cafe]{Name: name, Keys: []pagination.Key[models.Cafe]{key, ratingDesc, reviewsDesc, updatedDesc, listingIDDesc}}
 }
 
 type Repository struct {
@@ -38,30 +124,98 @@ func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) Create(c *models.CafeListing) error {
-	return r.db.Create(c).Error
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// Save does not fail when a concurrent save wins a unique index: if another request has just added a live cafe with
+// the same external_place_id, cafe takes that cafe's ID, and a saved place it just added is updated instead.
+func (r *Repository) Save(cafe *models.Cafe, place models.SavedPlace) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if cafe.ID == 0 {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(cafe)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				var existing models.Cafe
+				if err := tx.Where("external_place_id = ?", cafe.ExternalPlaceID).First(&existing).Error; err != nil {
+					return err
+				}
+				cafe.ID = existing.ID
+			}
+		}
+		place.CafeID = cafe.ID
+		updateSaved := func() (int64, error) {
+			result := tx.Model(&models.SavedPlace{}).
+				Where("user_id = ? AND cafe_id = ?", place.UserID, place.CafeID).
+				Updates(map[string]any{"visit_status": place.VisitStatus, "notes": place.Notes})
+			return result.RowsAffected, result.Error
+		}
+		if updated, err := updateSaved(); err != nil || updated > 0 {
+			return err
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&place)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+		_, err := updateSaved()
+		return err
+	})
+}
+
+func (r *Repository) GetByID(id uint) (*models.Cafe, error) {
+	return first(r.baseListingQuery().Where("gocafe_cafes.id = ?", id))
+}
+
+func (r *Repository) GetForUser(id, userID uint) (*models.Cafe, error) {
+	return first(withSavedPlace(r.baseListingQuery(), "LEFT JOIN", userID).Where("gocafe_cafes.id = ?", id))
 }
 
-func (r *Repository) GetByID(id uint) (*models.CafeListing, error) {
-	var listing models.CafeListing
-	err := r.baseListingQuery().
-		Where("gocafe_cafe_listings.id = ?", id).
-		First(&listing).Error
+// GetByExternalPlaceID returns the live cafe for a provider place; there is at most one.
+func (r *Repository) GetByExternalPlaceID(externalPlaceID string) (*models.Cafe, error) {
+	return first(r.baseListingQuery().Where("gocafe_cafes.external_place_id = ?", externalPlaceID))
+}
+
+func first(q *gorm.DB) (*models.Cafe, error) {
+	var cafe models.Cafe
+	err := q.First(&cafe).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
-	return &listing, err
+	return &cafe, err
 }
 
-func (r *Repository) ListDiscovery(filter DiscoveryFilter) ([]models.CafeListing, error) {
-	var listings []models.CafeListing
-	q := r.baseListingQuery().
-		Where("gocafe_cafe_listings.source_cafe_id IS NULL")
+// Full-text search (Postgres only, see migration 000014). The query is parsed with websearch_to_tsquery, so quoted
+// phrases, "or" and -exclusions work, and pg_trgm similarity on the name catches typos the stemmer cannot.
+const (
+	searchMatchSQL = `(gocafe_cafes.search_vector @@ websearch_to_tsquery('english', ?)
+		OR gocafe_cafes.name % ?
+		OR ? <% gocafe_cafes.name)`
+	// relevanceSQL blends the text rank (normalised to 0-1) and name similarity with the rating stats:
+	// a 5-star average adds half again, and review count adds a logarithmic boost.
+	relevanceSQL = `CAST(
+			ts_rank(gocafe_cafes.search_vector, websearch_to_tsquery('english', ?), 32)
+			+ 0.5 * word_similarity(?, gocafe_cafes.name)
+		AS double precision)
+		* (1 + CAST(COALESCE(stats.avg_rating, 0) AS double precision) / 10
+			+ LN(1 + CAST(COALESCE(stats.review_count, 0) AS double precision)) / 10) AS relevance`
+)
+
+func (r *Repository) ListDiscovery(filter DiscoveryFilter, page pagination.Request) (pagination.Page[models.Cafe], error) {
+	q := r.baseListingQuery().Where("gocafe_cafes.hidden_at IS NULL")
 
-	if query := strings.TrimSpace(filter.Query); query != "" {
+	query := strings.TrimSpace(filter.Query)
+	fullText := query != "" && r.supportsFullTextSearch()
+	if fullText {
+		q = q.Where(searchMatchSQL, query, query, query)
+	} else if query != "" {
 		likeQuery := "%" + strings.ToLower(query) + "%"
 		q = q.Where(
-			`LOWER(gocafe_cafe_listings.name) LIKE ? OR LOWER(gocafe_cafe_listings.address) LIKE ? OR LOWER(gocafe_cafe_listings.city) LIKE ? OR LOWER(gocafe_cafe_listings.neighborhood) LIKE ? OR LOWER(gocafe_cafe_listings.description) LIKE ?`,
+			`LOWER(gocafe_cafes.name) LIKE ? OR LOWER(gocafe_cafes.address) LIKE ? OR LOWER(gocafe_cafes.city) LIKE ? OR LOWER(gocafe_cafes.neighborhood) LIKE ? OR LOWER(gocafe_cafes.description) LIKE ?`,
 			likeQuery,
 			likeQuery,
 			likeQuery,
@@ -71,60 +225,152 @@ func (r *Repository) ListDiscovery(filter DiscoveryFilter) ([]models.CafeListing
 	}
 
 	if city := strings.TrimSpace(filter.City); city != "" {
-		q = q.Where("LOWER(gocafe_cafe_listings.city) = ?", strings.ToLower(city))
+		q = q.Where("LOWER(gocafe_cafes.city) = ?", strings.ToLower(city))
 	}
 
-	orderBy := "COALESCE(stats.review_count, 0) DESC, COALESCE(stats.avg_rating, 0) DESC, gocafe_cafe_listings.updated_at DESC"
-	switch filter.Sort {
-	case "newest":
-		orderBy = "gocafe_cafe_listings.created_at DESC"
-	case "name_asc":
-		orderBy = "gocafe_cafe_listings.name ASC"
-	case "rating_desc":
-		orderBy = "COALESCE(stats.avg_rating, 0) DESC, COALESCE(stats.review_count, 0) DESC, gocafe_cafe_listings.updated_at DESC"
+	columns := listingColumnsSQL
+	distance := ""
+	if filter.Near != nil {
+		q = q.Where("gocafe_cafes.latitude IS NOT NULL AND gocafe_cafes.longitude IS NOT NULL")
+		if r.supportsGeoDistance() {
+			distance = distanceSQL(*filter.Near)
+			columns += ", " + distance + " AS distance_m"
+			if filter.RadiusM > 0 {
+				// earth_box is a cube that bounds the circle and can use the GiST index; earth_distance trims its corners.
+				q = q.Where("earth_box(ll_to_earth(?, ?), ?) @> ll_to_earth(gocafe_cafes.latitude, gocafe_cafes.longitude) AND "+distance+" <= ?",
+					filter.Near.Lat, filter.Near.Lon, filter.RadiusM, filter.RadiusM)
+			}
+		} else if filter.RadiusM > 0 {
+			// Without earthdistance (e.g. SQLite in tests) the radius is approximated by its bounding box.
+			q = whereInBBox(q, filter.Near.boundingBox(filter.RadiusM))
+		}
+	}
+	if filter.BBox != nil {
+		q = whereInBBox(q, *filter.BBox)
+	}
+	if len(filter.Amenities) > 0 {
+		q = r.whereHasAmenities(q, filter.Amenities)
+	}
+	if len(filter.PriceLevels) > 0 {
+		q = q.Where("gocafe_cafes.price_level IN ?", filter.PriceLevels)
+	}
+
+	// Opening hours depend on each listing's timezone, so open_now is checked in Go on the rows SQL returns.
+	find := pagination.Find[models.Cafe]
+	if filter.OpenAt != nil {
+		q = q.Where("gocafe_cafes.opening_hours IS NOT NULL")
+		at := *filter.OpenAt
+		find = func(q *gorm.DB, order pagination.Order[models.Cafe], page pagination.Request) (pagination.Page[models.Cafe], error) {
+			return pagination.FindFiltered(q, order, page, func(c models.Cafe) bool { return IsOpenAt(c.OpeningHours, at) }, maxOpenNowScan)
+		}
+	}
+
+	if page.Limit <= 0 {
+		page.Limit = DefaultDiscoveryLimit
+	}
+	if page.Limit > MaxDiscoveryLimit {
+		page.Limit = MaxDiscoveryLimit
+	}
+
+	sort := filter.Sort
+	if sort == "" && query != "" {
+		sort = "relevance"
+	}
+	if sort == "relevance" && fullText {
+		// The score is selected in a subquery so the keyset can compare it like a column.
+		ranked := q.Select(columns+", "+relevanceSQL, query, query)
+		return find(r.db.Table("(?) AS ranked", ranked).Select("ranked.*"), relevanceOrder, page)
+	}
+	q = q.Select(columns)
+	if sort == "distance" && distance != "" {
+		return find(q, distanceOrder(*filter.Near, distance), page)
 	}
 
-	limit := filter.Limit
-	if limit <= 0 {
-		limit = 18
+	// Without full-text or earthdistance support, relevance and distance fall back to the default order.
+	order, ok := discoveryOrders[sort]
+	if !ok {
+		order = discoveryOrders["popular"]
 	}
-	if limit > 60 {
-		limit = 60
+	return find(q, order, page)
+}
+
+// supportsFullTextSearch reports whether the search_vector column and pg_trgm from migration 000014 are available.
+func (r *Repository) supportsFullTextSearch() bool {
+	return r.db.Dialector.Name() == "postgres"
+}
+
+// supportsJSONB reports whether the amenities column is JSONB (migration 000018) and can use the @> operator.
+func (r *Repository) supportsJSONB() bool {
+	return r.db.Dialector.Name() == "postgres"
+}
+
+// whereHasAmenities keeps listings whose amenities include every one of amenities. Amenities are validated against
+// the supported list before they get here.
+func (r *Repository) whereHasAmenities(q *gorm.DB, amenities []string) *gorm.DB {
+	if r.supportsJSONB() {
+		encoded, _ := json.Marshal(amenities)
+		return q.Where("gocafe_cafes.amenities @> CAST(? AS jsonb)", string(encoded))
+	}
+	// Without JSONB (e.g. SQLite in tests) the serialized array is matched as text.
+	for _, amenity := range amenities {
+		q = q.Where("gocafe_cafes.amenities LIKE ?", `%"`+amenity+`"%`)
 	}
+	return q
+}
 
-	err := q.Order(orderBy).Limit(limit).Find(&listings).Error
-	return listings, err
+// supportsGeoDistance reports whether the cube and earthdistance extensions from migration 000015 are available.
+func (r *Repository) supportsGeoDistance() bool {
+	return r.db.Dialector.Name() == "postgres"
 }
 
-func (r *Repository) GetByUserID(userID uint) ([]models.CafeListing, error) {
-	return r.GetByUserIDFiltered(userID, ListFilter{})
+// distanceSQL is the great-circle distance in meters from p. The point is inlined as numeric literals because
+// keyset Columns cannot carry bind arguments.
+func distanceSQL(p GeoPoint) string {
+	return fmt.Sprintf("earth_distance(ll_to_earth(%s, %s), ll_to_earth(gocafe_cafes.latitude, gocafe_cafes.longitude))",
+		strconv.FormatFloat(p.Lat, 'f', -1, 64), strconv.FormatFloat(p.Lon, 'f', -1, 64))
 }
 
-func (r *Repository) GetByUserIDFiltered(userID uint, filter ListFilter) ([]models.CafeListing, error) {
-	var listings []models.CafeListing
-	q := r.baseListingQuery().Where("gocafe_cafe_listings.user_id = ?", userID)
+// distanceOrder sorts nearest first. The order name includes the point, so a cursor cannot be reused with another near.
+func distanceOrder(p GeoPoint, distance string) pagination.Order[models.Cafe] {
+	return pagination.Order[models.Cafe]{
+		Name: fmt.Sprintf("distance:%g,%g", p.Lat, p.Lon),
+		Keys: []pagination.Key[models.Cafe]{
+			{Column: distance, Value: func(c models.Cafe) any {
+				if c.DistanceM == nil {
+					return float64(0)
+				}
+				return *c.DistanceM
+			}},
+			listingID,
+		},
+	}
+}
+
+// whereInBBox keeps listings inside box, splitting the longitude range when the box crosses the antimeridian.
+func whereInBBox(q *gorm.DB, box BBox) *gorm.DB {
+	q = q.Where("gocafe_cafes.latitude BETWEEN ? AND ?", box.MinLat, box.MaxLat)
+	if box.MinLon <= box.MaxLon {
+		return q.Where("gocafe_cafes.longitude BETWEEN ? AND ?", box.MinLon, box.MaxLon)
+	}
+	return q.Where("(gocafe_cafes.longitude >= ? OR gocafe_cafes.longitude <= ?)", box.MinLon, box.MaxLon)
+}
+
+// GetByUserIDFiltered pages the cafes the user has saved, with their saved places.
+func (r *Repository) GetByUserIDFiltered(userID uint, filter ListFilter, page pagination.Request) (pagination.Page[models.Cafe], error) {
+	q := withSavedPlace(r.baseListingQuery(), "JOIN", userID)
 	if filter.VisitStatus != "" {
-		q = q.Where("gocafe_cafe_listings.visit_status = ?", filter.VisitStatus)
-	}
-	orderBy := "gocafe_cafe_listings.updated_at DESC"
-	switch filter.Sort {
-	case "created_desc":
-		orderBy = "gocafe_cafe_listings.created_at DESC"
-	case "name_asc":
-		orderBy = "gocafe_cafe_listings.name ASC"
-	case "name_desc":
-		orderBy = "gocafe_cafe_listings.name DESC"
-	case "status_asc":
-		orderBy = "gocafe_cafe_listings.visit_status ASC, gocafe_cafe_listings.updated_at DESC"
-	case "status_desc":
-		orderBy = "gocafe_cafe_listings.visit_status DESC, gocafe_cafe_listings.updated_at DESC"
-	}
-	err := q.Order(orderBy).Find(&listings).Error
-	return listings, err
-}
-
-func (r *Repository) Update(id uint, updated models.CafeListing) error {
-	var existing models.CafeListing
+		q = q.Where("gocafe_saved_places.visit_status = ?", filter.VisitStatus)
+	}
+	order, ok := ownerOrders[filter.Sort]
+	if !ok {
+		order = ownerOrders["updated_desc"]
+	}
+	return pagination.Find(q, order, page)
+}
+
+// Update writes the cafe details; visit status and notes belong to saved places.
+func (r *Repository) Update(id uint, updated models.Cafe) error {
+	var existing models.Cafe
 	if err := r.db.First(&existing, id).Error; err != nil {
 		return err
 	}
@@ -138,34 +384,182 @@ func (r *Repository) Update(id uint, updated models.CafeListing) error {
 	existing.Longitude = updated.Longitude
 	existing.SourceProvider = updated.SourceProvider
 	existing.ExternalPlaceID = updated.ExternalPlaceID
-	existing.VisitStatus = updated.VisitStatus
-	return r.db.Save(&existing).Error
+	existing.OpeningHours = updated.OpeningHours
+	existing.Amenities = updated.Amenities
+	existing.PriceLevel = updated.PriceLevel
+	// hidden_at belongs to moderators; leave it out so an edit cannot race a hide or restore.
+	return r.db.Omit("hidden_at").Save(&existing).Error
+}
+
+func (r *Repository) GetSavedPlace(userID, cafeID uint) (*models.SavedPlace, error) {
+	var place models.SavedPlace
+	err := r.db.Where("user_id = ? AND cafe_id = ?", userID, cafeID).First(&place).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &place, err
 }
 
-func (r *Repository) Delete(id uint) error {
-	result := r.db.Delete(&models.CafeListing{}, id)
+func (r *Repository) UpdateSavedPlace(place models.SavedPlace) error {
+	result := r.db.Model(&models.SavedPlace{}).
+		Where("user_id = ? AND cafe_id = ?", place.UserID, place.CafeID).
+		Updates(map[string]any{"visit_status": place.VisitStatus, "notes": place.Notes})
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
 		return gorm.ErrRecordNotFound
 	}
-	return result.Error
+	return nil
 }
 
+// Remove soft-deletes the user's saved place. When the user added the cafe and no other user has saved or rated it,
+// the cafe and its ratings go too, with the same deleted_at, so Restore can bring back exactly those rows.
+func (r *Repository) Remove(id, userID uint) (bool, error) {
+	var cafeDeleted bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.SavedPlace{}).Where("user_id = ? AND cafe_id = ?", userID, id).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Cafe{}).Where("id = ? AND user_id = ?", id, userID).Where(unsharedCafeSQL, userID, userID).UpdateColumn("deleted_at", now)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		cafeDeleted = true
+		return deleteCafeContent(tx, []uint{id}, now)
+	})
+	return cafeDeleted, err
+}
+
+// unsharedCafeSQL matches cafes that no user other than the bound one (bound twice) has saved or rated.
+const unsharedCafeSQL = `NOT EXISTS (SELECT 1 FROM gocafe_saved_places WHERE gocafe_saved_places.cafe_id = gocafe_cafes.id AND gocafe_saved_places.user_id <> ? AND gocafe_saved_places.deleted_at IS NULL)
+		AND NOT EXISTS (SELECT 1 FROM gocafe_ratings WHERE gocafe_ratings.cafe_listing_id = gocafe_cafes.id AND gocafe_ratings.user_id <> ? AND gocafe_ratings.deleted_at IS NULL)`
+
+// deleteCafeContent stamps the ratings and saved places of the cafes with now.
+func deleteCafeContent(tx *gorm.DB, cafeIDs []uint, now time.Time) error {
+	if err := tx.Model(&models.Rating{}).Where("cafe_listing_id IN ?", cafeIDs).UpdateColumn("deleted_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.SavedPlace{}).Where("cafe_id IN ?", cafeIDs).UpdateColumn("deleted_at", now).Error
+}
+
+// GetWithDeleted returns the cafe whether or not it is soft-deleted, without rating stats.
+func (r *Repository) GetWithDeleted(id uint) (*models.Cafe, error) {
+	var listing models.Cafe
+	err := r.db.Unscoped().First(&listing, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &listing, err
+}
+
+// Restore undeletes the cafe and the ratings and saved places deleted with it at deletedAt, except those of users
+// who have deleted their accounts since. A cafe deleted along with the account that added it comes back only with
+// the account, and one whose place has been added again as a new cafe cannot come back.
+func (r *Repository) Restore(id uint, deletedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var cafe models.Cafe
+		if err := tx.Unscoped().First(&cafe, id).Error; err != nil {
+			return err
+		}
+		var ownerAlive int64
+		if err := tx.Model(&models.User{}).Where("id = ?", cafe.UserID).Count(&ownerAlive).Error; err != nil {
+			return err
+		}
+		if ownerAlive == 0 {
+			return ErrOwnerDeleted
+		}
+		if cafe.ExternalPlaceID != "" {
+			var taken int64
+			if err := tx.Model(&models.Cafe{}).Where("external_place_id = ?", cafe.ExternalPlaceID).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				return ErrPlaceTaken
+			}
+		}
+		result := tx.Unscoped().Model(&models.Cafe{}).Where("id = ? AND deleted_at = ?", id, deletedAt).UpdateColumn("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Unscoped().Model(&models.Rating{}).
+			Where("cafe_listing_id = ? AND deleted_at = ?", id, deletedAt).
+			Where("user_id IN (?)", tx.Model(&models.User{}).Select("id")).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.SavedPlace{}).
+			Where("cafe_id = ? AND deleted_at = ?", id, deletedAt).
+			Where("user_id IN (?)", tx.Model(&models.User{}).Select("id")).
+			UpdateColumn("deleted_at", nil).Error
+	})
+}
+
+func (r *Repository) ResolveRedirect(id uint) (uint, error) {
+	var redirect models.CafeRedirect
+	err := r.db.First(&redirect, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return redirect.CafeID, err
+}
+
+func (r *Repository) Transaction(fn func(tx *gorm.DB, store Storage) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(tx, NewRepository(tx))
+	})
+}
+
+const listingColumnsSQL = `
+			gocafe_cafes.*,
+			COALESCE(stats.avg_rating, 0) AS avg_rating,
+			COALESCE(stats.review_count, 0) AS review_count,
+			stats.avg_coffee AS avg_coffee,
+			stats.avg_ambience AS avg_ambience,
+			stats.avg_service AS avg_service,
+			stats.avg_value AS avg_value,
+			stats.avg_work AS avg_work
+		`
+
+// savedPlaceColumnsSQL selects a saved place joined by withSavedPlace onto the Cafe fields for it.
+const savedPlaceColumnsSQL = `,
+			COALESCE(gocafe_saved_places.visit_status, '') AS visit_status,
+			COALESCE(gocafe_saved_places.notes, '') AS notes,
+			gocafe_saved_places.created_at AS saved_at,
+			gocafe_saved_places.updated_at AS saved_updated_at
+		`
+
+// withSavedPlace joins userID's live saved place onto a baseListingQuery: "JOIN" keeps only the cafes the user
+// saved, "LEFT JOIN" keeps every cafe.
+func withSavedPlace(q *gorm.DB, join string, userID uint) *gorm.DB {
+	return q.Select(listingColumnsSQL+savedPlaceColumnsSQL).
+		Joins(join+" gocafe_saved_places ON gocafe_saved_places.cafe_id = gocafe_cafes.id AND gocafe_saved_places.user_id = ? AND gocafe_saved_places.deleted_at IS NULL", userID)
+}
+
+// baseListingQuery selects live cafes with their rating stats. Ratings hidden by moderators, held by screening or
+// deleted do not count. The tables are named directly, so soft-deleted rows are filtered here rather than by gorm.
 func (r *Repository) baseListingQuery() *gorm.DB {
-	statsQuery := r.db.Table("gocafe_cafe_listings AS stats_cafes").
+	statsQuery := r.db.Table("gocafe_ratings").
 		Select(`
-			COALESCE(stats_cafes.source_cafe_id, stats_cafes.id) AS root_id,
+			gocafe_ratings.cafe_listing_id AS cafe_id,
 			COALESCE(ROUND(AVG(CAST(gocafe_ratings.rating AS numeric)), 2), 0) AS avg_rating,
-			COUNT(gocafe_ratings.id) AS review_count
+			COUNT(gocafe_ratings.id) AS review_count,
+			ROUND(AVG(CAST(gocafe_ratings.coffee_score AS numeric)), 2) AS avg_coffee,
+			ROUND(AVG(CAST(gocafe_ratings.ambience_score AS numeric)), 2) AS avg_ambience,
+			ROUND(AVG(CAST(gocafe_ratings.service_score AS numeric)), 2) AS avg_service,
+			ROUND(AVG(CAST(gocafe_ratings.value_score AS numeric)), 2) AS avg_value,
+			ROUND(AVG(CAST(gocafe_ratings.work_score AS numeric)), 2) AS avg_work
 		`).
-		Joins("LEFT JOIN gocafe_ratings ON gocafe_ratings.cafe_listing_id = stats_cafes.id").
-		Group("COALESCE(stats_cafes.source_cafe_id, stats_cafes.id)")
+		Where("gocafe_ratings.hidden_at IS NULL AND gocafe_ratings.held_at IS NULL AND gocafe_ratings.deleted_at IS NULL").
+		Group("gocafe_ratings.cafe_listing_id")
 
 	return r.db.
-		Table("gocafe_cafe_listings").
-		Select(`
-			gocafe_cafe_listings.*,
-			COALESCE(stats.avg_rating, 0) AS avg_rating,
-			COALESCE(stats.review_count, 0) AS review_count
-		`).
-		Joins("LEFT JOIN (?) AS stats ON stats.root_id = COALESCE(gocafe_cafe_listings.source_cafe_id, gocafe_cafe_listings.id)", statsQuery)
+		Table("gocafe_cafes").
+		Select(listingColumnsSQL).
+		Joins("LEFT JOIN (?) AS stats ON stats.cafe_id = gocafe_cafes.id", statsQuery).
+		Where("gocafe_cafes.deleted_at IS NULL")
 }
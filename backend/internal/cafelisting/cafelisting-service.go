@@ -1,100 +1,168 @@
 package cafelisting
 
 import (
+	"net/url"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"gorm.io/gorm"
 )
 
 type Service struct {
-	store Storage
+	store   Storage
+	auditor audit.Recorder // nil records nothing
 }
 
-func NewService(store Storage) *Service {
-	return &Service{store: store}
+func NewService(store Storage, auditor audit.Recorder) *Service {
+	return &Service{store: store, auditor: auditor}
 }
 
-func (s *Service) GetByID(id uint) (*models.CafeListing, error) {
+func (s *Service) GetByID(id uint) (*models.Cafe, error) {
 	return s.store.GetByID(id)
 }
 
-func (s *Service) ListDiscovery(query, city, sort string, limit int) ([]models.CafeListing, error) {
-	return s.store.ListDiscovery(DiscoveryFilter{
-		Query: strings.TrimSpace(query),
-		City:  strings.TrimSpace(city),
-		Sort:  strings.TrimSpace(sort),
-		Limit: limit,
-	})
+// GetForViewer is GetByID with the viewer's saved place filled in; viewerID 0 is an anonymous viewer.
+func (s *Service) GetForViewer(id, viewerID uint) (*models.Cafe, error) {
+	if viewerID == 0 {
+		return s.store.GetByID(id)
+	}
+	return s.store.GetForUser(id, viewerID)
 }
 
-func (s *Service) GetByUserID(userID uint) ([]models.CafeListing, error) {
-	return s.GetByUserIDFiltered(userID, "", "")
+func (s *Service) ListDiscovery(filter DiscoveryFilter, page pagination.Request) (pagination.Page[models.Cafe], error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	filter.City = strings.TrimSpace(filter.City)
+	filter.Sort = strings.TrimSpace(filter.Sort)
+	if filter.RadiusM != 0 && (filter.Near == nil || !(filter.RadiusM > 0 && filter.RadiusM <= MaxRadiusM)) {
+		return pagination.Page[models.Cafe]{}, ErrInvalidRadius
+	}
+	if filter.Sort == "distance" && filter.Near == nil {
+		return pagination.Page[models.Cafe]{}, ErrDistanceSortRequiresNear
+	}
+	return s.store.ListDiscovery(filter, page)
 }
 
-func (s *Service) GetByUserIDFiltered(userID uint, visitStatus, sort string) ([]models.CafeListing, error) {
+func (s *Service) GetByUserIDFiltered(userID uint, visitStatus, sort string, page pagination.Request) (pagination.Page[models.Cafe], error) {
 	status, err := normalizeVisitStatus(visitStatus)
 	if err != nil {
-		return nil, err
+		return pagination.Page[models.Cafe]{}, err
 	}
 	if visitStatus == "" {
 		status = ""
 	}
 	return s.store.GetByUserIDFiltered(userID, ListFilter{
 		VisitStatus: status,
-		Sort:        sort,
-	})
+		Sort:        strings.TrimSpace(sort),
+	}, page)
 }
 
-func (s *Service) CreateListing(listing *models.CafeListing) error {
+// CreateListing saves a cafe to listing.UserID's list. The cafe is the one named by SourceCafeID, else the live
+// cafe with the same ExternalPlaceID, else a new cafe from listing, which that user then owns. Saving a cafe the
+// user already saved updates its visit status and notes. On success listing is replaced by the user's view of it.
+func (s *Service) CreateListing(listing *models.Cafe) error {
 	if err := sanitizeListing(listing); err != nil {
 		return err
 	}
-
-	status, err := normalizeVisitStatus(listing.VisitStatus)
+	place, err := savedPlaceFrom(listing.UserID, *listing)
 	if err != nil {
 		return err
 	}
-	listing.VisitStatus = status
+	listing.HiddenAt = nil
 
+	cafe := listing
 	if listing.SourceCafeID != nil {
-		source, err := s.store.GetByID(*listing.SourceCafeID)
+		sourceID := *listing.SourceCafeID
+		if canonical, err := s.store.ResolveRedirect(sourceID); err != nil {
+			return err
+		} else if canonical != 0 {
+			sourceID = canonical
+		}
+		cafe, err = s.store.GetByID(sourceID)
 		if err != nil {
 			return err
 		}
-		if source == nil {
+		if cafe == nil {
 			return gorm.ErrRecordNotFound
 		}
-		if source.SourceCafeID != nil {
-			listing.SourceCafeID = source.SourceCafeID
+	} else if listing.ExternalPlaceID != "" {
+		existing, err := s.store.GetByExternalPlaceID(listing.ExternalPlaceID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			cafe = existing
 		}
 	}
-
-	return s.store.Create(listing)
+	if err := s.store.Save(cafe, place); err != nil {
+		return err
+	}
+	saved, err := s.store.GetForUser(cafe.ID, place.UserID)
+	if err != nil {
+		return err
+	}
+	if saved == nil {
+		return gorm.ErrRecordNotFound
+	}
+	*listing = *saved
+	return nil
 }
 
-func (s *Service) UpdateListing(id uint, userID uint, updated models.CafeListing) error {
+// UpdateListing lets the user who added the cafe edit its details, and anyone who saved it change their visit
+// status and notes. Other users get ErrDetailsNotEditable if they try to change a detail; details they leave empty
+// or send back unchanged are fine, so a client can send back the whole listing it read.
+func (s *Service) UpdateListing(id uint, userID uint, updated models.Cafe) error {
 	existing, err := s.store.GetByID(id)
 	if err != nil || existing == nil {
 		return err
 	}
-	if existing.UserID != userID {
+	saved, err := s.store.GetSavedPlace(userID, id)
+	if err != nil {
+		return err
+	}
+	owner := existing.UserID == userID
+	if !owner && saved == nil {
 		return ErrNotOwner
 	}
-	status, err := normalizeVisitStatus(updated.VisitStatus)
+	place, err := savedPlaceFrom(userID, updated)
 	if err != nil {
 		return err
 	}
-	if err := sanitizeListing(&updated); err != nil {
-		return err
+	if owner {
+		if err := sanitizeListing(&updated); err != nil {
+			return err
+		}
+		updated.SourceProvider = existing.SourceProvider
+		updated.ExternalPlaceID = existing.ExternalPlaceID
+		err := s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+			if err := store.Update(id, updated); err != nil {
+				return err
+			}
+			after, err := store.GetByID(id)
+			if err != nil {
+				return err
+			}
+			return s.record(tx, userID, audit.ActionUpdate, id, existing, after)
+		})
+		if err != nil {
+			return err
+		}
+	} else if editsDetails(existing, updated) {
+		return ErrDetailsNotEditable
+	}
+	if saved == nil {
+		return nil
 	}
-	updated.VisitStatus = status
-	updated.SourceCafeID = existing.SourceCafeID
-	updated.SourceProvider = existing.SourceProvider
-	updated.ExternalPlaceID = existing.ExternalPlaceID
-	return s.store.Update(id, updated)
+	place.CafeID = id
+	return s.store.UpdateSavedPlace(place)
 }
 
+// DeleteListing takes the cafe off the user's list. The cafe itself is deleted only when the user added it and
+// nobody else has saved or rated it.
 func (s *Service) DeleteListing(id uint, userID uint) error {
 	existing, err := s.store.GetByID(id)
 	if err != nil {
@@ -103,13 +171,51 @@ func (s *Service) DeleteListing(id uint, userID uint) error {
 	if existing == nil {
 		return gorm.ErrRecordNotFound
 	}
-	if existing.UserID != userID {
+	saved, err := s.store.GetSavedPlace(userID, id)
+	if err != nil {
+		return err
+	}
+	if existing.UserID != userID && saved == nil {
 		return ErrNotOwner
 	}
-	return s.store.Delete(id)
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		deleted, err := store.Remove(id, userID)
+		if err != nil || !deleted {
+			return err
+		}
+		return s.record(tx, userID, audit.ActionDelete, id, existing, nil)
+	})
 }
 
-func (s *Service) IsListingVisited(id uint) (bool, error) {
+// Restore brings back a soft-deleted listing with the ratings deleted along with it (implements audit.Restorer).
+func (s *Service) Restore(id, actorID uint, deletedSince time.Time) error {
+	deleted, err := s.store.GetWithDeleted(id)
+	if err != nil {
+		return err
+	}
+	if deleted == nil {
+		return gorm.ErrRecordNotFound
+	}
+	if !deleted.DeletedAt.Valid {
+		return audit.ErrNotDeleted
+	}
+	if deleted.DeletedAt.Time.Before(deletedSince) {
+		return audit.ErrRestoreWindowPassed
+	}
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if err := store.Restore(id, deleted.DeletedAt.Time); err != nil {
+			return err
+		}
+		restored, err := store.GetByID(id)
+		if err != nil {
+			return err
+		}
+		return s.record(tx, actorID, audit.ActionRestore, id, nil, restored)
+	})
+}
+
+// IsVisitedBy reports whether the user has marked the cafe visited in their saved places.
+func (s *Service) IsVisitedBy(id, userID uint) (bool, error) {
 	existing, err := s.store.GetByID(id)
 	if err != nil {
 		return false, err
@@ -117,10 +223,76 @@ func (s *Service) IsListingVisited(id uint) (bool, error) {
 	if existing == nil {
 		return false, gorm.ErrRecordNotFound
 	}
-	return existing.VisitStatus == VisitStatusVisited, nil
+	saved, err := s.store.GetSavedPlace(userID, id)
+	if err != nil {
+		return false, err
+	}
+	return saved != nil && saved.VisitStatus == VisitStatusVisited, nil
+}
+
+// ResolveRedirect returns the cafe a listing id folded into a canonical cafe now points to, or 0.
+func (s *Service) ResolveRedirect(id uint) (uint, error) {
+	return s.store.ResolveRedirect(id)
+}
+
+// record audits a change to a listing through tx, the transaction making it.
+func (s *Service) record(tx *gorm.DB, actorID uint, action string, id uint, before, after *models.Cafe) error {
+	if s.auditor == nil {
+		return nil
+	}
+	return s.auditor.Record(tx, audit.Change{ActorID: actorID, Action: action, EntityType: audit.EntityCafeListing, EntityID: id, Before: before, After: after})
+}
+
+// savedPlaceFrom takes the visit status and notes the user sent with listing.
+func savedPlaceFrom(userID uint, listing models.Cafe) (models.SavedPlace, error) {
+	status, err := normalizeVisitStatus(listing.VisitStatus)
+	if err != nil {
+		return models.SavedPlace{}, err
+	}
+	notes := strings.TrimSpace(listing.Notes)
+	if len(notes) > MaxNotesLength {
+		return models.SavedPlace{}, ErrNotesTooLong
+	}
+	return models.SavedPlace{UserID: userID, VisitStatus: status, Notes: notes}, nil
+}
+
+// editsDetails reports whether updated sets a detail of cafe to a different value. Empty fields count as not sent.
+func editsDetails(cafe *models.Cafe, updated models.Cafe) bool {
+	for _, field := range [][2]string{
+		{updated.Name, cafe.Name},
+		{updated.Address, cafe.Address},
+		{updated.City, cafe.City},
+		{updated.Neighborhood, cafe.Neighborhood},
+		{updated.Description, cafe.Description},
+		{updated.ImageURL, cafe.ImageURL},
+		{updated.SourceProvider, cafe.SourceProvider},
+		{updated.ExternalPlaceID, cafe.ExternalPlaceID},
+	} {
+		if sent := strings.TrimSpace(field[0]); sent != "" && sent != field[1] {
+			return true
+		}
+	}
+	if updated.Latitude != nil && (cafe.Latitude == nil || *updated.Latitude != *cafe.Latitude) ||
+		updated.Longitude != nil && (cafe.Longitude == nil || *updated.Longitude != *cafe.Longitude) ||
+		updated.PriceLevel != nil && (cafe.PriceLevel == nil || *updated.PriceLevel != *cafe.PriceLevel) {
+		return true
+	}
+	if len(updated.Amenities) > 0 {
+		amenities, err := normalizeAmenities(updated.Amenities)
+		if err != nil || !slices.Equal(amenities, cafe.Amenities) {
+			return true
+		}
+	}
+	if updated.OpeningHours != nil {
+		hours, err := normalizeOpeningHours(updated.OpeningHours)
+		if err != nil || (hours != nil && !reflect.DeepEqual(hours, cafe.OpeningHours)) {
+			return true
+		}
+	}
+	return false
 }
 
-func sanitizeListing(listing *models.CafeListing) error {
+func sanitizeListing(listing *models.Cafe) error {
 	listing.Name = strings.TrimSpace(listing.Name)
 	listing.Address = strings.TrimSpace(listing.Address)
 	listing.City = strings.TrimSpace(listing.City)
@@ -134,6 +306,10 @@ func sanitizeListing(listing *models.CafeListing) error {
 		return ErrInvalidCafeName
 	}
 
+	if listing.ImageURL != "" && !validImageURL(listing.ImageURL) {
+		return ErrInvalidImageURL
+	}
+
 	if (listing.Latitude == nil) != (listing.Longitude == nil) {
 		return ErrInvalidCoordinates
 	}
@@ -146,5 +322,27 @@ func sanitizeListing(listing *models.CafeListing) error {
 		return ErrInvalidCoordinates
 	}
 
+	amenities, err := normalizeAmenities(listing.Amenities)
+	if err != nil {
+		return err
+	}
+	listing.Amenities = amenities
+
+	if !validPriceLevel(listing.PriceLevel) {
+		return ErrInvalidPriceLevel
+	}
+
+	hours, err := normalizeOpeningHours(listing.OpeningHours)
+	if err != nil {
+		return err
+	}
+	listing.OpeningHours = hours
+
 	return nil
 }
+
+// validImageURL accepts absolute http(s) URLs, which is what the frontend renders in <img> tags.
+func validImageURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
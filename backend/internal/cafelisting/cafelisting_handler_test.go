@@ -6,8 +6,10 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -88,3 +90,67 @@ func TestAddressAutocompleteHandler_ProviderError(t *testing.T) {
 	h.AddressAutocompleteHandler(rec, req)
 	require.Equal(t, http.StatusBadGateway, rec.Code)
 }
+
+func TestAutocompleteChain_FailsOverToNominatim(t *testing.T) {
+	nominatim := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search", r.URL.Path)
+		assert.Equal(t, "15th Ave", r.URL.Query().Get("q"))
+		assert.Equal(t, "go-cafe-test", r.UserAgent())
+		_, _ = w.Write([]byte(`[{"lat":"47.62","lon":"-122.31","display_name":"411, 15th Avenue East, Seattle, 98112, United States","address":{"house_number":"411","road":"15th Avenue East","city":"Seattle","postcode":"98112","country":"United States"}}]`))
+	}))
+	defer nominatim.Close()
+
+	chain := NewAutocompleteChain(
+		mockAutocompleteProvider{err: &upstream.StatusError{Service: "geoapify request", StatusCode: 429}},
+		NewNominatimClient(nominatim.URL+"/", "go-cafe-test"),
+	)
+	results, err := chain.Autocomplete(context.Background(), "15th Ave", 3)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "411 15th Avenue East", results[0].AddressLine1)
+	assert.Equal(t, "Seattle, 98112, United States", results[0].AddressLine2)
+	assert.Equal(t, "Seattle", results[0].City)
+	assert.Equal(t, 47.62, results[0].Lat)
+
+	_, err = NewAutocompleteChain(mockAutocompleteProvider{err: errors.New("bad key")}, NewNominatimClient(nominatim.URL, "go-cafe-test")).
+		Autocomplete(context.Background(), "15th Ave", 3)
+	assert.EqualError(t, err, "bad key")
+}
+
+func TestFollowRedirects(t *testing.T) {
+	m := &mockCafeStorage{redirects: map[uint]uint{12: 3}}
+	svc := NewService(m, nil)
+	// Stands in for the routes: cafe 3 exists, every other cafe is missing.
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v1/cafes/3") || strings.HasPrefix(r.URL.Path, "/api/v1/users/") {
+			w.WriteHeader(http.StatusTeapot)
+			return
+		}
+		http.Error(w, "Cafe listing not found", http.StatusNotFound)
+	})
+	handler := FollowRedirects(svc, "/api/v1")(next)
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+		location     string
+		lookups      int
+	}{
+		{http.MethodGet, "/api/v1/cafes/12", http.StatusPermanentRedirect, "/api/v1/cafes/3", 1},
+		{http.MethodPost, "/api/v1/cafes/12/ratings?sort=helpful", http.StatusPermanentRedirect, "/api/v1/cafes/3/ratings?sort=helpful", 1},
+		{http.MethodGet, "/api/v1/cafes/3", http.StatusTeapot, "", 0},
+		{http.MethodGet, "/api/v1/cafes/40", http.StatusNotFound, "", 1},
+		{http.MethodGet, "/api/v1/cafes/autocomplete", http.StatusNotFound, "", 0},
+		{http.MethodGet, "/api/v1/users/12/cafes", http.StatusTeapot, "", 0},
+	} {
+		m.lookups = 0
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.code, rec.Code, tc.path)
+		assert.Equal(t, tc.location, rec.Header().Get("Location"), tc.path)
+		assert.Equal(t, tc.lookups, m.lookups, tc.path)
+		if tc.code == http.StatusNotFound {
+			assert.Equal(t, "Cafe listing not found\n", rec.Body.String())
+		}
+	}
+}
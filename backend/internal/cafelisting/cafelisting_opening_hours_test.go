@@ -0,0 +1,97 @@
+package cafelisting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsOpenAt(t *testing.T) {
+	hours := &models.OpeningHours{
+		TimeZone: "Asia/Singapore",
+		Weekly: []models.OpeningPeriod{
+			{Day: "mon", Open: "08:00", Close: "12:00"},
+			{Day: "mon", Open: "13:00", Close: "18:00"},
+			{Day: "fri", Open: "18:00", Close: "02:00"},
+			{Day: "sun", Open: "00:00", Close: "24:00"},
+		},
+		Exceptions: []models.OpeningException{
+			{Date: "2026-10-19", Closed: true},
+			{Date: "2026-10-23", Periods: []models.TimeRange{{Open: "09:00", Close: "11:00"}}},
+		},
+	}
+	sgt := time.FixedZone("SGT", 8*60*60)
+	at := func(day, clock int) time.Time {
+		return time.Date(2026, 10, day, clock/100, clock%100, 0, 0, sgt)
+	}
+
+	cases := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"monday morning", at(12, 900), true},
+		{"monday lunch break", at(12, 1230), false},
+		{"monday closing time is exclusive", at(12, 1800), false},
+		{"monday evening in UTC", time.Date(2026, 10, 12, 9, 30, 0, 0, time.UTC), true},
+		{"friday late", at(16, 2330), true},
+		{"overnight into saturday", at(17, 130), true},
+		{"after overnight close", at(17, 200), false},
+		{"sunday all day", at(18, 2359), true},
+		{"holiday monday", at(19, 900), false},
+		{"special friday", at(23, 1000), true},
+		{"special friday replaces evening", at(23, 1900), false},
+		{"special friday drops overnight spill", at(24, 100), false},
+		{"tuesday", at(13, 1000), false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, IsOpenAt(hours, tc.at), tc.name)
+	}
+	assert.False(t, IsOpenAt(nil, at(12, 900)))
+}
+
+func TestParseOSMOpeningHours(t *testing.T) {
+	hours, err := ParseOSMOpeningHours("Mo-Fr 07:30-12:00,13:00-18:00; Sa 9:00-14:00; Su off; PH off", "Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", hours.TimeZone)
+	assert.Equal(t, []models.OpeningPeriod{
+		{Day: "mon", Open: "07:30", Close: "12:00"},
+		{Day: "mon", Open: "13:00", Close: "18:00"},
+		{Day: "tue", Open: "07:30", Close: "12:00"},
+		{Day: "tue", Open: "13:00", Close: "18:00"},
+		{Day: "wed", Open: "07:30", Close: "12:00"},
+		{Day: "wed", Open: "13:00", Close: "18:00"},
+		{Day: "thu", Open: "07:30", Close: "12:00"},
+		{Day: "thu", Open: "13:00", Close: "18:00"},
+		{Day: "fri", Open: "07:30", Close: "12:00"},
+		{Day: "fri", Open: "13:00", Close: "18:00"},
+		{Day: "sat", Open: "09:00", Close: "14:00"},
+	}, hours.Weekly)
+
+	// Later rules override earlier ones for the days they name, and ranges may wrap around the week.
+	hours, err = ParseOSMOpeningHours("08:00-17:00; Fr-Mo 10:00-22:00; We off", "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, []models.OpeningPeriod{
+		{Day: "mon", Open: "10:00", Close: "22:00"},
+		{Day: "tue", Open: "08:00", Close: "17:00"},
+		{Day: "thu", Open: "08:00", Close: "17:00"},
+		{Day: "fri", Open: "10:00", Close: "22:00"},
+		{Day: "sat", Open: "10:00", Close: "22:00"},
+		{Day: "sun", Open: "10:00", Close: "22:00"},
+	}, hours.Weekly)
+
+	hours, err = ParseOSMOpeningHours("24/7", "UTC")
+	require.NoError(t, err)
+	assert.Len(t, hours.Weekly, 7)
+	assert.True(t, IsOpenAt(hours, time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC)))
+
+	for _, spec := range []string{"", "Jan-Mar Mo-Fr 08:00-17:00", "Mo-Fr sunrise-sunset", "Mo-Fr 08:00+", "Su off"} {
+		_, err := ParseOSMOpeningHours(spec, "UTC")
+		assert.ErrorIs(t, err, ErrInvalidOpeningHours, spec)
+	}
+	_, err = ParseOSMOpeningHours("Mo-Fr 08:00-17:00", "")
+	assert.ErrorIs(t, err, ErrInvalidOpeningHours)
+}
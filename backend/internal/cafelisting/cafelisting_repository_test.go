@@ -0,0 +1,488 @@
+package cafelisting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func newListingTestRepo(t *testing.T) (*Repository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "gocafe_"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Cafe{}, &models.SavedPlace{}, &models.CafeRedirect{}, &models.Rating{}))
+	return NewRepository(db), db
+}
+
+// seedListings creates listings saved by user 1 with deliberate ties in name, status, timestamps and rating stats
+// so every keyset order has to fall back to its later keys.
+func seedListings(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	seed := []struct {
+		name    string
+		status  string
+		age     time.Duration
+		ratings []int
+	}{
+		{"Brew", VisitStatusVisited, 0, []int{5, 4}},
+		{"Acorn", VisitStatusToVisit, time.Hour, nil},
+		{"Brew", VisitStatusToVisit, time.Hour, []int{3}},
+		{"Cortado", VisitStatusVisited, 2 * time.Hour, []int{5, 4}},
+		{"Acorn", VisitStatusVisited, 0, []int{4}},
+		{"Drip", VisitStatusToVisit, 3 * time.Hour, nil},
+		{"Espresso", VisitStatusVisited, time.Hour, []int{5}},
+	}
+	for i, s := range seed {
+		at := base.Add(-s.age)
+		listing := models.Cafe{UserID: 1, Name: s.name, CreatedAt: at, UpdatedAt: at}
+		require.NoError(t, db.Create(&listing).Error)
+		require.NoError(t, db.Create(&models.SavedPlace{UserID: 1, CafeID: listing.ID, VisitStatus: s.status, CreatedAt: at, UpdatedAt: at}).Error)
+		for j, value := range s.ratings {
+			require.NoError(t, db.Create(&models.Rating{UserID: uint(100 + i*10 + j), CafeListingID: listing.ID, VisitedAt: at, Rating: value}).Error)
+		}
+	}
+}
+
+func listingIDs(listings []models.Cafe) []uint {
+	ids := make([]uint, len(listings))
+	for i, l := range listings {
+		ids[i] = l.ID
+	}
+	return ids
+}
+
+func walkPages(t *testing.T, fetch func(pagination.Request) (pagination.Page[models.Cafe], error), limit int) []uint {
+	t.Helper()
+	var ids []uint
+	req := pagination.Request{Limit: limit}
+	for pages := 0; pages < 20; pages++ {
+		page, err := fetch(req)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Items), limit)
+		ids = append(ids, listingIDs(page.Items)...)
+		if page.NextCursor == nil {
+			return ids
+		}
+		req.Cursor = *page.NextCursor
+	}
+	t.Fatal("pagination did not terminate")
+	return nil
+}
+
+func TestRepository_GetByUserIDFiltered_PagesMatchSingleQuery(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	seedListings(t, db)
+
+	for sort := range ownerOrders {
+		t.Run(sort, func(t *testing.T) {
+			filter := ListFilter{Sort: sort}
+			all, err := repo.GetByUserIDFiltered(1, filter, pagination.Request{Limit: 100})
+			require.NoError(t, err)
+			require.Len(t, all.Items, 7)
+			assert.Nil(t, all.NextCursor)
+
+			paged := walkPages(t, func(req pagination.Request) (pagination.Page[models.Cafe], error) {
+				return repo.GetByUserIDFiltered(1, filter, req)
+			}, 2)
+			assert.Equal(t, listingIDs(all.Items), paged)
+		})
+	}
+}
+
+func TestRepository_GetByUserIDFiltered_SortOrder(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	seedListings(t, db)
+
+	page, err := repo.GetByUserIDFiltered(1, ListFilter{Sort: "name_asc"}, pagination.Request{Limit: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2, 5, 1, 3, 4, 6, 7}, listingIDs(page.Items))
+
+	page, err = repo.GetByUserIDFiltered(1, ListFilter{}, pagination.Request{Limit: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{5, 1, 7, 3, 2, 4, 6}, listingIDs(page.Items))
+
+	page, err = repo.GetByUserIDFiltered(1, ListFilter{VisitStatus: VisitStatusToVisit}, pagination.Request{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3, 2}, listingIDs(page.Items))
+	require.NotNil(t, page.NextCursor)
+}
+
+func TestRepository_ListDiscovery_PagesMatchSingleQuery(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	seedListings(t, db)
+
+	for sort := range discoveryOrders {
+		t.Run(sort, func(t *testing.T) {
+			filter := DiscoveryFilter{Sort: sort}
+			all, err := repo.ListDiscovery(filter, pagination.Request{Limit: MaxDiscoveryLimit})
+			require.NoError(t, err)
+			require.Len(t, all.Items, 7)
+
+			paged := walkPages(t, func(req pagination.Request) (pagination.Page[models.Cafe], error) {
+				return repo.ListDiscovery(filter, req)
+			}, 3)
+			assert.Equal(t, listingIDs(all.Items), paged)
+		})
+	}
+
+	page, err := repo.ListDiscovery(DiscoveryFilter{Sort: "rating_desc"}, pagination.Request{Limit: 100})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 7, "limit is capped at MaxDiscoveryLimit, not rejected")
+	assert.Equal(t, uint(7), page.Items[0].ID)
+	assert.Equal(t, 5.0, page.Items[0].AvgRating)
+}
+
+func TestRepository_ListDiscovery_RejectsCursorFromAnotherSort(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	seedListings(t, db)
+
+	page, err := repo.ListDiscovery(DiscoveryFilter{Sort: "newest"}, pagination.Request{Limit: 2})
+	require.NoError(t, err)
+	require.NotNil(t, page.NextCursor)
+
+	_, err = repo.ListDiscovery(DiscoveryFilter{Sort: "name_asc"}, pagination.Request{Limit: 2, Cursor: *page.NextCursor})
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+
+	_, err = repo.ListDiscovery(DiscoveryFilter{Sort: "newest"}, pagination.Request{Limit: 2, Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
+
+func TestRepository_ListDiscovery_QueryWithoutFullTextFallsBackToSubstring(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	seedListings(t, db)
+
+	// SQLite has no search_vector, so the default relevance sort uses the popular order over LIKE matches.
+	page, err := repo.ListDiscovery(DiscoveryFilter{Query: "BREW"}, pagination.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1, 3}, listingIDs(page.Items))
+
+	next, err := repo.ListDiscovery(DiscoveryFilter{Query: "brew", Sort: "relevance"}, pagination.Request{Limit: 1})
+	require.NoError(t, err)
+	require.NotNil(t, next.NextCursor)
+	rest, err := repo.ListDiscovery(DiscoveryFilter{Query: "brew", Sort: "relevance"}, pagination.Request{Limit: 1, Cursor: *next.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3}, listingIDs(rest.Items))
+}
+
+func TestRepository_ListDiscovery_GeoFilters(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	for _, seed := range []struct {
+		name     string
+		lat, lon float64
+	}{
+		{"Tanjong Pagar", 1.2764, 103.8459},
+		{"Orchard", 1.3048, 103.8318},
+		{"Johor Bahru", 1.4927, 103.7414},
+		{"Suva", -18.1416, 178.4419},
+		{"Apia", -13.8333, -171.7667},
+	} {
+		lat, lon := seed.lat, seed.lon
+		require.NoError(t, db.Create(&models.Cafe{UserID: 1, Name: seed.name, Latitude: &lat, Longitude: &lon}).Error)
+	}
+	require.NoError(t, db.Create(&models.Cafe{UserID: 1, Name: "Nowhere"}).Error)
+
+	names := func(filter DiscoveryFilter) []string {
+		t.Helper()
+		filter.Sort = "name_asc"
+		page, err := repo.ListDiscovery(filter, pagination.Request{Limit: MaxDiscoveryLimit})
+		require.NoError(t, err)
+		var out []string
+		for _, listing := range page.Items {
+			out = append(out, listing.Name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"Orchard", "Tanjong Pagar"}, names(DiscoveryFilter{BBox: &BBox{MinLon: 103.6, MinLat: 1.2, MaxLon: 104.1, MaxLat: 1.48}}))
+	// minLon > maxLon crosses the antimeridian.
+	assert.Equal(t, []string{"Apia", "Suva"}, names(DiscoveryFilter{BBox: &BBox{MinLon: 170, MinLat: -25, MaxLon: -165, MaxLat: -10}}))
+
+	// Near keeps only listings with coordinates; SQLite approximates the radius by its bounding box.
+	assert.Equal(t, []string{"Apia", "Johor Bahru", "Orchard", "Suva", "Tanjong Pagar"}, names(DiscoveryFilter{Near: &GeoPoint{Lat: 1.29, Lon: 103.85}}))
+	assert.Equal(t, []string{"Orchard", "Tanjong Pagar"}, names(DiscoveryFilter{Near: &GeoPoint{Lat: 1.29, Lon: 103.85}, RadiusM: 5000}))
+
+	// Distance sort needs earthdistance, so SQLite falls back to the default order and leaves distance_m unset.
+	page, err := repo.ListDiscovery(DiscoveryFilter{Near: &GeoPoint{Lat: 1.29, Lon: 103.85}, Sort: "distance"}, pagination.Request{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 5)
+	assert.Nil(t, page.Items[0].DistanceM)
+}
+
+func TestRepository_ListDiscovery_AttributeFilters(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	alwaysOpen := &models.OpeningHours{TimeZone: "UTC", Weekly: []models.OpeningPeriod{
+		{Day: "mon", Open: "00:00", Close: "24:00"}, {Day: "tue", Open: "00:00", Close: "24:00"}, {Day: "wed", Open: "00:00", Close: "24:00"},
+		{Day: "thu", Open: "00:00", Close: "24:00"}, {Day: "fri", Open: "00:00", Close: "24:00"}, {Day: "sat", Open: "00:00", Close: "24:00"},
+		{Day: "sun", Open: "00:00", Close: "24:00"},
+	}}
+	mornings := &models.OpeningHours{TimeZone: "UTC", Weekly: []models.OpeningPeriod{{Day: "sat", Open: "07:00", Close: "11:00"}}}
+	level := func(n int) *int { return &n }
+	for _, listing := range []models.Cafe{
+		{Name: "Anchor", Amenities: []string{"outlets", "wifi"}, PriceLevel: level(1), OpeningHours: alwaysOpen},
+		{Name: "Bloom", Amenities: []string{"wifi"}, PriceLevel: level(2), OpeningHours: mornings},
+		{Name: "Crema", Amenities: []string{"oat_milk", "outlets"}, PriceLevel: level(3)},
+		{Name: "Dusk"},
+	} {
+		listing.UserID = 1
+		require.NoError(t, db.Create(&listing).Error)
+	}
+
+	names := func(filter DiscoveryFilter) []string {
+		t.Helper()
+		filter.Sort = "name_asc"
+		page, err := repo.ListDiscovery(filter, pagination.Request{Limit: MaxDiscoveryLimit})
+		require.NoError(t, err)
+		out := []string{}
+		for _, listing := range page.Items {
+			out = append(out, listing.Name)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"Anchor", "Bloom"}, names(DiscoveryFilter{Amenities: []string{"wifi"}}))
+	assert.Equal(t, []string{"Anchor"}, names(DiscoveryFilter{Amenities: []string{"outlets", "wifi"}}))
+	assert.Equal(t, []string{"Bloom", "Crema"}, names(DiscoveryFilter{PriceLevels: []int{2, 3}}))
+
+	saturdayMorning := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	saturdayEvening := time.Date(2026, 10, 17, 19, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"Anchor", "Bloom"}, names(DiscoveryFilter{OpenAt: &saturdayMorning}))
+	assert.Equal(t, []string{"Anchor"}, names(DiscoveryFilter{OpenAt: &saturdayEvening}))
+	assert.Equal(t, []string{"Bloom"}, names(DiscoveryFilter{OpenAt: &saturdayMorning, PriceLevels: []int{2}}))
+
+	listing, err := repo.GetByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, alwaysOpen, listing.OpeningHours)
+	assert.Equal(t, []string{"outlets", "wifi"}, listing.Amenities)
+}
+
+func TestRepository_ListDiscovery_DimensionAverages(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	score := func(n int) *int { return &n }
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, seed := range []struct {
+		name    string
+		ratings []models.Rating
+	}{
+		{"Laptop Loft", []models.Rating{{Rating: 3, WorkScore: score(5), CoffeeScore: score(2)}, {Rating: 4, WorkScore: score(4)}}},
+		{"Espresso Bar", []models.Rating{{Rating: 5, WorkScore: score(1), CoffeeScore: score(5)}}},
+		{"Unscored", []models.Rating{{Rating: 5}}},
+		{"Quiet Corner", []models.Rating{{Rating: 4, WorkScore: score(4)}}},
+	} {
+		listing := models.Cafe{UserID: 1, Name: seed.name}
+		require.NoError(t, db.Create(&listing).Error)
+		for i, r := range seed.ratings {
+			r.UserID = uint(100 + i)
+			r.CafeListingID = listing.ID
+			r.VisitedAt = at
+			require.NoError(t, db.Create(&r).Error)
+		}
+	}
+
+	page, err := repo.ListDiscovery(DiscoveryFilter{Sort: "work_desc"}, pagination.Request{Limit: MaxDiscoveryLimit})
+	require.NoError(t, err)
+	var names []string
+	for _, listing := range page.Items {
+		names = append(names, listing.Name)
+	}
+	// Ties on the dimension fall back to the overall rating; listings without the sub-score come last.
+	assert.Equal(t, []string{"Laptop Loft", "Quiet Corner", "Espresso Bar", "Unscored"}, names)
+
+	loft := page.Items[0]
+	require.NotNil(t, loft.AvgWork)
+	assert.InDelta(t, 4.5, *loft.AvgWork, 0.001)
+	require.NotNil(t, loft.AvgCoffee)
+	assert.InDelta(t, 2, *loft.AvgCoffee, 0.001)
+	assert.Nil(t, loft.AvgAmbience)
+	assert.InDelta(t, 3.5, loft.AvgRating, 0.001)
+	assert.Nil(t, page.Items[3].AvgWork)
+
+	// Keyset pages line up with the single query.
+	paged := walkPages(t, func(req pagination.Request) (pagination.Page[models.Cafe], error) {
+		return repo.ListDiscovery(DiscoveryFilter{Sort: "work_desc"}, req)
+	}, 1)
+	assert.Equal(t, listingIDs(page.Items), paged)
+}
+
+func TestRepository_ListDiscovery_ExcludesHidden(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	shown := models.Cafe{UserID: 1, Name: "Shown"}
+	hidden := models.Cafe{UserID: 1, Name: "Hidden", HiddenAt: &at}
+	require.NoError(t, repo.Save(&shown, models.SavedPlace{UserID: 1, VisitStatus: VisitStatusVisited}))
+	require.NoError(t, repo.Save(&hidden, models.SavedPlace{UserID: 1, VisitStatus: VisitStatusVisited}))
+	require.NoError(t, db.Create(&models.Rating{UserID: 2, CafeListingID: shown.ID, VisitedAt: at, Rating: 4}).Error)
+	require.NoError(t, db.Create(&models.Rating{UserID: 3, CafeListingID: shown.ID, VisitedAt: at, Rating: 1, HiddenAt: &at}).Error)
+
+	page, err := repo.ListDiscovery(DiscoveryFilter{}, pagination.Request{Limit: MaxDiscoveryLimit})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Shown", page.Items[0].Name)
+	// The hidden rating is left out of the stats.
+	assert.Equal(t, int64(1), page.Items[0].ReviewCount)
+	assert.InDelta(t, 4, page.Items[0].AvgRating, 0.001)
+
+	// Owners still see their hidden listing.
+	mine, err := repo.GetByUserIDFiltered(1, ListFilter{}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, mine.Items, 2)
+}
+
+func TestRepository_Save_PlaceAddedConcurrently(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX idx_gocafe_cafes_external_place_id_active ON gocafe_cafes (external_place_id) WHERE external_place_id <> '' AND deleted_at IS NULL").Error)
+	first := models.Cafe{UserID: 1, Name: "Brew", ExternalPlaceID: "place-1"}
+	require.NoError(t, repo.Save(&first, models.SavedPlace{UserID: 1, VisitStatus: VisitStatusToVisit}))
+
+	// A second request that missed the first cafe in its lookup saves that cafe instead of failing.
+	second := models.Cafe{UserID: 2, Name: "Brew Again", ExternalPlaceID: "place-1"}
+	require.NoError(t, repo.Save(&second, models.SavedPlace{UserID: 2, VisitStatus: VisitStatusVisited}))
+	assert.Equal(t, first.ID, second.ID)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Cafe{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	listing, err := repo.GetForUser(first.ID, 2)
+	require.NoError(t, err)
+	require.NotNil(t, listing)
+	assert.Equal(t, "Brew", listing.Name)
+	assert.Equal(t, VisitStatusVisited, listing.VisitStatus)
+}
+
+func TestRepository_SaveAndGetForUser(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	cafe := models.Cafe{UserID: 1, Name: "Brew", ExternalPlaceID: "place-1"}
+	require.NoError(t, repo.Save(&cafe, models.SavedPlace{UserID: 1, VisitStatus: VisitStatusVisited}))
+	require.NotZero(t, cafe.ID)
+	require.NoError(t, repo.Save(&cafe, models.SavedPlace{UserID: 2, VisitStatus: VisitStatusToVisit, Notes: "maybe"}))
+	// Saving again updates the saved place instead of adding another.
+	require.NoError(t, repo.Save(&cafe, models.SavedPlace{UserID: 2, VisitStatus: VisitStatusVisited, Notes: "go early"}))
+
+	var count int64
+	require.NoError(t, db.Model(&models.SavedPlace{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+	require.NoError(t, db.Model(&models.Cafe{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	listing, err := repo.GetForUser(cafe.ID, 2)
+	require.NoError(t, err)
+	require.NotNil(t, listing)
+	assert.Equal(t, uint(1), listing.UserID)
+	assert.Equal(t, VisitStatusVisited, listing.VisitStatus)
+	assert.Equal(t, "go early", listing.Notes)
+	require.NotNil(t, listing.SavedAt)
+
+	// A viewer who has not saved the cafe sees it without a saved place.
+	listing, err = repo.GetForUser(cafe.ID, 3)
+	require.NoError(t, err)
+	require.NotNil(t, listing)
+	assert.Empty(t, listing.VisitStatus)
+	assert.Nil(t, listing.SavedAt)
+
+	byPlace, err := repo.GetByExternalPlaceID("place-1")
+	require.NoError(t, err)
+	require.NotNil(t, byPlace)
+	assert.Equal(t, cafe.ID, byPlace.ID)
+
+	for _, userID := range []uint{1, 2} {
+		mine, err := repo.GetByUserIDFiltered(userID, ListFilter{VisitStatus: VisitStatusVisited}, pagination.Request{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{cafe.ID}, listingIDs(mine.Items))
+	}
+
+	require.NoError(t, repo.UpdateSavedPlace(models.SavedPlace{UserID: 2, CafeID: cafe.ID, VisitStatus: VisitStatusToVisit}))
+	assert.ErrorIs(t, repo.UpdateSavedPlace(models.SavedPlace{UserID: 3, CafeID: cafe.ID, VisitStatus: VisitStatusToVisit}), gorm.ErrRecordNotFound)
+	place, err := repo.GetSavedPlace(2, cafe.ID)
+	require.NoError(t, err)
+	require.NotNil(t, place)
+	assert.Equal(t, VisitStatusToVisit, place.VisitStatus)
+	assert.Empty(t, place.Notes)
+}
+
+func TestRepository_ResolveRedirect(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	require.NoError(t, db.Create(&models.CafeRedirect{FromID: 12, CafeID: 3}).Error)
+
+	to, err := repo.ResolveRedirect(12)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), to)
+	to, err = repo.ResolveRedirect(3)
+	require.NoError(t, err)
+	assert.Zero(t, to)
+}
+
+func TestRepository_RemoveAndRestore(t *testing.T) {
+	repo, db := newListingTestRepo(t)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, email := range []string{"owner@example.com", "saver@example.com", "critic@example.com"} {
+		require.NoError(t, db.Create(&models.User{Email: email}).Error)
+	}
+	cafe := models.Cafe{UserID: 1, Name: "Root", ExternalPlaceID: "place-1"}
+	require.NoError(t, repo.Save(&cafe, models.SavedPlace{UserID: 1, VisitStatus: VisitStatusVisited}))
+	require.NoError(t, repo.Save(&cafe, models.SavedPlace{UserID: 2, VisitStatus: VisitStatusToVisit}))
+	own := models.Rating{UserID: 1, CafeListingID: cafe.ID, VisitedAt: at, Rating: 4}
+	earlier := models.Rating{UserID: 3, CafeListingID: cafe.ID, VisitedAt: at, Rating: 1}
+	for _, r := range []*models.Rating{&own, &earlier} {
+		require.NoError(t, db.Create(r).Error)
+	}
+	// A rating its author deleted before the cafe went stays deleted after the restore.
+	require.NoError(t, db.Delete(&models.Rating{}, earlier.ID).Error)
+
+	// While another user has it saved, removing the cafe only takes it off the owner's list.
+	deleted, err := repo.Remove(cafe.ID, 1)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	listing, err := repo.GetForUser(cafe.ID, 1)
+	require.NoError(t, err)
+	require.NotNil(t, listing)
+	assert.Nil(t, listing.SavedAt)
+	require.NoError(t, repo.Save(&cafe, models.SavedPlace{UserID: 1, VisitStatus: VisitStatusVisited}))
+
+	// Other users only ever unsave it.
+	deleted, err = repo.Remove(cafe.ID, 2)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = repo.Remove(cafe.ID, 1)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	listing, err = repo.GetByID(cafe.ID)
+	require.NoError(t, err)
+	assert.Nil(t, listing)
+
+	gone, err := repo.GetWithDeleted(cafe.ID)
+	require.NoError(t, err)
+	require.True(t, gone.DeletedAt.Valid)
+
+	require.NoError(t, db.Delete(&models.User{}, 1).Error)
+	assert.ErrorIs(t, repo.Restore(cafe.ID, gone.DeletedAt.Time), ErrOwnerDeleted)
+	require.NoError(t, db.Unscoped().Model(&models.User{}).Where("id = ?", 1).Update("deleted_at", nil).Error)
+
+	again := models.Cafe{UserID: 3, Name: "Root again", ExternalPlaceID: "place-1"}
+	require.NoError(t, db.Create(&again).Error)
+	assert.ErrorIs(t, repo.Restore(cafe.ID, gone.DeletedAt.Time), ErrPlaceTaken)
+	require.NoError(t, db.Delete(&again).Error)
+
+	require.NoError(t, repo.Restore(cafe.ID, gone.DeletedAt.Time))
+	listing, err = repo.GetForUser(cafe.ID, 1)
+	require.NoError(t, err)
+	require.NotNil(t, listing)
+	assert.Equal(t, VisitStatusVisited, listing.VisitStatus)
+	assert.Equal(t, int64(1), listing.ReviewCount)
+	var stillDeleted models.Rating
+	require.NoError(t, db.Unscoped().First(&stillDeleted, earlier.ID).Error)
+	assert.True(t, stillDeleted.DeletedAt.Valid)
+	// The saver had unsaved it before, so their saved place stays deleted.
+	place, err := repo.GetSavedPlace(2, cafe.ID)
+	require.NoError(t, err)
+	assert.Nil(t, place)
+}
@@ -1,48 +1,92 @@
 package cafelisting
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 type mockCafeStorage struct {
-	listings   []models.CafeListing
-	getByID    *models.CafeListing
+	listings   []models.Cafe
+	places     []models.SavedPlace
+	getByID    *models.Cafe
 	getByIDErr error
+	byExternal *models.Cafe
 	createErr  error
 	updateErr  error
 	deleteErr  error
+
+	savedPlace   *models.SavedPlace
+	updated      bool
+	updatedPlace *models.SavedPlace
+	cafeDeleted  bool
+	redirects    map[uint]uint
+	lookups      int
+
+	withDeleted *models.Cafe
+	restoredAt  *time.Time
 }
 
-func (m *mockCafeStorage) Create(c *models.CafeListing) error {
+func (m *mockCafeStorage) Save(c *models.Cafe, place models.SavedPlace) error {
 	if m.createErr != nil {
 		return m.createErr
 	}
-	c.ID = uint(len(m.listings) + 1)
-	m.listings = append(m.listings, *c)
+	if c.ID == 0 {
+		c.ID = uint(len(m.listings) + 1)
+		m.listings = append(m.listings, *c)
+	}
+	place.CafeID = c.ID
+	m.places = append(m.places, place)
 	return nil
 }
 
-func (m *mockCafeStorage) GetByID(id uint) (*models.CafeListing, error) {
+func (m *mockCafeStorage) GetByID(id uint) (*models.Cafe, error) {
 	if m.getByIDErr != nil {
 		return nil, m.getByIDErr
 	}
 	return m.getByID, nil
 }
 
-func (m *mockCafeStorage) ListDiscovery(filter DiscoveryFilter) ([]models.CafeListing, error) {
-	return m.listings, nil
+func (m *mockCafeStorage) GetForUser(id, userID uint) (*models.Cafe, error) {
+	var cafe *models.Cafe
+	for i := range m.listings {
+		if m.listings[i].ID == id {
+			cafe = &m.listings[i]
+		}
+	}
+	if cafe == nil && m.getByID != nil && m.getByID.ID == id {
+		cafe = m.getByID
+	}
+	if cafe == nil {
+		return nil, nil
+	}
+	out := *cafe
+	for _, p := range m.places {
+		if p.CafeID == id && p.UserID == userID {
+			out.VisitStatus, out.Notes = p.VisitStatus, p.Notes
+		}
+	}
+	return &out, nil
+}
+
+func (m *mockCafeStorage) GetByExternalPlaceID(externalPlaceID string) (*models.Cafe, error) {
+	return m.byExternal, nil
 }
 
-func (m *mockCafeStorage) GetByUserID(userID uint) ([]models.CafeListing, error) {
-	return m.GetByUserIDFiltered(userID, ListFilter{})
+func (m *mockCafeStorage) ListDiscovery(filter DiscoveryFilter, page pagination.Request) (pagination.Page[models.Cafe], error) {
+	return pagination.Page[models.Cafe]{Items: m.listings}, nil
 }
 
-func (m *mockCafeStorage) GetByUserIDFiltered(userID uint, filter ListFilter) ([]models.CafeListing, error) {
-	var out []models.CafeListing
+func (m *mockCafeStorage) GetByUserIDFiltered(userID uint, filter ListFilter, page pagination.Request) (pagination.Page[models.Cafe], error) {
+	out := []models.Cafe{}
 	for _, l := range m.listings {
 		if l.UserID == userID {
 			if filter.VisitStatus != "" && l.VisitStatus != filter.VisitStatus {
@@ -51,80 +95,373 @@ func (m *mockCafeStorage) GetByUserIDFiltered(userID uint, filter ListFilter) ([
 			out = append(out, l)
 		}
 	}
-	return out, nil
+	return pagination.Page[models.Cafe]{Items: out}, nil
+}
+
+func (m *mockCafeStorage) Update(id uint, updated models.Cafe) error {
+	m.updated = true
+	return m.updateErr
+}
+
+func (m *mockCafeStorage) GetSavedPlace(userID, cafeID uint) (*models.SavedPlace, error) {
+	return m.savedPlace, nil
+}
+
+func (m *mockCafeStorage) UpdateSavedPlace(place models.SavedPlace) error {
+	m.updatedPlace = &place
+	return nil
+}
+
+func (m *mockCafeStorage) Remove(id, userID uint) (bool, error) {
+	return m.cafeDeleted, m.deleteErr
+}
+
+func (m *mockCafeStorage) GetWithDeleted(id uint) (*models.Cafe, error) {
+	return m.withDeleted, nil
+}
+
+func (m *mockCafeStorage) Restore(id uint, deletedAt time.Time) error {
+	m.restoredAt = &deletedAt
+	return nil
+}
+
+func (m *mockCafeStorage) ResolveRedirect(id uint) (uint, error) {
+	m.lookups++
+	return m.redirects[id], nil
+}
+
+func (m *mockCafeStorage) Transaction(fn func(tx *gorm.DB, store Storage) error) error {
+	return fn(nil, m)
 }
 
-func (m *mockCafeStorage) Update(id uint, updated models.CafeListing) error { return m.updateErr }
+type recordingAuditor struct {
+	changes []audit.Change
+	err     error
+}
 
-func (m *mockCafeStorage) Delete(id uint) error { return m.deleteErr }
+func (r *recordingAuditor) Record(tx *gorm.DB, change audit.Change) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.changes = append(r.changes, change)
+	return nil
+}
 
 func TestService_CreateListing(t *testing.T) {
 	m := &mockCafeStorage{}
-	svc := NewService(m)
-	listing := &models.CafeListing{UserID: 1, Name: "Cafe A", Address: "123"}
+	svc := NewService(m, nil)
+	listing := &models.Cafe{UserID: 1, Name: "Cafe A", Address: "123"}
 	err := svc.CreateListing(listing)
 	require.NoError(t, err)
 	require.Len(t, m.listings, 1)
 	assert.Equal(t, "Cafe A", m.listings[0].Name)
-	assert.Equal(t, VisitStatusToVisit, m.listings[0].VisitStatus)
+	require.Len(t, m.places, 1)
+	assert.Equal(t, models.SavedPlace{UserID: 1, CafeID: 1, VisitStatus: VisitStatusToVisit}, m.places[0])
+	assert.Equal(t, VisitStatusToVisit, listing.VisitStatus)
+}
+
+func TestService_CreateListing_SavesExistingCafe(t *testing.T) {
+	existing := &models.Cafe{ID: 7, UserID: 10, Name: "Brew", ExternalPlaceID: "place-1"}
+
+	// The same provider place is saved to the existing cafe rather than added again.
+	m := &mockCafeStorage{getByID: existing, byExternal: existing}
+	svc := NewService(m, nil)
+	listing := &models.Cafe{UserID: 1, Name: "Brew (copy)", ExternalPlaceID: " place-1 ", VisitStatus: "VISITED", Notes: " flat white "}
+	require.NoError(t, svc.CreateListing(listing))
+	assert.Empty(t, m.listings)
+	assert.Equal(t, []models.SavedPlace{{UserID: 1, CafeID: 7, VisitStatus: VisitStatusVisited, Notes: "flat white"}}, m.places)
+	assert.Equal(t, uint(7), listing.ID)
+	assert.Equal(t, "Brew", listing.Name)
+	assert.Equal(t, uint(10), listing.UserID, "the cafe still belongs to whoever added it")
+
+	// source_cafe_id names the cafe directly, following the redirect of a folded listing.
+	m = &mockCafeStorage{getByID: existing, redirects: map[uint]uint{3: 7}}
+	svc = NewService(m, nil)
+	sourceID := uint(3)
+	require.NoError(t, svc.CreateListing(&models.Cafe{UserID: 1, Name: "Brew", SourceCafeID: &sourceID}))
+	assert.Equal(t, uint(7), m.places[0].CafeID)
+
+	m = &mockCafeStorage{}
+	svc = NewService(m, nil)
+	assert.ErrorIs(t, svc.CreateListing(&models.Cafe{UserID: 1, Name: "Brew", SourceCafeID: &sourceID}), gorm.ErrRecordNotFound)
+}
+
+func TestService_CreateListing_NotesTooLong(t *testing.T) {
+	m := &mockCafeStorage{}
+	svc := NewService(m, nil)
+	err := svc.CreateListing(&models.Cafe{UserID: 1, Name: "Cafe A", Notes: strings.Repeat("a", MaxNotesLength+1)})
+	assert.ErrorIs(t, err, ErrNotesTooLong)
+	assert.Empty(t, m.places)
 }
 
 func TestService_CreateListing_InvalidVisitStatus(t *testing.T) {
 	m := &mockCafeStorage{}
-	svc := NewService(m)
-	listing := &models.CafeListing{UserID: 1, Name: "Cafe A", VisitStatus: "unknown"}
+	svc := NewService(m, nil)
+	listing := &models.Cafe{UserID: 1, Name: "Cafe A", VisitStatus: "unknown"}
 	err := svc.CreateListing(listing)
 	assert.ErrorIs(t, err, ErrInvalidVisitStatus)
 }
 
 func TestService_CreateListing_InvalidCoordinates(t *testing.T) {
 	m := &mockCafeStorage{}
-	svc := NewService(m)
+	svc := NewService(m, nil)
 	lat := 1.23
-	listing := &models.CafeListing{UserID: 1, Name: "Cafe A", Latitude: &lat}
+	listing := &models.Cafe{UserID: 1, Name: "Cafe A", Latitude: &lat}
 	err := svc.CreateListing(listing)
 	assert.ErrorIs(t, err, ErrInvalidCoordinates)
 }
 
+func TestService_CreateListing_InvalidImageURL(t *testing.T) {
+	m := &mockCafeStorage{}
+	svc := NewService(m, nil)
+	for _, imageURL := range []string{"not a url", "javascript:alert(1)", "/relative.jpg", "ftp://example.com/a.jpg"} {
+		err := svc.CreateListing(&models.Cafe{UserID: 1, Name: "Cafe A", ImageURL: imageURL})
+		assert.ErrorIs(t, err, ErrInvalidImageURL, imageURL)
+	}
+	require.NoError(t, svc.CreateListing(&models.Cafe{UserID: 1, Name: "Cafe A", ImageURL: " https://example.com/a.jpg "}))
+	assert.Equal(t, "https://example.com/a.jpg", m.listings[0].ImageURL)
+}
+
 func TestService_UpdateListing_NotOwner(t *testing.T) {
-	m := &mockCafeStorage{getByID: &models.CafeListing{ID: 1, UserID: 10}}
-	svc := NewService(m)
-	err := svc.UpdateListing(1, 99, models.CafeListing{Name: "X"})
+	m := &mockCafeStorage{getByID: &models.Cafe{ID: 1, UserID: 10}}
+	svc := NewService(m, nil)
+	err := svc.UpdateListing(1, 99, models.Cafe{Name: "X"})
 	assert.ErrorIs(t, err, ErrNotOwner)
 }
 
 func TestService_UpdateListing_Owner(t *testing.T) {
-	m := &mockCafeStorage{getByID: &models.CafeListing{ID: 1, UserID: 10, VisitStatus: VisitStatusToVisit}}
-	svc := NewService(m)
-	err := svc.UpdateListing(1, 10, models.CafeListing{Name: "New Name", VisitStatus: VisitStatusVisited})
+	m := &mockCafeStorage{getByID: &models.Cafe{ID: 1, UserID: 10}, savedPlace: &models.SavedPlace{ID: 4, UserID: 10, CafeID: 1, VisitStatus: VisitStatusToVisit}}
+	svc := NewService(m, nil)
+	err := svc.UpdateListing(1, 10, models.Cafe{Name: "New Name", VisitStatus: VisitStatusVisited})
+	require.NoError(t, err)
+	assert.True(t, m.updated)
+	require.NotNil(t, m.updatedPlace)
+	assert.Equal(t, VisitStatusVisited, m.updatedPlace.VisitStatus)
+}
+
+func TestService_UpdateListing_SaverChangesOnlyTheirSavedPlace(t *testing.T) {
+	lat, lng := 1.3, 103.8
+	cafe := &models.Cafe{ID: 1, UserID: 10, Name: "Shared Cafe", City: "Singapore", Latitude: &lat, Longitude: &lng, Amenities: []string{"outlets", "wifi"}}
+	m := &mockCafeStorage{getByID: cafe, savedPlace: &models.SavedPlace{ID: 4, UserID: 99, CafeID: 1, VisitStatus: VisitStatusToVisit}}
+	auditor := &recordingAuditor{}
+	svc := NewService(m, auditor)
+
+	// Changing a detail is refused rather than silently dropped.
+	err := svc.UpdateListing(1, 99, models.Cafe{Name: "Renamed", VisitStatus: VisitStatusVisited})
+	assert.ErrorIs(t, err, ErrDetailsNotEditable)
+	err = svc.UpdateListing(1, 99, models.Cafe{Amenities: []string{"wifi"}, VisitStatus: VisitStatusVisited})
+	assert.ErrorIs(t, err, ErrDetailsNotEditable)
+	assert.Nil(t, m.updatedPlace)
+
+	// Sending the cafe back as read, or only the saved place fields, updates the saver's saved place.
+	err = svc.UpdateListing(1, 99, models.Cafe{Name: " Shared Cafe ", City: "Singapore", Latitude: &lat, Longitude: &lng, Amenities: []string{"WiFi", "outlets"}, VisitStatus: VisitStatusVisited, Notes: "try the cortado"})
 	require.NoError(t, err)
-	assert.NoError(t, m.updateErr)
+	assert.False(t, m.updated, "only the user who added the cafe edits its details")
+	assert.Empty(t, auditor.changes)
+	assert.Equal(t, &models.SavedPlace{UserID: 99, CafeID: 1, VisitStatus: VisitStatusVisited, Notes: "try the cortado"}, m.updatedPlace)
+	require.NoError(t, svc.UpdateListing(1, 99, models.Cafe{VisitStatus: VisitStatusToVisit}))
+	assert.Equal(t, VisitStatusToVisit, m.updatedPlace.VisitStatus)
 }
 
 func TestService_UpdateListing_InvalidVisitStatus(t *testing.T) {
-	m := &mockCafeStorage{getByID: &models.CafeListing{ID: 1, UserID: 10, VisitStatus: VisitStatusToVisit}}
-	svc := NewService(m)
-	err := svc.UpdateListing(1, 10, models.CafeListing{Name: "New Name", VisitStatus: "bad_status"})
+	m := &mockCafeStorage{getByID: &models.Cafe{ID: 1, UserID: 10, VisitStatus: VisitStatusToVisit}}
+	svc := NewService(m, nil)
+	err := svc.UpdateListing(1, 10, models.Cafe{Name: "New Name", VisitStatus: "bad_status"})
 	assert.ErrorIs(t, err, ErrInvalidVisitStatus)
 }
 
 func TestService_DeleteListing_NotOwner(t *testing.T) {
-	m := &mockCafeStorage{getByID: &models.CafeListing{ID: 1, UserID: 10}}
-	svc := NewService(m)
+	m := &mockCafeStorage{getByID: &models.Cafe{ID: 1, UserID: 10}}
+	svc := NewService(m, nil)
 	err := svc.DeleteListing(1, 99)
 	assert.ErrorIs(t, err, ErrNotOwner)
 }
 
 func TestService_DeleteListing_NotFound(t *testing.T) {
 	m := &mockCafeStorage{getByID: nil}
-	svc := NewService(m)
+	svc := NewService(m, nil)
 	err := svc.DeleteListing(1, 10)
 	require.Error(t, err)
 }
 
 func TestService_DeleteListing_Owner(t *testing.T) {
-	m := &mockCafeStorage{getByID: &models.CafeListing{ID: 1, UserID: 10}}
-	svc := NewService(m)
+	m := &mockCafeStorage{getByID: &models.Cafe{ID: 1, UserID: 10}, cafeDeleted: true}
+	auditor := &recordingAuditor{}
+	svc := NewService(m, auditor)
 	err := svc.DeleteListing(1, 10)
 	require.NoError(t, err)
+	require.Len(t, auditor.changes, 1)
+	change := auditor.changes[0]
+	assert.Equal(t, audit.Change{ActorID: 10, Action: audit.ActionDelete, EntityType: audit.EntityCafeListing, EntityID: 1, Before: m.getByID, After: (*models.Cafe)(nil)}, change)
+}
+
+func TestService_DeleteListing_FailsWithAuditWrite(t *testing.T) {
+	m := &mockCafeStorage{getByID: &models.Cafe{ID: 1, UserID: 10}, cafeDeleted: true}
+	auditErr := errors.New("audit table unavailable")
+	svc := NewService(m, &recordingAuditor{err: auditErr})
+	assert.ErrorIs(t, svc.DeleteListing(1, 10), auditErr)
+}
+
+func TestService_DeleteListing_SaverUnsavesOnly(t *testing.T) {
+	m := &mockCafeStorage{getByID: &models.Cafe{ID: 1, UserID: 10}, savedPlace: &models.SavedPlace{ID: 4, UserID: 99, CafeID: 1}}
+	auditor := &recordingAuditor{}
+	svc := NewService(m, auditor)
+	require.NoError(t, svc.DeleteListing(1, 99))
+	assert.Empty(t, auditor.changes, "the cafe itself stays, so there is no delete to audit")
+}
+
+func TestService_IsVisitedBy(t *testing.T) {
+	svc := NewService(&mockCafeStorage{}, nil)
+	_, err := svc.IsVisitedBy(1, 10)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	cafe := &models.Cafe{ID: 1, UserID: 10}
+	for _, tc := range []struct {
+		saved *models.SavedPlace
+		want  bool
+	}{
+		{nil, false},
+		{&models.SavedPlace{VisitStatus: VisitStatusToVisit}, false},
+		{&models.SavedPlace{VisitStatus: VisitStatusVisited}, true},
+	} {
+		visited, err := NewService(&mockCafeStorage{getByID: cafe, savedPlace: tc.saved}, nil).IsVisitedBy(1, 20)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, visited)
+	}
+}
+
+func TestService_Restore(t *testing.T) {
+	now := time.Now()
+	since := now.Add(-24 * time.Hour)
+	deletedListing := func(at time.Time) *models.Cafe {
+		return &models.Cafe{ID: 1, UserID: 10, DeletedAt: gorm.DeletedAt{Time: at, Valid: true}}
+	}
+
+	svc := NewService(&mockCafeStorage{}, nil)
+	assert.ErrorIs(t, svc.Restore(1, 99, since), gorm.ErrRecordNotFound)
+
+	svc = NewService(&mockCafeStorage{withDeleted: &models.Cafe{ID: 1, UserID: 10}}, nil)
+	assert.ErrorIs(t, svc.Restore(1, 99, since), audit.ErrNotDeleted)
+
+	m := &mockCafeStorage{withDeleted: deletedListing(now.Add(-48 * time.Hour))}
+	svc = NewService(m, nil)
+	assert.ErrorIs(t, svc.Restore(1, 99, since), audit.ErrRestoreWindowPassed)
+	assert.Nil(t, m.restoredAt)
+
+	deletedAt := now.Add(-time.Hour)
+	m = &mockCafeStorage{withDeleted: deletedListing(deletedAt), getByID: &models.Cafe{ID: 1, UserID: 10}}
+	auditor := &recordingAuditor{}
+	svc = NewService(m, auditor)
+	require.NoError(t, svc.Restore(1, 99, since))
+	require.NotNil(t, m.restoredAt)
+	assert.True(t, deletedAt.Equal(*m.restoredAt), "ratings deleted with the listing are matched by its deleted_at")
+	require.Len(t, auditor.changes, 1)
+	assert.Equal(t, uint(99), auditor.changes[0].ActorID)
+	assert.Equal(t, audit.ActionRestore, auditor.changes[0].Action)
+}
+
+func TestService_ListDiscovery_ValidatesGeoFilters(t *testing.T) {
+	svc := NewService(&mockCafeStorage{}, nil)
+	near := &GeoPoint{Lat: 1.29, Lon: 103.85}
+
+	_, err := svc.ListDiscovery(DiscoveryFilter{RadiusM: 500}, pagination.Request{})
+	assert.ErrorIs(t, err, ErrInvalidRadius)
+	_, err = svc.ListDiscovery(DiscoveryFilter{Near: near, RadiusM: MaxRadiusM + 1}, pagination.Request{})
+	assert.ErrorIs(t, err, ErrInvalidRadius)
+	_, err = svc.ListDiscovery(DiscoveryFilter{Sort: " distance "}, pagination.Request{})
+	assert.ErrorIs(t, err, ErrDistanceSortRequiresNear)
+	_, err = svc.ListDiscovery(DiscoveryFilter{Near: near, RadiusM: 500, Sort: "distance"}, pagination.Request{})
+	assert.NoError(t, err)
+}
+
+func TestParseGeoPointAndBBox(t *testing.T) {
+	point, err := ParseGeoPoint(" 1.29, 103.85 ")
+	require.NoError(t, err)
+	assert.Equal(t, GeoPoint{Lat: 1.29, Lon: 103.85}, *point)
+	for _, bad := range []string{"1.29", "1.29,103.85,0", "91,0", "0,181", "NaN,0", "a,b"} {
+		_, err = ParseGeoPoint(bad)
+		assert.ErrorIs(t, err, ErrInvalidNear, bad)
+	}
+
+	box, err := ParseBBox("170,-25,-165,-10")
+	require.NoError(t, err)
+	assert.Equal(t, BBox{MinLon: 170, MinLat: -25, MaxLon: -165, MaxLat: -10}, *box)
+	for _, bad := range []string{"0,0,1", "0,2,1,1", "-181,0,0,1", "0,-91,0,0"} {
+		_, err = ParseBBox(bad)
+		assert.ErrorIs(t, err, ErrInvalidBBox, bad)
+	}
+}
+
+func TestGeoPoint_BoundingBox(t *testing.T) {
+	box := GeoPoint{Lat: 0, Lon: 179.99}.boundingBox(11120)
+	assert.InDelta(t, -0.1, box.MinLat, 0.001)
+	assert.InDelta(t, 0.1, box.MaxLat, 0.001)
+	// The box wraps across the antimeridian.
+	assert.InDelta(t, 179.89, box.MinLon, 0.001)
+	assert.InDelta(t, -179.91, box.MaxLon, 0.001)
+
+	polar := GeoPoint{Lat: 89.95, Lon: 0}.boundingBox(10000)
+	assert.Equal(t, BBox{MinLon: -180, MinLat: polar.MinLat, MaxLon: 180, MaxLat: 90}, polar)
+}
+
+func TestService_CreateListing_Attributes(t *testing.T) {
+	m := &mockCafeStorage{}
+	svc := NewService(m, nil)
+	level := 2
+	require.NoError(t, svc.CreateListing(&models.Cafe{
+		UserID:     1,
+		Name:       "Cafe A",
+		Amenities:  []string{" WiFi", "outlets", "wifi"},
+		PriceLevel: &level,
+		OpeningHours: &models.OpeningHours{
+			TimeZone: "Asia/Singapore",
+			Weekly:   []models.OpeningPeriod{{Day: "TUE", Open: "8:00", Close: "18:00"}, {Day: "mon", Open: "08:00", Close: "18:00"}},
+		},
+	}))
+	assert.Equal(t, []string{"outlets", "wifi"}, m.listings[0].Amenities)
+	assert.Equal(t, []models.OpeningPeriod{{Day: "mon", Open: "08:00", Close: "18:00"}, {Day: "tue", Open: "08:00", Close: "18:00"}}, m.listings[0].OpeningHours.Weekly)
+
+	require.NoError(t, svc.CreateListing(&models.Cafe{UserID: 1, Name: "Cafe B", Amenities: []string{}, OpeningHours: &models.OpeningHours{TimeZone: "UTC"}}))
+	assert.Nil(t, m.listings[1].Amenities)
+	assert.Nil(t, m.listings[1].OpeningHours)
+
+	assert.ErrorIs(t, svc.CreateListing(&models.Cafe{UserID: 1, Name: "Cafe C", Amenities: []string{"jacuzzi"}}), ErrInvalidAmenity)
+	bad := 5
+	assert.ErrorIs(t, svc.CreateListing(&models.Cafe{UserID: 1, Name: "Cafe C", PriceLevel: &bad}), ErrInvalidPriceLevel)
+}
+
+func TestService_CreateListing_InvalidOpeningHours(t *testing.T) {
+	svc := NewService(&mockCafeStorage{}, nil)
+	for name, hours := range map[string]models.OpeningHours{
+		"no timezone":      {Weekly: []models.OpeningPeriod{{Day: "mon", Open: "08:00", Close: "18:00"}}},
+		"unknown timezone": {TimeZone: "Mars/Olympus", Weekly: []models.OpeningPeriod{{Day: "mon", Open: "08:00", Close: "18:00"}}},
+		"bad day":          {TimeZone: "UTC", Weekly: []models.OpeningPeriod{{Day: "monday", Open: "08:00", Close: "18:00"}}},
+		"bad time":         {TimeZone: "UTC", Weekly: []models.OpeningPeriod{{Day: "mon", Open: "8am", Close: "18:00"}}},
+		"open at 24:00":    {TimeZone: "UTC", Weekly: []models.OpeningPeriod{{Day: "mon", Open: "24:00", Close: "02:00"}}},
+		"bad date":         {TimeZone: "UTC", Exceptions: []models.OpeningException{{Date: "2026-13-01", Closed: true}}},
+		"duplicate date":   {TimeZone: "UTC", Exceptions: []models.OpeningException{{Date: "2026-12-25", Closed: true}, {Date: "2026-12-25", Closed: true}}},
+		"closed and open": {TimeZone: "UTC", Exceptions: []models.OpeningException{
+			{Date: "2026-12-25", Closed: true, Periods: []models.TimeRange{{Open: "10:00", Close: "12:00"}}},
+		}},
+		"neither closed nor open": {TimeZone: "UTC", Exceptions: []models.OpeningException{{Date: "2026-12-25"}}},
+	} {
+		err := svc.CreateListing(&models.Cafe{UserID: 1, Name: "Cafe", OpeningHours: &hours})
+		assert.ErrorIs(t, err, ErrInvalidOpeningHours, name)
+	}
+}
+
+func TestParseAttributeQueries(t *testing.T) {
+	amenities, err := parseAmenitiesQuery("outlets,WIFI")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outlets", "wifi"}, amenities)
+	_, err = parseAmenitiesQuery("wifi,,outlets")
+	assert.ErrorIs(t, err, ErrInvalidAmenity)
+
+	levels, err := parsePriceLevelsQuery("2, 1,2")
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, levels)
+	for _, raw := range []string{"0", "5", "cheap", "1,"} {
+		_, err := parsePriceLevelsQuery(raw)
+		assert.ErrorIs(t, err, ErrInvalidPriceLevel, raw)
+	}
 }
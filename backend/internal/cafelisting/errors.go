@@ -1,8 +1,26 @@
 package cafelisting
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
+)
 
 var ErrNotOwner = errors.New("cafe listing does not belong to this user")
+var ErrDetailsNotEditable = errors.New("only the user who added this cafe can edit its details; send only visit_status and notes")
 var ErrInvalidVisitStatus = errors.New("invalid visit_status: must be to_visit or visited")
 var ErrInvalidCafeName = errors.New("cafe name is required")
+var ErrInvalidImageURL = errors.New("image_url must be an http(s) URL")
 var ErrInvalidCoordinates = errors.New("latitude and longitude must be provided together and within valid ranges")
+var ErrInvalidNear = errors.New("invalid near: must be lat,lon within valid ranges")
+var ErrInvalidRadius = errors.New("invalid radius_m: must be a positive number of meters up to 50000 and requires near")
+var ErrInvalidBBox = errors.New("invalid bbox: must be minLon,minLat,maxLon,maxLat within valid ranges")
+var ErrDistanceSortRequiresNear = errors.New("sort=distance requires near")
+var ErrInvalidAmenity = errors.New("invalid amenities: must be from the supported list")
+var ErrInvalidPriceLevel = errors.New("invalid price_level: must be between 1 and 4")
+var ErrInvalidOpeningHours = errors.New("invalid opening_hours")
+var ErrInvalidOpenNow = errors.New("invalid open_now: must be true or false")
+var ErrOwnerDeleted = fmt.Errorf("%w: the listing owner's account is deleted; restore the user instead", audit.ErrRestoreConflict)
+var ErrPlaceTaken = fmt.Errorf("%w: this place has been added again as another cafe since", audit.ErrRestoreConflict)
+var ErrNotesTooLong = errors.New("notes must be at most 2000 characters")
@@ -0,0 +1,100 @@
+package cafelisting
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxRadiusM bounds radius_m so a radius query stays an index range scan.
+	MaxRadiusM = 50000
+	// metersPerDegreeLat is the length of one degree of latitude on the mean Earth sphere.
+	metersPerDegreeLat = 111195.0
+)
+
+// GeoPoint is a WGS84 coordinate in degrees.
+type GeoPoint struct {
+	Lat float64
+	Lon float64
+}
+
+// BBox is a WGS84 bounding box. MinLon > MaxLon means the box crosses the antimeridian.
+type BBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// ParseGeoPoint parses "lat,lon".
+func ParseGeoPoint(raw string) (*GeoPoint, error) {
+	values, ok := parseFloats(raw, 2)
+	if !ok {
+		return nil, ErrInvalidNear
+	}
+	point := &GeoPoint{Lat: values[0], Lon: values[1]}
+	if !validLatitude(point.Lat) || !validLongitude(point.Lon) {
+		return nil, ErrInvalidNear
+	}
+	return point, nil
+}
+
+// ParseBBox parses "minLon,minLat,maxLon,maxLat".
+func ParseBBox(raw string) (*BBox, error) {
+	values, ok := parseFloats(raw, 4)
+	if !ok {
+		return nil, ErrInvalidBBox
+	}
+	box := &BBox{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+	if !validLongitude(box.MinLon) || !validLongitude(box.MaxLon) ||
+		!validLatitude(box.MinLat) || !validLatitude(box.MaxLat) || box.MinLat > box.MaxLat {
+		return nil, ErrInvalidBBox
+	}
+	return box, nil
+}
+
+// boundingBox returns a box that contains every point within radiusM of p; used to pre-filter radius queries.
+func (p GeoPoint) boundingBox(radiusM float64) BBox {
+	dLat := radiusM / metersPerDegreeLat
+	box := BBox{MinLon: -180, MinLat: math.Max(p.Lat-dLat, -90), MaxLon: 180, MaxLat: math.Min(p.Lat+dLat, 90)}
+	// Longitude degrees shrink towards the poles, so size the span at the box's most poleward latitude.
+	// A box that reaches a pole covers every longitude.
+	if edge := math.Abs(p.Lat) + dLat; edge < 90 {
+		if dLon := radiusM / (metersPerDegreeLat * math.Cos(edge*math.Pi/180)); dLon < 180 {
+			box.MinLon = wrapLongitude(p.Lon - dLon)
+			box.MaxLon = wrapLongitude(p.Lon + dLon)
+		}
+	}
+	return box
+}
+
+func parseFloats(raw string, n int) ([]float64, bool) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != n {
+		return nil, false
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, false
+		}
+		values[i] = value
+	}
+	return values, true
+}
+
+func validLatitude(lat float64) bool { return lat >= -90 && lat <= 90 }
+
+func validLongitude(lon float64) bool { return lon >= -180 && lon <= 180 }
+
+func wrapLongitude(lon float64) float64 {
+	if lon < -180 {
+		return lon + 360
+	}
+	if lon > 180 {
+		return lon - 360
+	}
+	return lon
+}
@@ -0,0 +1,311 @@
+package cafelisting
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	_ "time/tzdata" // opening hours name IANA zones; embed the database so hosts without zoneinfo still resolve them
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+)
+
+const (
+	maxWeeklyPeriods     = 28
+	maxPeriodsPerDay     = 4
+	maxOpeningExceptions = 60
+	maxExceptionNoteLen  = 120
+	minutesPerDay        = 24 * 60
+)
+
+// weekdays are the opening period day codes, indexed by time.Weekday.
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// normalizeOpeningHours validates hours and puts them in canonical form: lower-case day codes, periods sorted by
+// day and opening time, exceptions sorted by date. Hours with neither periods nor exceptions become nil.
+func normalizeOpeningHours(hours *models.OpeningHours) (*models.OpeningHours, error) {
+	if hours == nil || (len(hours.Weekly) == 0 && len(hours.Exceptions) == 0) {
+		return nil, nil
+	}
+	out := &models.OpeningHours{TimeZone: strings.TrimSpace(hours.TimeZone)}
+	if out.TimeZone == "" || strings.EqualFold(out.TimeZone, "local") {
+		return nil, fmt.Errorf("%w: timezone is required", ErrInvalidOpeningHours)
+	}
+	if _, err := time.LoadLocation(out.TimeZone); err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidOpeningHours, out.TimeZone)
+	}
+	if len(hours.Weekly) > maxWeeklyPeriods {
+		return nil, fmt.Errorf("%w: at most %d weekly periods", ErrInvalidOpeningHours, maxWeeklyPeriods)
+	}
+	if len(hours.Exceptions) > maxOpeningExceptions {
+		return nil, fmt.Errorf("%w: at most %d exceptions", ErrInvalidOpeningHours, maxOpeningExceptions)
+	}
+
+	perDay := map[string]int{}
+	for _, period := range hours.Weekly {
+		day := strings.ToLower(strings.TrimSpace(period.Day))
+		if !slices.Contains(weekdays, day) {
+			return nil, fmt.Errorf("%w: day must be one of mon, tue, wed, thu, fri, sat, sun", ErrInvalidOpeningHours)
+		}
+		open, close, err := normalizeTimeRange(period.Open, period.Close)
+		if err != nil {
+			return nil, err
+		}
+		if perDay[day]++; perDay[day] > maxPeriodsPerDay {
+			return nil, fmt.Errorf("%w: at most %d periods per day", ErrInvalidOpeningHours, maxPeriodsPerDay)
+		}
+		out.Weekly = append(out.Weekly, models.OpeningPeriod{Day: day, Open: open, Close: close})
+	}
+	slices.SortStableFunc(out.Weekly, func(a, b models.OpeningPeriod) int {
+		if c := weekOrder(a.Day) - weekOrder(b.Day); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Open, b.Open)
+	})
+
+	for _, exception := range hours.Exceptions {
+		date := strings.TrimSpace(exception.Date)
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("%w: exception date must be YYYY-MM-DD", ErrInvalidOpeningHours)
+		}
+		if slices.ContainsFunc(out.Exceptions, func(e models.OpeningException) bool { return e.Date == date }) {
+			return nil, fmt.Errorf("%w: duplicate exception date %s", ErrInvalidOpeningHours, date)
+		}
+		if exception.Closed == (len(exception.Periods) > 0) {
+			return nil, fmt.Errorf("%w: exception %s must be closed or list periods, not both", ErrInvalidOpeningHours, date)
+		}
+		if len(exception.Periods) > maxPeriodsPerDay {
+			return nil, fmt.Errorf("%w: at most %d periods per day", ErrInvalidOpeningHours, maxPeriodsPerDay)
+		}
+		note := strings.TrimSpace(exception.Note)
+		if len(note) > maxExceptionNoteLen {
+			return nil, fmt.Errorf("%w: exception note is longer than %d characters", ErrInvalidOpeningHours, maxExceptionNoteLen)
+		}
+		normalized := models.OpeningException{Date: date, Closed: exception.Closed, Note: note}
+		for _, period := range exception.Periods {
+			open, close, err := normalizeTimeRange(period.Open, period.Close)
+			if err != nil {
+				return nil, err
+			}
+			normalized.Periods = append(normalized.Periods, models.TimeRange{Open: open, Close: close})
+		}
+		out.Exceptions = append(out.Exceptions, normalized)
+	}
+	slices.SortFunc(out.Exceptions, func(a, b models.OpeningException) int { return strings.Compare(a.Date, b.Date) })
+	return out, nil
+}
+
+func normalizeTimeRange(open, close string) (string, string, error) {
+	openMin, ok := parseClock(open)
+	if !ok || openMin == minutesPerDay {
+		return "", "", fmt.Errorf("%w: open must be HH:MM between 00:00 and 23:59", ErrInvalidOpeningHours)
+	}
+	closeMin, ok := parseClock(close)
+	if !ok {
+		return "", "", fmt.Errorf("%w: close must be HH:MM between 00:00 and 24:00", ErrInvalidOpeningHours)
+	}
+	return formatClock(openMin), formatClock(closeMin), nil
+}
+
+// parseClock parses "H:MM" or "HH:MM" into minutes after midnight; "24:00" is allowed as the end of the day.
+func parseClock(raw string) (int, bool) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(raw), ":")
+	if !ok || len(hh) < 1 || len(hh) > 2 || len(mm) != 2 {
+		return 0, false
+	}
+	hour, minute := 0, 0
+	for _, c := range hh {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		hour = hour*10 + int(c-'0')
+	}
+	for _, c := range mm {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		minute = minute*10 + int(c-'0')
+	}
+	total := hour*60 + minute
+	if minute > 59 || total > minutesPerDay {
+		return 0, false
+	}
+	return total, true
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func weekdayIndex(day string) int {
+	return slices.Index(weekdays, day)
+}
+
+// weekOrder sorts days Monday first.
+func weekOrder(day string) int {
+	return (weekdayIndex(day) + 6) % 7
+}
+
+// IsOpenAt reports whether hours say the cafe is open at t. Periods that close after midnight count on the next
+// day too, and an exception replaces the weekly periods of its date (including their overnight spill). Nil hours
+// are never open.
+func IsOpenAt(hours *models.OpeningHours, t time.Time) bool {
+	if hours == nil {
+		return false
+	}
+	loc, err := time.LoadLocation(hours.TimeZone)
+	if err != nil {
+		return false
+	}
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+
+	for _, period := range periodsOn(hours, local) {
+		open, close := clockRange(period)
+		if close > open && now >= open && now < close {
+			return true
+		}
+		if close <= open && now >= open {
+			return true
+		}
+	}
+	for _, period := range periodsOn(hours, local.AddDate(0, 0, -1)) {
+		open, close := clockRange(period)
+		if close <= open && now < close {
+			return true
+		}
+	}
+	return false
+}
+
+// periodsOn returns the opening times for the local date of day.
+func periodsOn(hours *models.OpeningHours, day time.Time) []models.TimeRange {
+	date := day.Format(time.DateOnly)
+	for _, exception := range hours.Exceptions {
+		if exception.Date == date {
+			return exception.Periods
+		}
+	}
+	code := weekdays[day.Weekday()]
+	var out []models.TimeRange
+	for _, period := range hours.Weekly {
+		if period.Day == code {
+			out = append(out, models.TimeRange{Open: period.Open, Close: period.Close})
+		}
+	}
+	return out
+}
+
+func clockRange(period models.TimeRange) (int, int) {
+	open, _ := parseClock(period.Open)
+	close, _ := parseClock(period.Close)
+	return open, close
+}
+
+// osmDays maps OpenStreetMap day abbreviations onto weekday codes.
+var osmDays = map[string]string{"Mo": "mon", "Tu": "tue", "We": "wed", "Th": "thu", "Fr": "fri", "Sa": "sat", "Su": "sun"}
+
+// ParseOSMOpeningHours converts the common subset of the OpenStreetMap opening_hours syntax, as returned by
+// Geoapify and Overpass, into weekly opening hours in timeZone. It understands "24/7", day lists and ranges
+// ("Mo-Fr", "Sa,Su", "Fr-Mo"), several time ranges per rule, and "off"; later rules replace earlier ones for the
+// days they name, as in OSM. Rules for public holidays are skipped. Anything else (months, weeks, sunrise,
+// open-ended times) is an error, so callers can fall back to no hours rather than wrong ones.
+func ParseOSMOpeningHours(spec, timeZone string) (*models.OpeningHours, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("%w: empty opening_hours", ErrInvalidOpeningHours)
+	}
+	schedule := map[string][]models.TimeRange{}
+	for _, rule := range strings.FieldsFunc(strings.ReplaceAll(spec, "||", ";"), func(r rune) bool { return r == ';' }) {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if rule == "24/7" {
+			for _, day := range weekdays {
+				schedule[day] = []models.TimeRange{{Open: "00:00", Close: "24:00"}}
+			}
+			continue
+		}
+		selector, times, hasTimes := strings.Cut(rule, " ")
+		if !hasTimes || startsWithDigit(selector) {
+			// A rule without a day selector applies to every day.
+			selector, times = "Mo-Su", rule
+		}
+		days, err := parseOSMDays(selector)
+		if err != nil {
+			return nil, err
+		}
+		ranges, err := parseOSMTimes(strings.TrimSpace(times))
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			schedule[day] = ranges
+		}
+	}
+
+	hours := &models.OpeningHours{TimeZone: timeZone}
+	for _, day := range weekdays {
+		for _, r := range schedule[day] {
+			hours.Weekly = append(hours.Weekly, models.OpeningPeriod{Day: day, Open: r.Open, Close: r.Close})
+		}
+	}
+	if len(hours.Weekly) == 0 {
+		return nil, fmt.Errorf("%w: no opening periods", ErrInvalidOpeningHours)
+	}
+	return normalizeOpeningHours(hours)
+}
+
+func parseOSMDays(selector string) ([]string, error) {
+	var days []string
+	for _, part := range strings.Split(selector, ",") {
+		if part == "PH" || part == "SH" {
+			// Public and school holidays are not on the weekly schedule.
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		start, ok := osmDays[from]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported day selector %q", ErrInvalidOpeningHours, selector)
+		}
+		if !isRange {
+			days = append(days, start)
+			continue
+		}
+		end, ok := osmDays[to]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported day selector %q", ErrInvalidOpeningHours, selector)
+		}
+		for i := weekdayIndex(start); ; i = (i + 1) % len(weekdays) {
+			days = append(days, weekdays[i])
+			if weekdays[i] == end {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseOSMTimes(times string) ([]models.TimeRange, error) {
+	if times == "off" || times == "closed" {
+		return nil, nil
+	}
+	var ranges []models.TimeRange
+	for _, part := range strings.Split(times, ",") {
+		open, close, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported time range %q", ErrInvalidOpeningHours, part)
+		}
+		open, close, err := normalizeTimeRange(open, close)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, models.TimeRange{Open: open, Close: close})
+	}
+	return ranges, nil
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
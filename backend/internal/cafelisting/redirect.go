@@ -0,0 +1,86 @@
+package cafelisting
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// FollowRedirects answers requests under basePath/cafes/{id} for a listing id that migration 000024 folded into a
+// canonical cafe with a 308 to the same path on that cafe. A 308 keeps the method and body, so old links, bookmarks
+// and clients still holding the old id keep working, including for ratings, photos and reports under the cafe.
+// Redirects are only looked up once the route itself has answered 404, so requests for live cafes cost nothing extra.
+func FollowRedirects(service *Service, basePath string) func(http.Handler) http.Handler {
+	prefix := strings.TrimSuffix(basePath, "/") + "/cafes/"
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			idStr, tail, hasTail := strings.Cut(rest, "/")
+			id, err := strconv.ParseUint(idStr, 10, 0)
+			if err != nil || id == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			held := &notFoundHolder{ResponseWriter: w}
+			next.ServeHTTP(held, r)
+			if !held.notFound {
+				return
+			}
+			cafeID, err := service.ResolveRedirect(uint(id))
+			if err != nil {
+				log.Printf("resolve cafe redirect %d: %v", id, err)
+			}
+			if err != nil || cafeID == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write(held.body.Bytes())
+				return
+			}
+			target := *r.URL
+			target.Path = prefix + strconv.FormatUint(uint64(cafeID), 10)
+			if hasTail {
+				target.Path += "/" + tail
+			}
+			target.RawPath = ""
+			w.Header().Del("Content-Type")
+			w.Header().Del("X-Content-Type-Options")
+			http.Redirect(w, r, target.RequestURI(), http.StatusPermanentRedirect)
+		})
+	}
+}
+
+// notFoundHolder passes a response through unless its status is 404, which it keeps back so the caller can still
+// replace it with a redirect.
+type notFoundHolder struct {
+	http.ResponseWriter
+	wroteHeader bool
+	notFound    bool
+	body        bytes.Buffer
+}
+
+func (h *notFoundHolder) WriteHeader(status int) {
+	if h.wroteHeader {
+		return
+	}
+	h.wroteHeader = true
+	if status == http.StatusNotFound {
+		h.notFound = true
+		return
+	}
+	h.ResponseWriter.WriteHeader(status)
+}
+
+func (h *notFoundHolder) Write(b []byte) (int, error) {
+	if !h.wroteHeader {
+		h.WriteHeader(http.StatusOK)
+	}
+	if h.notFound {
+		return h.body.Write(b)
+	}
+	return h.ResponseWriter.Write(b)
+}
@@ -5,6 +5,8 @@ import "strings"
 const (
 	VisitStatusToVisit = "to_visit"
 	VisitStatusVisited = "visited"
+
+	MaxNotesLength = 2000
 )
 
 func normalizeVisitStatus(input string) (string, error) {
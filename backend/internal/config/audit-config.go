@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+// AuditConfig configures the admin restore of soft-deleted users, cafe listings and ratings.
+type AuditConfig struct {
+	RestoreWindow time.Duration // how long after deletion an admin can still restore an entity
+}
+
+func LoadAuditConfig() (*AuditConfig, error) {
+	return &AuditConfig{
+		RestoreWindow: parseDurationEnv("RESTORE_WINDOW", 30*24*time.Hour),
+	}, nil
+}
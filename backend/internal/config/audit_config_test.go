@@ -0,0 +1,29 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAuditConfig(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	cfg, err := LoadAuditConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, cfg.RestoreWindow)
+
+	os.Setenv("RESTORE_WINDOW", "168h")
+	cfg, err = LoadAuditConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, cfg.RestoreWindow)
+
+	os.Setenv("RESTORE_WINDOW", "0s")
+	cfg, err = LoadAuditConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, cfg.RestoreWindow, "a non-positive window falls back to the default")
+}
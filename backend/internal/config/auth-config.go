@@ -2,30 +2,170 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type AuthConfig struct {
-	JWTSecret []byte
-	JWTExpiry time.Duration
+	JWTSecret     []byte
+	JWTExpiry     time.Duration // access token lifetime
+	RefreshExpiry time.Duration // refresh token / session lifetime
+
+	JWTKeys        []JWTKey // asymmetric access-token keys; empty means HS256 with JWTSecret
+	JWTActiveKeyID string   // kid of the key new access tokens are signed with
+	JWTIssuer      string   // iss of issued access tokens, required by the middleware
+	JWTAudience    string   // aud of issued access tokens, required by the middleware
+
+	PasswordResetURL    string        // frontend page that receives ?token= from reset emails
+	PasswordResetExpiry time.Duration // reset link lifetime
+
+	EmailVerificationURL     string        // frontend page that receives ?token= from verification emails
+	EmailVerificationExpiry  time.Duration // verification link lifetime
+	RequireEmailVerification bool          // block write endpoints (e.g. creating ratings) until the email is verified
+
+	LoginAttemptStore     string        // "memory" (default) or "postgres" for failed-login counters
+	LoginMaxEmailFailures int           // failures per email before a lockout
+	LoginMaxIPFailures    int           // failures per client IP before a lockout
+	LoginFailureWindow    time.Duration // quiet period after which failure counts restart
+	LoginLockoutBase      time.Duration // first lockout, doubled for each further failure
+	LoginLockoutMax       time.Duration // longest lockout
+
+	TrustedProxies []netip.Prefix // peers whose X-Forwarded-For is believed; empty means the header is ignored
+
+	OIDCProviders       []OIDCProviderConfig
+	OIDCCallbackBaseURL string // public URL of this API's base path; callbacks go to <base>/auth/oidc/<provider>/callback
+	OIDCSuccessURL      string // frontend page that receives the token pair (or error) in the URL fragment
 }
 
+const (
+	LoginAttemptStoreMemory   = "memory"
+	LoginAttemptStorePostgres = "postgres"
+)
+
 func LoadAuthConfig() (*AuthConfig, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		return nil, fmt.Errorf("JWT_SECRET is required")
 	}
-	expiryStr := os.Getenv("JWT_EXPIRY")
-	if expiryStr == "" {
-		expiryStr = "24h"
+	loginAttemptStore := strings.ToLower(getEnvDefault("LOGIN_ATTEMPT_STORE", LoginAttemptStoreMemory))
+	if loginAttemptStore != LoginAttemptStoreMemory && loginAttemptStore != LoginAttemptStorePostgres {
+		return nil, fmt.Errorf("unsupported LOGIN_ATTEMPT_STORE %q", loginAttemptStore)
+	}
+	jwtKeys, activeKeyID, err := loadJWTKeys()
+	if err != nil {
+		return nil, err
 	}
-	expiry, err := time.ParseDuration(expiryStr)
+	oidcProviders, err := loadOIDCProviders()
 	if err != nil {
-		expiry = 24 * time.Hour
+		return nil, err
+	}
+	trustedProxies, err := loadTrustedProxies()
+	if err != nil {
+		return nil, err
 	}
 	return &AuthConfig{
-		JWTSecret: []byte(secret),
-		JWTExpiry: expiry,
+		JWTSecret:     []byte(secret),
+		JWTExpiry:     parseDurationEnv("JWT_EXPIRY", 15*time.Minute),
+		RefreshExpiry: parseDurationEnv("REFRESH_TOKEN_EXPIRY", 30*24*time.Hour),
+
+		JWTKeys:        jwtKeys,
+		JWTActiveKeyID: activeKeyID,
+		JWTIssuer:      getEnvDefault("JWT_ISSUER", "go-cafe"),
+		JWTAudience:    getEnvDefault("JWT_AUDIENCE", "go-cafe-api"),
+
+		PasswordResetURL:    getEnvDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		PasswordResetExpiry: parseDurationEnv("PASSWORD_RESET_EXPIRY", time.Hour),
+
+		EmailVerificationURL:     getEnvDefault("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email"),
+		EmailVerificationExpiry:  parseDurationEnv("EMAIL_VERIFICATION_EXPIRY", 48*time.Hour),
+		RequireEmailVerification: parseBoolEnv("REQUIRE_EMAIL_VERIFICATION", false),
+
+		LoginAttemptStore:     loginAttemptStore,
+		LoginMaxEmailFailures: parseIntEnv("LOGIN_MAX_EMAIL_FAILURES", 5),
+		LoginMaxIPFailures:    parseIntEnv("LOGIN_MAX_IP_FAILURES", 20),
+		LoginFailureWindow:    parseDurationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LoginLockoutBase:      parseDurationEnv("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:       parseDurationEnv("LOGIN_LOCKOUT_MAX", time.Hour),
+
+		TrustedProxies: trustedProxies,
+
+		OIDCProviders:       oidcProviders,
+		OIDCCallbackBaseURL: strings.TrimRight(getEnvDefault("OIDC_CALLBACK_BASE_URL", "http://localhost:8080/api/v1"), "/"),
+		OIDCSuccessURL:      getEnvDefault("OIDC_SUCCESS_URL", "http://localhost:3000/auth/oidc/callback"),
 	}, nil
 }
+
+// loadTrustedProxies reads TRUSTED_PROXIES, a comma-separated list of IP addresses and CIDR ranges.
+func loadTrustedProxies() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q", entry)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q", entry)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// parseDurationEnv reads a Go duration string from env, falling back when unset or invalid.
+func parseDurationEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+func getEnvDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// parseBoolEnv reads a strconv.ParseBool value from env, falling back when unset or invalid.
+func parseBoolEnv(key string, fallback bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+// parseIntEnv reads a positive integer from env, falling back when unset or invalid.
+func parseIntEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v <= 0 {
+		return fallback
+	}
+	return v
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"net/netip"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,3 +27,85 @@ func TestLoadAuthConfig_Ok(t *testing.T) {
 	assert.Equal(t, []byte("my-secret-key"), cfg.JWTSecret)
 	assert.NotZero(t, cfg.JWTExpiry)
 }
+
+func TestLoadAuthConfig_Defaults(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("JWT_SECRET", "my-secret-key")
+	defer os.Clearenv()
+
+	cfg, err := LoadAuthConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, cfg.JWTExpiry)
+	assert.Equal(t, 30*24*time.Hour, cfg.RefreshExpiry)
+	assert.Equal(t, time.Hour, cfg.PasswordResetExpiry)
+	assert.NotEmpty(t, cfg.PasswordResetURL)
+	assert.Equal(t, 48*time.Hour, cfg.EmailVerificationExpiry)
+	assert.False(t, cfg.RequireEmailVerification)
+	assert.Equal(t, LoginAttemptStoreMemory, cfg.LoginAttemptStore)
+	assert.Equal(t, 5, cfg.LoginMaxEmailFailures)
+	assert.Equal(t, 20, cfg.LoginMaxIPFailures)
+	assert.Empty(t, cfg.TrustedProxies)
+}
+
+func TestLoadAuthConfig_TrustedProxies(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("JWT_SECRET", "my-secret-key")
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.7,,::1")
+	defer os.Clearenv()
+
+	cfg, err := LoadAuthConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.1.7/32"),
+		netip.MustParsePrefix("::1/128"),
+	}, cfg.TrustedProxies)
+
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.0/33")
+	_, err = LoadAuthConfig()
+	assert.Error(t, err)
+	os.Setenv("TRUSTED_PROXIES", "proxy.internal")
+	_, err = LoadAuthConfig()
+	assert.Error(t, err)
+}
+
+func TestLoadAuthConfig_LoginAttemptStore(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("JWT_SECRET", "my-secret-key")
+	os.Setenv("LOGIN_ATTEMPT_STORE", "Postgres")
+	os.Setenv("LOGIN_MAX_EMAIL_FAILURES", "-1")
+	defer os.Clearenv()
+
+	cfg, err := LoadAuthConfig()
+	require.NoError(t, err)
+	assert.Equal(t, LoginAttemptStorePostgres, cfg.LoginAttemptStore)
+	assert.Equal(t, 5, cfg.LoginMaxEmailFailures)
+
+	os.Setenv("LOGIN_ATTEMPT_STORE", "redis")
+	_, err = LoadAuthConfig()
+	assert.Error(t, err)
+}
+
+func TestLoadAuthConfig_RequireEmailVerification(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("JWT_SECRET", "my-secret-key")
+	os.Setenv("REQUIRE_EMAIL_VERIFICATION", "true")
+	defer os.Clearenv()
+
+	cfg, err := LoadAuthConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.RequireEmailVerification)
+}
+
+func TestLoadAuthConfig_CustomExpiry(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("JWT_SECRET", "my-secret-key")
+	os.Setenv("JWT_EXPIRY", "5m")
+	os.Setenv("REFRESH_TOKEN_EXPIRY", "bogus")
+	defer os.Clearenv()
+
+	cfg, err := LoadAuthConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.JWTExpiry)
+	assert.Equal(t, 30*24*time.Hour, cfg.RefreshExpiry)
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	CacheStoreMemory   = "memory"
+	CacheStorePostgres = "postgres"
+	CacheStoreNone     = "none"
+)
+
+// CacheConfig controls caching of Geoapify responses. An empty Store behaves like "none".
+type CacheConfig struct {
+	Store           string        // "memory" (default, per-instance LRU), "postgres" (shared gocafe_cache_entries table) or "none"
+	MaxEntries      int           // memory store capacity across all caches
+	PlacesTTL       time.Duration // places search and place details
+	GeocodeTTL      time.Duration // city-to-coordinates lookups behind places search
+	AutocompleteTTL time.Duration // address autocomplete suggestions
+	StaleTTL        time.Duration // how long past its TTL an entry may be served when Geoapify fails
+}
+
+func LoadCacheConfig() (*CacheConfig, error) {
+	store := strings.ToLower(getEnvDefault("CACHE_STORE", CacheStoreMemory))
+	if store != CacheStoreMemory && store != CacheStorePostgres && store != CacheStoreNone {
+		return nil, fmt.Errorf("unsupported CACHE_STORE %q", store)
+	}
+	return &CacheConfig{
+		Store:           store,
+		MaxEntries:      parseIntEnv("CACHE_MAX_ENTRIES", 1000),
+		PlacesTTL:       parseDurationEnv("PLACES_CACHE_TTL", 15*time.Minute),
+		GeocodeTTL:      parseDurationEnv("GEOCODE_CACHE_TTL", 7*24*time.Hour),
+		AutocompleteTTL: parseDurationEnv("AUTOCOMPLETE_CACHE_TTL", time.Hour),
+		StaleTTL:        parseDurationEnv("CACHE_STALE_TTL", 24*time.Hour),
+	}, nil
+}
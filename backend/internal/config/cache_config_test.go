@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCacheConfig_Defaults(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := LoadCacheConfig()
+	require.NoError(t, err)
+	assert.Equal(t, CacheStoreMemory, cfg.Store)
+	assert.Equal(t, 1000, cfg.MaxEntries)
+	assert.Equal(t, 15*time.Minute, cfg.PlacesTTL)
+	assert.Equal(t, time.Hour, cfg.AutocompleteTTL)
+	assert.Equal(t, 24*time.Hour, cfg.StaleTTL)
+}
+
+func TestLoadCacheConfig_Store(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("CACHE_STORE", "Postgres")
+	os.Setenv("PLACES_CACHE_TTL", "5m")
+	defer os.Clearenv()
+
+	cfg, err := LoadCacheConfig()
+	require.NoError(t, err)
+	assert.Equal(t, CacheStorePostgres, cfg.Store)
+	assert.Equal(t, 5*time.Minute, cfg.PlacesTTL)
+
+	os.Setenv("CACHE_STORE", "redis")
+	_, err = LoadCacheConfig()
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	DiscoveryProviderGeoapify = "geoapify"
+	DiscoveryProviderOSM      = "osm"
+
+	defaultOSMUserAgent = "go-cafe/1.0 (+https://github.com/khorzhenwin/go-cafe)"
+)
+
+// DiscoveryConfig selects the places and address autocomplete providers. An empty Providers list means Geoapify only.
+type DiscoveryConfig struct {
+	Providers    []string // failover order, e.g. ["geoapify", "osm"]; geoapify is skipped when GEOAPIFY_API_KEY is unset
+	OverpassURL  string   // Overpass API interpreter endpoint
+	NominatimURL string   // Nominatim base URL (search is at /search)
+	OSMUserAgent string   // sent to Overpass and Nominatim, whose usage policies require an identifying User-Agent
+}
+
+func LoadDiscoveryConfig() (*DiscoveryConfig, error) {
+	cfg := &DiscoveryConfig{
+		OverpassURL:  getEnvDefault("OSM_OVERPASS_URL", "https://overpass-api.de/api/interpreter"),
+		NominatimURL: getEnvDefault("OSM_NOMINATIM_URL", "https://nominatim.openstreetmap.org"),
+		OSMUserAgent: getEnvDefault("OSM_USER_AGENT", defaultOSMUserAgent),
+	}
+
+	seen := map[string]bool{}
+	for _, name := range strings.Split(getEnvDefault("DISCOVERY_PROVIDERS", DiscoveryProviderGeoapify), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name != DiscoveryProviderGeoapify && name != DiscoveryProviderOSM {
+			return nil, fmt.Errorf("unsupported discovery provider %q in DISCOVERY_PROVIDERS", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("discovery provider %q is listed twice in DISCOVERY_PROVIDERS", name)
+		}
+		seen[name] = true
+		cfg.Providers = append(cfg.Providers, name)
+	}
+
+	if seen[DiscoveryProviderOSM] {
+		if !validHTTPURL(cfg.OverpassURL) {
+			return nil, fmt.Errorf("OSM_OVERPASS_URL must be an http(s) URL")
+		}
+		if !validHTTPURL(cfg.NominatimURL) {
+			return nil, fmt.Errorf("OSM_NOMINATIM_URL must be an http(s) URL")
+		}
+	}
+	return cfg, nil
+}
+
+func validHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDiscoveryConfig_Defaults(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := LoadDiscoveryConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DiscoveryProviderGeoapify}, cfg.Providers)
+	assert.Equal(t, "https://overpass-api.de/api/interpreter", cfg.OverpassURL)
+	assert.Equal(t, "https://nominatim.openstreetmap.org", cfg.NominatimURL)
+	assert.NotEmpty(t, cfg.OSMUserAgent)
+}
+
+func TestLoadDiscoveryConfig_Providers(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DISCOVERY_PROVIDERS", " Geoapify , osm ")
+	os.Setenv("OSM_NOMINATIM_URL", "http://nominatim.internal:8080")
+	defer os.Clearenv()
+
+	cfg, err := LoadDiscoveryConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DiscoveryProviderGeoapify, DiscoveryProviderOSM}, cfg.Providers)
+	assert.Equal(t, "http://nominatim.internal:8080", cfg.NominatimURL)
+
+	os.Setenv("DISCOVERY_PROVIDERS", "osm,google")
+	_, err = LoadDiscoveryConfig()
+	require.Error(t, err)
+
+	os.Setenv("DISCOVERY_PROVIDERS", "osm,osm")
+	_, err = LoadDiscoveryConfig()
+	require.Error(t, err)
+
+	os.Setenv("DISCOVERY_PROVIDERS", "osm")
+	os.Setenv("OSM_OVERPASS_URL", "overpass.internal/api/interpreter")
+	_, err = LoadDiscoveryConfig()
+	require.Error(t, err)
+}
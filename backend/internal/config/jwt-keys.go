@@ -0,0 +1,119 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+
+	minRSAKeyBits = 2048
+)
+
+// JWTKey is one asymmetric access-token key. Signer is nil for retired keys that are kept only to
+// verify tokens issued before a rotation.
+type JWTKey struct {
+	ID        string
+	Algorithm string // RS256 or EdDSA
+	Signer    crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// loadJWTKeys reads JWT_SIGNING_KEYS ("kid:path,kid:path") and picks the signing key from
+// JWT_ACTIVE_KEY_ID, defaulting to the first key with a private part. No keys means HS256 with JWT_SECRET.
+func loadJWTKeys() ([]JWTKey, string, error) {
+	raw := strings.TrimSpace(os.Getenv("JWT_SIGNING_KEYS"))
+	if raw == "" {
+		return nil, "", nil
+	}
+	var keys []JWTKey
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, path, ok := strings.Cut(entry, ":")
+		id, path = strings.TrimSpace(id), strings.TrimSpace(path)
+		if !ok || id == "" || path == "" {
+			return nil, "", fmt.Errorf("invalid JWT_SIGNING_KEYS entry %q, want kid:path", entry)
+		}
+		if seen[id] {
+			return nil, "", fmt.Errorf("duplicate JWT key id %q", id)
+		}
+		seen[id] = true
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("read JWT key %q: %w", id, err)
+		}
+		key, err := ParseJWTKey(id, data)
+		if err != nil {
+			return nil, "", err
+		}
+		keys = append(keys, key)
+	}
+	activeID := strings.TrimSpace(os.Getenv("JWT_ACTIVE_KEY_ID"))
+	for _, k := range keys {
+		if activeID == "" && k.Signer != nil {
+			activeID = k.ID
+		}
+		if k.ID == activeID {
+			if k.Signer == nil {
+				return nil, "", fmt.Errorf("JWT key %q has no private key and cannot be active", activeID)
+			}
+			return keys, activeID, nil
+		}
+	}
+	if activeID == "" {
+		return nil, "", fmt.Errorf("JWT_SIGNING_KEYS has no private key to sign with")
+	}
+	return nil, "", fmt.Errorf("JWT_ACTIVE_KEY_ID %q is not in JWT_SIGNING_KEYS", activeID)
+}
+
+// ParseJWTKey parses a PEM private key (PKCS#8, or PKCS#1 for RSA) or a PKIX public key for verification only.
+func ParseJWTKey(id string, data []byte) (JWTKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return JWTKey{}, fmt.Errorf("JWT key %q: no PEM block", id)
+	}
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return JWTKey{}, fmt.Errorf("JWT key %q: unsupported PEM type %q", id, block.Type)
+	}
+	if err != nil {
+		return JWTKey{}, fmt.Errorf("JWT key %q: %w", id, err)
+	}
+	key := JWTKey{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.Signer, key.Public = JWTAlgorithmRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Algorithm, key.Public = JWTAlgorithmRS256, k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.Signer, key.Public = JWTAlgorithmEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Algorithm, key.Public = JWTAlgorithmEdDSA, k
+	default:
+		return JWTKey{}, fmt.Errorf("JWT key %q: only RSA and Ed25519 keys are supported", id)
+	}
+	if pub, ok := key.Public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return JWTKey{}, fmt.Errorf("JWT key %q: RSA keys must be at least %d bits", id, minRSAKeyBits)
+	}
+	return key, nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func TestLoadAuthConfig_JWTKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	retiredDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	os.Clearenv()
+	os.Setenv("JWT_SECRET", "my-secret-key")
+	os.Setenv("JWT_SIGNING_KEYS", "old:"+writePEM(t, "old.pem", "PUBLIC KEY", retiredDER)+
+		", rsa:"+writePEM(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))+
+		", ed:"+writePEM(t, "ed.pem", "PRIVATE KEY", edDER))
+	defer os.Clearenv()
+
+	cfg, err := LoadAuthConfig()
+	require.NoError(t, err)
+	require.Len(t, cfg.JWTKeys, 3)
+	assert.Nil(t, cfg.JWTKeys[0].Signer)
+	assert.Equal(t, JWTAlgorithmRS256, cfg.JWTKeys[1].Algorithm)
+	assert.Equal(t, JWTAlgorithmEdDSA, cfg.JWTKeys[2].Algorithm)
+	assert.Equal(t, "rsa", cfg.JWTActiveKeyID, "first key with a private part signs by default")
+	assert.Equal(t, "go-cafe", cfg.JWTIssuer)
+	assert.Equal(t, "go-cafe-api", cfg.JWTAudience)
+
+	os.Setenv("JWT_ACTIVE_KEY_ID", "ed")
+	cfg, err = LoadAuthConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "ed", cfg.JWTActiveKeyID)
+
+	os.Setenv("JWT_ACTIVE_KEY_ID", "old")
+	_, err = LoadAuthConfig()
+	assert.ErrorContains(t, err, "cannot be active")
+
+	os.Setenv("JWT_ACTIVE_KEY_ID", "missing")
+	_, err = LoadAuthConfig()
+	assert.ErrorContains(t, err, "JWT_ACTIVE_KEY_ID")
+}
+
+func TestParseJWTKey_RejectsWeakOrUnsupportedKeys(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = ParseJWTKey("small", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(small)}))
+	assert.ErrorContains(t, err, "2048")
+
+	_, err = ParseJWTKey("junk", []byte("not a pem"))
+	assert.ErrorContains(t, err, "no PEM block")
+
+	_, err = ParseJWTKey("cert", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}))
+	assert.ErrorContains(t, err, "unsupported PEM type")
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	MailDriverLog  = "log"
+	MailDriverSMTP = "smtp"
+)
+
+type MailConfig struct {
+	Driver       string // "log" (default) or "smtp"
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	LogFile      string // optional file the log driver appends to; stderr when empty
+}
+
+func LoadMailConfig() (*MailConfig, error) {
+	cfg := &MailConfig{
+		Driver:       strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_DRIVER"))),
+		From:         os.Getenv("MAIL_FROM"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		LogFile:      os.Getenv("MAIL_LOG_FILE"),
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = MailDriverLog
+	}
+	if cfg.From == "" {
+		cfg.From = "no-reply@go-cafe.local"
+	}
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = "587"
+	}
+	switch cfg.Driver {
+	case MailDriverLog:
+	case MailDriverSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER=smtp")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported MAIL_DRIVER %q", cfg.Driver)
+	}
+	return cfg, nil
+}
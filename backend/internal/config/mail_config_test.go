@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMailConfig_Defaults(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := LoadMailConfig()
+	require.NoError(t, err)
+	assert.Equal(t, MailDriverLog, cfg.Driver)
+	assert.NotEmpty(t, cfg.From)
+}
+
+func TestLoadMailConfig_SMTPRequiresHost(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MAIL_DRIVER", "smtp")
+	defer os.Clearenv()
+
+	_, err := LoadMailConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SMTP_HOST")
+}
+
+func TestLoadMailConfig_UnknownDriver(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MAIL_DRIVER", "carrier-pigeon")
+	defer os.Clearenv()
+
+	_, err := LoadMailConfig()
+	require.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// OIDCProviderConfig configures one OpenID Connect provider, e.g. OIDC_GOOGLE_ISSUER for provider "google".
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+var oidcProviderName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS (comma-separated names).
+func loadOIDCProviders() ([]OIDCProviderConfig, error) {
+	raw := strings.TrimSpace(os.Getenv("OIDC_PROVIDERS"))
+	if raw == "" {
+		return nil, nil
+	}
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !oidcProviderName.MatchString(name) {
+			return nil, fmt.Errorf("invalid OIDC provider name %q", name)
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		p := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       strings.TrimSpace(os.Getenv(prefix + "ISSUER")),
+			ClientID:     strings.TrimSpace(os.Getenv(prefix + "CLIENT_ID")),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.FieldsFunc(getEnvDefault(prefix+"SCOPES", "openid email profile"), isScopeSeparator),
+		}
+		if p.Issuer == "" || p.ClientID == "" {
+			return nil, fmt.Errorf("%sISSUER and %sCLIENT_ID are required for OIDC provider %q", prefix, prefix, name)
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+func isScopeSeparator(r rune) bool {
+	return r == ' ' || r == ','
+}
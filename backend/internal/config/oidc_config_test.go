@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAuthConfig_OIDCProviders(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("JWT_SECRET", "my-secret-key")
+	os.Setenv("OIDC_PROVIDERS", "google, acme-sso")
+	os.Setenv("OIDC_GOOGLE_ISSUER", "https://accounts.google.com")
+	os.Setenv("OIDC_GOOGLE_CLIENT_ID", "google-client")
+	os.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "google-secret")
+	os.Setenv("OIDC_ACME_SSO_ISSUER", "https://sso.acme.test")
+	os.Setenv("OIDC_ACME_SSO_CLIENT_ID", "acme-client")
+	os.Setenv("OIDC_ACME_SSO_SCOPES", "openid,email")
+	defer os.Clearenv()
+
+	cfg, err := LoadAuthConfig()
+	require.NoError(t, err)
+	require.Len(t, cfg.OIDCProviders, 2)
+	assert.Equal(t, "google", cfg.OIDCProviders[0].Name)
+	assert.Equal(t, []string{"openid", "email", "profile"}, cfg.OIDCProviders[0].Scopes)
+	assert.Equal(t, "acme-sso", cfg.OIDCProviders[1].Name)
+	assert.Equal(t, []string{"openid", "email"}, cfg.OIDCProviders[1].Scopes)
+	assert.NotEmpty(t, cfg.OIDCCallbackBaseURL)
+}
+
+func TestLoadAuthConfig_OIDCProviderMissingIssuer(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("JWT_SECRET", "my-secret-key")
+	os.Setenv("OIDC_PROVIDERS", "google")
+	os.Setenv("OIDC_GOOGLE_CLIENT_ID", "google-client")
+	defer os.Clearenv()
+
+	_, err := LoadAuthConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "OIDC_GOOGLE_ISSUER")
+}
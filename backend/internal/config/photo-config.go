@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	PhotoStoreLocal = "local"
+	PhotoStoreS3    = "s3"
+	PhotoStoreNone  = "none"
+)
+
+// PhotoConfig selects where uploaded cafe and review photos are stored. Store "none" disables uploads.
+type PhotoConfig struct {
+	Store    string // "local", "s3" or "none"
+	LocalDir string // directory for the local store
+	MaxBytes int64  // per-file upload limit
+	// OrphanCleanupInterval is how often photos left without a cafe or rating are removed in the background.
+	OrphanCleanupInterval time.Duration
+
+	S3Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com, or http://localhost:9000 for MinIO
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool // bucket in the URL path, as MinIO and most S3-compatible services expect
+}
+
+func LoadPhotoConfig() (*PhotoConfig, error) {
+	cfg := &PhotoConfig{
+		Store:                 strings.ToLower(strings.TrimSpace(getEnvDefault("PHOTO_STORE", PhotoStoreLocal))),
+		LocalDir:              getEnvDefault("PHOTO_LOCAL_DIR", "./data/photos"),
+		MaxBytes:              int64(parseIntEnv("PHOTO_MAX_BYTES", 10<<20)),
+		OrphanCleanupInterval: parseDurationEnv("PHOTO_ORPHAN_CLEANUP_INTERVAL", time.Hour),
+		S3Endpoint:            strings.TrimSpace(os.Getenv("PHOTO_S3_ENDPOINT")),
+		S3Region:              getEnvDefault("PHOTO_S3_REGION", "us-east-1"),
+		S3Bucket:              strings.TrimSpace(os.Getenv("PHOTO_S3_BUCKET")),
+		S3AccessKeyID:         strings.TrimSpace(os.Getenv("PHOTO_S3_ACCESS_KEY_ID")),
+		S3SecretAccessKey:     strings.TrimSpace(os.Getenv("PHOTO_S3_SECRET_ACCESS_KEY")),
+		S3PathStyle:           parseBoolEnv("PHOTO_S3_PATH_STYLE", false),
+	}
+
+	switch cfg.Store {
+	case PhotoStoreLocal, PhotoStoreNone:
+	case PhotoStoreS3:
+		if cfg.S3Endpoint == "" {
+			cfg.S3Endpoint = "https://s3." + cfg.S3Region + ".amazonaws.com"
+		}
+		if !validHTTPURL(cfg.S3Endpoint) {
+			return nil, fmt.Errorf("PHOTO_S3_ENDPOINT must be an http(s) URL")
+		}
+		if cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return nil, fmt.Errorf("PHOTO_S3_BUCKET, PHOTO_S3_ACCESS_KEY_ID and PHOTO_S3_SECRET_ACCESS_KEY are required when PHOTO_STORE is s3")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PHOTO_STORE %q", cfg.Store)
+	}
+	return cfg, nil
+}
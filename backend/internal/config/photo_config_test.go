@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPhotoConfig_Defaults(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := LoadPhotoConfig()
+	require.NoError(t, err)
+	assert.Equal(t, PhotoStoreLocal, cfg.Store)
+	assert.Equal(t, "./data/photos", cfg.LocalDir)
+	assert.Equal(t, int64(10<<20), cfg.MaxBytes)
+	assert.Equal(t, time.Hour, cfg.OrphanCleanupInterval)
+}
+
+func TestLoadPhotoConfig_S3(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	os.Setenv("PHOTO_STORE", "s3")
+	_, err := LoadPhotoConfig()
+	require.Error(t, err)
+
+	os.Setenv("PHOTO_S3_BUCKET", "go-cafe-photos")
+	os.Setenv("PHOTO_S3_ACCESS_KEY_ID", "key")
+	os.Setenv("PHOTO_S3_SECRET_ACCESS_KEY", "secret")
+	os.Setenv("PHOTO_S3_REGION", "ap-southeast-1")
+	cfg, err := LoadPhotoConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://s3.ap-southeast-1.amazonaws.com", cfg.S3Endpoint)
+	assert.False(t, cfg.S3PathStyle)
+
+	os.Setenv("PHOTO_S3_ENDPOINT", "localhost:9000")
+	_, err = LoadPhotoConfig()
+	require.Error(t, err)
+
+	os.Setenv("PHOTO_STORE", "gcs")
+	_, err = LoadPhotoConfig()
+	require.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	ReviewScreenerRules = "rules"
+	ReviewScreenerNone  = "none"
+)
+
+// ScreeningConfig configures the screening of review text before it is saved. The zero value uses the rule engine
+// with its default limits and an empty word list; Screener "none" turns screening off.
+type ScreeningConfig struct {
+	Screener         string   // "rules" or "none"
+	Words            []string // reviews containing one of these whole words are held
+	MaxLength        int      // longer reviews are rejected
+	MaxLinks         int      // reviews with a link are held, and with more than this many are rejected
+	MaxRepeatedChars int      // a longer run of one repeated character is held
+}
+
+func LoadScreeningConfig() (*ScreeningConfig, error) {
+	cfg := &ScreeningConfig{
+		Screener:         strings.ToLower(strings.TrimSpace(getEnvDefault("REVIEW_SCREENER", ReviewScreenerRules))),
+		MaxLength:        parseIntEnv("REVIEW_MAX_LENGTH", 2000),
+		MaxLinks:         parseIntEnv("REVIEW_MAX_LINKS", 2),
+		MaxRepeatedChars: parseIntEnv("REVIEW_MAX_REPEATED_CHARS", 8),
+	}
+	if cfg.Screener != ReviewScreenerRules && cfg.Screener != ReviewScreenerNone {
+		return nil, fmt.Errorf("unsupported REVIEW_SCREENER %q", cfg.Screener)
+	}
+
+	cfg.Words = appendWords(cfg.Words, strings.Split(os.Getenv("REVIEW_SCREEN_WORDS"), ","))
+	if path := strings.TrimSpace(os.Getenv("REVIEW_SCREEN_WORDS_FILE")); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read REVIEW_SCREEN_WORDS_FILE: %w", err)
+		}
+		// One word or phrase per line; blank lines and lines starting with # are skipped.
+		var lines []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				lines = append(lines, line)
+			}
+		}
+		cfg.Words = appendWords(cfg.Words, lines)
+	}
+	return cfg, nil
+}
+
+func appendWords(words, raw []string) []string {
+	for _, word := range raw {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
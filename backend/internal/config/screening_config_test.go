@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadScreeningConfig_Defaults(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := LoadScreeningConfig()
+	require.NoError(t, err)
+	assert.Equal(t, ReviewScreenerRules, cfg.Screener)
+	assert.Empty(t, cfg.Words)
+	assert.Equal(t, 2000, cfg.MaxLength)
+	assert.Equal(t, 2, cfg.MaxLinks)
+	assert.Equal(t, 8, cfg.MaxRepeatedChars)
+}
+
+func TestLoadScreeningConfig_Words(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	path := filepath.Join(t.TempDir(), "words.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# blocked terms\nCasino\n\n  free money \n"), 0o600))
+	os.Setenv("REVIEW_SCREEN_WORDS", "scam, ,Crypto")
+	os.Setenv("REVIEW_SCREEN_WORDS_FILE", path)
+	cfg, err := LoadScreeningConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scam", "crypto", "casino", "free money"}, cfg.Words)
+
+	os.Setenv("REVIEW_SCREEN_WORDS_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+	_, err = LoadScreeningConfig()
+	require.Error(t, err)
+
+	os.Unsetenv("REVIEW_SCREEN_WORDS_FILE")
+	os.Setenv("REVIEW_SCREENER", "openai")
+	_, err = LoadScreeningConfig()
+	require.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	StaticMapRendererGeoapify = "geoapify"
+	StaticMapRendererTiles    = "tiles"
+	StaticMapRendererMBTiles  = "mbtiles"
+	StaticMapCacheNone        = "none"
+)
+
+// StaticMapConfig selects how discovery map images are rendered. An empty Renderer means Geoapify when
+// GEOAPIFY_API_KEY is set and no static maps otherwise; an empty CacheDir disables the disk cache.
+type StaticMapConfig struct {
+	Renderer    string        // "geoapify" (Geoapify Static Maps), "tiles" (compose tiles from TileURL) or "mbtiles"
+	TileURL     string        // XYZ tile URL template with {z}, {x} and {y}
+	MBTilesPath string        // raster MBTiles file for the mbtiles renderer
+	UserAgent   string        // sent to the tile server; the OpenStreetMap tile policy requires an identifying one
+	CacheDir    string        // rendered maps are kept here for CacheTTL
+	CacheTTL    time.Duration // how long a rendered map is served from disk
+}
+
+func LoadStaticMapConfig() (*StaticMapConfig, error) {
+	defaultRenderer := StaticMapRendererTiles
+	if strings.TrimSpace(os.Getenv("GEOAPIFY_API_KEY")) != "" {
+		defaultRenderer = StaticMapRendererGeoapify
+	}
+
+	cfg := &StaticMapConfig{
+		Renderer:    strings.ToLower(strings.TrimSpace(getEnvDefault("STATIC_MAP_RENDERER", defaultRenderer))),
+		TileURL:     getEnvDefault("STATIC_MAP_TILE_URL", "https://tile.openstreetmap.org/{z}/{x}/{y}.png"),
+		MBTilesPath: strings.TrimSpace(os.Getenv("STATIC_MAP_MBTILES_PATH")),
+		UserAgent:   getEnvDefault("OSM_USER_AGENT", defaultOSMUserAgent),
+		CacheDir:    getEnvDefault("STATIC_MAP_CACHE_DIR", filepath.Join(os.TempDir(), "go-cafe-static-maps")),
+		CacheTTL:    parseDurationEnv("STATIC_MAP_CACHE_TTL", 24*time.Hour),
+	}
+	if strings.EqualFold(cfg.CacheDir, StaticMapCacheNone) {
+		cfg.CacheDir = ""
+	}
+
+	switch cfg.Renderer {
+	case StaticMapRendererGeoapify:
+	case StaticMapRendererTiles:
+		if !validHTTPURL(strings.NewReplacer("{z}", "0", "{x}", "0", "{y}", "0").Replace(cfg.TileURL)) ||
+			!strings.Contains(cfg.TileURL, "{z}") || !strings.Contains(cfg.TileURL, "{x}") || !strings.Contains(cfg.TileURL, "{y}") {
+			return nil, fmt.Errorf("STATIC_MAP_TILE_URL must be an http(s) URL with {z}, {x} and {y} placeholders")
+		}
+	case StaticMapRendererMBTiles:
+		if cfg.MBTilesPath == "" {
+			return nil, fmt.Errorf("STATIC_MAP_MBTILES_PATH is required when STATIC_MAP_RENDERER is mbtiles")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported STATIC_MAP_RENDERER %q", cfg.Renderer)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStaticMapConfig_Defaults(t *testing.T) {
+	os.Clearenv()
+
+	cfg, err := LoadStaticMapConfig()
+	require.NoError(t, err)
+	assert.Equal(t, StaticMapRendererTiles, cfg.Renderer)
+	assert.Equal(t, "https://tile.openstreetmap.org/{z}/{x}/{y}.png", cfg.TileURL)
+	assert.Equal(t, defaultOSMUserAgent, cfg.UserAgent)
+	assert.Equal(t, filepath.Join(os.TempDir(), "go-cafe-static-maps"), cfg.CacheDir)
+	assert.Equal(t, 24*time.Hour, cfg.CacheTTL)
+
+	os.Setenv("GEOAPIFY_API_KEY", "test-key")
+	defer os.Clearenv()
+	cfg, err = LoadStaticMapConfig()
+	require.NoError(t, err)
+	assert.Equal(t, StaticMapRendererGeoapify, cfg.Renderer)
+}
+
+func TestLoadStaticMapConfig_Validation(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	os.Setenv("STATIC_MAP_RENDERER", "mbtiles")
+	_, err := LoadStaticMapConfig()
+	require.Error(t, err)
+
+	os.Setenv("STATIC_MAP_MBTILES_PATH", "/data/singapore.mbtiles")
+	os.Setenv("STATIC_MAP_CACHE_DIR", "none")
+	cfg, err := LoadStaticMapConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "/data/singapore.mbtiles", cfg.MBTilesPath)
+	assert.Empty(t, cfg.CacheDir)
+
+	os.Setenv("STATIC_MAP_RENDERER", "tiles")
+	os.Setenv("STATIC_MAP_TILE_URL", "https://tiles.example.com/{z}/{x}.png")
+	_, err = LoadStaticMapConfig()
+	require.Error(t, err)
+
+	os.Setenv("STATIC_MAP_RENDERER", "mapbox")
+	_, err = LoadStaticMapConfig()
+	require.Error(t, err)
+}
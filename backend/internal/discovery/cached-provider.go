@@ -0,0 +1,34 @@
+package discovery
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/cache"
+)
+
+// CachedProvider caches a Provider's search results and place details. Not-found details are cached too.
+type CachedProvider struct {
+	next  Provider
+	cache *cache.Cache
+}
+
+func NewCachedProvider(next Provider, c *cache.Cache) *CachedProvider {
+	return &CachedProvider{next: next, cache: c}
+}
+
+func (p *CachedProvider) Search(ctx context.Context, filter SearchFilter) ([]Place, error) {
+	key := "search:" + strings.ToLower(strings.TrimSpace(filter.Query)) +
+		"|" + strings.ToLower(strings.TrimSpace(filter.City)) +
+		"|" + strconv.Itoa(filter.Limit)
+	return cache.Load(ctx, p.cache, key, func(ctx context.Context) ([]Place, error) {
+		return p.next.Search(ctx, filter)
+	})
+}
+
+func (p *CachedProvider) GetByID(ctx context.Context, placeID string) (*Place, error) {
+	return cache.Load(ctx, p.cache, "place:"+strings.TrimSpace(placeID), func(ctx context.Context) (*Place, error) {
+		return p.next.GetByID(ctx, placeID)
+	})
+}
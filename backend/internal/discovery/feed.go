@@ -0,0 +1,302 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/cafelisting"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/khorzhenwin/go-cafe/backend/internal/rating"
+)
+
+// Feed result origins.
+const (
+	OriginProvider  = "provider"  // only the places provider returned it
+	OriginCommunity = "community" // only a community listing matched
+	OriginBoth      = "both"      // a community listing was merged into a provider place
+)
+
+const (
+	// duplicateRadiusMeters is how close two places with matching names must be to count as the same cafe.
+	duplicateRadiusMeters = 75
+	// defaultFeedProviderTimeout bounds how long the feed waits for the provider before serving community results alone.
+	defaultFeedProviderTimeout = 4 * time.Second
+	earthRadiusMeters          = 6371000
+)
+
+var ErrFeedUnavailable = errors.New("discovery is unavailable")
+
+// CommunityListings is satisfied by *cafelisting.Service.
+type CommunityListings interface {
+	ListDiscovery(filter cafelisting.DiscoveryFilter, page pagination.Request) (pagination.Page[models.Cafe], error)
+}
+
+// PlaceStatsFinder is satisfied by *rating.Service.
+type PlaceStatsFinder interface {
+	StatsByExternalPlaceIDs(externalPlaceIDs []string) (map[string]rating.PlaceStats, error)
+}
+
+// FeedPlace is a discovery result with its origin. CafeListingID is the community listing merged into it, if any.
+type FeedPlace struct {
+	Place
+	Origin        string `json:"origin" example:"both"`
+	CafeListingID *uint  `json:"cafe_listing_id,omitempty"`
+}
+
+// FeedResponse lists merged results. Unavailable names the sources (provider, community) that failed, so the
+// client can tell a partial feed from an empty one.
+type FeedResponse struct {
+	Items       []FeedPlace `json:"items"`
+	Unavailable []string    `json:"unavailable"`
+}
+
+// Feed merges places provider results with community listings.
+type Feed struct {
+	provider        Provider
+	listings        CommunityListings
+	stats           PlaceStatsFinder
+	providerTimeout time.Duration
+}
+
+func NewFeed(provider Provider, listings CommunityListings, stats PlaceStatsFinder) *Feed {
+	return &Feed{provider: provider, listings: listings, stats: stats, providerTimeout: defaultFeedProviderTimeout}
+}
+
+// Search queries the provider and community listings concurrently and merges them. A failing source is reported in
+// Unavailable; ErrFeedUnavailable is returned only when both fail. A city the provider cannot resolve is the caller's
+// mistake rather than an outage, so its ErrCityNotFound is returned as is.
+func (f *Feed) Search(ctx context.Context, filter SearchFilter) (FeedResponse, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	filter.Limit = limit
+
+	var (
+		wg                        sync.WaitGroup
+		places                    []Place
+		listings                  []models.Cafe
+		providerErr, communityErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if f.provider == nil {
+			providerErr = errors.New("places provider is not configured")
+			return
+		}
+		providerCtx, cancel := context.WithTimeout(ctx, f.providerTimeout)
+		defer cancel()
+		places, providerErr = f.provider.Search(providerCtx, filter)
+	}()
+	go func() {
+		defer wg.Done()
+		page, err := f.listings.ListDiscovery(cafelisting.DiscoveryFilter{Query: filter.Query, City: filter.City}, pagination.Request{Limit: limit})
+		listings, communityErr = page.Items, err
+	}()
+	wg.Wait()
+
+	if errors.Is(providerErr, ErrCityNotFound) {
+		return FeedResponse{}, providerErr
+	}
+	resp := FeedResponse{Unavailable: []string{}}
+	if providerErr != nil {
+		resp.Unavailable = append(resp.Unavailable, OriginProvider)
+		places = nil
+	}
+	if communityErr != nil {
+		if providerErr != nil {
+			return FeedResponse{}, ErrFeedUnavailable
+		}
+		resp.Unavailable = append(resp.Unavailable, OriginCommunity)
+		listings = nil
+	}
+
+	stats, err := f.stats.StatsByExternalPlaceIDs(externalPlaceIDs(places, listings))
+	if err != nil {
+		if communityErr == nil {
+			resp.Unavailable = append(resp.Unavailable, OriginCommunity)
+		}
+		stats = nil
+	}
+
+	resp.Items = mergeFeed(places, listings, stats, limit)
+	return resp, nil
+}
+
+// feedEntry accumulates one merged result. Ratings are pooled as sum and count so stats from several sources combine.
+type feedEntry struct {
+	place       FeedPlace
+	externalIDs map[string]bool
+	ratingSum   float64
+	ratingCount int64
+}
+
+func (e *feedEntry) addStats(avg float64, count int64) {
+	e.ratingSum += avg * float64(count)
+	e.ratingCount += count
+}
+
+// mergeFeed keeps provider order, which carries the provider's relevance or distance ranking, folds community
+// listings into the provider place they duplicate (same ExternalPlaceID, or matching name within
+// duplicateRadiusMeters), and appends the rest in community order. Stats for an external place cover every listing
+// saved from it, so they are added once per place; a listing without one adds its own stats. Results are cut to limit.
+func mergeFeed(places []Place, listings []models.Cafe, stats map[string]rating.PlaceStats, limit int) []FeedPlace {
+	var entries []*feedEntry
+	byExternalID := map[string]*feedEntry{}
+	addExternalID := func(e *feedEntry, id string) {
+		if id == "" || e.externalIDs[id] {
+			return
+		}
+		e.externalIDs[id] = true
+		byExternalID[id] = e
+		if s, ok := stats[id]; ok {
+			e.addStats(s.AvgRating, s.ReviewCount)
+		}
+	}
+
+	for _, place := range places {
+		if place.ExternalPlaceID != "" && byExternalID[place.ExternalPlaceID] != nil {
+			continue
+		}
+		e := &feedEntry{place: FeedPlace{Place: place, Origin: OriginProvider}, externalIDs: map[string]bool{}}
+		addExternalID(e, place.ExternalPlaceID)
+		entries = append(entries, e)
+	}
+
+	for _, listing := range listings {
+		listingID := listing.ID
+		e := byExternalID[listing.ExternalPlaceID]
+		if e == nil {
+			e = findNearbyNamesake(entries, listing)
+		}
+		if e == nil {
+			e = &feedEntry{place: FeedPlace{Place: listingPlace(listing), Origin: OriginCommunity, CafeListingID: &listingID}, externalIDs: map[string]bool{}}
+			entries = append(entries, e)
+		} else {
+			if e.place.Origin == OriginProvider {
+				e.place.Origin = OriginBoth
+			}
+			if e.place.CafeListingID == nil {
+				e.place.CafeListingID = &listingID
+			}
+		}
+		if listing.ExternalPlaceID != "" {
+			addExternalID(e, listing.ExternalPlaceID)
+		} else {
+			e.addStats(listing.AvgRating, listing.ReviewCount)
+		}
+	}
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	out := make([]FeedPlace, len(entries))
+	for i, e := range entries {
+		out[i] = e.place
+		out[i].ReviewCount = e.ratingCount
+		out[i].AvgRating = 0
+		if e.ratingCount > 0 {
+			out[i].AvgRating = math.Round(e.ratingSum/float64(e.ratingCount)*100) / 100
+		}
+	}
+	return out
+}
+
+func findNearbyNamesake(entries []*feedEntry, listing models.Cafe) *feedEntry {
+	if listing.Latitude == nil || listing.Longitude == nil {
+		return nil
+	}
+	for _, e := range entries {
+		p := e.place
+		if p.Latitude == nil || p.Longitude == nil || !sameCafeName(p.Name, listing.Name) {
+			continue
+		}
+		if haversineMeters(*p.Latitude, *p.Longitude, *listing.Latitude, *listing.Longitude) <= duplicateRadiusMeters {
+			return e
+		}
+	}
+	return nil
+}
+
+func listingPlace(listing models.Cafe) Place {
+	id := listing.ExternalPlaceID
+	if id == "" {
+		id = "listing:" + strconv.FormatUint(uint64(listing.ID), 10)
+	}
+	return Place{
+		ID:              id,
+		ExternalPlaceID: listing.ExternalPlaceID,
+		SourceProvider:  listing.SourceProvider,
+		Name:            listing.Name,
+		Address:         listing.Address,
+		City:            listing.City,
+		Neighborhood:    listing.Neighborhood,
+		Description:     listing.Description,
+		ImageURL:        listing.ImageURL,
+		Latitude:        listing.Latitude,
+		Longitude:       listing.Longitude,
+		OpeningHours:    listing.OpeningHours,
+		Amenities:       listing.Amenities,
+		PriceLevel:      listing.PriceLevel,
+	}
+}
+
+func externalPlaceIDs(places []Place, listings []models.Cafe) []string {
+	seen := map[string]bool{}
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, place := range places {
+		add(place.ExternalPlaceID)
+	}
+	for _, listing := range listings {
+		add(listing.ExternalPlaceID)
+	}
+	return ids
+}
+
+// sameCafeName compares names by letters and digits only; one may extend the other ("Nylon Coffee" and
+// "Nylon Coffee Roasters") as long as the shorter keeps at least five characters.
+func sameCafeName(a, b string) bool {
+	a, b = nameKey(a), nameKey(b)
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if a == b {
+		return a != ""
+	}
+	return len(a) >= 5 && strings.Contains(b, a)
+}
+
+func nameKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/cafelisting"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/khorzhenwin/go-cafe/backend/internal/rating"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubProvider struct {
+	places []Place
+	err    error
+}
+
+func (s stubProvider) Search(ctx context.Context, filter SearchFilter) ([]Place, error) {
+	return s.places, s.err
+}
+
+func (s stubProvider) GetByID(ctx context.Context, placeID string) (*Place, error) { return nil, nil }
+
+type stubListings struct {
+	listings []models.Cafe
+	err      error
+}
+
+func (s stubListings) ListDiscovery(filter cafelisting.DiscoveryFilter, page pagination.Request) (pagination.Page[models.Cafe], error) {
+	return pagination.Page[models.Cafe]{Items: s.listings}, s.err
+}
+
+type stubStats map[string]rating.PlaceStats
+
+func (s stubStats) StatsByExternalPlaceIDs(externalPlaceIDs []string) (map[string]rating.PlaceStats, error) {
+	return s, nil
+}
+
+func coords(lat, lon float64) (*float64, *float64) { return &lat, &lon }
+
+func feedFixture() ([]Place, []models.Cafe, stubStats) {
+	nylonLat, nylonLon := coords(1.2765, 103.8412)
+	apiLat, apiLon := coords(1.2844, 103.8437)
+	places := []Place{
+		{ID: "geo-nylon", ExternalPlaceID: "geo-nylon", Name: "Nylon Coffee Roasters", Latitude: nylonLat, Longitude: nylonLon},
+		{ID: "geo-apiary", ExternalPlaceID: "geo-apiary", Name: "Apiary", Latitude: apiLat, Longitude: apiLon},
+		{ID: "geo-quiet", ExternalPlaceID: "geo-quiet", Name: "Quiet Corner"},
+	}
+	// 30 m from the Geoapify pin, entered by hand without a place ID.
+	nearLat, nearLon := coords(1.2767, 103.8414)
+	farLat, farLon := coords(1.3500, 103.9000)
+	listings := []models.Cafe{
+		{ID: 1, Name: "Apiary", ExternalPlaceID: "geo-apiary"},
+		{ID: 2, Name: "nylon coffee", Latitude: nearLat, Longitude: nearLon, AvgRating: 3, ReviewCount: 1},
+		{ID: 3, Name: "Apiary (saved)", ExternalPlaceID: "geo-apiary"},
+		{ID: 4, Name: "Nylon Coffee", Latitude: farLat, Longitude: farLon, AvgRating: 4, ReviewCount: 2},
+	}
+	stats := stubStats{
+		"geo-apiary": {ExternalPlaceID: "geo-apiary", AvgRating: 4.5, ReviewCount: 4},
+		"geo-nylon":  {ExternalPlaceID: "geo-nylon", AvgRating: 5, ReviewCount: 1},
+	}
+	return places, listings, stats
+}
+
+func TestFeed_MergesDeduplicatesAndAttachesStats(t *testing.T) {
+	places, listings, stats := feedFixture()
+	feed := NewFeed(stubProvider{places: places}, stubListings{listings: listings}, stats)
+
+	resp, err := feed.Search(context.Background(), SearchFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Unavailable)
+
+	byID := map[string]FeedPlace{}
+	var order []string
+	for _, item := range resp.Items {
+		byID[item.ID] = item
+		order = append(order, item.ID)
+	}
+	// Provider order is kept, however many reviews a place has; community-only results follow.
+	assert.Equal(t, []string{"geo-nylon", "geo-apiary", "geo-quiet", "listing:4"}, order)
+
+	apiary := byID["geo-apiary"]
+	assert.Equal(t, OriginBoth, apiary.Origin, "merged by external place ID")
+	assert.Equal(t, uint(1), *apiary.CafeListingID)
+	assert.Equal(t, int64(4), apiary.ReviewCount, "place stats count once however many listings share the ID")
+	assert.Equal(t, 4.5, apiary.AvgRating)
+
+	nylon := byID["geo-nylon"]
+	assert.Equal(t, OriginBoth, nylon.Origin, "merged by name within the duplicate radius")
+	assert.Equal(t, uint(2), *nylon.CafeListingID)
+	assert.Equal(t, int64(2), nylon.ReviewCount)
+	assert.Equal(t, 4.0, nylon.AvgRating)
+
+	assert.Equal(t, OriginCommunity, byID["listing:4"].Origin, "same name but too far away")
+	assert.Equal(t, OriginProvider, byID["geo-quiet"].Origin)
+	assert.Equal(t, 0.0, byID["geo-quiet"].AvgRating)
+
+	resp, err = feed.Search(context.Background(), SearchFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 2)
+	assert.Equal(t, "geo-nylon", resp.Items[0].ID)
+	assert.Equal(t, "geo-apiary", resp.Items[1].ID)
+}
+
+func TestFeed_DegradesWhenASourceFails(t *testing.T) {
+	places, listings, stats := feedFixture()
+
+	resp, err := NewFeed(stubProvider{err: errors.New("geoapify down")}, stubListings{listings: listings}, stats).Search(context.Background(), SearchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{OriginProvider}, resp.Unavailable)
+	require.Len(t, resp.Items, 3)
+	for _, item := range resp.Items {
+		assert.Equal(t, OriginCommunity, item.Origin)
+	}
+
+	resp, err = NewFeed(nil, stubListings{listings: listings}, stats).Search(context.Background(), SearchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{OriginProvider}, resp.Unavailable)
+
+	resp, err = NewFeed(stubProvider{places: places}, stubListings{err: errors.New("db down")}, stats).Search(context.Background(), SearchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{OriginCommunity}, resp.Unavailable)
+	assert.Len(t, resp.Items, 3)
+
+	_, err = NewFeed(stubProvider{err: errors.New("geoapify down")}, stubListings{err: errors.New("db down")}, stats).Search(context.Background(), SearchFilter{})
+	assert.ErrorIs(t, err, ErrFeedUnavailable)
+
+	// An unknown city is not an outage, even though community listings could still be served.
+	_, err = NewFeed(stubProvider{err: fmt.Errorf("could not resolve city %q in Geoapify: %w", "Atlantis", ErrCityNotFound)}, stubListings{listings: listings}, stats).Search(context.Background(), SearchFilter{City: "Atlantis"})
+	assert.ErrorIs(t, err, ErrCityNotFound)
+}
+
+func TestSameCafeName(t *testing.T) {
+	assert.True(t, sameCafeName("Nylon Coffee Roasters", "nylon-coffee"))
+	assert.False(t, sameCafeName("Cafe", "Cafe Nero"), "a short name is not a prefix match")
+	assert.False(t, sameCafeName("", ""))
+}
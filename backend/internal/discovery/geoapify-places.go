@@ -3,14 +3,18 @@ package discovery
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
-	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/cache"
+	"github.com/khorzhenwin/go-cafe/backend/internal/cafelisting"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
 )
 
 const (
@@ -22,6 +26,8 @@ const (
 	defaultDiscoveryRadiusMeters = 12000
 )
 
+var ErrCityNotFound = errors.New("city not found")
+
 type Place struct {
 	ID              string   `json:"id"`
 	ExternalPlaceID string   `json:"external_place_id"`
@@ -37,6 +43,10 @@ type Place struct {
 	AvgRating       float64  `json:"avg_rating"`
 	ReviewCount     int64    `json:"review_count"`
 	VisitStatus     string   `json:"visit_status,omitempty"`
+	// OpeningHours, Amenities and PriceLevel are copied onto the listing when a place is saved.
+	OpeningHours *models.OpeningHours `json:"opening_hours,omitempty"`
+	Amenities    []string             `json:"amenities,omitempty"`
+	PriceLevel   *int                 `json:"price_level,omitempty"`
 }
 
 type SearchFilter struct {
@@ -81,6 +91,48 @@ type geoapifyProperties struct {
 	Catering     struct {
 		Cuisine string `json:"cuisine"`
 	} `json:"catering"`
+	// OpeningHours is the OpenStreetMap opening_hours tag; TimeZone says where its times are local.
+	OpeningHours string             `json:"opening_hours"`
+	TimeZone     geoapifyTimeZone   `json:"timezone"`
+	Facilities   geoapifyFacilities `json:"facilities"`
+}
+
+type geoapifyTimeZone struct {
+	Name string `json:"name"`
+}
+
+type geoapifyFacilities struct {
+	InternetAccess  geoapifyFlag `json:"internet_access"`
+	Wheelchair      geoapifyFlag `json:"wheelchair"`
+	OutdoorSeating  geoapifyFlag `json:"outdoor_seating"`
+	Dogs            geoapifyFlag `json:"dogs"`
+	Takeaway        geoapifyFlag `json:"takeaway"`
+	AirConditioning geoapifyFlag `json:"air_conditioning"`
+}
+
+// geoapifyFlag is a facility that Geoapify reports either as a boolean or as an object of details (for example
+// internet_access with fee and for_customers). An object counts as present unless it says "available": false.
+// Values of any other shape are ignored rather than failing the whole response.
+type geoapifyFlag bool
+
+func (f *geoapifyFlag) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*f = geoapifyFlag(b)
+		return nil
+	}
+	var details struct {
+		Available *bool `json:"available"`
+	}
+	if err := json.Unmarshal(data, &details); err == nil && len(data) > 0 && data[0] == '{' {
+		*f = geoapifyFlag(details.Available == nil || *details.Available)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*f = geoapifyFlag(s == "yes")
+	}
+	return nil
 }
 
 type geoapifyGeocodeResponse struct {
@@ -104,10 +156,11 @@ type searchCenter struct {
 
 type GeoapifyPlacesClient struct {
 	apiKey           string
-	httpClient       *http.Client
+	httpClient       *upstream.Client
 	placesURL        string
 	placeDetailsURL  string
 	geocodeSearchURL string
+	geocodeCache     *cache.Cache
 }
 
 func NewGeoapifyPlacesClient(apiKey string) *GeoapifyPlacesClient {
@@ -116,9 +169,7 @@ func NewGeoapifyPlacesClient(apiKey string) *GeoapifyPlacesClient {
 		placesURL:        "https://api.geoapify.com/v2/places",
 		placeDetailsURL:  "https://api.geoapify.com/v2/place-details",
 		geocodeSearchURL: "https://api.geoapify.com/v1/geocode/search",
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient:       upstream.Shared(upstream.Geoapify, upstream.DefaultPolicy),
 	}
 }
 
@@ -130,6 +181,14 @@ func NewGeoapifyPlacesClientFromEnv() *GeoapifyPlacesClient {
 	return NewGeoapifyPlacesClient(apiKey)
 }
 
+// WithGeocodeCache caches the city lookups that precede every places search.
+func (c *GeoapifyPlacesClient) WithGeocodeCache(geocodeCache *cache.Cache) *GeoapifyPlacesClient {
+	if c != nil {
+		c.geocodeCache = geocodeCache
+	}
+	return c
+}
+
 func (c *GeoapifyPlacesClient) Search(ctx context.Context, filter SearchFilter) ([]Place, error) {
 	if c == nil || c.apiKey == "" {
 		return nil, fmt.Errorf("geoapify API key is not configured")
@@ -173,8 +232,7 @@ func (c *GeoapifyPlacesClient) Search(ctx context.Context, filter SearchFilter)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return nil, fmt.Errorf("geoapify places search failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, upstream.NewStatusError("geoapify places search", resp)
 	}
 
 	var payload geoapifyFeatureCollection
@@ -225,8 +283,7 @@ func (c *GeoapifyPlacesClient) GetByID(ctx context.Context, placeID string) (*Pl
 		return nil, nil
 	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return nil, fmt.Errorf("geoapify place details failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, upstream.NewStatusError("geoapify place details", resp)
 	}
 
 	var payload geoapifyFeatureCollection
@@ -252,6 +309,12 @@ func (c *GeoapifyPlacesClient) resolveSearchCenter(ctx context.Context, city str
 		}, nil
 	}
 
+	return cache.Load(ctx, c.geocodeCache, strings.ToLower(trimmedCity), func(ctx context.Context) (searchCenter, error) {
+		return c.geocodeCity(ctx, trimmedCity)
+	})
+}
+
+func (c *GeoapifyPlacesClient) geocodeCity(ctx context.Context, trimmedCity string) (searchCenter, error) {
 	params := url.Values{}
 	params.Set("text", trimmedCity)
 	params.Set("type", "city")
@@ -272,8 +335,7 @@ func (c *GeoapifyPlacesClient) resolveSearchCenter(ctx context.Context, city str
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return searchCenter{}, fmt.Errorf("geoapify geocode search failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return searchCenter{}, upstream.NewStatusError("geoapify geocode search", resp)
 	}
 
 	var payload geoapifyGeocodeResponse
@@ -281,7 +343,7 @@ func (c *GeoapifyPlacesClient) resolveSearchCenter(ctx context.Context, city str
 		return searchCenter{}, err
 	}
 	if len(payload.Results) == 0 {
-		return searchCenter{}, fmt.Errorf("could not resolve city %q in Geoapify", trimmedCity)
+		return searchCenter{}, fmt.Errorf("could not resolve city %q in Geoapify: %w", trimmedCity, ErrCityNotFound)
 	}
 
 	result := payload.Results[0]
@@ -311,7 +373,44 @@ func normalizeGeoapifyFeature(feature geoapifyFeature) Place {
 		AvgRating:       0,
 		ReviewCount:     0,
 		VisitStatus:     "discover",
+		OpeningHours:    geoapifyOpeningHours(feature.Properties),
+		Amenities:       geoapifyAmenities(feature.Properties.Facilities),
+	}
+}
+
+// geoapifyOpeningHours imports the OSM opening hours of a place when they are in the supported syntax and the
+// place's timezone is known; otherwise the place has no hours rather than guessed ones.
+func geoapifyOpeningHours(properties geoapifyProperties) *models.OpeningHours {
+	spec := strings.TrimSpace(properties.OpeningHours)
+	timeZone := strings.TrimSpace(properties.TimeZone.Name)
+	if spec == "" || timeZone == "" {
+		return nil
+	}
+	hours, err := cafelisting.ParseOSMOpeningHours(spec, timeZone)
+	if err != nil {
+		return nil
+	}
+	return hours
+}
+
+func geoapifyAmenities(facilities geoapifyFacilities) []string {
+	var amenities []string
+	for _, f := range []struct {
+		present geoapifyFlag
+		amenity string
+	}{
+		{facilities.InternetAccess, cafelisting.AmenityWiFi},
+		{facilities.Wheelchair, cafelisting.AmenityWheelchairAccessible},
+		{facilities.OutdoorSeating, cafelisting.AmenityOutdoorSeating},
+		{facilities.Dogs, cafelisting.AmenityPetFriendly},
+		{facilities.Takeaway, cafelisting.AmenityTakeaway},
+		{facilities.AirConditioning, cafelisting.AmenityAirConditioning},
+	} {
+		if f.present {
+			amenities = append(amenities, f.amenity)
+		}
 	}
+	return amenities
 }
 
 func resolveCoordinates(feature geoapifyFeature) (*float64, *float64) {
@@ -1,9 +1,11 @@
 package discovery
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResolveCoordinatesUsesPropertiesFirst(t *testing.T) {
@@ -58,3 +60,31 @@ func TestFirstNonEmpty(t *testing.T) {
 	assert.Equal(t, "Singapore", firstNonEmpty("", " ", "Singapore"))
 	assert.Equal(t, "", firstNonEmpty("", " "))
 }
+
+func TestNormalizeGeoapifyFeature_ImportsHoursAndFacilities(t *testing.T) {
+	const details = `{"features":[{"type":"Feature","properties":{
+		"name":"Night Owl","place_id":"owl1","formatted":"1 Owl St, Singapore","lat":1.3,"lon":103.8,
+		"opening_hours":"Mo-Th 08:00-18:00; Fr,Sa 08:00-02:00; Su off",
+		"timezone":{"name":"Asia/Singapore","offset_STD":"+08:00"},
+		"facilities":{"internet_access":{"fee":false,"for_customers":true},"wheelchair":true,"outdoor_seating":false,"dogs":"yes","takeaway":{"available":false}}
+	}}]}`
+	var payload geoapifyFeatureCollection
+	require.NoError(t, json.Unmarshal([]byte(details), &payload))
+	require.Len(t, payload.Features, 1)
+
+	place := normalizeGeoapifyFeature(payload.Features[0])
+	assert.Equal(t, []string{"wifi", "wheelchair_accessible", "pet_friendly"}, place.Amenities)
+	if assert.NotNil(t, place.OpeningHours) {
+		assert.Equal(t, "Asia/Singapore", place.OpeningHours.TimeZone)
+		assert.Len(t, place.OpeningHours.Weekly, 6)
+		assert.Equal(t, "02:00", place.OpeningHours.Weekly[5].Close)
+	}
+
+	// Hours in syntax the importer does not understand, or without a timezone, are left out rather than guessed.
+	feature := payload.Features[0]
+	feature.Properties.OpeningHours = "Mo-Fr sunrise-sunset"
+	assert.Nil(t, normalizeGeoapifyFeature(feature).OpeningHours)
+	feature.Properties.OpeningHours = "Mo-Fr 08:00-18:00"
+	feature.Properties.TimeZone.Name = ""
+	assert.Nil(t, normalizeGeoapifyFeature(feature).OpeningHours)
+}
@@ -1,14 +1,16 @@
 package discovery
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
 )
 
 type RatingsFinder interface {
@@ -16,15 +18,22 @@ type RatingsFinder interface {
 }
 
 type Handler struct {
-	Provider Provider
+	Provider   Provider
+	Feed       *Feed
+	StaticMaps StaticMapRenderer
 }
 
-func RegisterRoutes(r chi.Router, provider Provider) {
+// RegisterRoutes registers discovery routes. listings and stats supply the community side of the merged feed;
+// staticMaps renders the map images and may be nil when no renderer is configured.
+func RegisterRoutes(r chi.Router, provider Provider, listings CommunityListings, stats PlaceStatsFinder, staticMaps StaticMapRenderer) {
 	if provider == nil {
-		provider = NewGeoapifyPlacesClientFromEnv()
+		if client := NewGeoapifyPlacesClientFromEnv(); client != nil {
+			provider = client
+		}
 	}
 
-	h := &Handler{Provider: provider}
+	h := &Handler{Provider: provider, Feed: NewFeed(provider, listings, stats), StaticMaps: staticMaps}
+	r.Get("/discovery/feed", h.FeedHandler)
 	r.Route("/discovery/cafes", func(r chi.Router) {
 		r.Get("/", h.ListHandler)
 		r.Get("/static-map", h.StaticMapHandler)
@@ -33,20 +42,22 @@ func RegisterRoutes(r chi.Router, provider Provider) {
 }
 
 // ListHandler godoc
-// @Summary Discover cafes from Geoapify Places
-// @Description Returns discovery cafes sourced from Geoapify Places instead of the shared application database.
+// @Summary Discover cafes from the places providers
+// @Description Returns discovery cafes from the configured places providers (Geoapify Places, OpenStreetMap) instead of the shared application database. Providers are tried in DISCOVERY_PROVIDERS order, failing over to the next on 429 or 5xx; source_provider names the one that answered.
 // @Tags discovery
 // @Produce json
 // @Param query query string false "Search query"
 // @Param city query string false "City filter"
 // @Param limit query int false "Result limit"
 // @Success 200 {array} Place
-// @Failure 500 {string} string
+// @Failure 400 {string} string
+// @Failure 502 {string} string
 // @Failure 503 {string} string
+// @Failure 504 {string} string
 // @Router /discovery/cafes/ [get]
 func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	if h.Provider == nil {
-		http.Error(w, "Geoapify discovery is not configured", http.StatusServiceUnavailable)
+		http.Error(w, "Places discovery is not configured", http.StatusServiceUnavailable)
 		return
 	}
 
@@ -65,8 +76,12 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 		City:  r.URL.Query().Get("city"),
 		Limit: limit,
 	})
+	if errors.Is(err, ErrCityNotFound) {
+		http.Error(w, "City not found", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		upstream.WriteError(w, err)
 		return
 	}
 
@@ -74,19 +89,62 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(places)
 }
 
+// FeedHandler godoc
+// @Summary Unified discovery feed
+// @Description Merges places provider results with community cafe listings. Duplicates (same external place ID, or a matching name within 75 m) are folded into one result carrying the community avg_rating and review_count; origin is provider, community or both. When one source fails the other is still served and the failed source is listed in unavailable. A city the provider cannot resolve is 400.
+// @Tags discovery
+// @Produce json
+// @Param query query string false "Search query"
+// @Param city query string false "City filter"
+// @Param limit query int false "Result limit (1-20, default 12)"
+// @Success 200 {object} FeedResponse
+// @Failure 400 {string} string
+// @Failure 503 {string} string
+// @Router /discovery/feed [get]
+func (h *Handler) FeedHandler(w http.ResponseWriter, r *http.Request) {
+	limit := defaultSearchLimit
+	if limitStr := strings.TrimSpace(r.URL.Query().Get("limit")); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	feed, err := h.Feed.Search(r.Context(), SearchFilter{
+		Query: r.URL.Query().Get("query"),
+		City:  r.URL.Query().Get("city"),
+		Limit: limit,
+	})
+	if errors.Is(err, ErrCityNotFound) {
+		http.Error(w, "City not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(feed)
+}
+
 // GetByIDHandler godoc
 // @Summary Get discovery cafe by external place ID
-// @Description Returns a Geoapify cafe detail payload by place ID.
+// @Description Returns a cafe detail payload by place ID. OpenStreetMap IDs (osm-node-123) go to OpenStreetMap; other IDs to Geoapify.
 // @Tags discovery
 // @Produce json
 // @Param placeId path string true "External place ID"
 // @Success 200 {object} Place
 // @Failure 404 {string} string
+// @Failure 502 {string} string
 // @Failure 503 {string} string
+// @Failure 504 {string} string
 // @Router /discovery/cafes/{placeId} [get]
 func (h *Handler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 	if h.Provider == nil {
-		http.Error(w, "Geoapify discovery is not configured", http.StatusServiceUnavailable)
+		http.Error(w, "Places discovery is not configured", http.StatusServiceUnavailable)
 		return
 	}
 
@@ -98,7 +156,7 @@ func (h *Handler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 
 	place, err := h.Provider.GetByID(r.Context(), placeID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		upstream.WriteError(w, err)
 		return
 	}
 	if place == nil {
@@ -111,23 +169,25 @@ func (h *Handler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // StaticMapHandler godoc
-// @Summary Get Geoapify static map image
-// @Description Returns a Geoapify Static Maps image for discovery coordinates without exposing the API key to the browser.
+// @Summary Get static map image
+// @Description Returns a map image for discovery coordinates from the configured renderer (STATIC_MAP_RENDERER: Geoapify Static Maps, a tile server, or an MBTiles file) without exposing any API key to the browser. Responses carry an ETag; send it back in If-None-Match to get 304 Not Modified.
 // @Tags discovery
 // @Produce image/png
 // @Param point query []string false "Map points in lat,lon format"
 // @Param selected query string false "Selected point in lat,lon format"
 // @Param width query int false "Image width"
 // @Param height query int false "Image height"
+// @Param If-None-Match header string false "ETag from an earlier response"
 // @Success 200 {file} binary
+// @Success 304 {string} string
 // @Failure 400 {string} string
 // @Failure 502 {string} string
 // @Failure 503 {string} string
+// @Failure 504 {string} string
 // @Router /discovery/cafes/static-map [get]
 func (h *Handler) StaticMapHandler(w http.ResponseWriter, r *http.Request) {
-	staticMapClient := NewStaticMapClientFromEnv()
-	if staticMapClient == nil {
-		http.Error(w, "Geoapify static maps is not configured", http.StatusServiceUnavailable)
+	if h.StaticMaps == nil {
+		http.Error(w, "Static maps are not configured", http.StatusServiceUnavailable)
 		return
 	}
 
@@ -155,17 +215,40 @@ func (h *Handler) StaticMapHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := staticMapClient.GetMap(r.Context(), points, selected, width, height)
+	staticMap, err := h.StaticMaps.Render(r.Context(), StaticMapRequest{Points: points, Selected: selected, Width: width, Height: height})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadGateway)
+		upstream.WriteError(w, err)
 		return
 	}
-	defer resp.Body.Close()
 
-	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	etag := staticMap.ETag
+	if etag == "" {
+		sum := sha256.Sum256(staticMap.Data)
+		etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+	w.Header().Set("ETag", etag)
 	w.Header().Set("Cache-Control", "public, max-age=300")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", staticMap.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(staticMap.Data)))
 	w.WriteHeader(http.StatusOK)
-	_, _ = io.Copy(w, resp.Body)
+	_, _ = w.Write(staticMap.Data)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or is "*". Comparison is weak, as RFC 9110
+// requires for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
 }
 
 func parseStaticMapDimension(raw string, fallback int) (int, error) {
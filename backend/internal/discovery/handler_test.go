@@ -0,0 +1,81 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingProvider struct{ err error }
+
+func (p failingProvider) Search(ctx context.Context, filter SearchFilter) ([]Place, error) {
+	return nil, p.err
+}
+
+func (p failingProvider) GetByID(ctx context.Context, placeID string) (*Place, error) {
+	return nil, p.err
+}
+
+func TestListHandler_MapsUpstreamErrorsWithoutLeakingDetails(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{&upstream.StatusError{Service: "geoapify places search", StatusCode: 500}, http.StatusBadGateway},
+		{&upstream.StatusError{Service: "geoapify places search", StatusCode: 429}, http.StatusServiceUnavailable},
+		{&upstream.CircuitOpenError{Service: upstream.Geoapify}, http.StatusServiceUnavailable},
+		{fmt.Errorf(`could not resolve city "Atlantis" in Geoapify: %w`, ErrCityNotFound), http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		h := &Handler{Provider: failingProvider{err: tc.err}}
+		rec := httptest.NewRecorder()
+		h.ListHandler(rec, httptest.NewRequest(http.MethodGet, "/discovery/cafes/?city=Atlantis", nil))
+		assert.Equal(t, tc.status, rec.Code, tc.err.Error())
+		assert.NotContains(t, rec.Body.String(), "geoapify", tc.err.Error())
+	}
+}
+
+func TestFeedHandler_UnknownCityIsBadRequest(t *testing.T) {
+	h := &Handler{Feed: NewFeed(failingProvider{err: fmt.Errorf(`could not resolve city "Atlantis" in Geoapify: %w`, ErrCityNotFound)}, stubListings{}, stubStats{})}
+	rec := httptest.NewRecorder()
+	h.FeedHandler(rec, httptest.NewRequest(http.MethodGet, "/discovery/feed?city=Atlantis", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "Geoapify")
+}
+
+type fixedStaticMap struct{ image StaticMapImage }
+
+func (r fixedStaticMap) Render(ctx context.Context, req StaticMapRequest) (*StaticMapImage, error) {
+	image := r.image
+	return &image, nil
+}
+
+func TestStaticMapHandler_ETagAndNotModified(t *testing.T) {
+	h := &Handler{StaticMaps: fixedStaticMap{image: StaticMapImage{ContentType: "image/png", Data: []byte("png-bytes")}}}
+
+	rec := httptest.NewRecorder()
+	h.StaticMapHandler(rec, httptest.NewRequest(http.MethodGet, "/discovery/cafes/static-map?point=1.29,103.85", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "png-bytes", rec.Body.String())
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/discovery/cafes/static-map?point=1.29,103.85", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	h.StaticMapHandler(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+}
+
+func TestStaticMapHandler_NotConfigured(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&Handler{}).StaticMapHandler(rec, httptest.NewRequest(http.MethodGet, "/discovery/cafes/static-map", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
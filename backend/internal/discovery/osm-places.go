@@ -0,0 +1,288 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/cache"
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
+)
+
+const (
+	SourceProviderOpenStreetMap = "openstreetmap"
+	// osmPlaceIDPrefix marks OpenStreetMap place IDs ("osm-node-123"); they stay plain path segments in URLs.
+	osmPlaceIDPrefix = "osm-"
+	// osmQueryTimeoutSeconds is the Overpass server-side query timeout.
+	osmQueryTimeoutSeconds = 10
+)
+
+type overpassResponse struct {
+	Elements []overpassElement `json:"elements"`
+}
+
+// overpassElement is a node (lat/lon) or a way or relation (center, from "out center").
+type overpassElement struct {
+	Type   string            `json:"type"`
+	ID     int64             `json:"id"`
+	Lat    float64           `json:"lat"`
+	Lon    float64           `json:"lon"`
+	Center *overpassCenter   `json:"center"`
+	Tags   map[string]string `json:"tags"`
+}
+
+type overpassCenter struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type nominatimResult struct {
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OSMPlacesClient finds cafes in OpenStreetMap: Overpass for the cafes, Nominatim to geocode the city.
+// Both base URLs are configurable so a self-hosted instance can take the load off the public servers.
+type OSMPlacesClient struct {
+	overpassURL  string
+	nominatimURL string
+	userAgent    string
+	overpass     *upstream.Client
+	nominatim    *upstream.Client
+	geocodeCache *cache.Cache
+}
+
+// NewOSMPlacesClient takes the Overpass interpreter URL, the Nominatim base URL, and the User-Agent the
+// Nominatim usage policy asks every client to identify itself with.
+func NewOSMPlacesClient(overpassURL, nominatimURL, userAgent string) *OSMPlacesClient {
+	return &OSMPlacesClient{
+		overpassURL:  strings.TrimSpace(overpassURL),
+		nominatimURL: strings.TrimRight(strings.TrimSpace(nominatimURL), "/"),
+		userAgent:    strings.TrimSpace(userAgent),
+		overpass:     upstream.Shared(upstream.Overpass, upstream.Policy{Timeout: 15 * time.Second}),
+		nominatim:    upstream.Shared(upstream.Nominatim, upstream.DefaultPolicy),
+	}
+}
+
+// WithGeocodeCache caches the city lookups that precede every places search.
+func (c *OSMPlacesClient) WithGeocodeCache(geocodeCache *cache.Cache) *OSMPlacesClient {
+	if c != nil {
+		c.geocodeCache = geocodeCache
+	}
+	return c
+}
+
+// OwnsPlaceID reports whether placeID came from this client, so lookups can skip other providers.
+func (c *OSMPlacesClient) OwnsPlaceID(placeID string) bool {
+	return strings.HasPrefix(strings.TrimSpace(placeID), osmPlaceIDPrefix)
+}
+
+func (c *OSMPlacesClient) Search(ctx context.Context, filter SearchFilter) ([]Place, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	center, err := c.resolveSearchCenter(ctx, filter.City)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := `["amenity"="cafe"]["name"]`
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		selector += fmt.Sprintf(`["name"~%s,i]`, overpassString(regexp.QuoteMeta(query)))
+	}
+	query := fmt.Sprintf("[out:json][timeout:%d];nwr%s(around:%d,%f,%f);out center %d;",
+		osmQueryTimeoutSeconds, selector, center.RadiusMeters, center.Lat, center.Lon, limit)
+
+	elements, err := c.queryOverpass(ctx, "overpass places search", query)
+	if err != nil {
+		return nil, err
+	}
+
+	places := make([]Place, 0, len(elements))
+	for _, element := range elements {
+		place := normalizeOverpassElement(element)
+		if place.Name == "" {
+			continue
+		}
+		places = append(places, place)
+	}
+	return places, nil
+}
+
+func (c *OSMPlacesClient) GetByID(ctx context.Context, placeID string) (*Place, error) {
+	elementType, id, ok := parseOSMPlaceID(placeID)
+	if !ok {
+		return nil, nil
+	}
+
+	elements, err := c.queryOverpass(ctx, "overpass place details", fmt.Sprintf("[out:json][timeout:%d];%s(%d);out center;", osmQueryTimeoutSeconds, elementType, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		return nil, nil
+	}
+
+	place := normalizeOverpassElement(elements[0])
+	return &place, nil
+}
+
+func (c *OSMPlacesClient) queryOverpass(ctx context.Context, service, query string) ([]overpassElement, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.overpassURL, strings.NewReader(url.Values{"data": {query}}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.overpass.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstream.NewStatusError(service, resp)
+	}
+
+	var payload overpassResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	return payload.Elements, nil
+}
+
+func (c *OSMPlacesClient) resolveSearchCenter(ctx context.Context, city string) (searchCenter, error) {
+	trimmedCity := strings.TrimSpace(city)
+	if trimmedCity == "" {
+		return searchCenter{
+			Lat:           defaultDiscoveryLat,
+			Lon:           defaultDiscoveryLon,
+			RadiusMeters:  defaultDiscoveryRadiusMeters,
+			ResolvedLabel: "Singapore",
+		}, nil
+	}
+
+	return cache.Load(ctx, c.geocodeCache, "nominatim|"+strings.ToLower(trimmedCity), func(ctx context.Context) (searchCenter, error) {
+		return c.geocodeCity(ctx, trimmedCity)
+	})
+}
+
+func (c *OSMPlacesClient) geocodeCity(ctx context.Context, trimmedCity string) (searchCenter, error) {
+	params := url.Values{}
+	params.Set("q", trimmedCity)
+	params.Set("format", "jsonv2")
+	params.Set("limit", "1")
+	params.Set("accept-language", "en")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.nominatimURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return searchCenter{}, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.nominatim.Do(req)
+	if err != nil {
+		return searchCenter{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return searchCenter{}, upstream.NewStatusError("nominatim geocode search", resp)
+	}
+
+	var results []nominatimResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return searchCenter{}, err
+	}
+	if len(results) == 0 {
+		return searchCenter{}, fmt.Errorf("could not resolve city %q in Nominatim: %w", trimmedCity, ErrCityNotFound)
+	}
+
+	lat, latErr := strconv.ParseFloat(results[0].Lat, 64)
+	lon, lonErr := strconv.ParseFloat(results[0].Lon, 64)
+	if latErr != nil || lonErr != nil {
+		return searchCenter{}, fmt.Errorf("nominatim returned invalid coordinates for %q", trimmedCity)
+	}
+	return searchCenter{
+		Lat:           lat,
+		Lon:           lon,
+		RadiusMeters:  defaultDiscoveryRadiusMeters,
+		ResolvedLabel: firstNonEmpty(results[0].Name, results[0].DisplayName, trimmedCity),
+	}, nil
+}
+
+func normalizeOverpassElement(element overpassElement) Place {
+	tags := element.Tags
+	id := osmPlaceIDPrefix + element.Type + "-" + strconv.FormatInt(element.ID, 10)
+
+	var lat, lon *float64
+	switch {
+	case element.Center != nil:
+		lat, lon = &element.Center.Lat, &element.Center.Lon
+	case element.Lat != 0 || element.Lon != 0:
+		lat, lon = &element.Lat, &element.Lon
+	}
+
+	street := strings.TrimSpace(strings.Join(strings.Fields(tags["addr:housenumber"]+" "+tags["addr:street"]), " "))
+	var addressParts []string
+	for _, part := range []string{street, tags["addr:city"], tags["addr:postcode"]} {
+		if part = strings.TrimSpace(part); part != "" {
+			addressParts = append(addressParts, part)
+		}
+	}
+
+	description := "OpenStreetMap discovery result."
+	if cuisine := strings.TrimSpace(tags["cuisine"]); cuisine != "" {
+		description = fmt.Sprintf("OpenStreetMap discovery: %s cafe.", strings.ReplaceAll(strings.ReplaceAll(cuisine, ";", ", "), "_", " "))
+	}
+
+	return Place{
+		ID:              id,
+		ExternalPlaceID: id,
+		SourceProvider:  SourceProviderOpenStreetMap,
+		Name:            firstNonEmpty(tags["name:en"], tags["name"]),
+		Address:         strings.Join(addressParts, ", "),
+		City:            strings.TrimSpace(tags["addr:city"]),
+		Neighborhood:    firstNonEmpty(tags["addr:suburb"], tags["addr:district"], tags["addr:quarter"]),
+		Description:     description,
+		Latitude:        lat,
+		Longitude:       lon,
+		VisitStatus:     "discover",
+	}
+}
+
+// parseOSMPlaceID splits "osm-<node|way|relation>-<id>".
+func parseOSMPlaceID(placeID string) (string, int64, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(placeID), osmPlaceIDPrefix)
+	if !ok {
+		return "", 0, false
+	}
+	elementType, rawID, ok := strings.Cut(rest, "-")
+	if !ok || (elementType != "node" && elementType != "way" && elementType != "relation") {
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || id <= 0 {
+		return "", 0, false
+	}
+	return elementType, id, true
+}
+
+// overpassString quotes s as an Overpass QL string literal.
+func overpassString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
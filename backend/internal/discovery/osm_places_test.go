@@ -0,0 +1,82 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOSMPlacesClient_Search(t *testing.T) {
+	var overpassQuery, userAgent string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Seattle", r.URL.Query().Get("q"))
+		_, _ = w.Write([]byte(`[{"lat":"47.6038","lon":"-122.3300","name":"Seattle"}]`))
+	})
+	mux.HandleFunc("/api/interpreter", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		overpassQuery, userAgent = r.PostForm.Get("data"), r.UserAgent()
+		_, _ = w.Write([]byte(`{"elements":[
+			{"type":"node","id":101,"lat":47.61,"lon":-122.34,"tags":{"amenity":"cafe","name":"Victrola","cuisine":"coffee_shop","addr:housenumber":"411","addr:street":"15th Ave E","addr:city":"Seattle"}},
+			{"type":"way","id":202,"center":{"lat":47.62,"lon":-122.32},"tags":{"amenity":"cafe","name":"Storyville"}}
+		]}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := NewOSMPlacesClient(srv.URL+"/api/interpreter", srv.URL, "go-cafe-test")
+	places, err := client.Search(context.Background(), SearchFilter{Query: `Vic"trola.`, City: "Seattle", Limit: 5})
+	require.NoError(t, err)
+
+	assert.Equal(t, "go-cafe-test", userAgent)
+	assert.Contains(t, overpassQuery, `["name"~"Vic\"trola\\.",i]`)
+	assert.Contains(t, overpassQuery, "(around:12000,47.603800,-122.330000)")
+	assert.Contains(t, overpassQuery, "out center 5;")
+
+	require.Len(t, places, 2)
+	assert.Equal(t, "osm-node-101", places[0].ID)
+	assert.Equal(t, "osm-node-101", places[0].ExternalPlaceID)
+	assert.Equal(t, SourceProviderOpenStreetMap, places[0].SourceProvider)
+	assert.Equal(t, "411 15th Ave E, Seattle", places[0].Address)
+	assert.Equal(t, "OpenStreetMap discovery: coffee shop cafe.", places[0].Description)
+	assert.Equal(t, "osm-way-202", places[1].ID)
+	require.NotNil(t, places[1].Latitude)
+	assert.Equal(t, 47.62, *places[1].Latitude)
+}
+
+func TestOSMPlacesClient_GetByID(t *testing.T) {
+	var overpassQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		overpassQuery = r.PostForm.Get("data")
+		_, _ = w.Write([]byte(`{"elements":[{"type":"way","id":202,"center":{"lat":47.62,"lon":-122.32},"tags":{"name":"Storyville"}}]}`))
+	}))
+	defer srv.Close()
+
+	client := NewOSMPlacesClient(srv.URL, srv.URL, "go-cafe-test")
+	place, err := client.GetByID(context.Background(), "osm-way-202")
+	require.NoError(t, err)
+	require.NotNil(t, place)
+	assert.Contains(t, overpassQuery, "way(202);")
+	assert.Equal(t, "Storyville", place.Name)
+
+	place, err = client.GetByID(context.Background(), "osm-area-1")
+	require.NoError(t, err)
+	assert.Nil(t, place)
+}
+
+func TestParseOSMPlaceID(t *testing.T) {
+	elementType, id, ok := parseOSMPlaceID("osm-relation-42")
+	assert.True(t, ok)
+	assert.Equal(t, "relation", elementType)
+	assert.Equal(t, int64(42), id)
+
+	for _, bad := range []string{"42", "osm-node-", "osm-node-x", "osm-node--1", "geo-apiary"} {
+		_, _, ok := parseOSMPlaceID(bad)
+		assert.False(t, ok, bad)
+	}
+}
@@ -0,0 +1,61 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
+)
+
+// placeIDOwner is implemented by providers whose place IDs are recognisable, such as OpenStreetMap's "osm-" IDs.
+type placeIDOwner interface {
+	OwnsPlaceID(placeID string) bool
+}
+
+// ProviderChain asks providers in order and moves to the next only when one is rate limited, failing, or
+// unreachable (see upstream.Retryable). Any other error, such as an unknown city, is returned as is.
+type ProviderChain struct {
+	providers []Provider
+}
+
+func NewProviderChain(providers ...Provider) *ProviderChain {
+	return &ProviderChain{providers: providers}
+}
+
+func (c *ProviderChain) Search(ctx context.Context, filter SearchFilter) ([]Place, error) {
+	err := errors.New("no places provider is configured")
+	for _, provider := range c.providers {
+		var places []Place
+		places, err = provider.Search(ctx, filter)
+		if err == nil || !upstream.Retryable(ctx, err) {
+			return places, err
+		}
+	}
+	return nil, err
+}
+
+// GetByID sends an ID claimed by a provider only to that provider, since no other one knows it. Unclaimed IDs go
+// down the chain, skipping providers that recognise their own IDs.
+func (c *ProviderChain) GetByID(ctx context.Context, placeID string) (*Place, error) {
+	candidates := make([]Provider, 0, len(c.providers))
+	for _, provider := range c.providers {
+		owner, ok := provider.(placeIDOwner)
+		if !ok {
+			candidates = append(candidates, provider)
+			continue
+		}
+		if owner.OwnsPlaceID(placeID) {
+			return provider.GetByID(ctx, placeID)
+		}
+	}
+
+	err := errors.New("no places provider is configured")
+	for _, provider := range candidates {
+		var place *Place
+		place, err = provider.GetByID(ctx, placeID)
+		if err == nil || !upstream.Retryable(ctx, err) {
+			return place, err
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,94 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chainStub struct {
+	name   string
+	err    error
+	owns   string
+	called *[]string
+}
+
+func (s chainStub) Search(ctx context.Context, filter SearchFilter) ([]Place, error) {
+	*s.called = append(*s.called, s.name)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []Place{{ID: s.name, SourceProvider: s.name}}, nil
+}
+
+func (s chainStub) GetByID(ctx context.Context, placeID string) (*Place, error) {
+	*s.called = append(*s.called, s.name)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Place{ID: placeID, SourceProvider: s.name}, nil
+}
+
+// ownerStub is a chainStub that recognises IDs with its prefix.
+type ownerStub struct{ chainStub }
+
+func (s ownerStub) OwnsPlaceID(placeID string) bool { return strings.HasPrefix(placeID, s.owns) }
+
+func TestProviderChain_SearchFailsOverOnRateLimitAndServerErrors(t *testing.T) {
+	var called []string
+	chain := NewProviderChain(
+		chainStub{name: "geoapify", err: &upstream.StatusError{Service: "geoapify places search", StatusCode: 429}, called: &called},
+		chainStub{name: "backup", err: &upstream.StatusError{Service: "backup", StatusCode: 502}, called: &called},
+		chainStub{name: "osm", called: &called},
+	)
+
+	places, err := chain.Search(context.Background(), SearchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"geoapify", "backup", "osm"}, called)
+	assert.Equal(t, "osm", places[0].SourceProvider)
+}
+
+func TestProviderChain_SearchStopsOnOtherErrors(t *testing.T) {
+	var called []string
+	chain := NewProviderChain(
+		chainStub{name: "geoapify", err: &upstream.StatusError{StatusCode: 401}, called: &called},
+		chainStub{name: "osm", called: &called},
+	)
+	_, err := chain.Search(context.Background(), SearchFilter{})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"geoapify"}, called)
+
+	called = nil
+	chain = NewProviderChain(chainStub{name: "geoapify", err: &upstream.StatusError{StatusCode: 503}, called: &called})
+	_, err = chain.Search(context.Background(), SearchFilter{})
+	var statusErr *upstream.StatusError
+	require.True(t, errors.As(err, &statusErr), "the last provider's error is returned")
+	assert.Equal(t, 503, statusErr.StatusCode)
+
+	_, err = NewProviderChain().Search(context.Background(), SearchFilter{})
+	assert.Error(t, err)
+}
+
+func TestProviderChain_GetByIDRoutesOwnedIDs(t *testing.T) {
+	var called []string
+	chain := NewProviderChain(
+		chainStub{name: "geoapify", err: &upstream.StatusError{StatusCode: 500}, called: &called},
+		ownerStub{chainStub{name: "osm", owns: "osm-", called: &called}},
+	)
+
+	place, err := chain.GetByID(context.Background(), "osm-node-1")
+	require.NoError(t, err)
+	assert.Equal(t, "osm", place.SourceProvider)
+	assert.Equal(t, []string{"osm"}, called)
+
+	// Geoapify IDs are not sent to a provider that only knows its own.
+	called = nil
+	_, err = chain.GetByID(context.Background(), "51a3c0")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"geoapify"}, called)
+}
@@ -0,0 +1,167 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const staticMapCacheExt = ".map"
+
+// StaticMapDiskCache keeps rendered maps on disk for ttl, so repeated views of the same map skip the upstream
+// calls and the rendering. Concurrent requests for one uncached map share a single render, and each cached map
+// gets an ETag derived from its key and write time. Files are swept once they are older than ttl.
+type StaticMapDiskCache struct {
+	next  StaticMapRenderer
+	name  string
+	dir   string
+	ttl   time.Duration
+	group singleflight.Group
+	now   func() time.Time
+
+	sweepMu   sync.Mutex
+	lastSweep time.Time
+}
+
+// NewStaticMapDiskCache wraps next. name identifies the renderer, so switching renderers never serves maps
+// cached from the previous one.
+func NewStaticMapDiskCache(next StaticMapRenderer, name, dir string, ttl time.Duration) (*StaticMapDiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create static map cache dir: %w", err)
+	}
+	return &StaticMapDiskCache{next: next, name: name, dir: dir, ttl: ttl, now: time.Now}, nil
+}
+
+func (c *StaticMapDiskCache) Render(ctx context.Context, req StaticMapRequest) (*StaticMapImage, error) {
+	key := c.key(req)
+	if image, ok := c.read(key); ok {
+		return image, nil
+	}
+
+	result, err, _ := c.group.Do(key, func() (any, error) {
+		if image, ok := c.read(key); ok {
+			return image, nil
+		}
+		image, err := c.next.Render(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		c.sweep()
+		// A failed write only costs the next request a render.
+		if modTime, err := c.write(key, image); err == nil {
+			image.ETag = staticMapETag(key, modTime)
+		}
+		return image, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*StaticMapImage), nil
+}
+
+// key identifies the map a request draws: same points, selection and size after clamping, same renderer.
+func (c *StaticMapDiskCache) key(req StaticMapRequest) string {
+	width, height := clampStaticMapSize(req.Width, req.Height)
+	var canonical strings.Builder
+	fmt.Fprintf(&canonical, "%s|%dx%d|", c.name, width, height)
+	for _, point := range req.Points {
+		fmt.Fprintf(&canonical, "%.6f,%.6f;", point.Lat, point.Lon)
+	}
+	if req.Selected != nil {
+		fmt.Fprintf(&canonical, "|selected:%.6f,%.6f", req.Selected.Lat, req.Selected.Lon)
+	}
+	sum := sha256.Sum256([]byte(canonical.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *StaticMapDiskCache) path(key string) string {
+	return filepath.Join(c.dir, key+staticMapCacheExt)
+}
+
+// read returns the cached map for key unless it is missing, expired or unreadable. Files hold the content type,
+// a newline, then the image.
+func (c *StaticMapDiskCache) read(key string) (*StaticMapImage, bool) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil || c.expired(info.ModTime()) {
+		return nil, false
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	contentType, data, ok := bytes.Cut(raw, []byte("\n"))
+	if !ok {
+		return nil, false
+	}
+	return &StaticMapImage{ContentType: string(contentType), Data: data, ETag: staticMapETag(key, info.ModTime())}, true
+}
+
+// write stores the map through a temporary file, so readers never see a partial one.
+func (c *StaticMapDiskCache) write(key string, image *StaticMapImage) (time.Time, error) {
+	tmp, err := os.CreateTemp(c.dir, key+"-*.tmp")
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append([]byte(image.ContentType+"\n"), image.Data...))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return time.Time{}, err
+	}
+	info, err := os.Stat(c.path(key))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// sweep removes expired maps and leftover temporary files, at most once per ttl.
+func (c *StaticMapDiskCache) sweep() {
+	c.sweepMu.Lock()
+	now := c.now()
+	if now.Sub(c.lastSweep) < c.ttl {
+		c.sweepMu.Unlock()
+		return
+	}
+	c.lastSweep = now
+	c.sweepMu.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (!strings.HasSuffix(name, staticMapCacheExt) && !strings.HasSuffix(name, ".tmp")) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && c.expired(info.ModTime()) {
+			_ = os.Remove(filepath.Join(c.dir, name))
+		}
+	}
+}
+
+func (c *StaticMapDiskCache) expired(modTime time.Time) bool {
+	return c.now().Sub(modTime) >= c.ttl
+}
+
+func staticMapETag(key string, modTime time.Time) string {
+	return `"` + key[:16] + "-" + strconv.FormatInt(modTime.UnixNano(), 16) + `"`
+}
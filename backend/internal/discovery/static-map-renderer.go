@@ -0,0 +1,261 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	tileSize = 256
+	// maxMercatorLat is where Web Mercator tiles end.
+	maxMercatorLat = 85.05112878
+	// maxTileFetches bounds concurrent tile requests for one map.
+	maxTileFetches = 8
+)
+
+// ErrTileNotFound is returned by a TileSource that has no tile at the requested position; the renderer leaves the
+// area blank instead of failing.
+var ErrTileNotFound = errors.New("tile not found")
+
+// staticMapBackground fills areas without tiles; it is the land colour of the standard OpenStreetMap style.
+var staticMapBackground = color.RGBA{R: 0xf2, G: 0xef, B: 0xe9, A: 0xff}
+
+// StaticMapRequest is a map of cafes: every point gets a marker, and Selected gets a larger one on top.
+type StaticMapRequest struct {
+	Points   []StaticMapPoint
+	Selected *StaticMapPoint
+	Width    int
+	Height   int
+}
+
+// StaticMapImage is an encoded map image. ETag is set by renderers that can name the image without hashing it.
+type StaticMapImage struct {
+	ContentType string
+	Data        []byte
+	ETag        string
+}
+
+// StaticMapRenderer renders discovery maps. Implementations: *StaticMapClient (Geoapify), *TileRenderer, and
+// *StaticMapDiskCache wrapping either.
+type StaticMapRenderer interface {
+	Render(ctx context.Context, req StaticMapRequest) (*StaticMapImage, error)
+}
+
+// TileSource supplies 256 px Web Mercator (XYZ) raster tiles.
+type TileSource interface {
+	Tile(ctx context.Context, z, x, y int) (image.Image, error)
+	// ZoomRange is the lowest and highest zoom the source has tiles for.
+	ZoomRange() (int, int)
+}
+
+// TileRenderer composes maps from a TileSource and draws the markers buildStaticCircle describes to Geoapify.
+type TileRenderer struct {
+	source TileSource
+}
+
+func NewTileRenderer(source TileSource) *TileRenderer {
+	return &TileRenderer{source: source}
+}
+
+func (r *TileRenderer) Render(ctx context.Context, req StaticMapRequest) (*StaticMapImage, error) {
+	width, height := clampStaticMapSize(req.Width, req.Height)
+	layout := planStaticMap(req.Points, req.Selected)
+
+	minZoom, maxZoom := r.source.ZoomRange()
+	zoom := min(max(staticMapPointZoom, minZoom), maxZoom)
+	center := layout.Center
+	if layout.Area != nil {
+		area := layout.Area
+		center = StaticMapPoint{Lat: (area.MinLat + area.MaxLat) / 2, Lon: (area.MinLon + area.MaxLon) / 2}
+		zoom = fitZoom(*area, width, height, minZoom, maxZoom)
+	}
+
+	// Global pixel coordinates of the image's top-left corner at this zoom.
+	cx, cy := projectMercator(center, zoom)
+	originX := math.Round(cx) - float64(width/2)
+	originY := math.Round(cy) - float64(height/2)
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), &image.Uniform{C: staticMapBackground}, image.Point{}, draw.Src)
+	if err := r.drawTiles(ctx, canvas, zoom, int(originX), int(originY)); err != nil {
+		return nil, err
+	}
+
+	for _, marker := range layout.Markers {
+		x, y := projectMercator(marker.Point, zoom)
+		drawMarker(canvas, x-originX, y-originY, markerStyle(marker.Selected))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, err
+	}
+	return &StaticMapImage{ContentType: "image/png", Data: buf.Bytes()}, nil
+}
+
+func (r *TileRenderer) drawTiles(ctx context.Context, canvas *image.RGBA, zoom, originX, originY int) error {
+	bounds := canvas.Bounds()
+	tilesPerSide := 1 << zoom
+	firstX, lastX := floorDiv(originX, tileSize), floorDiv(originX+bounds.Dx()-1, tileSize)
+	firstY, lastY := max(floorDiv(originY, tileSize), 0), min(floorDiv(originY+bounds.Dy()-1, tileSize), tilesPerSide-1)
+
+	type placed struct {
+		tile   image.Image
+		offset image.Point
+	}
+	var (
+		mu    sync.Mutex
+		tiles []placed
+	)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxTileFetches)
+	for ty := firstY; ty <= lastY; ty++ {
+		for tx := firstX; tx <= lastX; tx++ {
+			offset := image.Pt(tx*tileSize-originX, ty*tileSize-originY)
+			// Longitude wraps, so a map across the antimeridian draws tiles from the other side of the world.
+			x, y := ((tx%tilesPerSide)+tilesPerSide)%tilesPerSide, ty
+			group.Go(func() error {
+				tile, err := r.source.Tile(groupCtx, zoom, x, y)
+				if errors.Is(err, ErrTileNotFound) {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("tile %d/%d/%d: %w", zoom, x, y, err)
+				}
+				mu.Lock()
+				tiles = append(tiles, placed{tile: tile, offset: offset})
+				mu.Unlock()
+				return nil
+			})
+		}
+	}
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	for _, t := range tiles {
+		rect := image.Rectangle{Min: t.offset, Max: t.offset.Add(image.Pt(tileSize, tileSize))}
+		draw.Draw(canvas, rect, t.tile, t.tile.Bounds().Min, draw.Src)
+	}
+	return nil
+}
+
+// fitZoom is the highest zoom at which area fits in width x height.
+func fitZoom(area staticMapArea, width, height, minZoom, maxZoom int) int {
+	for zoom := maxZoom; zoom > minZoom; zoom-- {
+		left, top := projectMercator(StaticMapPoint{Lat: area.MaxLat, Lon: area.MinLon}, zoom)
+		right, bottom := projectMercator(StaticMapPoint{Lat: area.MinLat, Lon: area.MaxLon}, zoom)
+		if right-left <= float64(width) && bottom-top <= float64(height) {
+			return zoom
+		}
+	}
+	return minZoom
+}
+
+// projectMercator returns p's global pixel coordinates in the Web Mercator tile grid at zoom.
+func projectMercator(p StaticMapPoint, zoom int) (float64, float64) {
+	scale := float64(tileSize) * math.Exp2(float64(zoom))
+	lat := math.Max(math.Min(p.Lat, maxMercatorLat), -maxMercatorLat) * math.Pi / 180
+	x := (p.Lon + 180) / 360 * scale
+	y := (1 - math.Log(math.Tan(lat)+1/math.Cos(lat))/math.Pi) / 2 * scale
+	return x, y
+}
+
+// drawMarker paints a filled, outlined circle centred on (cx, cy), anti-aliased over one pixel.
+func drawMarker(canvas *image.RGBA, cx, cy float64, style staticMarkerStyle) {
+	fill := parseHexColor(style.FillColor)
+	line := parseHexColor(style.LineColor)
+	radius := float64(style.Radius)
+	halfLine := float64(style.LineWidth) / 2
+	outer := radius + halfLine
+
+	bounds := image.Rect(int(math.Floor(cx-outer-1)), int(math.Floor(cy-outer-1)), int(math.Ceil(cx+outer+1)), int(math.Ceil(cy+outer+1))).Intersect(canvas.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+			if fillCover := clamp01(radius - halfLine + 0.5 - d); fillCover > 0 {
+				blendPixel(canvas, x, y, fill, style.FillOpacity*fillCover)
+			}
+			// The outline straddles the circle's edge, like Geoapify's linewidth.
+			lineCover := clamp01(outer+0.5-d) * clamp01(d-(radius-halfLine)+0.5)
+			if lineCover > 0 {
+				blendPixel(canvas, x, y, line, style.LineOpacity*lineCover)
+			}
+		}
+	}
+}
+
+func blendPixel(canvas *image.RGBA, x, y int, c color.RGBA, alpha float64) {
+	dst := canvas.RGBAAt(x, y)
+	mix := func(src, dst uint8) uint8 {
+		return uint8(math.Round(float64(src)*alpha + float64(dst)*(1-alpha)))
+	}
+	canvas.SetRGBA(x, y, color.RGBA{R: mix(c.R, dst.R), G: mix(c.G, dst.G), B: mix(c.B, dst.B), A: 0xff})
+}
+
+// parseHexColor parses "#rrggbb"; anything else is black.
+func parseHexColor(hex string) color.RGBA {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return color.RGBA{A: 0xff}
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// NewStaticMapRendererFromConfig builds the renderer selected by STATIC_MAP_RENDERER, wrapped in the disk cache
+// unless it is disabled. The geoapify renderer is nil when GEOAPIFY_API_KEY is unset.
+func NewStaticMapRendererFromConfig(cfg *config.StaticMapConfig) (StaticMapRenderer, error) {
+	var renderer StaticMapRenderer
+	switch cfg.Renderer {
+	case config.StaticMapRendererGeoapify, "":
+		client := NewStaticMapClientFromEnv()
+		if client == nil {
+			return nil, nil
+		}
+		renderer = client
+	case config.StaticMapRendererTiles:
+		renderer = NewTileRenderer(NewHTTPTileSource(cfg.TileURL, cfg.UserAgent))
+	case config.StaticMapRendererMBTiles:
+		source, err := OpenMBTiles(cfg.MBTilesPath)
+		if err != nil {
+			return nil, err
+		}
+		renderer = NewTileRenderer(source)
+	default:
+		return nil, fmt.Errorf("unsupported static map renderer %q", cfg.Renderer)
+	}
+
+	if cfg.CacheDir == "" || cfg.CacheTTL <= 0 {
+		return renderer, nil
+	}
+	cached, err := NewStaticMapDiskCache(renderer, firstNonEmpty(cfg.Renderer, config.StaticMapRendererGeoapify), cfg.CacheDir, cfg.CacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	return cached, nil
+}
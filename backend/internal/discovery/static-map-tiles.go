@@ -0,0 +1,161 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	DefaultTileURL = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
+	// maxTileBytes bounds one tile read from a tile server.
+	maxTileBytes = 2 << 20
+	// maxTileZoom is the deepest zoom standard tile servers render.
+	maxTileZoom = 19
+)
+
+// HTTPTileSource fetches XYZ tiles from a tile server. The OpenStreetMap tile usage policy asks for an identifying
+// User-Agent, and heavy use belongs on a self-hosted or commercial tile server.
+type HTTPTileSource struct {
+	urlTemplate string
+	userAgent   string
+	httpClient  *upstream.Client
+}
+
+// NewHTTPTileSource takes a URL template with {z}, {x} and {y} placeholders.
+func NewHTTPTileSource(urlTemplate, userAgent string) *HTTPTileSource {
+	return &HTTPTileSource{
+		urlTemplate: strings.TrimSpace(urlTemplate),
+		userAgent:   strings.TrimSpace(userAgent),
+		httpClient:  upstream.Shared(upstream.Tiles, upstream.DefaultPolicy),
+	}
+}
+
+func (s *HTTPTileSource) ZoomRange() (int, int) {
+	return 0, maxTileZoom
+}
+
+func (s *HTTPTileSource) Tile(ctx context.Context, z, x, y int) (image.Image, error) {
+	tileURL := strings.NewReplacer("{z}", strconv.Itoa(z), "{x}", strconv.Itoa(x), "{y}", strconv.Itoa(y)).Replace(s.urlTemplate)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrTileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstream.NewStatusError("map tiles", resp)
+	}
+
+	tile, _, err := image.Decode(io.LimitReader(resp.Body, maxTileBytes))
+	return tile, err
+}
+
+// MBTilesSource reads raster tiles from an MBTiles file (a SQLite database of PNG or JPEG tiles), so maps render
+// without any network calls.
+type MBTilesSource struct {
+	db      *gorm.DB
+	minZoom int
+	maxZoom int
+}
+
+type mbtilesMetadata struct {
+	Name  string
+	Value string
+}
+
+// OpenMBTiles opens path read-only and checks that it holds raster tiles.
+func OpenMBTiles(path string) (*MBTilesSource, error) {
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("open mbtiles %s: %w", path, err)
+	}
+
+	source := &MBTilesSource{db: db, maxZoom: maxTileZoom}
+	if err := source.readMetadata(); err != nil {
+		_ = source.Close()
+		return nil, err
+	}
+	return source, nil
+}
+
+func (s *MBTilesSource) readMetadata() error {
+	var rows []mbtilesMetadata
+	if err := s.db.Table("metadata").Select("name, value").Find(&rows).Error; err != nil {
+		return fmt.Errorf("read mbtiles metadata: %w", err)
+	}
+	metadata := make(map[string]string, len(rows))
+	for _, row := range rows {
+		metadata[row.Name] = strings.TrimSpace(row.Value)
+	}
+
+	if format := metadata["format"]; format != "png" && format != "jpg" && format != "jpeg" {
+		return fmt.Errorf("mbtiles format %q is not supported; use raster png or jpg tiles", format)
+	}
+	if value, err := strconv.Atoi(metadata["minzoom"]); err == nil {
+		s.minZoom = value
+	}
+	if value, err := strconv.Atoi(metadata["maxzoom"]); err == nil {
+		s.maxZoom = value
+	}
+	if s.minZoom > s.maxZoom {
+		return fmt.Errorf("mbtiles minzoom %d is above maxzoom %d", s.minZoom, s.maxZoom)
+	}
+	return nil
+}
+
+func (s *MBTilesSource) ZoomRange() (int, int) {
+	return s.minZoom, s.maxZoom
+}
+
+func (s *MBTilesSource) Tile(ctx context.Context, z, x, y int) (image.Image, error) {
+	// MBTiles rows are numbered from the bottom (TMS), XYZ rows from the top.
+	tmsY := (1 << z) - 1 - y
+
+	var data []byte
+	err := s.db.WithContext(ctx).
+		Raw("SELECT tile_data FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ? LIMIT 1", z, x, tmsY).
+		Row().Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tile, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode mbtiles tile %d/%d/%d: %w", z, x, y, err)
+	}
+	return tile, nil
+}
+
+func (s *MBTilesSource) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
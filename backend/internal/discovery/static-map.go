@@ -10,13 +10,18 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/upstream"
 )
 
 const (
 	defaultStaticMapWidth  = 1200
 	defaultStaticMapHeight = 720
 	maxStaticMapDimension  = 2000
+	// staticMapPointZoom is the zoom used when the map centers on a single point.
+	staticMapPointZoom = 15
+	// maxStaticMapBytes bounds an image read from an upstream renderer.
+	maxStaticMapBytes = 16 << 20
 )
 
 type StaticMapPoint struct {
@@ -24,19 +29,18 @@ type StaticMapPoint struct {
 	Lon float64
 }
 
+// StaticMapClient renders maps with the Geoapify Static Maps API.
 type StaticMapClient struct {
 	apiKey     string
 	baseURL    string
-	httpClient *http.Client
+	httpClient *upstream.Client
 }
 
 func NewStaticMapClient(apiKey string) *StaticMapClient {
 	return &StaticMapClient{
-		apiKey:  strings.TrimSpace(apiKey),
-		baseURL: "https://maps.geoapify.com/v1/staticmap",
-		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
-		},
+		apiKey:     strings.TrimSpace(apiKey),
+		baseURL:    "https://maps.geoapify.com/v1/staticmap",
+		httpClient: upstream.Shared(upstream.Geoapify, upstream.DefaultPolicy),
 	}
 }
 
@@ -48,33 +52,66 @@ func NewStaticMapClientFromEnv() *StaticMapClient {
 	return NewStaticMapClient(apiKey)
 }
 
-func (c *StaticMapClient) GetMap(ctx context.Context, points []StaticMapPoint, selected *StaticMapPoint, width, height int) (*http.Response, error) {
+// Render fetches the map from the Geoapify Static Maps API.
+func (c *StaticMapClient) Render(ctx context.Context, req StaticMapRequest) (*StaticMapImage, error) {
 	if c == nil || c.apiKey == "" {
 		return nil, fmt.Errorf("geoapify static maps is not configured")
 	}
 
-	params := buildStaticMapParams(points, selected, width, height)
+	params := buildStaticMapParams(req.Points, req.Selected, req.Width, req.Height)
 	params.Set("apiKey", c.apiKey)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"?"+params.Encode(), nil)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return nil, fmt.Errorf("geoapify static map failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, upstream.NewStatusError("geoapify static map", resp)
 	}
 
-	return resp, nil
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxStaticMapBytes))
+	if err != nil {
+		return nil, err
+	}
+	return &StaticMapImage{ContentType: firstNonEmpty(resp.Header.Get("Content-Type"), "image/png"), Data: data}, nil
 }
 
 func buildStaticMapParams(points []StaticMapPoint, selected *StaticMapPoint, width, height int) url.Values {
+	width, height = clampStaticMapSize(width, height)
+	layout := planStaticMap(points, selected)
+
+	params := url.Values{}
+	params.Set("style", "osm-bright")
+	params.Set("format", "png")
+	params.Set("width", strconv.Itoa(width))
+	params.Set("height", strconv.Itoa(height))
+	params.Set("scaleFactor", "2")
+	params.Set("lang", "en")
+
+	if layout.Area == nil {
+		params.Set("center", fmt.Sprintf("lonlat:%f,%f", layout.Center.Lon, layout.Center.Lat))
+		params.Set("zoom", strconv.Itoa(staticMapPointZoom))
+	} else {
+		area := layout.Area
+		params.Set("area", fmt.Sprintf("rect:%f,%f,%f,%f", area.MinLon, area.MaxLat, area.MaxLon, area.MinLat))
+	}
+
+	geometries := make([]string, 0, len(layout.Markers))
+	for _, marker := range layout.Markers {
+		geometries = append(geometries, buildStaticCircle(marker.Point, marker.Selected))
+	}
+	params.Set("geometry", strings.Join(geometries, "|"))
+
+	return params
+}
+
+func clampStaticMapSize(width, height int) (int, int) {
 	if width <= 0 {
 		width = defaultStaticMapWidth
 	}
@@ -87,63 +124,84 @@ func buildStaticMapParams(points []StaticMapPoint, selected *StaticMapPoint, wid
 	if height > maxStaticMapDimension {
 		height = maxStaticMapDimension
 	}
+	return width, height
+}
 
-	params := url.Values{}
-	params.Set("style", "osm-bright")
-	params.Set("format", "png")
-	params.Set("width", strconv.Itoa(width))
-	params.Set("height", strconv.Itoa(height))
-	params.Set("scaleFactor", "2")
-	params.Set("lang", "en")
+// staticMapLayout is what every renderer draws: either a Center at staticMapPointZoom or an Area to fit, plus
+// markers in drawing order (the selected marker last, on top).
+type staticMapLayout struct {
+	Center  StaticMapPoint
+	Area    *staticMapArea
+	Markers []staticMarker
+}
+
+type staticMapArea struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
 
+type staticMarker struct {
+	Point    StaticMapPoint
+	Selected bool
+}
+
+func planStaticMap(points []StaticMapPoint, selected *StaticMapPoint) staticMapLayout {
 	if len(points) == 0 && selected != nil {
 		points = append(points, *selected)
 	}
 
+	var layout staticMapLayout
 	if len(points) <= 1 {
-		point := StaticMapPoint{Lat: defaultDiscoveryLat, Lon: defaultDiscoveryLon}
+		layout.Center = StaticMapPoint{Lat: defaultDiscoveryLat, Lon: defaultDiscoveryLon}
 		if len(points) == 1 {
-			point = points[0]
+			layout.Center = points[0]
 		}
-		params.Set("center", fmt.Sprintf("lonlat:%f,%f", point.Lon, point.Lat))
-		params.Set("zoom", "15")
 	} else {
-		params.Set("area", buildStaticMapArea(points))
+		layout.Area = buildStaticMapArea(points)
 	}
 
-	geometries := make([]string, 0, len(points)+1)
 	for _, point := range points {
-		geometries = append(geometries, buildStaticCircle(point, false))
+		layout.Markers = append(layout.Markers, staticMarker{Point: point})
 	}
 	if selected != nil {
-		geometries = append(geometries, buildStaticCircle(*selected, true))
+		layout.Markers = append(layout.Markers, staticMarker{Point: *selected, Selected: true})
 	}
-	params.Set("geometry", strings.Join(geometries, "|"))
+	return layout
+}
 
-	return params
+// staticMarkerStyle is the cafe marker: a warm brown circle, or a larger green one for the selected cafe.
+type staticMarkerStyle struct {
+	Radius      int
+	LineWidth   int
+	LineColor   string
+	FillColor   string
+	LineOpacity float64
+	FillOpacity float64
 }
 
-func buildStaticCircle(point StaticMapPoint, selected bool) string {
-	fillColor := "#b7794e"
-	lineColor := "#7c5234"
-	radius := 11
+func markerStyle(selected bool) staticMarkerStyle {
 	if selected {
-		fillColor = "#2f6f62"
-		lineColor = "#1f5147"
-		radius = 16
+		return staticMarkerStyle{Radius: 16, LineWidth: 3, LineColor: "#1f5147", FillColor: "#2f6f62", LineOpacity: 0.95, FillOpacity: 0.82}
 	}
+	return staticMarkerStyle{Radius: 11, LineWidth: 3, LineColor: "#7c5234", FillColor: "#b7794e", LineOpacity: 0.95, FillOpacity: 0.82}
+}
 
+func buildStaticCircle(point StaticMapPoint, selected bool) string {
+	style := markerStyle(selected)
 	return fmt.Sprintf(
-		"circle:%f,%f,%d;linewidth:3;linecolor:%s;fillcolor:%s;lineopacity:0.95;fillopacity:0.82",
+		"circle:%f,%f,%d;linewidth:%d;linecolor:%s;fillcolor:%s;lineopacity:%g;fillopacity:%g",
 		point.Lon,
 		point.Lat,
-		radius,
-		lineColor,
-		fillColor,
+		style.Radius,
+		style.LineWidth,
+		style.LineColor,
+		style.FillColor,
+		style.LineOpacity,
+		style.FillOpacity,
 	)
 }
 
-func buildStaticMapArea(points []StaticMapPoint) string {
+// buildStaticMapArea pads the points' bounding box by 18% (at least 0.01 degrees) so markers clear the edges.
+func buildStaticMapArea(points []StaticMapPoint) *staticMapArea {
 	minLat := points[0].Lat
 	maxLat := points[0].Lat
 	minLon := points[0].Lon
@@ -159,11 +217,10 @@ func buildStaticMapArea(points []StaticMapPoint) string {
 	latPadding := math.Max((maxLat-minLat)*0.18, 0.01)
 	lonPadding := math.Max((maxLon-minLon)*0.18, 0.01)
 
-	return fmt.Sprintf(
-		"rect:%f,%f,%f,%f",
-		minLon-lonPadding,
-		maxLat+latPadding,
-		maxLon+lonPadding,
-		minLat-latPadding,
-	)
+	return &staticMapArea{
+		MinLon: minLon - lonPadding,
+		MinLat: minLat - latPadding,
+		MaxLon: maxLon + lonPadding,
+		MaxLat: maxLat + latPadding,
+	}
 }
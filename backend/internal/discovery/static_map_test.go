@@ -1,9 +1,25 @@
 package discovery
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestBuildStaticMapParamsSinglePointUsesCenter(t *testing.T) {
@@ -30,3 +46,202 @@ func TestBuildStaticMapParamsMultiplePointsUsesArea(t *testing.T) {
 	assert.Equal(t, "1200", params.Get("width"))
 	assert.Equal(t, "720", params.Get("height"))
 }
+
+// solidTileSource serves uniformly coloured tiles and counts requests.
+type solidTileSource struct {
+	color            color.RGBA
+	minZoom, maxZoom int
+	requests         atomic.Int64
+}
+
+func (s *solidTileSource) ZoomRange() (int, int) {
+	return s.minZoom, s.maxZoom
+}
+
+func (s *solidTileSource) Tile(ctx context.Context, z, x, y int) (image.Image, error) {
+	s.requests.Add(1)
+	return solidTile(s.color), nil
+}
+
+func solidTile(c color.RGBA) *image.RGBA {
+	tile := image.NewRGBA(image.Rect(0, 0, tileSize, tileSize))
+	draw.Draw(tile, tile.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	return tile
+}
+
+func decodePNG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
+
+func assertColorNear(t *testing.T, want color.RGBA, got color.Color) {
+	t.Helper()
+	r, g, b, _ := got.RGBA()
+	near := func(a uint8, b uint32) bool { return math.Abs(float64(a)-float64(b>>8)) <= 2 }
+	assert.True(t, near(want.R, r) && near(want.G, g) && near(want.B, b), "want %v, got %v", want, got)
+}
+
+func TestTileRenderer_DrawsTilesAndMarkers(t *testing.T) {
+	tiles := &solidTileSource{color: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, maxZoom: 19}
+	renderer := NewTileRenderer(tiles)
+
+	rendered, err := renderer.Render(context.Background(), StaticMapRequest{Selected: &StaticMapPoint{Lat: 1.29, Lon: 103.85}, Width: 400, Height: 300})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", rendered.ContentType)
+
+	img := decodePNG(t, rendered.Data)
+	assert.Equal(t, 400, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+	assert.Positive(t, tiles.requests.Load())
+
+	// The corner shows the tile. At the centre the selected marker is drawn over the point's regular marker.
+	assertColorNear(t, tiles.color, img.At(0, 0))
+	over := func(style staticMarkerStyle, under color.RGBA) color.RGBA {
+		fill, alpha := parseHexColor(style.FillColor), style.FillOpacity
+		mix := func(c, u uint8) uint8 { return uint8(math.Round(float64(c)*alpha + float64(u)*(1-alpha))) }
+		return color.RGBA{R: mix(fill.R, under.R), G: mix(fill.G, under.G), B: mix(fill.B, under.B), A: 0xff}
+	}
+	assertColorNear(t, over(markerStyle(true), over(markerStyle(false), tiles.color)), img.At(200, 150))
+}
+
+func TestTileRenderer_FitsAreaAndToleratesMissingTiles(t *testing.T) {
+	points := []StaticMapPoint{{Lat: 1.29, Lon: 103.85}, {Lat: 1.31, Lon: 103.89}}
+	area := buildStaticMapArea(points)
+
+	zoom := fitZoom(*area, 800, 500, 0, 19)
+	left, top := projectMercator(StaticMapPoint{Lat: area.MaxLat, Lon: area.MinLon}, zoom)
+	right, bottom := projectMercator(StaticMapPoint{Lat: area.MinLat, Lon: area.MaxLon}, zoom)
+	assert.LessOrEqual(t, right-left, 800.0)
+	assert.LessOrEqual(t, bottom-top, 500.0)
+	assert.Greater(t, (right-left)*2, 800.0, "one zoom level deeper should no longer fit")
+
+	rendered, err := NewTileRenderer(missingTileSource{}).Render(context.Background(), StaticMapRequest{Points: points, Width: 800, Height: 500})
+	require.NoError(t, err)
+	assertColorNear(t, staticMapBackground, decodePNG(t, rendered.Data).At(0, 0))
+}
+
+type missingTileSource struct{}
+
+func (missingTileSource) ZoomRange() (int, int) { return 0, 19 }
+
+func (missingTileSource) Tile(ctx context.Context, z, x, y int) (image.Image, error) {
+	return nil, ErrTileNotFound
+}
+
+func TestMBTilesSource_ReadsTMSRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mbtiles")
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("CREATE TABLE metadata (name TEXT, value TEXT)").Error)
+	require.NoError(t, db.Exec("CREATE TABLE tiles (zoom_level INTEGER, tile_column INTEGER, tile_row INTEGER, tile_data BLOB)").Error)
+	require.NoError(t, db.Exec("INSERT INTO metadata (name, value) VALUES ('format', 'png'), ('minzoom', '1'), ('maxzoom', '3')").Error)
+
+	var tile bytes.Buffer
+	require.NoError(t, png.Encode(&tile, solidTile(color.RGBA{R: 10, G: 20, B: 30, A: 255})))
+	// XYZ tile 2/1/0 is TMS row 3 at zoom 2.
+	require.NoError(t, db.Exec("INSERT INTO tiles VALUES (2, 1, 3, ?)", tile.Bytes()).Error)
+	sqlDB, _ := db.DB()
+	require.NoError(t, sqlDB.Close())
+
+	source, err := OpenMBTiles(path)
+	require.NoError(t, err)
+	defer source.Close()
+
+	minZoom, maxZoom := source.ZoomRange()
+	assert.Equal(t, 1, minZoom)
+	assert.Equal(t, 3, maxZoom)
+
+	img, err := source.Tile(context.Background(), 2, 1, 0)
+	require.NoError(t, err)
+	assertColorNear(t, color.RGBA{R: 10, G: 20, B: 30}, img.At(0, 0))
+
+	_, err = source.Tile(context.Background(), 2, 1, 3)
+	assert.ErrorIs(t, err, ErrTileNotFound)
+}
+
+func TestOpenMBTiles_RejectsVectorTiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vector.mbtiles")
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("CREATE TABLE metadata (name TEXT, value TEXT)").Error)
+	require.NoError(t, db.Exec("INSERT INTO metadata (name, value) VALUES ('format', 'pbf')").Error)
+	sqlDB, _ := db.DB()
+	require.NoError(t, sqlDB.Close())
+
+	_, err = OpenMBTiles(path)
+	assert.Error(t, err)
+}
+
+type countingRenderer struct {
+	calls atomic.Int64
+}
+
+func (r *countingRenderer) Render(ctx context.Context, req StaticMapRequest) (*StaticMapImage, error) {
+	r.calls.Add(1)
+	return &StaticMapImage{ContentType: "image/png", Data: []byte(fmt.Sprintf("map-%d", r.calls.Load()))}, nil
+}
+
+func TestStaticMapDiskCache_ServesFromDiskWithStableETag(t *testing.T) {
+	next := &countingRenderer{}
+	cache, err := NewStaticMapDiskCache(next, "tiles", t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	req := StaticMapRequest{Points: []StaticMapPoint{{Lat: 1.29, Lon: 103.85}}, Width: 800, Height: 500}
+
+	first, err := cache.Render(context.Background(), req)
+	require.NoError(t, err)
+	second, err := cache.Render(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), next.calls.Load())
+	assert.Equal(t, first.Data, second.Data)
+	assert.Equal(t, "image/png", second.ContentType)
+	assert.NotEmpty(t, first.ETag)
+	assert.Equal(t, first.ETag, second.ETag)
+
+	other, err := cache.Render(context.Background(), StaticMapRequest{Points: req.Points, Width: 400, Height: 500})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), next.calls.Load())
+	assert.NotEqual(t, first.ETag, other.ETag)
+}
+
+func TestStaticMapDiskCache_RerendersExpiredMaps(t *testing.T) {
+	next := &countingRenderer{}
+	cache, err := NewStaticMapDiskCache(next, "tiles", t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	req := StaticMapRequest{Width: 800, Height: 500}
+
+	_, err = cache.Render(context.Background(), req)
+	require.NoError(t, err)
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	refreshed, err := cache.Render(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), next.calls.Load())
+	assert.Equal(t, "map-2", string(refreshed.Data))
+}
+
+func TestHTTPTileSource_FetchesTemplateURL(t *testing.T) {
+	var tile bytes.Buffer
+	require.NoError(t, png.Encode(&tile, solidTile(color.RGBA{R: 1, G: 2, B: 3, A: 255})))
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		if r.URL.Path != "/tiles/3/4/5.png" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(tile.Bytes())
+	}))
+	defer server.Close()
+
+	source := NewHTTPTileSource(server.URL+"/tiles/{z}/{x}/{y}.png", "go-cafe-test")
+	img, err := source.Tile(context.Background(), 3, 4, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "go-cafe-test", userAgent)
+	assertColorNear(t, color.RGBA{R: 1, G: 2, B: 3}, img.At(10, 10))
+
+	_, err = source.Tile(context.Background(), 3, 4, 6)
+	assert.ErrorIs(t, err, ErrTileNotFound)
+}
@@ -0,0 +1,57 @@
+package emailverification
+
+import (
+	"errors"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type Storage interface {
+	Create(token *models.EmailVerificationToken) error
+	GetByTokenHash(hash string) (*models.EmailVerificationToken, error)
+	MarkUsed(id uint, at time.Time) error
+	InvalidateAllForUser(userID uint, at time.Time) error
+}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(token *models.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *Repository) GetByTokenHash(hash string) (*models.EmailVerificationToken, error) {
+	var t models.EmailVerificationToken
+	err := r.db.Where("token_hash = ?", hash).First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &t, err
+}
+
+// MarkUsed consumes the token only if it is still unused; returns gorm.ErrRecordNotFound when it was already consumed.
+func (r *Repository) MarkUsed(id uint, at time.Time) error {
+	res := r.db.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *Repository) InvalidateAllForUser(userID uint, at time.Time) error {
+	return r.db.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
@@ -0,0 +1,135 @@
+package emailverification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/mail"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+const sendTimeout = 30 * time.Second
+
+// UserAccounts is implemented by user service.
+type UserAccounts interface {
+	GetByID(id uint) (*models.User, error)
+	MarkEmailVerified(id uint, email string, at time.Time) error
+}
+
+type Service struct {
+	store     Storage
+	users     UserAccounts
+	mailer    mail.Mailer
+	verifyURL string
+	ttl       time.Duration
+	now       func() time.Time
+	dispatch  func(func()) // runs mail delivery; async by default so registration is not held up by SMTP
+}
+
+func NewService(store Storage, users UserAccounts, mailer mail.Mailer, verifyURL string, ttl time.Duration) *Service {
+	return &Service{
+		store:     store,
+		users:     users,
+		mailer:    mailer,
+		verifyURL: verifyURL,
+		ttl:       ttl,
+		now:       time.Now,
+		dispatch:  func(f func()) { go f() },
+	}
+}
+
+// SendVerification emails a fresh verification link to the user's current address, replacing any
+// outstanding link. Already-verified users are a no-op (implements auth.EmailVerifier).
+func (s *Service) SendVerification(ctx context.Context, userID uint) error {
+	u, err := s.users.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return gorm.ErrRecordNotFound
+	}
+	if u.EmailVerifiedAt != nil {
+		return nil
+	}
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	if err := s.store.InvalidateAllForUser(u.ID, now); err != nil {
+		return err
+	}
+	record := &models.EmailVerificationToken{
+		UserID:    u.ID,
+		Email:     u.Email,
+		TokenHash: auth.HashOpaqueToken(token),
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.store.Create(record); err != nil {
+		return err
+	}
+	msg := mail.Message{
+		To:      u.Email,
+		Subject: "Confirm your go-cafe email",
+		Body: fmt.Sprintf(
+			"Welcome to go-cafe!\n\n"+
+				"Open this link to confirm your email address (valid for %s):\n%s\n\n"+
+				"If you didn't create an account, you can ignore this email.\n",
+			s.ttl, buildLink(s.verifyURL, token),
+		),
+	}
+	s.dispatch(func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+		defer cancel()
+		if err := s.mailer.Send(sendCtx, msg); err != nil {
+			log.Printf("verification mail to user %d failed: %v", u.ID, err)
+		}
+	})
+	return nil
+}
+
+// VerifyEmail consumes a verification token and marks the address it was sent to as verified
+// (implements auth.EmailVerifier).
+func (s *Service) VerifyEmail(token string) error {
+	record, err := s.store.GetByTokenHash(auth.HashOpaqueToken(strings.TrimSpace(token)))
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	if record == nil || record.UsedAt != nil || !now.Before(record.ExpiresAt) {
+		return auth.ErrInvalidToken
+	}
+	if err := s.store.MarkUsed(record.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.ErrInvalidToken
+		}
+		return err
+	}
+	if err := s.users.MarkEmailVerified(record.UserID, record.Email, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The account was deleted or its email changed since the link was sent.
+			return auth.ErrInvalidToken
+		}
+		return err
+	}
+	return nil
+}
+
+// buildLink appends the token as a query parameter to the configured frontend URL.
+func buildLink(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,108 @@
+package emailverification
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/mail"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeUsers struct {
+	users map[uint]*models.User
+}
+
+func (f *fakeUsers) GetByID(id uint) (*models.User, error) {
+	return f.users[id], nil
+}
+
+func (f *fakeUsers) MarkEmailVerified(id uint, email string, at time.Time) error {
+	u := f.users[id]
+	if u == nil || u.Email != email {
+		return gorm.ErrRecordNotFound
+	}
+	u.EmailVerifiedAt = &at
+	return nil
+}
+
+var tokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+func newTestService(t *testing.T) (*Service, *fakeUsers, *bytes.Buffer) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.EmailVerificationToken{}))
+
+	users := &fakeUsers{users: map[uint]*models.User{7: {ID: 7, Email: "a@b.com"}}}
+	var out bytes.Buffer
+	svc := NewService(NewRepository(db), users, mail.NewLogMailer(&out, "from@example.com"), "http://app.test/verify-email", time.Hour)
+	svc.dispatch = func(f func()) { f() }
+	return svc, users, &out
+}
+
+func extractToken(t *testing.T, out string) string {
+	t.Helper()
+	m := tokenPattern.FindStringSubmatch(out)
+	require.Len(t, m, 2, "verification link not found in mail output")
+	return m[1]
+}
+
+func TestVerifyEmail_SingleUse(t *testing.T) {
+	svc, users, out := newTestService(t)
+
+	require.NoError(t, svc.SendVerification(context.Background(), 7))
+	assert.Contains(t, out.String(), "To: a@b.com")
+	token := extractToken(t, out.String())
+
+	require.NoError(t, svc.VerifyEmail(token))
+	assert.NotNil(t, users.users[7].EmailVerifiedAt)
+	assert.ErrorIs(t, svc.VerifyEmail(token), auth.ErrInvalidToken)
+}
+
+func TestSendVerification_AlreadyVerifiedIsNoop(t *testing.T) {
+	svc, users, out := newTestService(t)
+	now := time.Now()
+	users.users[7].EmailVerifiedAt = &now
+
+	require.NoError(t, svc.SendVerification(context.Background(), 7))
+	assert.Empty(t, out.String())
+}
+
+func TestSendVerification_ResendReplacesOldLink(t *testing.T) {
+	svc, _, out := newTestService(t)
+	require.NoError(t, svc.SendVerification(context.Background(), 7))
+	first := extractToken(t, out.String())
+	out.Reset()
+	require.NoError(t, svc.SendVerification(context.Background(), 7))
+	second := extractToken(t, out.String())
+
+	assert.ErrorIs(t, svc.VerifyEmail(first), auth.ErrInvalidToken)
+	assert.NoError(t, svc.VerifyEmail(second))
+}
+
+func TestVerifyEmail_Expired(t *testing.T) {
+	svc, _, out := newTestService(t)
+	require.NoError(t, svc.SendVerification(context.Background(), 7))
+	token := extractToken(t, out.String())
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.ErrorIs(t, svc.VerifyEmail(token), auth.ErrInvalidToken)
+}
+
+func TestVerifyEmail_EmailChangedSinceSend(t *testing.T) {
+	svc, users, out := newTestService(t)
+	require.NoError(t, svc.SendVerification(context.Background(), 7))
+	token := extractToken(t, out.String())
+
+	users.users[7].Email = "changed@b.com"
+	assert.ErrorIs(t, svc.VerifyEmail(token), auth.ErrInvalidToken)
+	assert.Nil(t, users.users[7].EmailVerifiedAt)
+}
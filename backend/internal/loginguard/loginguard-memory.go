@@ -0,0 +1,78 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+)
+
+// sweepEvery is how many increments pass between sweeps of idle keys.
+const sweepEvery = 1024
+
+// MemoryStore is an in-process Storage for single-instance deployments. Counters reset on restart.
+type MemoryStore struct {
+	mu         sync.Mutex
+	attempts   map[string]models.LoginAttempt
+	increments int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: make(map[string]models.LoginAttempt)}
+}
+
+func (m *MemoryStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (m *MemoryStore) Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	staleBefore := now.Add(-window)
+	a, ok := m.attempts[key]
+	if !ok || isIdle(a, staleBefore) {
+		a = models.LoginAttempt{Key: key}
+	}
+	a.Failures++
+	a.LastFailedAt = now
+	m.attempts[key] = a
+
+	m.increments++
+	if m.increments%sweepEvery == 0 {
+		for k, v := range m.attempts {
+			if isIdle(v, staleBefore) {
+				delete(m.attempts, k)
+			}
+		}
+	}
+	return &a, nil
+}
+
+func (m *MemoryStore) Lock(ctx context.Context, key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if a, ok := m.attempts[key]; ok {
+		a.LockedUntil = &until
+		m.attempts[key] = a
+	}
+	return nil
+}
+
+func (m *MemoryStore) Reset(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.attempts, key)
+	return nil
+}
+
+// isIdle reports whether the key had no failure and no active lock since staleBefore.
+func isIdle(a models.LoginAttempt, staleBefore time.Time) bool {
+	return a.LastFailedAt.Before(staleBefore) && (a.LockedUntil == nil || a.LockedUntil.Before(staleBefore))
+}
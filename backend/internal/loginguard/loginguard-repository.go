@@ -0,0 +1,81 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Storage holds failed-login counters. Implementations must make Increment atomic per key so
+// parallel guesses cannot slip past the threshold.
+type Storage interface {
+	Get(ctx context.Context, key string) (*models.LoginAttempt, error)
+	// Increment adds one failure, restarting the count at 1 when the key has been quiet for window.
+	Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// AuditLog records lockouts.
+type AuditLog interface {
+	RecordLockout(ctx context.Context, lockout *models.LoginLockout) error
+}
+
+// Repository is the Postgres-backed Storage and AuditLog.
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	var a models.LoginAttempt
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &a, err
+}
+
+// Increment upserts the counter; the row lock taken by the upsert serialises concurrent failures for the same key.
+func (r *Repository) Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	staleBefore := now.Add(-window)
+	var a models.LoginAttempt
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+INSERT INTO gocafe_login_attempts (key, failures, last_failed_at) VALUES (?, 1, ?)
+ON CONFLICT (key) DO UPDATE SET
+	failures = CASE
+		WHEN gocafe_login_attempts.last_failed_at < ?
+			AND (gocafe_login_attempts.locked_until IS NULL OR gocafe_login_attempts.locked_until < ?)
+		THEN 1
+		ELSE gocafe_login_attempts.failures + 1
+	END,
+	last_failed_at = excluded.last_failed_at`,
+			key, now, staleBefore, staleBefore).Error; err != nil {
+			return err
+		}
+		return tx.Where("key = ?", key).First(&a).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *Repository) Lock(ctx context.Context, key string, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+}
+
+func (r *Repository) Reset(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+func (r *Repository) RecordLockout(ctx context.Context, lockout *models.LoginLockout) error {
+	return r.db.WithContext(ctx).Create(lockout).Error
+}
@@ -0,0 +1,148 @@
+package loginguard
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+)
+
+const (
+	ScopeEmail = "email"
+	ScopeIP    = "ip"
+)
+
+// Policy controls when keys are locked. Zero fields take the DefaultPolicy value.
+type Policy struct {
+	MaxEmailFailures int           // failures per email before it is locked
+	MaxIPFailures    int           // failures per client IP before it is locked (higher: IPs can be shared)
+	Window           time.Duration // quiet period after which a key's failure count restarts
+	LockoutBase      time.Duration // first lockout; doubles with each further failure
+	LockoutMax       time.Duration // cap for the doubling lockout
+}
+
+var DefaultPolicy = Policy{
+	MaxEmailFailures: 5,
+	MaxIPFailures:    20,
+	Window:           15 * time.Minute,
+	LockoutBase:      time.Minute,
+	LockoutMax:       time.Hour,
+}
+
+type Service struct {
+	store  Storage
+	audit  AuditLog
+	policy Policy
+	now    func() time.Time
+}
+
+func NewService(store Storage, audit AuditLog, policy Policy) *Service {
+	if policy.MaxEmailFailures <= 0 {
+		policy.MaxEmailFailures = DefaultPolicy.MaxEmailFailures
+	}
+	if policy.MaxIPFailures <= 0 {
+		policy.MaxIPFailures = DefaultPolicy.MaxIPFailures
+	}
+	if policy.Window <= 0 {
+		policy.Window = DefaultPolicy.Window
+	}
+	if policy.LockoutBase <= 0 {
+		policy.LockoutBase = DefaultPolicy.LockoutBase
+	}
+	if policy.LockoutMax < policy.LockoutBase {
+		policy.LockoutMax = max(DefaultPolicy.LockoutMax, policy.LockoutBase)
+	}
+	return &Service{store: store, audit: audit, policy: policy, now: time.Now}
+}
+
+// Check returns how long the caller must wait before trying to log in again; zero means go ahead
+// (implements auth.LoginGuard).
+func (s *Service) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := s.now().UTC()
+	var wait time.Duration
+	for _, key := range keys(email, ip) {
+		a, err := s.store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if a != nil && a.LockedUntil != nil && a.LockedUntil.After(now) {
+			wait = max(wait, a.LockedUntil.Sub(now))
+		}
+	}
+	return wait, nil
+}
+
+// RecordFailure counts a failed login against the email and the IP, locking whichever crossed its
+// threshold. It returns the resulting wait, zero when nothing got locked (implements auth.LoginGuard).
+func (s *Service) RecordFailure(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := s.now().UTC()
+	var wait time.Duration
+	for _, key := range keys(email, ip) {
+		a, err := s.store.Increment(ctx, key, now, s.policy.Window)
+		if err != nil {
+			return 0, err
+		}
+		scope, subject, _ := strings.Cut(key, ":")
+		limit := s.policy.MaxEmailFailures
+		if scope == ScopeIP {
+			limit = s.policy.MaxIPFailures
+		}
+		if a.Failures < limit {
+			continue
+		}
+		d := s.lockoutFor(a.Failures - limit)
+		until := now.Add(d)
+		if err := s.store.Lock(ctx, key, until); err != nil {
+			return 0, err
+		}
+		lockout := &models.LoginLockout{
+			Scope:       scope,
+			Subject:     subject,
+			IPAddress:   ip,
+			Failures:    a.Failures,
+			LockedUntil: until,
+		}
+		if err := s.audit.RecordLockout(ctx, lockout); err != nil {
+			// The lock itself is in place; a missing audit row should not unlock the account.
+			log.Printf("record login lockout for %s %q: %v", scope, subject, err)
+		}
+		wait = max(wait, d)
+	}
+	return wait, nil
+}
+
+// RecordSuccess clears the email's failure count. The IP counter is left alone so an attacker
+// cannot reset it by logging into their own account (implements auth.LoginGuard).
+func (s *Service) RecordSuccess(ctx context.Context, email, ip string) error {
+	return s.store.Reset(ctx, emailKey(email))
+}
+
+// lockoutFor doubles LockoutBase for each failure past the threshold, capped at LockoutMax.
+func (s *Service) lockoutFor(over int) time.Duration {
+	d := s.policy.LockoutBase
+	for i := 0; i < over && d < s.policy.LockoutMax; i++ {
+		d *= 2
+	}
+	return min(d, s.policy.LockoutMax)
+}
+
+func keys(email, ip string) []string {
+	out := []string{emailKey(email)}
+	if ip != "" {
+		out = append(out, ScopeIP+":"+ip)
+	}
+	return out
+}
+
+// maxSubjectLength keeps keys within the 320-character key column.
+const maxSubjectLength = 300
+
+func emailKey(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(email) > maxSubjectLength {
+		email = email[:maxSubjectLength]
+	}
+	return ScopeEmail + ":" + email
+}
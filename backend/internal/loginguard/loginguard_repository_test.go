@@ -0,0 +1,69 @@
+package loginguard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "gocafe_"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.LoginAttempt{}, &models.LoginLockout{}))
+	return NewRepository(db)
+}
+
+func TestRepository_IncrementLockReset(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	a, err := repo.Increment(ctx, "email:a@b.com", now, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, a.Failures)
+	a, err = repo.Increment(ctx, "email:a@b.com", now.Add(10*time.Second), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, a.Failures)
+
+	until := now.Add(5 * time.Minute)
+	require.NoError(t, repo.Lock(ctx, "email:a@b.com", until))
+	got, err := repo.Get(ctx, "email:a@b.com")
+	require.NoError(t, err)
+	require.NotNil(t, got.LockedUntil)
+	assert.True(t, got.LockedUntil.Equal(until))
+
+	// Still within a minute of the lock expiring: the count keeps growing.
+	a, err = repo.Increment(ctx, "email:a@b.com", until.Add(30*time.Second), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, a.Failures)
+	// Quiet for longer than the window: restart at 1.
+	a, err = repo.Increment(ctx, "email:a@b.com", until.Add(10*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, a.Failures)
+
+	require.NoError(t, repo.Reset(ctx, "email:a@b.com"))
+	got, err = repo.Get(ctx, "email:a@b.com")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestRepository_RecordLockout(t *testing.T) {
+	repo := newTestRepository(t)
+	err := repo.RecordLockout(context.Background(), &models.LoginLockout{
+		Scope: ScopeEmail, Subject: "a@b.com", IPAddress: "10.0.0.1", Failures: 5, LockedUntil: time.Now(),
+	})
+	require.NoError(t, err)
+	var count int64
+	require.NoError(t, repo.db.Model(&models.LoginLockout{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
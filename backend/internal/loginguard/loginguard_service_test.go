@@ -0,0 +1,118 @@
+package loginguard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingAudit struct {
+	lockouts []models.LoginLockout
+}
+
+func (r *recordingAudit) RecordLockout(ctx context.Context, l *models.LoginLockout) error {
+	r.lockouts = append(r.lockouts, *l)
+	return nil
+}
+
+func newTestService(policy Policy) (*Service, *recordingAudit, *time.Time) {
+	audit := &recordingAudit{}
+	svc := NewService(NewMemoryStore(), audit, policy)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, audit, &now
+}
+
+func TestService_LocksEmailAfterThreshold(t *testing.T) {
+	svc, audit, _ := newTestService(Policy{MaxEmailFailures: 3, LockoutBase: time.Minute})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		wait, err := svc.RecordFailure(ctx, "A@B.com", "10.0.0.1")
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+	}
+	wait, err := svc.RecordFailure(ctx, "a@b.com", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, wait)
+
+	wait, err = svc.Check(ctx, " a@b.com ", "10.0.0.2")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, wait)
+
+	require.Len(t, audit.lockouts, 1)
+	assert.Equal(t, ScopeEmail, audit.lockouts[0].Scope)
+	assert.Equal(t, "a@b.com", audit.lockouts[0].Subject)
+	assert.Equal(t, "10.0.0.1", audit.lockouts[0].IPAddress)
+	assert.Equal(t, 3, audit.lockouts[0].Failures)
+}
+
+func TestService_LockoutDoublesAndCaps(t *testing.T) {
+	svc, _, now := newTestService(Policy{MaxEmailFailures: 1, LockoutBase: time.Minute, LockoutMax: 3 * time.Minute, Window: time.Hour})
+	ctx := context.Background()
+
+	var waits []time.Duration
+	for i := 0; i < 4; i++ {
+		wait, err := svc.RecordFailure(ctx, "a@b.com", "")
+		require.NoError(t, err)
+		waits = append(waits, wait)
+		*now = now.Add(wait) // wait out the lock before the next guess
+	}
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}, waits)
+}
+
+func TestService_LockExpiresAndCountRestartsAfterWindow(t *testing.T) {
+	svc, _, now := newTestService(Policy{MaxEmailFailures: 2, LockoutBase: time.Minute, Window: 10 * time.Minute})
+	ctx := context.Background()
+
+	_, _ = svc.RecordFailure(ctx, "a@b.com", "")
+	wait, _ := svc.RecordFailure(ctx, "a@b.com", "")
+	require.Equal(t, time.Minute, wait)
+
+	*now = now.Add(2 * time.Minute)
+	wait, err := svc.Check(ctx, "a@b.com", "")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// Quiet for longer than the window after the lock: the next failure starts a fresh count.
+	*now = now.Add(11 * time.Minute)
+	wait, _ = svc.RecordFailure(ctx, "a@b.com", "")
+	assert.Zero(t, wait)
+}
+
+func TestService_IPThresholdIndependentOfEmail(t *testing.T) {
+	svc, audit, _ := newTestService(Policy{MaxEmailFailures: 100, MaxIPFailures: 3})
+	ctx := context.Background()
+
+	for _, email := range []string{"a@x.com", "b@x.com"} {
+		wait, _ := svc.RecordFailure(ctx, email, "10.0.0.9")
+		assert.Zero(t, wait)
+	}
+	wait, err := svc.RecordFailure(ctx, "c@x.com", "10.0.0.9")
+	require.NoError(t, err)
+	assert.Positive(t, wait)
+
+	wait, _ = svc.Check(ctx, "fresh@x.com", "10.0.0.9")
+	assert.Positive(t, wait)
+	wait, _ = svc.Check(ctx, "fresh@x.com", "10.0.0.10")
+	assert.Zero(t, wait)
+
+	require.Len(t, audit.lockouts, 1)
+	assert.Equal(t, ScopeIP, audit.lockouts[0].Scope)
+}
+
+func TestService_SuccessResetsEmailOnly(t *testing.T) {
+	svc, _, _ := newTestService(Policy{MaxEmailFailures: 2, MaxIPFailures: 3})
+	ctx := context.Background()
+
+	_, _ = svc.RecordFailure(ctx, "a@b.com", "10.0.0.1")
+	_, _ = svc.RecordFailure(ctx, "other@b.com", "10.0.0.1")
+	require.NoError(t, svc.RecordSuccess(ctx, "a@b.com", "10.0.0.1"))
+
+	wait, _ := svc.RecordFailure(ctx, "a@b.com", "10.0.0.1")
+	assert.Equal(t, time.Minute, wait, "email count restarted but the IP reached its limit")
+}
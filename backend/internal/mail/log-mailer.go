@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// LogMailer writes messages to an io.Writer instead of delivering them (local dev and tests).
+type LogMailer struct {
+	mu   sync.Mutex
+	out  io.Writer
+	from string
+}
+
+func NewLogMailer(out io.Writer, from string) *LogMailer {
+	return &LogMailer{out: out, from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.out, "--- mail %s ---\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n--- end mail ---\n",
+		time.Now().UTC().Format(time.RFC3339), m.from, msg.To, msg.Subject, msg.Body)
+	return err
+}
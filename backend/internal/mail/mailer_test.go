@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMailer_WritesMessage(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewLogMailer(&buf, "from@example.com")
+
+	err := m.Send(context.Background(), Message{To: "to@example.com", Subject: "Hello", Body: "body text"})
+	require.NoError(t, err)
+	out := buf.String()
+	assert.Contains(t, out, "To: to@example.com")
+	assert.Contains(t, out, "Subject: Hello")
+	assert.Contains(t, out, "body text")
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	m := NewSMTPMailer("smtp.example.com", "587", "user", "pass", "from@example.com")
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	m.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+		assert.NotNil(t, a)
+		return nil
+	}
+
+	err := m.Send(context.Background(), Message{To: "to@example.com", Subject: "Reset\r\nBcc: evil@example.com", Body: "line1\nline2"})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, "from@example.com", gotFrom)
+	assert.Equal(t, []string{"to@example.com"}, gotTo)
+	assert.Contains(t, string(gotMsg), "Subject: ResetBcc: evil@example.com\r\n")
+	assert.Contains(t, string(gotMsg), "line1\r\nline2")
+}
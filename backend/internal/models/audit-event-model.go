@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent is one append-only row of the audit trail: who changed which user, cafe listing or rating, and how.
+// Before and After are the entity's JSON on either side of the change; Before is empty for a restore and After for
+// a delete.
+type AuditEvent struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ActorID    uint            `gorm:"not null;index:idx_gocafe_audit_events_actor" json:"actor_id"` // no FK: events outlive the actor's account
+	Action     string          `gorm:"not null;size:16" json:"action" enums:"update,delete,restore"`
+	EntityType string          `gorm:"not null;size:16;index:idx_gocafe_audit_events_entity" json:"entity_type" enums:"user,cafe_listing,rating"`
+	EntityID   uint            `gorm:"not null;index:idx_gocafe_audit_events_entity" json:"entity_id"`
+	Before     json.RawMessage `gorm:"serializer:json" json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `gorm:"serializer:json" json:"after,omitempty" swaggertype:"object"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// CacheEntry is one cached upstream response (JSON) in the Postgres cache store. It is served fresh until
+// ExpiresAt and only as a fallback, when the upstream fails, until StaleUntil.
+type CacheEntry struct {
+	Key        string    `gorm:"primaryKey;size:512" json:"key"`
+	Value      []byte    `gorm:"not null" json:"-"`
+	ExpiresAt  time.Time `gorm:"not null" json:"expires_at"`
+	StaleUntil time.Time `gorm:"not null;index" json:"stale_until"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Cafe is the one canonical record of a place. Users keep it in their own lists through SavedPlace, and ratings,
+// photos and reports all point at the cafe. UserID is the user who added it and may edit its details.
+type Cafe struct {
+	ID              uint          `gorm:"primaryKey" json:"id"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	UserID          uint          `gorm:"not null;index" json:"user_id"`
+	User            *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Name            string        `gorm:"not null" json:"name"`
+	Address         string        `json:"address"`
+	City            string        `json:"city,omitempty"`
+	Neighborhood    string        `json:"neighborhood,omitempty"`
+	Description     string        `json:"description,omitempty"`
+	ImageURL        string        `json:"image_url,omitempty"`
+	Latitude        *float64      `json:"latitude,omitempty"`
+	Longitude       *float64      `json:"longitude,omitempty"`
+	SourceProvider  string        `gorm:"index" json:"source_provider,omitempty"`
+	ExternalPlaceID string        `gorm:"index" json:"external_place_id,omitempty"`
+	OpeningHours    *OpeningHours `gorm:"serializer:json" json:"opening_hours,omitempty"`
+	Amenities       []string      `gorm:"serializer:json" json:"amenities,omitempty" enums:"wifi,outlets,laptop_friendly,oat_milk,vegan_options,outdoor_seating,pet_friendly,wheelchair_accessible,takeaway,air_conditioning"`
+	PriceLevel      *int          `gorm:"index" json:"price_level,omitempty" minimum:"1" maximum:"4"` // 1 (inexpensive) to 4 (very expensive)
+	AvgRating       float64       `gorm:"->;-:migration" json:"avg_rating"`                           // read-only: selected by the stats join, never stored
+	ReviewCount     int64         `gorm:"->;-:migration" json:"review_count"`
+	Relevance       float64       `gorm:"->;-:migration" json:"relevance,omitempty"`  // search score, only set by sort=relevance
+	DistanceM       *float64      `gorm:"->;-:migration" json:"distance_m,omitempty"` // meters from the near point, only set when near is given
+	// Per-dimension averages over the ratings that scored that dimension; omitted when none did.
+	AvgCoffee   *float64 `gorm:"->;-:migration" json:"avg_coffee,omitempty"`
+	AvgAmbience *float64 `gorm:"->;-:migration" json:"avg_ambience,omitempty"`
+	AvgService  *float64 `gorm:"->;-:migration" json:"avg_service,omitempty"`
+	AvgValue    *float64 `gorm:"->;-:migration" json:"avg_value,omitempty"`
+	AvgWork     *float64 `gorm:"->;-:migration" json:"avg_work,omitempty"`
+	// The viewer's saved place, selected by the per-user reads; empty when the viewer has not saved the cafe.
+	VisitStatus    string     `gorm:"->;-:migration" json:"visit_status"`
+	Notes          string     `gorm:"->;-:migration" json:"notes,omitempty"`
+	SavedAt        *time.Time `gorm:"->;-:migration" json:"saved_at,omitempty"`
+	SavedUpdatedAt *time.Time `gorm:"->;-:migration" json:"-"`
+	// SourceCafeID is only read on create, where it saves an existing cafe instead of adding one.
+	SourceCafeID *uint `gorm:"-" json:"source_cafe_id,omitempty"`
+	// HiddenAt is set by a moderator; hidden cafes are left out of discovery but shown to the user who added them.
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+	// DeletedAt soft-deletes the cafe together with its ratings and saved places; all come back if an admin restores it.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName is set because the naming strategy would pluralise Cafe to "caves".
+func (Cafe) TableName() string {
+	return "gocafe_cafes"
+}
+
+// CafeRedirect keeps the id of a listing that was folded into a canonical cafe resolvable.
+type CafeRedirect struct {
+	FromID    uint `gorm:"primaryKey;autoIncrement:false"`
+	CafeID    uint `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// OpeningHours is a weekly schedule in an IANA time zone, with dated exceptions such as public holidays.
+type OpeningHours struct {
+	TimeZone   string             `json:"timezone" example:"Asia/Singapore"`
+	Weekly     []OpeningPeriod    `json:"weekly"`
+	Exceptions []OpeningException `json:"exceptions,omitempty"`
+}
+
+// OpeningPeriod is one opening on a weekday. Times are local "HH:MM"; a Close at or before Open runs past
+// midnight into the next day, and "24:00" closes at midnight.
+type OpeningPeriod struct {
+	Day   string `json:"day" enums:"mon,tue,wed,thu,fri,sat,sun"`
+	Open  string `json:"open" example:"08:00"`
+	Close string `json:"close" example:"18:00"`
+}
+
+// OpeningException replaces the weekly schedule on one local date: closed all day, or open only for Periods.
+type OpeningException struct {
+	Date    string      `json:"date" example:"2026-12-25"`
+	Closed  bool        `json:"closed,omitempty"`
+	Periods []TimeRange `json:"periods,omitempty"`
+	Note    string      `json:"note,omitempty" example:"Christmas Day"`
+}
+
+type TimeRange struct {
+	Open  string `json:"open" example:"10:00"`
+	Close string `json:"close" example:"14:00"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ContentReport is a user's report of a rating or cafe listing, worked through by moderators.
+type ContentReport struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ReporterID   uint       `gorm:"not null;index" json:"reporter_id"`
+	TargetType   string     `gorm:"not null;index:idx_gocafe_content_reports_target" json:"target_type" enums:"rating,cafe_listing"`
+	TargetID     uint       `gorm:"not null;index:idx_gocafe_content_reports_target" json:"target_id"`
+	Reason       string     `gorm:"not null" json:"reason" enums:"spam,offensive,harassment,off_topic,misleading,other"`
+	Note         string     `json:"note,omitempty"`
+	Status       string     `gorm:"not null;default:open;index" json:"status" enums:"open,hidden,restored,dismissed"`
+	ResolvedByID *uint      `json:"resolved_by_id,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+
+	// The reported content, attached for the moderation queue; hidden content is included.
+	Rating      *Rating `gorm:"-" json:"rating,omitempty"`
+	CafeListing *Cafe   `gorm:"-" json:"cafe_listing,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// EmailVerificationToken is a single-use, expiring email verification link. Only the token hash is stored.
+type EmailVerificationToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Email     string     `gorm:"not null" json:"email"` // address the link was sent to; stale after an email change
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// LoginAttempt is the failed-login counter for one key ("email:<addr>" or "ip:<addr>").
+type LoginAttempt struct {
+	Key          string     `gorm:"primaryKey;size:320" json:"key"`
+	Failures     int        `gorm:"not null;default:0" json:"failures"`
+	LastFailedAt time.Time  `gorm:"not null" json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+}
+
+// LoginLockout is an append-only audit record written whenever a login key gets locked.
+type LoginLockout struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+	Scope       string    `gorm:"not null" json:"scope"`         // email or ip
+	Subject     string    `gorm:"not null;index" json:"subject"` // the locked email or IP address
+	IPAddress   string    `json:"ip_address"`                    // client that triggered the lockout
+	Failures    int       `gorm:"not null" json:"failures"`
+	LockedUntil time.Time `gorm:"not null" json:"locked_until"`
+}
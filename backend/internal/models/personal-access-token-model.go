@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// PersonalAccessToken is a named, scoped API credential for scripts. Only its SHA-256 hash is stored.
+type PersonalAccessToken struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Name        string     `gorm:"not null;size:100" json:"name"`
+	TokenHash   string     `gorm:"not null;uniqueIndex" json:"-"`
+	TokenPrefix string     `gorm:"not null;size:32" json:"token_prefix"` // leading characters shown to identify the token
+	Scopes      string     `gorm:"not null" json:"-"`                    // space-separated
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+}
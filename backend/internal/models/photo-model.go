@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Photo is an uploaded image attached to either a cafe listing or a rating. The image and its thumbnail live in
+// blob storage under StorageKey and ThumbnailKey; both target IDs are NULL once the target is deleted, until the
+// blobs are cleaned up.
+type Photo struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UserID          uint      `gorm:"not null;index" json:"user_id"`
+	CafeListingID   *uint     `gorm:"index" json:"cafe_listing_id,omitempty"`
+	RatingID        *uint     `gorm:"index" json:"rating_id,omitempty"`
+	ContentType     string    `gorm:"not null;size:32" json:"content_type"`
+	Width           int       `gorm:"not null" json:"width"`
+	Height          int       `gorm:"not null" json:"height"`
+	SizeBytes       int64     `gorm:"not null" json:"size_bytes"`
+	StorageKey      string    `gorm:"not null" json:"-"`
+	ThumbnailKey    string    `gorm:"not null" json:"-"`
+	ThumbnailWidth  int       `gorm:"not null" json:"thumbnail_width"`
+	ThumbnailHeight int       `gorm:"not null" json:"thumbnail_height"`
+	URL             string    `gorm:"-" json:"url"`           // set by the handler
+	ThumbnailURL    string    `gorm:"-" json:"thumbnail_url"` // set by the handler
+}
@@ -1,16 +1,41 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Rating struct {
-	ID            uint        `gorm:"primaryKey" json:"id"`
-	CreatedAt     time.Time   `json:"created_at"`
-	UpdatedAt     time.Time   `json:"updated_at"`
-	UserID        uint        `gorm:"not null;index" json:"user_id"`
-	User          *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	CafeListingID uint        `gorm:"not null;index" json:"cafe_listing_id"`
-	CafeListing   *CafeListing `gorm:"foreignKey:CafeListingID" json:"cafe_listing,omitempty"`
-	VisitedAt     time.Time   `gorm:"not null" json:"visited_at"`
-	Rating        int         `gorm:"not null" json:"rating"` // e.g. 1-5
-	Review        string      `json:"review,omitempty"`
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	User          *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CafeListingID uint      `gorm:"not null;index" json:"cafe_listing_id"`
+	CafeListing   *Cafe     `gorm:"foreignKey:CafeListingID" json:"cafe_listing,omitempty"`
+	VisitedAt     time.Time `gorm:"not null" json:"visited_at"`
+	Rating        int       `gorm:"not null" json:"rating"` // e.g. 1-5
+	Review        string    `json:"review,omitempty"`
+
+	// Optional 1-5 sub-scores per dimension; Rating stays the overall score.
+	CoffeeScore   *int `json:"coffee_score,omitempty" minimum:"1" maximum:"5"`
+	AmbienceScore *int `json:"ambience_score,omitempty" minimum:"1" maximum:"5"`
+	ServiceScore  *int `json:"service_score,omitempty" minimum:"1" maximum:"5"`
+	ValueScore    *int `json:"value_score,omitempty" minimum:"1" maximum:"5"`
+	WorkScore     *int `json:"work_score,omitempty" minimum:"1" maximum:"5"` // how well the cafe suits working on a laptop
+
+	HelpfulCount int64 `gorm:"->;-:migration" json:"helpful_count"` // read-only: selected by the votes join, never stored
+
+	// HiddenAt is set by a moderator; hidden ratings are left out of public lists and stats but shown to their author.
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+
+	// HeldAt is set when review screening holds the rating for a moderator; HeldReason names the rule that held it.
+	// Held ratings are pending: out of public lists and stats, shown to their author, until a moderator approves them.
+	HeldAt     *time.Time `json:"held_at,omitempty"`
+	HeldReason string     `json:"held_reason,omitempty"`
+
+	// DeletedAt soft-deletes the rating, alone or with its listing or author; deleted ratings are gone from every
+	// list and stat.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
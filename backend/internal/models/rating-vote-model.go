@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// RatingVote marks a rating as helpful. Each user votes at most once per rating, and never on their own.
+type RatingVote struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_gocafe_rating_votes_user_rating" json:"user_id"`
+	RatingID  uint      `gorm:"not null;uniqueIndex:idx_gocafe_rating_votes_user_rating;index" json:"rating_id"`
+}
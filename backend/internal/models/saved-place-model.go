@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedPlace is a cafe in one user's list, with that user's visit status and notes.
+type SavedPlace struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	UserID      uint           `gorm:"not null;uniqueIndex:idx_gocafe_saved_places_user_cafe,where:deleted_at IS NULL" json:"user_id"`
+	CafeID      uint           `gorm:"not null;uniqueIndex:idx_gocafe_saved_places_user_cafe,where:deleted_at IS NULL;index" json:"cafe_id"` // at most one live entry per user and cafe
+	VisitStatus string         `gorm:"not null;default:to_visit" json:"visit_status"`
+	Notes       string         `json:"notes,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session is a server-side login session backing a rotating refresh token.
+type Session struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	UserID            uint       `gorm:"not null;index" json:"user_id"`
+	RefreshTokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	PreviousTokenHash string     `gorm:"index" json:"-"` // last rotated-out token, kept for reuse detection
+	UserAgent         string     `json:"user_agent,omitempty"`
+	IPAddress         string     `json:"ip_address,omitempty"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt        *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt         *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+}
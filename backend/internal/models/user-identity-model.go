@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UserIdentity links an external OIDC identity (issuer-scoped subject) to a user.
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Provider  string    `gorm:"not null;uniqueIndex:idx_gocafe_user_identities_provider_subject" json:"provider"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_gocafe_user_identities_provider_subject" json:"-"` // provider "sub" claim
+	Email     string    `json:"email"`                                                                     // email the provider reported when linked
+}
@@ -1,12 +1,21 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	ID           uint      `gorm:"primaryKey" json:"id"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
-	Email        string    `gorm:"uniqueIndex;not null" json:"email"`
+	Email        string    `gorm:"uniqueIndex:idx_gocafe_users_email_active,where:deleted_at IS NULL;not null" json:"email"` // unique among live accounts
 	Name         string    `json:"name"`
-	PasswordHash string    `json:"-"` // empty for legacy users; required for login
+	PasswordHash string    `json:"-"`                                       // empty for legacy users; required for login
+	Role         string    `gorm:"not null;default:user;index" json:"role"` // user, moderator or admin
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"` // nil until the emailed verification link is used
+
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // set by account deletion; an admin can restore within the retention window
 }
@@ -0,0 +1,16 @@
+package moderation
+
+import "errors"
+
+var ErrInvalidReason = errors.New("reason must be one of spam, offensive, harassment, off_topic, misleading, other")
+var ErrNoteTooLong = errors.New("note must be at most 500 characters")
+var ErrTargetNotFound = errors.New("rating or cafe listing not found")
+var ErrOwnContent = errors.New("you cannot report your own content")
+var ErrAlreadyReported = errors.New("you already reported this and it is awaiting review")
+var ErrReportNotFound = errors.New("report not found")
+var ErrInvalidStatus = errors.New("status must be one of open, hidden, restored, dismissed, all")
+var ErrInvalidTargetType = errors.New("target_type must be rating or cafe_listing")
+var ErrReportResolved = errors.New("report has already been resolved")
+var ErrAlreadyHidden = errors.New("content is already hidden")
+var ErrNotHidden = errors.New("content is not hidden")
+var ErrNotHeld = errors.New("rating is not held for review")
@@ -0,0 +1,343 @@
+package moderation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+)
+
+type Handler struct {
+	Service *Service
+}
+
+// ReportRequest is the body of a report.
+type ReportRequest struct {
+	Reason string `json:"reason" enums:"spam,offensive,harassment,off_topic,misleading,other"`
+	Note   string `json:"note,omitempty"`
+}
+
+// RegisterRoutes registers report and moderation routes. Reporting needs ratings:write or cafes:write and passes
+// writeMiddleware (e.g. email verification); the queue and its actions are for moderators and admins only.
+func RegisterRoutes(r chi.Router, service *Service, authMiddleware, writeMiddleware func(http.Handler) http.Handler) {
+	h := &Handler{Service: service}
+	r.With(auth.WithScope(auth.ScopeRatingsWrite, authMiddleware), writeMiddleware).Post("/ratings/{id}/reports", h.ReportRatingHandler)
+	r.With(auth.WithScope(auth.ScopeCafesWrite, authMiddleware), writeMiddleware).Post("/cafes/{id}/reports", h.ReportCafeHandler)
+	r.Route("/moderation/reports", func(r chi.Router) {
+		r.Use(authMiddleware, auth.RequireRole(auth.RoleModerator, auth.RoleAdmin))
+		r.Get("/", h.ListHandler)
+		r.Post("/{id}/hide", h.HideHandler)
+		r.Post("/{id}/restore", h.RestoreHandler)
+		r.Post("/{id}/dismiss", h.DismissHandler)
+	})
+	r.Route("/moderation/held-ratings", func(r chi.Router) {
+		r.Use(authMiddleware, auth.RequireRole(auth.RoleModerator, auth.RoleAdmin))
+		r.Get("/", h.ListHeldHandler)
+		r.Post("/{id}/approve", h.ApproveHeldHandler)
+		r.Post("/{id}/reject", h.RejectHeldHandler)
+	})
+}
+
+// ReportRatingHandler godoc
+// @Summary Report rating
+// @Description Reports someone else's rating to the moderators. Each user has at most one open report per rating.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rating ID"
+// @Param body body ReportRequest true "Reason code and optional note"
+// @Success 201 {object} models.ContentReport
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /ratings/{id}/reports [post]
+func (h *Handler) ReportRatingHandler(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, h.Service.ReportRating)
+}
+
+// ReportCafeHandler godoc
+// @Summary Report cafe listing
+// @Description Reports someone else's cafe listing to the moderators. Each user has at most one open report per listing.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Cafe ID"
+// @Param body body ReportRequest true "Reason code and optional note"
+// @Success 201 {object} models.ContentReport
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /cafes/{id}/reports [post]
+func (h *Handler) ReportCafeHandler(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, h.Service.ReportCafe)
+}
+
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, file func(reporterID, targetID uint, reason, note string) (*models.ContentReport, error)) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	var req ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	report, err := file(userID, uint(id), req.Reason, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidReason), errors.Is(err, ErrNoteTooLong):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, ErrOwnContent):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, ErrTargetNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, ErrAlreadyReported):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Failed to file report", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// ListHandler godoc
+// @Summary List reports
+// @Description Returns the moderation queue, oldest report first, with the reported rating or cafe listing attached (hidden or not). Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "open (default), hidden, restored, dismissed or all"
+// @Param target_type query string false "rating or cafe_listing"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.ContentReport}
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /moderation/reports/ [get]
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	reports, err := h.Service.List(ListFilter{
+		Status:     r.URL.Query().Get("status"),
+		TargetType: r.URL.Query().Get("target_type"),
+	}, page)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) || errors.Is(err, ErrInvalidTargetType) || errors.Is(err, pagination.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to retrieve reports", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(reports)
+}
+
+// HideHandler godoc
+// @Summary Hide reported content
+// @Description Hides the reported rating or cafe listing from discovery, stats and public lists, and resolves every open report on it. Its author still sees it. Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.ContentReport
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /moderation/reports/{id}/hide [post]
+func (h *Handler) HideHandler(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.Service.Hide)
+}
+
+// RestoreHandler godoc
+// @Summary Restore hidden content
+// @Description Makes the hidden rating or cafe listing public again and resolves its reports as restored. Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.ContentReport
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /moderation/reports/{id}/restore [post]
+func (h *Handler) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.Service.Restore)
+}
+
+// DismissHandler godoc
+// @Summary Dismiss report
+// @Description Closes every open report on the content without hiding it. Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.ContentReport
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /moderation/reports/{id}/dismiss [post]
+func (h *Handler) DismissHandler(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.Service.Dismiss)
+}
+
+func (h *Handler) act(w http.ResponseWriter, r *http.Request, action func(reportID, moderatorID uint) (*models.ContentReport, error)) {
+	moderatorID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	report, err := action(uint(id), moderatorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrReportNotFound), errors.Is(err, ErrTargetNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, ErrAlreadyHidden), errors.Is(err, ErrNotHidden), errors.Is(err, ErrReportResolved):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Failed to update report", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// ListHeldHandler godoc
+// @Summary List held ratings
+// @Description Returns ratings that review screening held for a moderator, longest-waiting first, with held_reason naming the rule that held each one. Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.Rating}
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /moderation/held-ratings/ [get]
+func (h *Handler) ListHeldHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	ratings, err := h.Service.ListHeld(page)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to retrieve held ratings", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ratings)
+}
+
+// ApproveHeldHandler godoc
+// @Summary Approve held rating
+// @Description Publishes a rating that review screening held. Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rating ID"
+// @Success 200 {object} models.Rating
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /moderation/held-ratings/{id}/approve [post]
+func (h *Handler) ApproveHeldHandler(w http.ResponseWriter, r *http.Request) {
+	h.decideHeld(w, r, h.Service.ApproveHeld)
+}
+
+// RejectHeldHandler godoc
+// @Summary Reject held rating
+// @Description Hides a rating that review screening held, as if a moderator had hidden it after a report. Its author still sees it. Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rating ID"
+// @Success 200 {object} models.Rating
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /moderation/held-ratings/{id}/reject [post]
+func (h *Handler) RejectHeldHandler(w http.ResponseWriter, r *http.Request) {
+	h.decideHeld(w, r, h.Service.RejectHeld)
+}
+
+func (h *Handler) decideHeld(w http.ResponseWriter, r *http.Request, decide func(ratingID, moderatorID uint) (*models.Rating, error)) {
+	moderatorID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	rating, err := decide(uint(id), moderatorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTargetNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, ErrNotHeld):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Failed to update rating", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(rating)
+}
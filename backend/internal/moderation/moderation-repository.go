@@ -0,0 +1,224 @@
+package moderation
+
+import (
+	"errors"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+type Storage interface {
+	Create(report *models.ContentReport) error
+	GetByID(id uint) (*models.ContentReport, error)
+	HasOpenReport(reporterID uint, targetType string, targetID uint) (bool, error)
+	List(filter ListFilter, page pagination.Request) (pagination.Page[models.ContentReport], error)
+	Hide(targetType string, targetID, moderatorID uint, at time.Time) error
+	Restore(targetType string, targetID, moderatorID uint, at time.Time) error
+	Dismiss(targetType string, targetID, moderatorID uint, at time.Time) error
+	ListHeld(page pagination.Request) (pagination.Page[models.Rating], error)
+	ReleaseHeld(ratingID uint, hiddenAt *time.Time) error
+	// Target returns the rating or cafe listing a report is about, without preloads.
+	Target(targetType string, targetID uint) (any, error)
+	// Transaction runs fn with a Storage bound to tx, committing only if fn returns nil.
+	Transaction(fn func(tx *gorm.DB, store Storage) error) error
+}
+
+// ListFilter narrows the moderation queue; empty fields match every report.
+type ListFilter struct {
+	Status     string
+	TargetType string
+}
+
+// queueOrder works through the oldest reports first.
+var queueOrder = pagination.Order[models.ContentReport]{Name: "oldest", Keys: []pagination.Key[models.ContentReport]{
+	{Column: "gocafe_content_reports.created_at", Value: func(r models.ContentReport) any { return r.CreatedAt }},
+	{Column: "gocafe_content_reports.id", Value: func(r models.ContentReport) any { return r.ID }},
+}}
+
+// heldOrder works through the longest-held ratings first.
+var heldOrder = pagination.Order[models.Rating]{Name: "oldest", Keys: []pagination.Key[models.Rating]{
+	{Column: "gocafe_ratings.held_at", Value: func(r models.Rating) any { return r.HeldAt }},
+	{Column: "gocafe_ratings.id", Value: func(r models.Rating) any { return r.ID }},
+}}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(report *models.ContentReport) error {
+	return r.db.Create(report).Error
+}
+
+func (r *Repository) GetByID(id uint) (*models.ContentReport, error) {
+	var report models.ContentReport
+	err := r.db.First(&report, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &report, err
+}
+
+func (r *Repository) HasOpenReport(reporterID uint, targetType string, targetID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ContentReport{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?", reporterID, targetType, targetID, StatusOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List returns one page of reports with the reported rating or listing attached, loading each kind of target in
+// one query for the whole page. Reports on deleted content drop out of the queue and come back if it is restored.
+func (r *Repository) List(filter ListFilter, page pagination.Request) (pagination.Page[models.ContentReport], error) {
+	q := r.db.Model(&models.ContentReport{}).
+		Where("gocafe_content_reports.target_type <> ? OR EXISTS (SELECT 1 FROM gocafe_ratings WHERE gocafe_ratings.id = gocafe_content_reports.target_id AND gocafe_ratings.deleted_at IS NULL)", TargetRating).
+		Where("gocafe_content_reports.target_type <> ? OR EXISTS (SELECT 1 FROM gocafe_cafes WHERE gocafe_cafes.id = gocafe_content_reports.target_id AND gocafe_cafes.deleted_at IS NULL)", TargetCafeListing)
+	if filter.Status != "" {
+		q = q.Where("gocafe_content_reports.status = ?", filter.Status)
+	}
+	if filter.TargetType != "" {
+		q = q.Where("gocafe_content_reports.target_type = ?", filter.TargetType)
+	}
+	result, err := pagination.Find(q, queueOrder, page)
+	if err != nil {
+		return result, err
+	}
+	return result, r.attachTargets(result.Items)
+}
+
+func (r *Repository) attachTargets(reports []models.ContentReport) error {
+	var ratingIDs, listingIDs []uint
+	for _, report := range reports {
+		switch report.TargetType {
+		case TargetRating:
+			ratingIDs = append(ratingIDs, report.TargetID)
+		case TargetCafeListing:
+			listingIDs = append(listingIDs, report.TargetID)
+		}
+	}
+	ratings := map[uint]*models.Rating{}
+	if len(ratingIDs) > 0 {
+		var rows []models.Rating
+		if err := r.db.Preload("User").Where("id IN ?", ratingIDs).Find(&rows).Error; err != nil {
+			return err
+		}
+		for i := range rows {
+			ratings[rows[i].ID] = &rows[i]
+		}
+	}
+	listings := map[uint]*models.Cafe{}
+	if len(listingIDs) > 0 {
+		var rows []models.Cafe
+		if err := r.db.Preload("User").Where("id IN ?", listingIDs).Find(&rows).Error; err != nil {
+			return err
+		}
+		for i := range rows {
+			listings[rows[i].ID] = &rows[i]
+		}
+	}
+	for i := range reports {
+		switch reports[i].TargetType {
+		case TargetRating:
+			reports[i].Rating = ratings[reports[i].TargetID]
+		case TargetCafeListing:
+			reports[i].CafeListing = listings[reports[i].TargetID]
+		}
+	}
+	return nil
+}
+
+// Hide hides the target and resolves its open reports as hidden, in one transaction.
+func (r *Repository) Hide(targetType string, targetID, moderatorID uint, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setHidden(tx, targetType, targetID, &at); err != nil {
+			return err
+		}
+		return resolve(tx, targetType, targetID, []string{StatusOpen}, StatusHidden, moderatorID, at)
+	})
+}
+
+// Restore unhides the target and resolves its open and hidden reports as restored, in one transaction.
+func (r *Repository) Restore(targetType string, targetID, moderatorID uint, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setHidden(tx, targetType, targetID, nil); err != nil {
+			return err
+		}
+		return resolve(tx, targetType, targetID, []string{StatusOpen, StatusHidden}, StatusRestored, moderatorID, at)
+	})
+}
+
+// Dismiss resolves the target's open reports as dismissed and leaves the target alone.
+func (r *Repository) Dismiss(targetType string, targetID, moderatorID uint, at time.Time) error {
+	return resolve(r.db, targetType, targetID, []string{StatusOpen}, StatusDismissed, moderatorID, at)
+}
+
+func (r *Repository) ListHeld(page pagination.Request) (pagination.Page[models.Rating], error) {
+	q := r.db.Model(&models.Rating{}).
+		Preload("User").
+		Preload("CafeListing").
+		Where("gocafe_ratings.held_at IS NOT NULL")
+	return pagination.Find(q, heldOrder, page)
+}
+
+// ReleaseHeld clears a rating's held state, hiding it when hiddenAt is set. Like setHidden it leaves updated_at
+// alone.
+func (r *Repository) ReleaseHeld(ratingID uint, hiddenAt *time.Time) error {
+	columns := map[string]any{"held_at": nil, "held_reason": ""}
+	if hiddenAt != nil {
+		columns["hidden_at"] = hiddenAt
+	}
+	result := r.db.Model(&models.Rating{}).Where("id = ? AND held_at IS NOT NULL", ratingID).UpdateColumns(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Target returns the rating or cafe listing as stored, for audit snapshots; ErrTargetNotFound if it is gone.
+func (r *Repository) Target(targetType string, targetID uint) (any, error) {
+	var target any = &models.Rating{}
+	if targetType == TargetCafeListing {
+		target = &models.Cafe{}
+	}
+	err := r.db.First(target, targetID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTargetNotFound
+	}
+	return target, err
+}
+
+func (r *Repository) Transaction(fn func(tx *gorm.DB, store Storage) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(tx, NewRepository(tx))
+	})
+}
+
+// setHidden writes hidden_at without touching updated_at, which owners sort their lists by.
+func setHidden(tx *gorm.DB, targetType string, targetID uint, at *time.Time) error {
+	var target any = &models.Rating{}
+	if targetType == TargetCafeListing {
+		target = &models.Cafe{}
+	}
+	result := tx.Model(target).Where("id = ?", targetID).UpdateColumn("hidden_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+func resolve(tx *gorm.DB, targetType string, targetID uint, from []string, status string, moderatorID uint, at time.Time) error {
+	return tx.Model(&models.ContentReport{}).
+		Where("target_type = ? AND target_id = ? AND status IN ?", targetType, targetID, from).
+		Updates(map[string]any{"status": status, "resolved_by_id": moderatorID, "resolved_at": at}).Error
+}
@@ -0,0 +1,291 @@
+package moderation
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"gorm.io/gorm"
+)
+
+const (
+	TargetRating      = "rating"
+	TargetCafeListing = "cafe_listing"
+
+	StatusOpen      = "open"
+	StatusHidden    = "hidden"
+	StatusRestored  = "restored"
+	StatusDismissed = "dismissed"
+	// StatusAll lists reports in every status.
+	StatusAll = "all"
+
+	MaxNoteLength = 500
+)
+
+// Reasons lists every reason code a report may give.
+var Reasons = []string{"spam", "offensive", "harassment", "off_topic", "misleading", "other"}
+
+// CafeLookup is satisfied by *cafelisting.Service.
+type CafeLookup interface {
+	GetByID(id uint) (*models.Cafe, error)
+}
+
+// RatingLookup is satisfied by *rating.Service.
+type RatingLookup interface {
+	GetByID(id uint) (*models.Rating, error)
+}
+
+type Service struct {
+	store   Storage
+	cafes   CafeLookup
+	ratings RatingLookup
+	auditor audit.Recorder // nil records nothing
+	now     func() time.Time
+}
+
+// NewService takes a nil auditor to keep moderator actions out of the audit trail.
+func NewService(store Storage, cafes CafeLookup, ratings RatingLookup, auditor audit.Recorder) *Service {
+	return &Service{store: store, cafes: cafes, ratings: ratings, auditor: auditor, now: time.Now}
+}
+
+// ReportRating files a report against someone else's rating.
+func (s *Service) ReportRating(reporterID, ratingID uint, reason, note string) (*models.ContentReport, error) {
+	return s.report(reporterID, TargetRating, ratingID, reason, note)
+}
+
+// ReportCafe files a report against someone else's cafe listing.
+func (s *Service) ReportCafe(reporterID, cafeListingID uint, reason, note string) (*models.ContentReport, error) {
+	return s.report(reporterID, TargetCafeListing, cafeListingID, reason, note)
+}
+
+func (s *Service) report(reporterID uint, targetType string, targetID uint, reason, note string) (*models.ContentReport, error) {
+	reason = strings.ToLower(strings.TrimSpace(reason))
+	if !slices.Contains(Reasons, reason) {
+		return nil, ErrInvalidReason
+	}
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > MaxNoteLength {
+		return nil, ErrNoteTooLong
+	}
+	ownerID, hidden, held, err := s.target(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == reporterID {
+		return nil, ErrOwnContent
+	}
+	if hidden || held {
+		// Hidden and held content is already out of public view, and the reporter cannot see it.
+		return nil, ErrTargetNotFound
+	}
+	open, err := s.store.HasOpenReport(reporterID, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, ErrAlreadyReported
+	}
+	report := &models.ContentReport{
+		ReporterID: reporterID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		Note:       note,
+		Status:     StatusOpen,
+	}
+	if err := s.store.Create(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// List returns the moderation queue; an empty status means open reports.
+func (s *Service) List(filter ListFilter, page pagination.Request) (pagination.Page[models.ContentReport], error) {
+	filter.Status = strings.TrimSpace(filter.Status)
+	filter.TargetType = strings.TrimSpace(filter.TargetType)
+	switch filter.Status {
+	case "":
+		filter.Status = StatusOpen
+	case StatusAll:
+		filter.Status = ""
+	case StatusOpen, StatusHidden, StatusRestored, StatusDismissed:
+	default:
+		return pagination.Page[models.ContentReport]{}, ErrInvalidStatus
+	}
+	if filter.TargetType != "" && filter.TargetType != TargetRating && filter.TargetType != TargetCafeListing {
+		return pagination.Page[models.ContentReport]{}, ErrInvalidTargetType
+	}
+	return s.store.List(filter, page)
+}
+
+// Hide hides the reported content and resolves every open report on it.
+func (s *Service) Hide(reportID, moderatorID uint) (*models.ContentReport, error) {
+	report, err := s.getReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	_, hidden, _, err := s.target(report.TargetType, report.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		return nil, ErrAlreadyHidden
+	}
+	now := s.now()
+	err = s.audited(report.TargetType, report.TargetID, moderatorID, func(store Storage) error {
+		return store.Hide(report.TargetType, report.TargetID, moderatorID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getReport(reportID)
+}
+
+// Restore makes hidden content public again and resolves its reports as restored.
+func (s *Service) Restore(reportID, moderatorID uint) (*models.ContentReport, error) {
+	report, err := s.getReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	_, hidden, _, err := s.target(report.TargetType, report.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if !hidden {
+		return nil, ErrNotHidden
+	}
+	now := s.now()
+	err = s.audited(report.TargetType, report.TargetID, moderatorID, func(store Storage) error {
+		return store.Restore(report.TargetType, report.TargetID, moderatorID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getReport(reportID)
+}
+
+// Dismiss closes every open report on the content without hiding it. It works on reports whose content has since
+// been deleted.
+func (s *Service) Dismiss(reportID, moderatorID uint) (*models.ContentReport, error) {
+	report, err := s.getReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != StatusOpen {
+		return nil, ErrReportResolved
+	}
+	if err := s.store.Dismiss(report.TargetType, report.TargetID, moderatorID, s.now()); err != nil {
+		return nil, err
+	}
+	return s.getReport(reportID)
+}
+
+// ListHeld returns ratings held by review screening, longest-waiting first.
+func (s *Service) ListHeld(page pagination.Request) (pagination.Page[models.Rating], error) {
+	return s.store.ListHeld(page)
+}
+
+// ApproveHeld publishes a held rating on moderatorID's behalf.
+func (s *Service) ApproveHeld(ratingID, moderatorID uint) (*models.Rating, error) {
+	return s.releaseHeld(ratingID, moderatorID, nil)
+}
+
+// RejectHeld turns a held rating into a hidden one, so it stays out of public view for good but its author can
+// still see it, as with reported content a moderator hid.
+func (s *Service) RejectHeld(ratingID, moderatorID uint) (*models.Rating, error) {
+	now := s.now()
+	return s.releaseHeld(ratingID, moderatorID, &now)
+}
+
+func (s *Service) releaseHeld(ratingID, moderatorID uint, hiddenAt *time.Time) (*models.Rating, error) {
+	if err := s.checkHeld(ratingID); err != nil {
+		return nil, err
+	}
+	err := s.audited(TargetRating, ratingID, moderatorID, func(store Storage) error {
+		return store.ReleaseHeld(ratingID, hiddenAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.ratings.GetByID(ratingID)
+}
+
+func (s *Service) checkHeld(ratingID uint) error {
+	rating, err := s.ratings.GetByID(ratingID)
+	if err != nil {
+		return err
+	}
+	if rating == nil {
+		return ErrTargetNotFound
+	}
+	if rating.HeldAt == nil {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// audited runs change in one transaction with an update event recording the target before and after it.
+func (s *Service) audited(targetType string, targetID, moderatorID uint, change func(store Storage) error) error {
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if s.auditor == nil {
+			return change(store)
+		}
+		before, err := store.Target(targetType, targetID)
+		if err != nil {
+			return err
+		}
+		if err := change(store); err != nil {
+			return err
+		}
+		after, err := store.Target(targetType, targetID)
+		if err != nil {
+			return err
+		}
+		entityType := audit.EntityRating
+		if targetType == TargetCafeListing {
+			entityType = audit.EntityCafeListing
+		}
+		return s.auditor.Record(tx, audit.Change{ActorID: moderatorID, Action: audit.ActionUpdate, EntityType: entityType, EntityID: targetID, Before: before, After: after})
+	})
+}
+
+func (s *Service) getReport(id uint) (*models.ContentReport, error) {
+	report, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrReportNotFound
+	}
+	return report, nil
+}
+
+// target returns the owner of the reported content, whether it is hidden, and whether it is a rating held by review
+// screening.
+func (s *Service) target(targetType string, targetID uint) (ownerID uint, hidden, held bool, err error) {
+	switch targetType {
+	case TargetRating:
+		rating, err := s.ratings.GetByID(targetID)
+		if err != nil {
+			return 0, false, false, err
+		}
+		if rating == nil {
+			return 0, false, false, ErrTargetNotFound
+		}
+		return rating.UserID, rating.HiddenAt != nil, rating.HeldAt != nil, nil
+	case TargetCafeListing:
+		listing, err := s.cafes.GetByID(targetID)
+		if err != nil {
+			return 0, false, false, err
+		}
+		if listing == nil {
+			return 0, false, false, ErrTargetNotFound
+		}
+		return listing.UserID, listing.HiddenAt != nil, false, nil
+	}
+	return 0, false, false, ErrInvalidTargetType
+}
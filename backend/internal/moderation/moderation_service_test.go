@@ -0,0 +1,233 @@
+package moderation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// dbLookup reads targets straight from the test database, as the cafe and rating services would.
+type dbLookup struct{ db *gorm.DB }
+
+func (l dbLookup) GetByID(id uint) (*models.Rating, error) {
+	var rating models.Rating
+	if err := l.db.First(&rating, id).Error; err != nil {
+		return nil, nil
+	}
+	return &rating, nil
+}
+
+type dbCafeLookup struct{ db *gorm.DB }
+
+func (l dbCafeLookup) GetByID(id uint) (*models.Cafe, error) {
+	var listing models.Cafe
+	if err := l.db.First(&listing, id).Error; err != nil {
+		return nil, nil
+	}
+	return &listing, nil
+}
+
+func newTestService(t *testing.T) (*Service, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "gocafe_"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Cafe{}, &models.SavedPlace{}, &models.Rating{}, &models.ContentReport{}, &models.AuditEvent{}))
+	require.NoError(t, db.Create(&models.Cafe{UserID: 1, Name: "Spam Cafe", Description: "buy now"}).Error)
+	require.NoError(t, db.Create(&models.Rating{UserID: 1, CafeListingID: 1, Rating: 1, Review: "rude words"}).Error)
+	svc := NewService(NewRepository(db), dbCafeLookup{db}, dbLookup{db}, audit.NewService(audit.NewRepository(db), 0))
+	svc.now = func() time.Time { return time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC) }
+	return svc, db
+}
+
+func auditEvents(t *testing.T, db *gorm.DB) []models.AuditEvent {
+	t.Helper()
+	var events []models.AuditEvent
+	require.NoError(t, db.Order("id").Find(&events).Error)
+	return events
+}
+
+func TestService_Report(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	_, err := svc.ReportRating(2, 1, "boring", "")
+	assert.ErrorIs(t, err, ErrInvalidReason)
+	_, err = svc.ReportRating(1, 1, "spam", "")
+	assert.ErrorIs(t, err, ErrOwnContent)
+	_, err = svc.ReportRating(2, 99, "spam", "")
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+
+	report, err := svc.ReportRating(2, 1, " Offensive ", "  name-calling ")
+	require.NoError(t, err)
+	assert.Equal(t, TargetRating, report.TargetType)
+	assert.Equal(t, "offensive", report.Reason)
+	assert.Equal(t, "name-calling", report.Note)
+	assert.Equal(t, StatusOpen, report.Status)
+
+	_, err = svc.ReportRating(2, 1, "spam", "")
+	assert.ErrorIs(t, err, ErrAlreadyReported)
+	_, err = svc.ReportRating(3, 1, "spam", "")
+	require.NoError(t, err)
+	_, err = svc.ReportCafe(2, 1, "spam", "")
+	require.NoError(t, err)
+}
+
+func TestService_HideRestoreDismiss(t *testing.T) {
+	svc, db := newTestService(t)
+	first, err := svc.ReportRating(2, 1, "offensive", "")
+	require.NoError(t, err)
+	second, err := svc.ReportRating(3, 1, "harassment", "")
+	require.NoError(t, err)
+	cafeReport, err := svc.ReportCafe(2, 1, "spam", "")
+	require.NoError(t, err)
+
+	queue, err := svc.List(ListFilter{}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, queue.Items, 3)
+	require.NotNil(t, queue.Items[0].Rating)
+	assert.Equal(t, "rude words", queue.Items[0].Rating.Review)
+	require.NotNil(t, queue.Items[2].CafeListing)
+
+	_, err = svc.Restore(first.ID, 9)
+	assert.ErrorIs(t, err, ErrNotHidden)
+
+	hidden, err := svc.Hide(first.ID, 9)
+	require.NoError(t, err)
+	assert.Equal(t, StatusHidden, hidden.Status)
+	require.NotNil(t, hidden.ResolvedByID)
+	assert.Equal(t, uint(9), *hidden.ResolvedByID)
+	var rating models.Rating
+	require.NoError(t, db.First(&rating, 1).Error)
+	require.NotNil(t, rating.HiddenAt)
+
+	// Hiding resolves every open report on the same rating.
+	other, err := svc.store.GetByID(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusHidden, other.Status)
+	_, err = svc.Hide(second.ID, 9)
+	assert.ErrorIs(t, err, ErrAlreadyHidden)
+	_, err = svc.ReportRating(4, 1, "spam", "")
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+
+	restored, err := svc.Restore(second.ID, 9)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRestored, restored.Status)
+	var unhidden models.Rating
+	require.NoError(t, db.First(&unhidden, 1).Error)
+	assert.Nil(t, unhidden.HiddenAt)
+
+	events := auditEvents(t, db)
+	require.Len(t, events, 2, "hide and restore are audited")
+	for _, event := range events {
+		assert.Equal(t, uint(9), event.ActorID)
+		assert.Equal(t, audit.ActionUpdate, event.Action)
+		assert.Equal(t, audit.EntityRating, event.EntityType)
+		assert.Equal(t, uint(1), event.EntityID)
+	}
+	assert.NotContains(t, string(events[0].Before), "hidden_at")
+	assert.Contains(t, string(events[0].After), "hidden_at")
+	assert.Contains(t, string(events[1].Before), "hidden_at")
+	assert.NotContains(t, string(events[1].After), "hidden_at")
+
+	dismissed, err := svc.Dismiss(cafeReport.ID, 9)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDismissed, dismissed.Status)
+	_, err = svc.Dismiss(cafeReport.ID, 9)
+	assert.ErrorIs(t, err, ErrReportResolved)
+	var listing models.Cafe
+	require.NoError(t, db.First(&listing, 1).Error)
+	assert.Nil(t, listing.HiddenAt)
+	assert.Len(t, auditEvents(t, db), 2, "dismissing changes no content")
+
+	open, err := svc.List(ListFilter{}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, open.Items)
+	all, err := svc.List(ListFilter{Status: StatusAll, TargetType: TargetRating}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, all.Items, 2)
+	_, err = svc.List(ListFilter{Status: "pending"}, pagination.Request{Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+	_, err = svc.Hide(99, 9)
+	assert.ErrorIs(t, err, ErrReportNotFound)
+}
+
+type failingRecorder struct{ err error }
+
+func (r failingRecorder) Record(tx *gorm.DB, change audit.Change) error { return r.err }
+
+func TestService_Hide_RolledBackWithAuditWrite(t *testing.T) {
+	svc, db := newTestService(t)
+	report, err := svc.ReportRating(2, 1, "offensive", "")
+	require.NoError(t, err)
+	auditErr := errors.New("audit table unavailable")
+	svc.auditor = failingRecorder{err: auditErr}
+
+	_, err = svc.Hide(report.ID, 9)
+	assert.ErrorIs(t, err, auditErr)
+	var rating models.Rating
+	require.NoError(t, db.First(&rating, 1).Error)
+	assert.Nil(t, rating.HiddenAt)
+	unresolved, err := svc.store.GetByID(report.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, unresolved.Status)
+}
+
+func TestService_HeldRatings(t *testing.T) {
+	svc, db := newTestService(t)
+	heldAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	for i, review := range []string{"visit spam.xyz", "call 9123 4567"} {
+		require.NoError(t, db.Create(&models.Cafe{UserID: uint(i + 2), Name: "Cafe"}).Error)
+		held := heldAt.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, db.Create(&models.Rating{UserID: uint(i + 2), CafeListingID: uint(i + 2), Rating: 5, Review: review, HeldAt: &held, HeldReason: "links"}).Error)
+	}
+
+	queue, err := svc.ListHeld(pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2, 3}, []uint{queue.Items[0].ID, queue.Items[1].ID})
+	require.NotNil(t, queue.Items[0].CafeListing)
+
+	// Held ratings are out of public view, so they cannot be reported.
+	_, err = svc.ReportRating(9, 2, "spam", "")
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+
+	approved, err := svc.ApproveHeld(2, 9)
+	require.NoError(t, err)
+	assert.Nil(t, approved.HeldAt)
+	assert.Empty(t, approved.HeldReason)
+	assert.Nil(t, approved.HiddenAt)
+	_, err = svc.ApproveHeld(2, 9)
+	assert.ErrorIs(t, err, ErrNotHeld)
+
+	rejected, err := svc.RejectHeld(3, 9)
+	require.NoError(t, err)
+	assert.Nil(t, rejected.HeldAt)
+	require.NotNil(t, rejected.HiddenAt)
+	assert.True(t, rejected.HiddenAt.Equal(svc.now()))
+
+	_, err = svc.RejectHeld(99, 9)
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+
+	events := auditEvents(t, db)
+	require.Len(t, events, 2, "approve and reject are audited")
+	assert.Equal(t, []uint{2, 3}, []uint{events[0].EntityID, events[1].EntityID})
+	for _, event := range events {
+		assert.Equal(t, uint(9), event.ActorID)
+		assert.Equal(t, audit.EntityRating, event.EntityType)
+		assert.Contains(t, string(event.Before), "held_at")
+		assert.NotContains(t, string(event.After), "held_at")
+	}
+	assert.Contains(t, string(events[1].After), "hidden_at")
+	queue, err = svc.ListHeld(pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, queue.Items)
+}
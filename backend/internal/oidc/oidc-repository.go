@@ -0,0 +1,34 @@
+package oidc
+
+import (
+	"errors"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type Storage interface {
+	GetByProviderSubject(provider, subject string) (*models.UserIdentity, error)
+	Create(identity *models.UserIdentity) error
+}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) GetByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &identity, err
+}
+
+func (r *Repository) Create(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
@@ -0,0 +1,217 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const httpTimeout = 10 * time.Second
+
+// UserAccounts is implemented by user service.
+type UserAccounts interface {
+	GetByEmailFold(email string) (*models.User, error)
+	CreateExternal(email, name string) (*models.User, error)
+	MarkEmailVerified(id uint, email string, at time.Time) error
+	ClearPassword(id uint) error
+}
+
+// CredentialRevoker signs a user out everywhere; implemented by the session and API token services together.
+type CredentialRevoker interface {
+	RevokeAllForUser(userID uint) error
+}
+
+// provider is a configured issuer whose discovery document is fetched on first use, so an
+// unreachable provider does not stop the API from starting.
+type provider struct {
+	cfg config.OIDCProviderConfig
+
+	mu       sync.Mutex
+	oidc     *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+}
+
+type Service struct {
+	providers    map[string]*provider
+	identities   Storage
+	users        UserAccounts
+	credentials  CredentialRevoker
+	callbackBase string
+	client       *http.Client
+	now          func() time.Time
+}
+
+func NewService(cfgs []config.OIDCProviderConfig, callbackBase string, identities Storage, users UserAccounts, credentials CredentialRevoker) *Service {
+	providers := make(map[string]*provider, len(cfgs))
+	for _, cfg := range cfgs {
+		providers[cfg.Name] = &provider{cfg: cfg}
+	}
+	return &Service{
+		providers:    providers,
+		identities:   identities,
+		users:        users,
+		credentials:  credentials,
+		callbackBase: strings.TrimRight(callbackBase, "/"),
+		client:       &http.Client{Timeout: httpTimeout},
+		now:          time.Now,
+	}
+}
+
+// Providers lists the configured provider names (implements auth.OIDCLogin).
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthCodeURL returns the provider's authorization URL carrying state, nonce and a PKCE S256 challenge
+// (implements auth.OIDCLogin).
+func (s *Service) AuthCodeURL(ctx context.Context, providerName, state, nonce, verifier string) (string, error) {
+	_, conf, err := s.oauthConfig(providerName)
+	if err != nil {
+		return "", err
+	}
+	return conf.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Authenticate exchanges the authorization code, verifies the ID token and nonce, and returns the linked
+// user, creating or linking one by verified email on first sign-in (implements auth.OIDCLogin).
+func (s *Service) Authenticate(ctx context.Context, providerName, code, nonce, verifier string) (uint, error) {
+	p, conf, err := s.oauthConfig(providerName)
+	if err != nil {
+		return 0, err
+	}
+	token, err := conf.Exchange(context.WithValue(ctx, oauth2.HTTPClient, s.client), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return 0, fmt.Errorf("exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return 0, errors.New("token response has no id_token")
+	}
+	idToken, err := p.verifier.Verify(gooidc.ClientContext(ctx, s.client), rawIDToken)
+	if err != nil {
+		return 0, fmt.Errorf("verify id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return 0, errors.New("id_token nonce mismatch")
+	}
+	var claims struct {
+		Email         string       `json:"email"`
+		EmailVerified flexibleBool `json:"email_verified"`
+		Name          string       `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return 0, fmt.Errorf("decode id_token claims: %w", err)
+	}
+	return s.link(providerName, idToken.Subject, strings.TrimSpace(claims.Email), bool(claims.EmailVerified), claims.Name)
+}
+
+// link resolves the identity to a user: an existing link wins, otherwise a verified email is matched
+// to an existing account or used to create a password-less one. Linking an account whose email was never
+// verified removes its password and signs it out everywhere, since whoever registered it may not own the address.
+func (s *Service) link(providerName, subject, email string, emailVerified bool, name string) (uint, error) {
+	identity, err := s.identities.GetByProviderSubject(providerName, subject)
+	if err != nil {
+		return 0, err
+	}
+	if identity != nil {
+		return identity.UserID, nil
+	}
+	if email == "" || !emailVerified {
+		return 0, auth.ErrExternalEmailNotVerified
+	}
+	u, err := s.users.GetByEmailFold(email)
+	if err != nil {
+		return 0, err
+	}
+	if u == nil {
+		if u, err = s.users.CreateExternal(email, name); err != nil {
+			return 0, err
+		}
+	} else if u.EmailVerifiedAt == nil {
+		// The provider has proven control of this address; the password holder has not, and could be someone who
+		// registered it first to take over the account once the owner signs in.
+		if err := s.users.ClearPassword(u.ID); err != nil {
+			return 0, err
+		}
+		if err := s.credentials.RevokeAllForUser(u.ID); err != nil {
+			return 0, err
+		}
+		if err := s.users.MarkEmailVerified(u.ID, u.Email, s.now()); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+	}
+	if err := s.identities.Create(&models.UserIdentity{UserID: u.ID, Provider: providerName, Subject: subject, Email: email}); err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (s *Service) oauthConfig(providerName string) (*provider, *oauth2.Config, error) {
+	p, ok := s.providers[providerName]
+	if !ok {
+		return nil, nil, auth.ErrUnknownProvider
+	}
+	if err := s.discover(p); err != nil {
+		return nil, nil, err
+	}
+	return p, &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint:     p.oidc.Endpoint(),
+		RedirectURL:  s.callbackBase + "/auth/oidc/" + p.cfg.Name + "/callback",
+		Scopes:       p.cfg.Scopes,
+	}, nil
+}
+
+// discover fetches the provider's discovery document once; failures are retried on the next call.
+func (s *Service) discover(p *provider) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oidc != nil {
+		return nil
+	}
+	// The context outlives this request: go-oidc keeps it for refreshing the provider's signing keys.
+	ctx := gooidc.ClientContext(context.Background(), s.client)
+	discovered, err := gooidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return fmt.Errorf("discover %s: %w", p.cfg.Name, err)
+	}
+	p.oidc = discovered
+	p.verifier = discovered.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	return nil
+}
+
+// flexibleBool accepts both JSON booleans and the "true"/"false" strings some providers send for email_verified.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var v bool
+	if err := json.Unmarshal(data, &v); err == nil {
+		*b = flexibleBool(v)
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	*b = flexibleBool(strings.EqualFold(str, "true"))
+	return nil
+}
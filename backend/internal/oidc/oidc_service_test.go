@@ -0,0 +1,188 @@
+package oidc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const testClientID = "gocafe-test"
+
+type fakeUsers struct {
+	byEmail  map[string]*models.User
+	nextID   uint
+	verified map[uint]string
+	revoked  []uint
+}
+
+func newFakeUsers() *fakeUsers {
+	return &fakeUsers{byEmail: map[string]*models.User{}, nextID: 1, verified: map[uint]string{}}
+}
+
+func (f *fakeUsers) add(email string, verified bool) *models.User {
+	u := &models.User{ID: f.nextID, Email: email}
+	if verified {
+		now := time.Now()
+		u.EmailVerifiedAt = &now
+	}
+	f.nextID++
+	f.byEmail[strings.ToLower(email)] = u
+	return u
+}
+
+func (f *fakeUsers) GetByEmailFold(email string) (*models.User, error) {
+	return f.byEmail[strings.ToLower(email)], nil
+}
+
+func (f *fakeUsers) CreateExternal(email, name string) (*models.User, error) {
+	u := f.add(email, true)
+	u.Name = name
+	return u, nil
+}
+
+func (f *fakeUsers) MarkEmailVerified(id uint, email string, at time.Time) error {
+	f.verified[id] = email
+	return nil
+}
+
+func (f *fakeUsers) ClearPassword(id uint) error {
+	for _, u := range f.byEmail {
+		if u.ID == id {
+			u.PasswordHash = ""
+		}
+	}
+	return nil
+}
+
+func (f *fakeUsers) RevokeAllForUser(userID uint) error {
+	f.revoked = append(f.revoked, userID)
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *oidctest.Issuer, *fakeUsers, *Repository) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.UserIdentity{}))
+	issuer := oidctest.NewIssuer(t)
+	repo := NewRepository(db)
+	users := newFakeUsers()
+	svc := NewService([]config.OIDCProviderConfig{{
+		Name:     "stub",
+		Issuer:   issuer.URL,
+		ClientID: testClientID,
+		Scopes:   []string{"openid", "email", "profile"},
+	}}, "http://api.test/api/v1/", repo, users, users)
+	return svc, issuer, users, repo
+}
+
+// signIn runs the authorization code flow against the stub issuer; tamperNonce simulates a replayed ID token.
+func signIn(t *testing.T, svc *Service, issuer *oidctest.Issuer, identity oidctest.Identity, tamperNonce bool) (uint, error) {
+	t.Helper()
+	issuer.SetIdentity(identity)
+	nonce, verifier := "nonce-123", oauth2.GenerateVerifier()
+	authURL, err := svc.AuthCodeURL(context.Background(), "stub", "state-abc", nonce, verifier)
+	require.NoError(t, err)
+	callback := issuer.Authorize(t, authURL)
+	require.Equal(t, "/api/v1/auth/oidc/stub/callback", callback.Path)
+	require.Equal(t, "state-abc", callback.Query().Get("state"))
+	if tamperNonce {
+		nonce = "other-nonce"
+	}
+	return svc.Authenticate(context.Background(), "stub", callback.Query().Get("code"), nonce, verifier)
+}
+
+func TestService_Authenticate_CreatesUserAndReusesIdentity(t *testing.T) {
+	svc, issuer, users, repo := newTestService(t)
+	identity := oidctest.Identity{Subject: "sub-1", Email: "new@example.com", EmailVerified: true, Name: "New User"}
+
+	userID, err := signIn(t, svc, issuer, identity, false)
+	require.NoError(t, err)
+	created := users.byEmail["new@example.com"]
+	require.NotNil(t, created)
+	assert.Equal(t, created.ID, userID)
+	assert.Equal(t, "New User", created.Name)
+
+	linked, err := repo.GetByProviderSubject("stub", "sub-1")
+	require.NoError(t, err)
+	require.NotNil(t, linked)
+	assert.Equal(t, userID, linked.UserID)
+
+	// A later sign-in resolves through the identity, even after the provider-side email changes.
+	identity.Email = "renamed@example.com"
+	again, err := signIn(t, svc, issuer, identity, false)
+	require.NoError(t, err)
+	assert.Equal(t, userID, again)
+	assert.Nil(t, users.byEmail["renamed@example.com"])
+}
+
+func TestService_Authenticate_LinksExistingAccountByVerifiedEmail(t *testing.T) {
+	svc, issuer, users, _ := newTestService(t)
+	existing := users.add("Member@Example.com", false)
+
+	userID, err := signIn(t, svc, issuer, oidctest.Identity{Subject: "sub-2", Email: "member@example.com", EmailVerified: true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, userID)
+	assert.Equal(t, existing.Email, users.verified[existing.ID])
+}
+
+func TestService_Authenticate_LinkingUnverifiedPasswordAccountRemovesPassword(t *testing.T) {
+	svc, issuer, users, repo := newTestService(t)
+	squatter := users.add("owner@example.com", false)
+	squatter.PasswordHash = "attacker-password-hash"
+
+	userID, err := signIn(t, svc, issuer, oidctest.Identity{Subject: "sub-5", Email: "owner@example.com", EmailVerified: true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, squatter.ID, userID)
+	assert.Empty(t, squatter.PasswordHash)
+	assert.Equal(t, []uint{squatter.ID}, users.revoked)
+	assert.Equal(t, squatter.Email, users.verified[squatter.ID])
+	linked, err := repo.GetByProviderSubject("stub", "sub-5")
+	require.NoError(t, err)
+	require.NotNil(t, linked)
+
+	// A verified account keeps its password and sessions when a provider is linked to it.
+	member := users.add("member@example.com", true)
+	member.PasswordHash = "member-password-hash"
+	_, err = signIn(t, svc, issuer, oidctest.Identity{Subject: "sub-6", Email: "member@example.com", EmailVerified: true}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "member-password-hash", member.PasswordHash)
+	assert.Equal(t, []uint{squatter.ID}, users.revoked)
+}
+
+func TestService_Authenticate_RejectsUnverifiedEmail(t *testing.T) {
+	svc, issuer, users, repo := newTestService(t)
+	users.add("victim@example.com", true)
+
+	_, err := signIn(t, svc, issuer, oidctest.Identity{Subject: "sub-3", Email: "victim@example.com", EmailVerified: false}, false)
+	assert.ErrorIs(t, err, auth.ErrExternalEmailNotVerified)
+	linked, err := repo.GetByProviderSubject("stub", "sub-3")
+	require.NoError(t, err)
+	assert.Nil(t, linked)
+}
+
+func TestService_Authenticate_RejectsNonceMismatch(t *testing.T) {
+	svc, issuer, _, _ := newTestService(t)
+
+	_, err := signIn(t, svc, issuer, oidctest.Identity{Subject: "sub-4", Email: "a@example.com", EmailVerified: true}, true)
+	assert.ErrorContains(t, err, "nonce")
+}
+
+func TestService_UnknownProvider(t *testing.T) {
+	svc, _, _, _ := newTestService(t)
+
+	assert.Equal(t, []string{"stub"}, svc.Providers())
+	_, err := svc.AuthCodeURL(context.Background(), "nope", "s", "n", "v")
+	assert.ErrorIs(t, err, auth.ErrUnknownProvider)
+}
@@ -0,0 +1,265 @@
+// Package pagination implements keyset (cursor) paging and the {items, next_cursor} list envelope.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+var ErrInvalidLimit = errors.New("invalid limit")
+
+// Page is the response envelope for list endpoints. NextCursor is null on the last page.
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// PageDoc describes Page in Swagger annotations, which cannot name generic types:
+// use {object} pagination.PageDoc{items=[]models.X}.
+type PageDoc struct {
+	Items      any     `json:"items"`
+	NextCursor *string `json:"next_cursor" example:"eyJzIjoidXBkYXRlZF9kZXNjIiwidiI6W119"`
+}
+
+// Request carries the client's cursor (empty for the first page) and page size.
+type Request struct {
+	Cursor string
+	Limit  int
+}
+
+// FromQuery reads the cursor and limit query parameters. A missing or non-positive limit falls back to
+// def and anything above max is clamped to max; a non-numeric limit is ErrInvalidLimit.
+func FromQuery(values url.Values, def, max int) (Request, error) {
+	req := Request{Cursor: strings.TrimSpace(values.Get("cursor")), Limit: def}
+	if raw := strings.TrimSpace(values.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return Request{}, ErrInvalidLimit
+		}
+		if parsed > 0 {
+			req.Limit = parsed
+		}
+	}
+	if req.Limit > max {
+		req.Limit = max
+	}
+	return req, nil
+}
+
+// Key is one ORDER BY term. Column is the SQL expression (it is also compared in the keyset WHERE)
+// and Value reads the same value from a loaded row.
+type Key[T any] struct {
+	Column string
+	Desc   bool
+	Value  func(T) any
+}
+
+// Order is a named, total sort order: the last key must be unique (normally the primary key).
+// Cursors record the order name, so a cursor is rejected when the sort changes between pages.
+type Order[T any] struct {
+	Name string
+	Keys []Key[T]
+}
+
+type cursorPayload struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// Find runs q ordered by order, starting after req.Cursor, and returns at most req.Limit rows plus the
+// cursor for the next page.
+func Find[T any](q *gorm.DB, order Order[T], req Request) (Page[T], error) {
+	if req.Cursor != "" {
+		values, err := order.decode(req.Cursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		where, args := order.after(values)
+		q = q.Where(where, args...)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	var items []T
+	if err := q.Order(order.orderBy()).Limit(limit + 1).Find(&items).Error; err != nil {
+		return Page[T]{}, err
+	}
+	page := Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		next, err := order.encode(page.Items[limit-1])
+		if err != nil {
+			return Page[T]{}, err
+		}
+		page.NextCursor = &next
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page, nil
+}
+
+// FindFiltered is Find for conditions SQL cannot express: rows are read in batches in order and kept when keep
+// returns true. It stops once req.Limit rows are kept or maxScan rows have been read, so a page can hold fewer
+// than req.Limit rows (even none) while NextCursor is still set; clients keep following it as usual.
+func FindFiltered[T any](q *gorm.DB, order Order[T], req Request, keep func(T) bool, maxScan int) (Page[T], error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	batch := max(limit*2, 50)
+	base := q.Session(&gorm.Session{})
+
+	var values []any
+	if req.Cursor != "" {
+		decoded, err := order.decode(req.Cursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		values = decoded
+	}
+
+	page := Page[T]{Items: []T{}}
+	scanned := 0
+	for {
+		batchQuery := base
+		if values != nil {
+			where, args := order.after(values)
+			batchQuery = batchQuery.Where(where, args...)
+		}
+		var rows []T
+		if err := batchQuery.Order(order.orderBy()).Limit(batch).Find(&rows).Error; err != nil {
+			return Page[T]{}, err
+		}
+		for _, row := range rows {
+			scanned++
+			if !keep(row) {
+				continue
+			}
+			if len(page.Items) == limit {
+				// A further match exists, so the page ends at the previous kept row.
+				next, err := order.encode(page.Items[limit-1])
+				if err != nil {
+					return Page[T]{}, err
+				}
+				page.NextCursor = &next
+				return page, nil
+			}
+			page.Items = append(page.Items, row)
+		}
+		if len(rows) < batch {
+			return page, nil
+		}
+		last := rows[len(rows)-1]
+		if scanned >= maxScan {
+			// Resume after the last row read, whether or not it was kept.
+			next, err := order.encode(last)
+			if err != nil {
+				return Page[T]{}, err
+			}
+			page.NextCursor = &next
+			return page, nil
+		}
+		values = order.valuesOf(last)
+	}
+}
+
+func (o Order[T]) orderBy() string {
+	terms := make([]string, len(o.Keys))
+	for i, key := range o.Keys {
+		terms[i] = key.Column + direction(key.Desc)
+	}
+	return strings.Join(terms, ", ")
+}
+
+// after builds "(k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..." with < for descending keys.
+func (o Order[T]) after(values []any) (string, []any) {
+	var branches []string
+	var args []any
+	for i, key := range o.Keys {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, o.Keys[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if key.Desc {
+			op = " < ?"
+		}
+		terms = append(terms, key.Column+op)
+		args = append(args, values[i])
+		branches = append(branches, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", args
+}
+
+func (o Order[T]) valuesOf(row T) []any {
+	values := make([]any, len(o.Keys))
+	for i, key := range o.Keys {
+		values[i] = key.Value(row)
+	}
+	return values
+}
+
+func (o Order[T]) encode(last T) (string, error) {
+	payload := cursorPayload{Sort: o.Name, Values: make([]json.RawMessage, len(o.Keys))}
+	for i, key := range o.Keys {
+		raw, err := json.Marshal(key.Value(last))
+		if err != nil {
+			return "", err
+		}
+		payload.Values[i] = raw
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decode unmarshals each cursor value into the Go type its Key produces, so it binds like the column.
+func (o Order[T]) decode(cursor string) ([]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if payload.Sort != o.Name || len(payload.Values) != len(o.Keys) {
+		return nil, ErrInvalidCursor
+	}
+	var zero T
+	values := make([]any, len(o.Keys))
+	for i, key := range o.Keys {
+		target := reflect.New(reflect.TypeOf(key.Value(zero)))
+		if err := json.Unmarshal(payload.Values[i], target.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = target.Elem().Interface()
+	}
+	return values, nil
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
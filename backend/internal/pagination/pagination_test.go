@@ -0,0 +1,122 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type row struct {
+	ID   uint
+	Name string
+	At   time.Time
+}
+
+var testOrder = Order[row]{Name: "name_asc", Keys: []Key[row]{
+	{Column: "name", Value: func(r row) any { return r.Name }},
+	{Column: "at", Desc: true, Value: func(r row) any { return r.At }},
+	{Column: "id", Value: func(r row) any { return r.ID }},
+}}
+
+func TestFromQuery(t *testing.T) {
+	req, err := FromQuery(url.Values{}, DefaultLimit, MaxLimit)
+	require.NoError(t, err)
+	assert.Equal(t, Request{Limit: DefaultLimit}, req)
+
+	req, err = FromQuery(url.Values{"limit": {"500"}, "cursor": {" abc "}}, DefaultLimit, MaxLimit)
+	require.NoError(t, err)
+	assert.Equal(t, Request{Cursor: "abc", Limit: MaxLimit}, req)
+
+	req, err = FromQuery(url.Values{"limit": {"0"}}, 18, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 18, req.Limit)
+
+	_, err = FromQuery(url.Values{"limit": {"ten"}}, DefaultLimit, MaxLimit)
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+}
+
+func TestOrder_CursorRoundTrip(t *testing.T) {
+	at := time.Date(2026, 4, 2, 9, 30, 0, 123456000, time.FixedZone("SGT", 8*3600))
+	cursor, err := testOrder.encode(row{ID: 42, Name: "Brew", At: at})
+	require.NoError(t, err)
+
+	values, err := testOrder.decode(cursor)
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+	assert.Equal(t, "Brew", values[0])
+	assert.True(t, at.Equal(values[1].(time.Time)))
+	assert.Equal(t, uint(42), values[2])
+}
+
+func TestOrder_DecodeRejectsForeignOrTamperedCursors(t *testing.T) {
+	cursor, err := testOrder.encode(row{ID: 1, Name: "Brew"})
+	require.NoError(t, err)
+
+	other := Order[row]{Name: "name_desc", Keys: testOrder.Keys}
+	_, err = other.decode(cursor)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	for _, bad := range []string{
+		"%%%",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"name_asc","v":["Brew"]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"name_asc","v":[1,"2026-01-01T00:00:00Z",1]}`)),
+	} {
+		_, err = testOrder.decode(bad)
+		assert.ErrorIs(t, err, ErrInvalidCursor, bad)
+	}
+}
+
+func TestOrder_After(t *testing.T) {
+	where, args := testOrder.after([]any{"Brew", "t", uint(3)})
+	assert.Equal(t, "((name > ?) OR (name = ? AND at < ?) OR (name = ? AND at = ? AND id > ?))", where)
+	assert.Equal(t, []any{"Brew", "Brew", "t", "Brew", "t", uint(3)}, args)
+	assert.Equal(t, "name ASC, at DESC, id ASC", testOrder.orderBy())
+}
+
+type numberRow struct {
+	ID uint
+}
+
+func TestFindFiltered_PagesThroughMatchesOnly(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&numberRow{}))
+	for i := 1; i <= 200; i++ {
+		require.NoError(t, db.Create(&numberRow{ID: uint(i)}).Error)
+	}
+	order := Order[numberRow]{Name: "id", Keys: []Key[numberRow]{{Column: "id", Value: func(r numberRow) any { return r.ID }}}}
+	keep := func(r numberRow) bool { return r.ID%7 == 0 }
+
+	for _, maxScan := range []int{1000, 60} {
+		var got []uint
+		cursor, pages := "", 0
+		for {
+			page, err := FindFiltered(db.Model(&numberRow{}), order, Request{Cursor: cursor, Limit: 5}, keep, maxScan)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Items), 5)
+			for _, item := range page.Items {
+				got = append(got, item.ID)
+			}
+			pages++
+			if page.NextCursor == nil {
+				break
+			}
+			cursor = *page.NextCursor
+		}
+		var want []uint
+		for i := uint(7); i <= 200; i += 7 {
+			want = append(want, i)
+		}
+		assert.Equal(t, want, got, "maxScan %d", maxScan)
+		if maxScan == 1000 {
+			assert.Equal(t, 6, pages, "28 matches in full pages of 5, and no trailing empty page")
+		}
+	}
+}
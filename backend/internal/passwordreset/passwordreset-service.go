@@ -0,0 +1,138 @@
+package passwordreset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/mail"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+const sendTimeout = 30 * time.Second
+
+// UserAccounts is implemented by user service.
+type UserAccounts interface {
+	GetByEmail(email string) (*models.User, error)
+	SetPassword(id uint, newPassword string) error
+}
+
+// SessionRevoker is implemented by session service; a reset signs the user out everywhere.
+type SessionRevoker interface {
+	RevokeAllForUser(userID uint) error
+}
+
+type Service struct {
+	store    Storage
+	users    UserAccounts
+	sessions SessionRevoker
+	mailer   mail.Mailer
+	resetURL string
+	ttl      time.Duration
+	now      func() time.Time
+	dispatch func(func()) // runs mail delivery; async by default so response timing does not reveal registered emails
+}
+
+func NewService(store Storage, users UserAccounts, sessions SessionRevoker, mailer mail.Mailer, resetURL string, ttl time.Duration) *Service {
+	return &Service{
+		store:    store,
+		users:    users,
+		sessions: sessions,
+		mailer:   mailer,
+		resetURL: resetURL,
+		ttl:      ttl,
+		now:      time.Now,
+		dispatch: func(f func()) { go f() },
+	}
+}
+
+// RequestReset emails a reset link when the address is registered. Unknown addresses are a silent no-op
+// so callers cannot probe which emails have accounts (implements auth.PasswordResetter).
+func (s *Service) RequestReset(ctx context.Context, email string) error {
+	email = strings.TrimSpace(email)
+	u, err := s.users.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return nil
+	}
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	record := &models.PasswordResetToken{
+		UserID:    u.ID,
+		TokenHash: auth.HashOpaqueToken(token),
+		ExpiresAt: s.now().Add(s.ttl),
+	}
+	if err := s.store.Create(record); err != nil {
+		return err
+	}
+	msg := mail.Message{
+		To:      u.Email,
+		Subject: "Reset your go-cafe password",
+		Body: fmt.Sprintf(
+			"Someone requested a password reset for your go-cafe account.\n\n"+
+				"Open this link to choose a new password (valid for %s):\n%s\n\n"+
+				"If this wasn't you, you can ignore this email.\n",
+			s.ttl, buildLink(s.resetURL, token),
+		),
+	}
+	s.dispatch(func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+		defer cancel()
+		if err := s.mailer.Send(sendCtx, msg); err != nil {
+			log.Printf("password reset mail to user %d failed: %v", u.ID, err)
+		}
+	})
+	return nil
+}
+
+// ResetPassword consumes a reset token, sets the new password, and revokes every session of the user
+// (implements auth.PasswordResetter).
+func (s *Service) ResetPassword(token, newPassword string) error {
+	// Check the policy first so a weak password does not burn the single-use token.
+	if err := auth.ValidatePasswordStrength(newPassword); err != nil {
+		return err
+	}
+	record, err := s.store.GetByTokenHash(auth.HashOpaqueToken(strings.TrimSpace(token)))
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	if record == nil || record.UsedAt != nil || !now.Before(record.ExpiresAt) {
+		return auth.ErrInvalidToken
+	}
+	if err := s.store.MarkUsed(record.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.ErrInvalidToken
+		}
+		return err
+	}
+	if err := s.users.SetPassword(record.UserID, newPassword); err != nil {
+		return err
+	}
+	if err := s.store.InvalidateAllForUser(record.UserID, now); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAllForUser(record.UserID)
+}
+
+// buildLink appends the token as a query parameter to the configured frontend URL.
+func buildLink(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,122 @@
+package passwordreset
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/mail"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeUsers struct {
+	users     map[string]*models.User
+	passwords map[uint]string
+}
+
+func (f *fakeUsers) GetByEmail(email string) (*models.User, error) {
+	return f.users[email], nil
+}
+
+func (f *fakeUsers) SetPassword(id uint, newPassword string) error {
+	f.passwords[id] = newPassword
+	return nil
+}
+
+type fakeSessions struct {
+	revokedUsers []uint
+}
+
+func (f *fakeSessions) RevokeAllForUser(userID uint) error {
+	f.revokedUsers = append(f.revokedUsers, userID)
+	return nil
+}
+
+var tokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+func newTestService(t *testing.T) (*Service, *fakeUsers, *fakeSessions, *bytes.Buffer) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.PasswordResetToken{}))
+
+	users := &fakeUsers{
+		users:     map[string]*models.User{"a@b.com": {ID: 7, Email: "a@b.com"}},
+		passwords: map[uint]string{},
+	}
+	sessions := &fakeSessions{}
+	var out bytes.Buffer
+	svc := NewService(NewRepository(db), users, sessions, mail.NewLogMailer(&out, "from@example.com"), "http://app.test/reset-password", time.Hour)
+	svc.dispatch = func(f func()) { f() }
+	return svc, users, sessions, &out
+}
+
+func extractToken(t *testing.T, out string) string {
+	t.Helper()
+	m := tokenPattern.FindStringSubmatch(out)
+	require.Len(t, m, 2, "reset link not found in mail output")
+	return m[1]
+}
+
+func TestRequestReset_UnknownEmailSendsNothing(t *testing.T) {
+	svc, _, _, out := newTestService(t)
+
+	require.NoError(t, svc.RequestReset(context.Background(), "nobody@example.com"))
+	assert.Empty(t, out.String())
+}
+
+func TestResetPassword_SingleUse(t *testing.T) {
+	svc, users, sessions, out := newTestService(t)
+
+	require.NoError(t, svc.RequestReset(context.Background(), "a@b.com"))
+	assert.Contains(t, out.String(), "To: a@b.com")
+	token := extractToken(t, out.String())
+
+	require.NoError(t, svc.ResetPassword(token, "brand-new-pass"))
+	assert.Equal(t, "brand-new-pass", users.passwords[7])
+	assert.Equal(t, []uint{7}, sessions.revokedUsers)
+
+	assert.ErrorIs(t, svc.ResetPassword(token, "another-pass"), auth.ErrInvalidToken)
+}
+
+func TestResetPassword_WeakPasswordKeepsToken(t *testing.T) {
+	svc, _, _, out := newTestService(t)
+	require.NoError(t, svc.RequestReset(context.Background(), "a@b.com"))
+	token := extractToken(t, out.String())
+
+	assert.ErrorIs(t, svc.ResetPassword(token, "short"), auth.ErrWeakPassword)
+	assert.NoError(t, svc.ResetPassword(token, "long-enough-now"))
+}
+
+func TestResetPassword_Expired(t *testing.T) {
+	svc, _, _, out := newTestService(t)
+	require.NoError(t, svc.RequestReset(context.Background(), "a@b.com"))
+	token := extractToken(t, out.String())
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.ErrorIs(t, svc.ResetPassword(token, "brand-new-pass"), auth.ErrInvalidToken)
+}
+
+func TestResetPassword_UnknownToken(t *testing.T) {
+	svc, _, _, _ := newTestService(t)
+	assert.ErrorIs(t, svc.ResetPassword("not-a-token", "brand-new-pass"), auth.ErrInvalidToken)
+}
+
+func TestResetPassword_InvalidatesOtherOutstandingTokens(t *testing.T) {
+	svc, _, _, out := newTestService(t)
+	require.NoError(t, svc.RequestReset(context.Background(), "a@b.com"))
+	first := extractToken(t, out.String())
+	out.Reset()
+	require.NoError(t, svc.RequestReset(context.Background(), "a@b.com"))
+	second := extractToken(t, out.String())
+
+	require.NoError(t, svc.ResetPassword(second, "brand-new-pass"))
+	assert.ErrorIs(t, svc.ResetPassword(first, "another-pass"), auth.ErrInvalidToken)
+}
@@ -0,0 +1,14 @@
+package photo
+
+import "errors"
+
+var ErrPhotoNotFound = errors.New("photo not found")
+var ErrTargetNotFound = errors.New("cafe listing or rating not found")
+var ErrNotOwner = errors.New("photos can only be added or removed by the owner")
+var ErrNoPhotos = errors.New("no photos in request: send image files in the multipart field \"photos\"")
+var ErrTooManyPhotos = errors.New("too many photos")
+var ErrPhotoTooLarge = errors.New("photo exceeds the maximum upload size")
+var ErrUnsupportedImageType = errors.New("photos must be JPEG or PNG images")
+var ErrInvalidImage = errors.New("photo could not be decoded")
+var ErrImageDimensions = errors.New("photo dimensions are too large")
+var ErrStorageNotConfigured = errors.New("photo storage is not configured")
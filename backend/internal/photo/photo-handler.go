@@ -0,0 +1,417 @@
+package photo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+)
+
+// DefaultMaxUploadBytes is the per-file size limit when none is configured.
+const DefaultMaxUploadBytes = 10 << 20
+
+type Handler struct {
+	Service *Service
+	// BasePath prefixes the photo URLs in responses, e.g. /api/v1.
+	BasePath string
+	// MaxUploadBytes limits each uploaded file.
+	MaxUploadBytes int64
+}
+
+// RegisterRoutes registers photo routes. Listing and serving photos is public, with an optional login so the
+// authors of hidden content and moderators still see its photos; uploads need cafes:write or ratings:write and pass
+// writeMiddleware (e.g. email verification), deletes need the same scope.
+func RegisterRoutes(r chi.Router, service *Service, basePath string, maxUploadBytes int64, authMiddleware, writeMiddleware func(http.Handler) http.Handler) {
+	if maxUploadBytes <= 0 {
+		maxUploadBytes = DefaultMaxUploadBytes
+	}
+	h := &Handler{Service: service, BasePath: basePath, MaxUploadBytes: maxUploadBytes}
+	cafesWrite := auth.WithScope(auth.ScopeCafesWrite, authMiddleware)
+	ratingsWrite := auth.WithScope(auth.ScopeRatingsWrite, authMiddleware)
+	cafesViewer := auth.Optional(auth.WithScope(auth.ScopeCafesRead, authMiddleware))
+	ratingsViewer := auth.Optional(auth.WithScope(auth.ScopeRatingsRead, authMiddleware))
+	r.Route("/cafes/{id}/photos", func(r chi.Router) {
+		r.With(cafesViewer).Get("/", h.ListByCafeHandler)
+		r.With(cafesWrite, writeMiddleware).Post("/", h.UploadForCafeHandler)
+		r.With(cafesWrite).Delete("/{photoId}", h.DeleteFromCafeHandler)
+	})
+	r.Route("/ratings/{id}/photos", func(r chi.Router) {
+		r.With(ratingsViewer).Get("/", h.ListByRatingHandler)
+		r.With(ratingsWrite, writeMiddleware).Post("/", h.UploadForRatingHandler)
+		r.With(ratingsWrite).Delete("/{photoId}", h.DeleteFromRatingHandler)
+	})
+	r.Route("/photos/{id}", func(r chi.Router) {
+		r.Use(sessionViewer(authMiddleware))
+		r.Get("/", h.ServeHandler)
+		r.Get("/thumbnail", h.ServeThumbnailHandler)
+	})
+}
+
+// sessionViewer identifies a signed-in session on the photo file routes. A photo belongs to a cafe or a review, so
+// no single scope fits: personal access tokens are ignored here and fetch photos as an anonymous caller would.
+func sessionViewer(authMiddleware func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	optional := auth.Optional(authMiddleware)
+	return func(next http.Handler) http.Handler {
+		withViewer := optional(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "+auth.PersonalTokenPrefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			withViewer.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UploadForCafeHandler godoc
+// @Summary Upload cafe photos
+// @Description Adds JPEG or PNG photos to a cafe listing owned by the authenticated user. Send one or more files in the multipart field "photos". Images are scaled down to 2048px, EXIF metadata (including GPS) is stripped, and a 400px thumbnail is generated. A cafe holds at most 20 photos.
+// @Tags photos
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Cafe ID"
+// @Param photos formData file true "Image files (up to 10 per request)"
+// @Success 201 {array} models.Photo
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 413 {string} string
+// @Failure 415 {string} string
+// @Failure 500 {string} string
+// @Failure 503 {string} string
+// @Router /cafes/{id}/photos/ [post]
+func (h *Handler) UploadForCafeHandler(w http.ResponseWriter, r *http.Request) {
+	h.upload(w, r, h.Service.UploadForCafe)
+}
+
+// UploadForRatingHandler godoc
+// @Summary Upload review photos
+// @Description Adds JPEG or PNG photos to a rating written by the authenticated user. Send one or more files in the multipart field "photos". Images are processed as for cafe photos. A rating holds at most 6 photos.
+// @Tags photos
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rating ID"
+// @Param photos formData file true "Image files (up to 10 per request)"
+// @Success 201 {array} models.Photo
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 413 {string} string
+// @Failure 415 {string} string
+// @Failure 500 {string} string
+// @Failure 503 {string} string
+// @Router /ratings/{id}/photos/ [post]
+func (h *Handler) UploadForRatingHandler(w http.ResponseWriter, r *http.Request) {
+	h.upload(w, r, h.Service.UploadForRating)
+}
+
+type uploadFunc func(ctx context.Context, userID, targetID uint, uploads []Upload) ([]models.Photo, error)
+
+func (h *Handler) upload(w http.ResponseWriter, r *http.Request, upload uploadFunc) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	targetID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	uploads, err := h.readUploads(w, r)
+	if err != nil {
+		writeError(w, err, "Failed to read upload")
+		return
+	}
+	photos, err := upload(r.Context(), userID, uint(targetID), uploads)
+	if err != nil {
+		writeError(w, err, "Failed to upload photos")
+		return
+	}
+	h.setURLs(photos)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(photos)
+}
+
+// readUploads streams the multipart body and keeps the "photos" (or "photo") file parts, enforcing the per-file
+// limit while reading so an oversized file is never held in memory whole.
+func (h *Handler) readUploads(w http.ResponseWriter, r *http.Request) ([]Upload, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxUploadBytes*MaxFilesPerRequest+1<<20)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, errBadMultipart
+	}
+	var uploads []Upload
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return uploads, nil
+		}
+		if err != nil {
+			return nil, multipartError(err)
+		}
+		if (part.FormName() != "photos" && part.FormName() != "photo") || part.FileName() == "" {
+			_ = part.Close()
+			continue
+		}
+		if len(uploads) == MaxFilesPerRequest {
+			return nil, ErrTooManyPhotos
+		}
+		data, err := io.ReadAll(io.LimitReader(part, h.MaxUploadBytes+1))
+		_ = part.Close()
+		if err != nil {
+			return nil, multipartError(err)
+		}
+		if int64(len(data)) > h.MaxUploadBytes {
+			return nil, ErrPhotoTooLarge
+		}
+		uploads = append(uploads, Upload{Filename: part.FileName(), Data: data})
+	}
+}
+
+var errBadMultipart = errors.New("request must be multipart/form-data")
+
+func multipartError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrPhotoTooLarge
+	}
+	return errBadMultipart
+}
+
+// ListByCafeHandler godoc
+// @Summary List cafe photos
+// @Description Returns the photos of a cafe listing, oldest first. A cafe hidden by moderators is 404 except for the user who added it and moderators, who must send a bearer token.
+// @Tags photos
+// @Produce json
+// @Param id path int true "Cafe ID"
+// @Success 200 {array} models.Photo
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /cafes/{id}/photos/ [get]
+func (h *Handler) ListByCafeHandler(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.Service.ListByCafe)
+}
+
+// ListByRatingHandler godoc
+// @Summary List review photos
+// @Description Returns the photos of a rating, oldest first. A rating hidden by moderators or held by screening, or one on a hidden cafe, is 404 except for its author and moderators, who must send a bearer token.
+// @Tags photos
+// @Produce json
+// @Param id path int true "Rating ID"
+// @Success 200 {array} models.Photo
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /ratings/{id}/photos/ [get]
+func (h *Handler) ListByRatingHandler(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.Service.ListByRating)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, list func(context.Context, uint) ([]models.Photo, error)) {
+	targetID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	photos, err := list(r.Context(), uint(targetID))
+	if err != nil {
+		writeError(w, err, "Failed to retrieve photos")
+		return
+	}
+	if photos == nil {
+		photos = []models.Photo{}
+	}
+	h.setURLs(photos)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(photos)
+}
+
+// DeleteFromCafeHandler godoc
+// @Summary Delete a cafe photo
+// @Description Removes a photo from a cafe listing owned by the authenticated user.
+// @Tags photos
+// @Security BearerAuth
+// @Param id path int true "Cafe ID"
+// @Param photoId path int true "Photo ID"
+// @Success 204
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Failure 503 {string} string
+// @Router /cafes/{id}/photos/{photoId} [delete]
+func (h *Handler) DeleteFromCafeHandler(w http.ResponseWriter, r *http.Request) {
+	h.delete(w, r, h.Service.DeleteFromCafe)
+}
+
+// DeleteFromRatingHandler godoc
+// @Summary Delete a review photo
+// @Description Removes a photo from a rating written by the authenticated user.
+// @Tags photos
+// @Security BearerAuth
+// @Param id path int true "Rating ID"
+// @Param photoId path int true "Photo ID"
+// @Success 204
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Failure 503 {string} string
+// @Router /ratings/{id}/photos/{photoId} [delete]
+func (h *Handler) DeleteFromRatingHandler(w http.ResponseWriter, r *http.Request) {
+	h.delete(w, r, h.Service.DeleteFromRating)
+}
+
+type deleteFunc func(ctx context.Context, userID, targetID, photoID uint) error
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, remove deleteFunc) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	targetID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	photoID, err := strconv.Atoi(chi.URLParam(r, "photoId"))
+	if err != nil {
+		http.Error(w, "Invalid photo ID", http.StatusBadRequest)
+		return
+	}
+	if err := remove(r.Context(), userID, uint(targetID), uint(photoID)); err != nil {
+		writeError(w, err, "Failed to delete photo")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeHandler godoc
+// @Summary Get a photo
+// @Description Returns the photo image. Photos never change once uploaded, so responses are cacheable indefinitely. Photos of hidden content are 404 except for its author and moderators, who must send a session bearer token; those responses are only privately cacheable.
+// @Tags photos
+// @Produce jpeg
+// @Produce png
+// @Param id path int true "Photo ID"
+// @Success 200 {file} binary
+// @Success 304
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Failure 503 {string} string
+// @Router /photos/{id} [get]
+func (h *Handler) ServeHandler(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, false)
+}
+
+// ServeThumbnailHandler godoc
+// @Summary Get a photo thumbnail
+// @Description Returns the photo thumbnail (longest side 400px). Hidden content is handled as for the full photo.
+// @Tags photos
+// @Produce jpeg
+// @Produce png
+// @Param id path int true "Photo ID"
+// @Success 200 {file} binary
+// @Success 304
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Failure 503 {string} string
+// @Router /photos/{id}/thumbnail [get]
+func (h *Handler) ServeThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, true)
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	photoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	photo, body, err := h.Service.Open(r.Context(), uint(photoID), thumbnail)
+	if err != nil {
+		writeError(w, err, "Failed to retrieve photo")
+		return
+	}
+	defer body.Close()
+
+	variant := "full"
+	if thumbnail {
+		variant = "thumb"
+	}
+	etag := fmt.Sprintf(`"photo-%d-%s"`, photo.ID, variant)
+	w.Header().Set("ETag", etag)
+	// A signed-in caller may be seeing a photo of hidden content, which shared caches must not keep.
+	cacheControl := "public, max-age=31536000, immutable"
+	if _, ok := auth.UserIDFromContext(r.Context()); ok {
+		cacheControl = "private, max-age=31536000, immutable"
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", photo.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, body)
+}
+
+func (h *Handler) setURLs(photos []models.Photo) {
+	for i := range photos {
+		photos[i].URL = fmt.Sprintf("%s/photos/%d", h.BasePath, photos[i].ID)
+		photos[i].ThumbnailURL = photos[i].URL + "/thumbnail"
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, errBadMultipart), errors.Is(err, ErrNoPhotos), errors.Is(err, ErrInvalidImage), errors.Is(err, ErrImageDimensions):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotOwner):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrTargetNotFound), errors.Is(err, ErrPhotoNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrTooManyPhotos):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrPhotoTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, ErrUnsupportedImageType):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, ErrStorageNotConfigured):
+		http.Error(w, "Photo storage is not configured", http.StatusServiceUnavailable)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or is "*", comparing weakly.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
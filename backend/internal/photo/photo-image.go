@@ -0,0 +1,267 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"net/http"
+)
+
+const (
+	// MaxImageDimension is the longest side of a stored photo; larger uploads are scaled down.
+	MaxImageDimension = 2048
+	// ThumbnailDimension is the longest side of a thumbnail.
+	ThumbnailDimension = 400
+	// maxImagePixels rejects images whose decoded size would exhaust memory, whatever their file size. 16 MP
+	// decodes to 64 MB of RGBA and covers the default output of phone cameras.
+	maxImagePixels = 16_000_000
+	jpegQuality    = 85
+)
+
+// processedImage is an encoded image ready for storage.
+type processedImage struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// processImage checks an upload and re-encodes it as a full-size photo and a thumbnail. Only pixels survive
+// re-encoding, so EXIF (including GPS position), XMP and PNG text chunks are dropped; the EXIF orientation is
+// applied to the pixels so photos stay upright.
+func processImage(data []byte) (processedImage, processedImage, error) {
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return processedImage{}, processedImage{}, ErrUnsupportedImageType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return processedImage{}, processedImage{}, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return processedImage{}, processedImage{}, ErrImageDimensions
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return processedImage{}, processedImage{}, ErrInvalidImage
+	}
+	// Scale down before turning upright, so only the smaller image is copied again, and make the thumbnail from
+	// the scaled photo rather than from the original.
+	img := resizeToFit(toRGBA(decoded), MaxImageDimension)
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	full, err := encodeImage(img, contentType)
+	if err != nil {
+		return processedImage{}, processedImage{}, err
+	}
+	thumb, err := encodeImage(resizeToFit(img, ThumbnailDimension), contentType)
+	if err != nil {
+		return processedImage{}, processedImage{}, err
+	}
+	return full, thumb, nil
+}
+
+// encodeImage keeps JPEG photos as JPEG and PNG images (which may be transparent) as PNG.
+func encodeImage(img *image.RGBA, contentType string) (processedImage, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return processedImage{}, err
+	}
+	bounds := img.Bounds()
+	return processedImage{ContentType: contentType, Data: buf.Bytes(), Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+	return dst
+}
+
+// resizeToFit scales img down so its longer side is at most maxSide, averaging the source pixels each output
+// pixel covers. Smaller images are returned as they are.
+func resizeToFit(img *image.RGBA, maxSide int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	scale := float64(maxSide) / float64(max(w, h))
+	dw := max(1, int(math.Round(float64(w)*scale)))
+	dh := max(1, int(math.Round(float64(h)*scale)))
+
+	// Separable box filter in fixed point, one output row at a time so memory stays proportional to the output
+	// width: each source row the output row covers is averaged along its length, then weighted into acc. A source
+	// row straddling two output rows is averaged twice, which is cheaper than buffering every averaged row.
+	cols := areaWeights(w, dw)
+	rows := areaWeights(h, dh)
+	row := make([]uint64, dw*4)
+	acc := make([]uint64, dw*4)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy, rowWeights := range rows {
+		clear(acc)
+		for _, rw := range rowWeights {
+			src := img.Pix[rw.index*img.Stride:]
+			for dx, colWeights := range cols {
+				var sum [4]uint64
+				for _, cw := range colWeights {
+					p := src[cw.index*4:]
+					for c := range sum {
+						sum[c] += uint64(p[c]) * cw.weight
+					}
+				}
+				copy(row[dx*4:], sum[:])
+			}
+			for i, v := range row {
+				acc[i] += v * rw.weight
+			}
+		}
+		out := dst.Pix[dy*dst.Stride:]
+		for i, v := range acc {
+			out[i] = uint8(min(255, (v+weightOne*weightOne/2)/(weightOne*weightOne)))
+		}
+	}
+	return dst
+}
+
+// weightOne is the fixed-point value of a full weight; the weights of one output pixel add up to exactly this.
+const weightOne = 1 << 16
+
+type areaWeight struct {
+	index  int
+	weight uint64
+}
+
+// areaWeights lists, for each of dstLen output pixels, the source pixels it covers and the share of each, in units
+// of weightOne. Rounding is settled on the last share so every output pixel's shares add up to weightOne.
+func areaWeights(srcLen, dstLen int) [][]areaWeight {
+	ratio := float64(srcLen) / float64(dstLen)
+	out := make([][]areaWeight, dstLen)
+	for i := range out {
+		start, end := float64(i)*ratio, float64(i+1)*ratio
+		var total uint64
+		for j := int(start); j < srcLen && float64(j) < end; j++ {
+			cover := math.Min(end, float64(j+1)) - math.Max(start, float64(j))
+			weight := min(uint64(math.Round(cover/ratio*weightOne)), weightOne-total)
+			if cover > 0 && weight > 0 {
+				out[i] = append(out[i], areaWeight{index: j, weight: weight})
+				total += weight
+			}
+		}
+		if n := len(out[i]); n > 0 {
+			out[i][n-1].weight += weightOne - total
+		}
+	}
+	return out
+}
+
+// applyOrientation turns img upright according to an EXIF orientation (1-8).
+func applyOrientation(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			// (sx, sy) is the stored pixel that appears at (x, y) once upright.
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], img.Pix[sy*img.Stride+sx*4:])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag from a JPEG's APP1 segment; 1 (upright) when there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan or end of image: no more metadata
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation finds tag 0x0112 in IFD0 of a TIFF-structured EXIF block.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+			return value
+		}
+	}
+	return 1
+}
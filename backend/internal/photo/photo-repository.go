@@ -0,0 +1,109 @@
+package photo
+
+import (
+	"errors"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Storage interface {
+	Create(photos []models.Photo, limit int64) error
+	GetByID(id uint) (*models.Photo, error)
+	ListByCafeListingID(cafeListingID uint) ([]models.Photo, error)
+	ListByRatingID(ratingID uint) ([]models.Photo, error)
+	CountByCafeListingID(cafeListingID uint) (int64, error)
+	CountByRatingID(ratingID uint) (int64, error)
+	ListOrphaned(limit int) ([]models.Photo, error)
+	Delete(id uint) error
+}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create inserts all photos of one upload, which share a cafe listing or rating, in a single transaction. The
+// parent row is locked while its photos are counted, so concurrent uploads cannot together take it past limit;
+// ErrTooManyPhotos when they would, ErrTargetNotFound when the parent is gone.
+func (r *Repository) Create(photos []models.Photo, limit int64) error {
+	if len(photos) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var parent any
+		var column string
+		var parentID uint
+		switch first := photos[0]; {
+		case first.CafeListingID != nil:
+			parent, column, parentID = &models.Cafe{}, "cafe_listing_id", *first.CafeListingID
+		case first.RatingID != nil:
+			parent, column, parentID = &models.Rating{}, "rating_id", *first.RatingID
+		default:
+			return ErrTargetNotFound
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(parent, parentID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTargetNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.Photo{}).Where(column+" = ?", parentID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count+int64(len(photos)) > limit {
+			return ErrTooManyPhotos
+		}
+		return tx.Create(&photos).Error
+	})
+}
+
+func (r *Repository) GetByID(id uint) (*models.Photo, error) {
+	var p models.Photo
+	err := r.db.First(&p, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &p, err
+}
+
+func (r *Repository) ListByCafeListingID(cafeListingID uint) ([]models.Photo, error) {
+	var photos []models.Photo
+	err := r.db.Where("cafe_listing_id = ?", cafeListingID).Order("id").Find(&photos).Error
+	return photos, err
+}
+
+func (r *Repository) ListByRatingID(ratingID uint) ([]models.Photo, error) {
+	var photos []models.Photo
+	err := r.db.Where("rating_id = ?", ratingID).Order("id").Find(&photos).Error
+	return photos, err
+}
+
+func (r *Repository) CountByCafeListingID(cafeListingID uint) (int64, error) {
+	var n int64
+	err := r.db.Model(&models.Photo{}).Where("cafe_listing_id = ?", cafeListingID).Count(&n).Error
+	return n, err
+}
+
+func (r *Repository) CountByRatingID(ratingID uint) (int64, error) {
+	var n int64
+	err := r.db.Model(&models.Photo{}).Where("rating_id = ?", ratingID).Count(&n).Error
+	return n, err
+}
+
+// ListOrphaned returns photos whose cafe listing or rating was deleted.
+func (r *Repository) ListOrphaned(limit int) ([]models.Photo, error) {
+	var photos []models.Photo
+	err := r.db.Where("cafe_listing_id IS NULL AND rating_id IS NULL").Order("id").Limit(limit).Find(&photos).Error
+	return photos, err
+}
+
+func (r *Repository) Delete(id uint) error {
+	return r.db.Delete(&models.Photo{}, id).Error
+}
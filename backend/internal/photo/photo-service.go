@@ -0,0 +1,408 @@
+package photo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/blob"
+	"github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+)
+
+const (
+	MaxPhotosPerCafe   = 20
+	MaxPhotosPerRating = 6
+	// MaxFilesPerRequest bounds one multipart upload.
+	MaxFilesPerRequest = 10
+	// orphanBatchSize is how many orphaned photos are listed and removed at a time.
+	orphanBatchSize = 20
+)
+
+// BlobStore holds photo files. Implemented by *blob.LocalStore and *blob.S3Store.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStoreFromConfig builds the store selected by PHOTO_STORE; nil for "none".
+func NewBlobStoreFromConfig(cfg *config.PhotoConfig) (BlobStore, error) {
+	switch cfg.Store {
+	case config.PhotoStoreLocal:
+		store, err := blob.NewLocalStore(cfg.LocalDir)
+		if err != nil {
+			return nil, fmt.Errorf("open photo dir: %w", err)
+		}
+		return store, nil
+	case config.PhotoStoreS3:
+		store, err := blob.NewS3Store(blob.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case config.PhotoStoreNone, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported photo store %q", cfg.Store)
+	}
+}
+
+// CafeLookup is satisfied by *cafelisting.Service.
+type CafeLookup interface {
+	GetByID(id uint) (*models.Cafe, error)
+}
+
+// RatingLookup is satisfied by *rating.Service.
+type RatingLookup interface {
+	GetByID(id uint) (*models.Rating, error)
+}
+
+// Upload is one file from a multipart request.
+type Upload struct {
+	Filename string
+	Data     []byte
+}
+
+type Service struct {
+	store   Storage
+	blobs   BlobStore
+	cafes   CafeLookup
+	ratings RatingLookup
+	now     func() time.Time
+}
+
+// NewService takes a nil blobs when photo storage is not configured; uploads and downloads then fail with
+// ErrStorageNotConfigured.
+func NewService(store Storage, blobs BlobStore, cafes CafeLookup, ratings RatingLookup) *Service {
+	return &Service{store: store, blobs: blobs, cafes: cafes, ratings: ratings, now: time.Now}
+}
+
+// UploadForCafe adds photos to a cafe listing the user owns.
+func (s *Service) UploadForCafe(ctx context.Context, userID, cafeListingID uint, uploads []Upload) ([]models.Photo, error) {
+	if err := s.checkCafeOwner(userID, cafeListingID); err != nil {
+		return nil, err
+	}
+	// Checked here to fail before any image work; Create checks again under a lock on the listing.
+	count, err := s.store.CountByCafeListingID(cafeListingID)
+	if err != nil {
+		return nil, err
+	}
+	if count+int64(len(uploads)) > MaxPhotosPerCafe {
+		return nil, ErrTooManyPhotos
+	}
+	return s.upload(ctx, models.Photo{UserID: userID, CafeListingID: &cafeListingID}, uploads, MaxPhotosPerCafe)
+}
+
+// UploadForRating adds photos to a rating the user wrote.
+func (s *Service) UploadForRating(ctx context.Context, userID, ratingID uint, uploads []Upload) ([]models.Photo, error) {
+	if err := s.checkRatingOwner(userID, ratingID); err != nil {
+		return nil, err
+	}
+	count, err := s.store.CountByRatingID(ratingID)
+	if err != nil {
+		return nil, err
+	}
+	if count+int64(len(uploads)) > MaxPhotosPerRating {
+		return nil, ErrTooManyPhotos
+	}
+	return s.upload(ctx, models.Photo{UserID: userID, RatingID: &ratingID}, uploads, MaxPhotosPerRating)
+}
+
+// upload processes every file before storing any, so one bad file rejects the whole request. limit is the most
+// photos the target may hold.
+func (s *Service) upload(ctx context.Context, base models.Photo, uploads []Upload, limit int64) ([]models.Photo, error) {
+	if s.blobs == nil {
+		return nil, ErrStorageNotConfigured
+	}
+	if len(uploads) == 0 {
+		return nil, ErrNoPhotos
+	}
+	if len(uploads) > MaxFilesPerRequest {
+		return nil, ErrTooManyPhotos
+	}
+
+	type processed struct{ full, thumb processedImage }
+	images := make([]processed, 0, len(uploads))
+	for _, upload := range uploads {
+		full, thumb, err := processImage(upload.Data)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, processed{full: full, thumb: thumb})
+	}
+
+	photos := make([]models.Photo, 0, len(images))
+	var stored []string
+	for _, img := range images {
+		key, err := newStorageKey(s.now())
+		if err != nil {
+			return nil, err
+		}
+		ext := extensionFor(img.full.ContentType)
+		photo := base
+		photo.ContentType = img.full.ContentType
+		photo.Width, photo.Height = img.full.Width, img.full.Height
+		photo.SizeBytes = int64(len(img.full.Data))
+		photo.StorageKey = key + ext
+		photo.ThumbnailKey = key + "_thumb" + ext
+		photo.ThumbnailWidth, photo.ThumbnailHeight = img.thumb.Width, img.thumb.Height
+
+		if err := s.blobs.Put(ctx, photo.StorageKey, img.full.Data, img.full.ContentType); err != nil {
+			s.deleteBlobs(stored...)
+			return nil, err
+		}
+		stored = append(stored, photo.StorageKey)
+		if err := s.blobs.Put(ctx, photo.ThumbnailKey, img.thumb.Data, img.thumb.ContentType); err != nil {
+			s.deleteBlobs(stored...)
+			return nil, err
+		}
+		stored = append(stored, photo.ThumbnailKey)
+		photos = append(photos, photo)
+	}
+
+	if err := s.store.Create(photos, limit); err != nil {
+		s.deleteBlobs(stored...)
+		return nil, err
+	}
+	return photos, nil
+}
+
+// ListByCafe lists a cafe's photos. A hidden cafe's photos are listed only for the caller in ctx who added it, or
+// a moderator.
+func (s *Service) ListByCafe(ctx context.Context, cafeListingID uint) ([]models.Photo, error) {
+	listing, err := s.cafes.GetByID(cafeListingID)
+	if err != nil {
+		return nil, err
+	}
+	if !cafeVisible(ctx, listing) {
+		return nil, ErrTargetNotFound
+	}
+	return s.store.ListByCafeListingID(cafeListingID)
+}
+
+// ListByRating lists a rating's photos. Photos of a hidden or held rating, or of a rating on a hidden cafe, are
+// listed only for the caller in ctx who wrote it, or a moderator.
+func (s *Service) ListByRating(ctx context.Context, ratingID uint) ([]models.Photo, error) {
+	rt, err := s.ratings.GetByID(ratingID)
+	if err != nil {
+		return nil, err
+	}
+	if !ratingVisible(ctx, rt) {
+		return nil, ErrTargetNotFound
+	}
+	return s.store.ListByRatingID(ratingID)
+}
+
+// DeleteFromCafe removes a photo from a cafe listing the user owns.
+func (s *Service) DeleteFromCafe(ctx context.Context, userID, cafeListingID, photoID uint) error {
+	if err := s.checkCafeOwner(userID, cafeListingID); err != nil {
+		return err
+	}
+	photo, err := s.store.GetByID(photoID)
+	if err != nil {
+		return err
+	}
+	if photo == nil || photo.CafeListingID == nil || *photo.CafeListingID != cafeListingID {
+		return ErrPhotoNotFound
+	}
+	return s.delete(ctx, photo)
+}
+
+// DeleteFromRating removes a photo from a rating the user wrote.
+func (s *Service) DeleteFromRating(ctx context.Context, userID, ratingID, photoID uint) error {
+	if err := s.checkRatingOwner(userID, ratingID); err != nil {
+		return err
+	}
+	photo, err := s.store.GetByID(photoID)
+	if err != nil {
+		return err
+	}
+	if photo == nil || photo.RatingID == nil || *photo.RatingID != ratingID {
+		return ErrPhotoNotFound
+	}
+	return s.delete(ctx, photo)
+}
+
+// delete removes the record first: a leftover blob is only wasted space, a record without its blob is a broken image.
+func (s *Service) delete(ctx context.Context, photo *models.Photo) error {
+	if s.blobs == nil {
+		return ErrStorageNotConfigured
+	}
+	if err := s.store.Delete(photo.ID); err != nil {
+		return err
+	}
+	s.deleteBlobs(photo.StorageKey, photo.ThumbnailKey)
+	return nil
+}
+
+// Open returns a photo or its thumbnail for download. Photos of deleted cafes and ratings are not served, nor are
+// photos of hidden content to anyone the list methods would not show them to.
+func (s *Service) Open(ctx context.Context, photoID uint, thumbnail bool) (*models.Photo, io.ReadCloser, error) {
+	if s.blobs == nil {
+		return nil, nil, ErrStorageNotConfigured
+	}
+	photo, err := s.store.GetByID(photoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if photo == nil {
+		return nil, nil, ErrPhotoNotFound
+	}
+	if ok, err := s.visible(ctx, photo); err != nil || !ok {
+		if err == nil {
+			err = ErrPhotoNotFound
+		}
+		return nil, nil, err
+	}
+	key := photo.StorageKey
+	if thumbnail {
+		key = photo.ThumbnailKey
+	}
+	body, err := s.blobs.Get(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, nil, ErrPhotoNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return photo, body, nil
+}
+
+// visible reports whether the photo's cafe or rating still exists and the caller in ctx may see it. A soft-deleted
+// target keeps its photos so a restore brings them back, but they are hidden until then.
+func (s *Service) visible(ctx context.Context, photo *models.Photo) (bool, error) {
+	switch {
+	case photo.CafeListingID != nil:
+		listing, err := s.cafes.GetByID(*photo.CafeListingID)
+		return err == nil && cafeVisible(ctx, listing), err
+	case photo.RatingID != nil:
+		rt, err := s.ratings.GetByID(*photo.RatingID)
+		return err == nil && ratingVisible(ctx, rt), err
+	default:
+		return false, nil
+	}
+}
+
+// cafeVisible mirrors the cafe detail endpoint: a hidden cafe is shown only to the user who added it and moderators.
+func cafeVisible(ctx context.Context, listing *models.Cafe) bool {
+	return listing != nil && (listing.HiddenAt == nil || auth.CanViewHidden(ctx, listing.UserID))
+}
+
+// ratingVisible mirrors the rating endpoints: a hidden or held rating, or one on a hidden cafe, is shown only to
+// its author and moderators.
+func ratingVisible(ctx context.Context, rt *models.Rating) bool {
+	if rt == nil {
+		return false
+	}
+	public := rt.HiddenAt == nil && rt.HeldAt == nil && (rt.CafeListing == nil || rt.CafeListing.HiddenAt == nil)
+	return public || auth.CanViewHidden(ctx, rt.UserID)
+}
+
+func (s *Service) checkCafeOwner(userID, cafeListingID uint) error {
+	listing, err := s.cafes.GetByID(cafeListingID)
+	if err != nil {
+		return err
+	}
+	if listing == nil {
+		return ErrTargetNotFound
+	}
+	if listing.UserID != userID {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+func (s *Service) checkRatingOwner(userID, ratingID uint) error {
+	rt, err := s.ratings.GetByID(ratingID)
+	if err != nil {
+		return err
+	}
+	if rt == nil {
+		return ErrTargetNotFound
+	}
+	if rt.UserID != userID {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// RunOrphanCleanup removes orphaned photos every interval until ctx is done. Deletes are soft, so orphans only
+// appear when a cafe or rating row is purged from the database; this keeps their blobs from lingering without
+// putting the work on any request.
+func (s *Service) RunOrphanCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.cleanupOrphans(ctx)
+		}
+	}
+}
+
+// cleanupOrphans deletes photos whose cafe listing or rating is gone, a batch at a time. It stops at the first
+// failure, which is logged and retried on the next run.
+func (s *Service) cleanupOrphans(ctx context.Context) {
+	if s.blobs == nil {
+		return
+	}
+	for ctx.Err() == nil {
+		orphans, err := s.store.ListOrphaned(orphanBatchSize)
+		if err != nil {
+			log.Printf("list orphaned photos: %v", err)
+			return
+		}
+		for i := range orphans {
+			if err := s.delete(ctx, &orphans[i]); err != nil {
+				log.Printf("delete orphaned photo %d: %v", orphans[i].ID, err)
+				return
+			}
+		}
+		if len(orphans) < orphanBatchSize {
+			return
+		}
+	}
+}
+
+// deleteBlobs removes blobs on a best-effort basis, with a fresh context so a cancelled request still cleans up.
+func (s *Service) deleteBlobs(keys ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// newStorageKey is "photos/<yyyy>/<mm>/<random hex>"; the random part keeps keys unguessable and never reused.
+func newStorageKey(now time.Time) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "photos/" + now.UTC().Format("2006/01") + "/" + hex.EncodeToString(b[:]), nil
+}
+
+func extensionFor(contentType string) string {
+	if contentType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
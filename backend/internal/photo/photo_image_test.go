@@ -0,0 +1,146 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / w), G: 120, B: 80, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 segment holding an orientation tag and a GPS marker string right after SOI.
+func withEXIF(jpegData []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)      // one IFD0 entry
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112) // orientation
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)      // SHORT
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, []byte("GPS 1.2833N 103.8500E")...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, app1...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestProcessImage_AppliesOrientationAndStripsEXIF(t *testing.T) {
+	data := withEXIF(testJPEG(t, 40, 20), 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	full, thumb, err := processImage(data)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", full.ContentType)
+	assert.Equal(t, 20, full.Width)
+	assert.Equal(t, 40, full.Height)
+	assert.Equal(t, 20, thumb.Width)
+	assert.False(t, bytes.Contains(full.Data, []byte("Exif")))
+	assert.False(t, bytes.Contains(full.Data, []byte("GPS")))
+	assert.Equal(t, 1, jpegOrientation(full.Data))
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(full.Data))
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Width)
+}
+
+func TestProcessImage_ResizesLargeImages(t *testing.T) {
+	full, thumb, err := processImage(testPNG(t, 3000, 1000))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", full.ContentType)
+	assert.Equal(t, MaxImageDimension, full.Width)
+	assert.Equal(t, 683, full.Height)
+	assert.Equal(t, ThumbnailDimension, thumb.Width)
+	assert.Equal(t, 133, thumb.Height)
+}
+
+func TestProcessImage_RejectsByContentNotName(t *testing.T) {
+	_, _, err := processImage([]byte("GIF89a\x01\x00\x01\x00"))
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+
+	_, _, err = processImage([]byte("<html><body>not a photo</body></html>"))
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+
+	_, _, err = processImage(append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...))
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestApplyOrientation(t *testing.T) {
+	a, b := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, a)
+	src.SetRGBA(1, 0, b)
+
+	rotated := applyOrientation(src, 6) // stored turned 90° counter-clockwise: rotate clockwise
+	require.Equal(t, image.Rect(0, 0, 1, 2), rotated.Bounds())
+	assert.Equal(t, a, rotated.RGBAAt(0, 0))
+	assert.Equal(t, b, rotated.RGBAAt(0, 1))
+
+	rotated = applyOrientation(src, 8)
+	assert.Equal(t, b, rotated.RGBAAt(0, 0))
+	assert.Equal(t, a, rotated.RGBAAt(0, 1))
+
+	mirrored := applyOrientation(src, 2)
+	assert.Equal(t, b, mirrored.RGBAAt(0, 0))
+	assert.Equal(t, a, mirrored.RGBAAt(1, 0))
+}
+
+func TestResizeToFit_AveragesPixels(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	for x, v := range []uint8{0, 100, 200, 40} {
+		src.SetRGBA(x, 0, color.RGBA{R: v, A: 255})
+	}
+	dst := resizeToFit(src, 2)
+	require.Equal(t, image.Rect(0, 0, 2, 1), dst.Bounds())
+	assert.Equal(t, uint8(50), dst.RGBAAt(0, 0).R)
+	assert.Equal(t, uint8(120), dst.RGBAAt(1, 0).R)
+	assert.Equal(t, uint8(255), dst.RGBAAt(1, 0).A)
+}
+
+func TestProcessImage_RejectsTooManyPixels(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4001, 4000))))
+	_, _, err := processImage(buf.Bytes())
+	assert.ErrorIs(t, err, ErrImageDimensions)
+}
+
+func TestAreaWeights_AddUpToOne(t *testing.T) {
+	for _, sizes := range [][2]int{{3000, 2048}, {6324, 2048}, {2048, 400}, {683, 133}, {7, 3}} {
+		for i, weights := range areaWeights(sizes[0], sizes[1]) {
+			var total uint64
+			for _, w := range weights {
+				total += w.weight
+			}
+			require.Equal(t, uint64(weightOne), total, "%v output pixel %d", sizes, i)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package photo
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/blob"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeCafes map[uint]*models.Cafe
+
+func (f fakeCafes) GetByID(id uint) (*models.Cafe, error) { return f[id], nil }
+
+type fakeRatings map[uint]*models.Rating
+
+func (f fakeRatings) GetByID(id uint) (*models.Rating, error) { return f[id], nil }
+
+func newTestService(t *testing.T) (*Service, *Repository, *blob.LocalStore) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Cafe{}, &models.Rating{}, &models.Photo{}))
+	// Create locks the parent row, so the fakes' cafe and rating exist in the database too.
+	require.NoError(t, db.Create(&models.Cafe{ID: 1, UserID: 7, Name: "Cafe"}).Error)
+	require.NoError(t, db.Create(&models.Rating{ID: 5, UserID: 7, CafeListingID: 1, Rating: 4}).Error)
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = blobs.Close() })
+
+	repo := NewRepository(db)
+	cafes := fakeCafes{1: {ID: 1, UserID: 7}}
+	ratings := fakeRatings{5: {ID: 5, UserID: 7, CafeListingID: 1}}
+	return NewService(repo, blobs, cafes, ratings), repo, blobs
+}
+
+func TestUploadForCafe_StoresPhotoAndThumbnail(t *testing.T) {
+	svc, _, blobs := newTestService(t)
+	ctx := context.Background()
+
+	photos, err := svc.UploadForCafe(ctx, 7, 1, []Upload{{Filename: "a.jpg", Data: testJPEG(t, 800, 600)}})
+	require.NoError(t, err)
+	require.Len(t, photos, 1)
+	p := photos[0]
+	assert.NotZero(t, p.ID)
+	assert.Equal(t, uint(1), *p.CafeListingID)
+	assert.Equal(t, ThumbnailDimension, p.ThumbnailWidth)
+	assert.Equal(t, 300, p.ThumbnailHeight)
+	assert.Regexp(t, `^photos/\d{4}/\d{2}/[0-9a-f]{32}\.jpg$`, p.StorageKey)
+
+	_, body, err := svc.Open(ctx, p.ID, true)
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, body.Close())
+	require.NoError(t, err)
+	assert.NotEmpty(t, data)
+
+	listed, err := svc.ListByCafe(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	require.NoError(t, svc.DeleteFromCafe(ctx, 7, 1, p.ID))
+	_, err = blobs.Get(ctx, p.StorageKey)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+	_, _, err = svc.Open(ctx, p.ID, false)
+	assert.ErrorIs(t, err, ErrPhotoNotFound)
+}
+
+func TestUpload_OwnershipAndTargets(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	ctx := context.Background()
+	uploads := []Upload{{Data: testJPEG(t, 10, 10)}}
+
+	_, err := svc.UploadForCafe(ctx, 8, 1, uploads)
+	assert.ErrorIs(t, err, ErrNotOwner)
+	_, err = svc.UploadForRating(ctx, 8, 5, uploads)
+	assert.ErrorIs(t, err, ErrNotOwner)
+	_, err = svc.UploadForCafe(ctx, 7, 2, uploads)
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+	_, err = svc.ListByRating(ctx, 6)
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+
+	photos, err := svc.UploadForRating(ctx, 7, 5, uploads)
+	require.NoError(t, err)
+	err = svc.DeleteFromCafe(ctx, 7, 1, photos[0].ID)
+	assert.ErrorIs(t, err, ErrPhotoNotFound, "a rating photo cannot be deleted through the cafe")
+}
+
+func TestUpload_RejectsWholeBatchOnBadFile(t *testing.T) {
+	svc, repo, _ := newTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.UploadForCafe(ctx, 7, 1, []Upload{{Data: testJPEG(t, 10, 10)}, {Data: []byte("plain text")}})
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+	count, err := repo.CountByCafeListingID(1)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = svc.UploadForCafe(ctx, 7, 1, nil)
+	assert.ErrorIs(t, err, ErrNoPhotos)
+}
+
+func TestUpload_EnforcesPerTargetLimit(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	ctx := context.Background()
+	image := testPNG(t, 4, 4)
+
+	uploads := make([]Upload, MaxPhotosPerRating)
+	for i := range uploads {
+		uploads[i] = Upload{Data: image}
+	}
+	_, err := svc.UploadForRating(ctx, 7, 5, uploads)
+	require.NoError(t, err)
+	_, err = svc.UploadForRating(ctx, 7, 5, uploads[:1])
+	assert.ErrorIs(t, err, ErrTooManyPhotos)
+}
+
+func TestRepository_Create_EnforcesLimitAtInsert(t *testing.T) {
+	_, repo, _ := newTestService(t)
+	cafeID := uint(1)
+	photo := func() models.Photo {
+		return models.Photo{UserID: 7, CafeListingID: &cafeID, ContentType: "image/png", StorageKey: "k", ThumbnailKey: "t"}
+	}
+
+	// Two uploads that each passed the service's early count: the second must not take the cafe past the limit.
+	require.NoError(t, repo.Create([]models.Photo{photo(), photo()}, 3))
+	assert.ErrorIs(t, repo.Create([]models.Photo{photo(), photo()}, 3), ErrTooManyPhotos)
+	count, err := repo.CountByCafeListingID(cafeID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	missing := uint(99)
+	assert.ErrorIs(t, repo.Create([]models.Photo{{UserID: 7, RatingID: &missing}}, 3), ErrTargetNotFound)
+}
+
+func TestCleanupOrphans_RemovesRecordsAndBlobs(t *testing.T) {
+	svc, repo, blobs := newTestService(t)
+	ctx := context.Background()
+
+	photos, err := svc.UploadForRating(ctx, 7, 5, []Upload{{Data: testJPEG(t, 10, 10)}})
+	require.NoError(t, err)
+	orphan := photos[0]
+	// What ON DELETE SET NULL does when the rating row is purged.
+	require.NoError(t, repo.db.Model(&models.Photo{}).Where("id = ?", orphan.ID).Update("rating_id", nil).Error)
+
+	_, _, err = svc.Open(ctx, orphan.ID, false)
+	assert.ErrorIs(t, err, ErrPhotoNotFound)
+
+	// Uploads leave orphans to the background cleanup.
+	kept, err := svc.UploadForCafe(ctx, 7, 1, []Upload{{Data: testJPEG(t, 10, 10)}})
+	require.NoError(t, err)
+	got, err := repo.GetByID(orphan.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+
+	svc.cleanupOrphans(ctx)
+	got, err = repo.GetByID(orphan.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	_, err = blobs.Get(ctx, orphan.StorageKey)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+	got, err = repo.GetByID(kept[0].ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+}
+
+func TestService_StorageNotConfigured(t *testing.T) {
+	_, repo, _ := newTestService(t)
+	svc := NewService(repo, nil, fakeCafes{1: {ID: 1, UserID: 7}}, fakeRatings{})
+	_, err := svc.UploadForCafe(context.Background(), 7, 1, []Upload{{Data: []byte("x")}})
+	assert.ErrorIs(t, err, ErrStorageNotConfigured)
+}
+
+func TestOpen_HidesPhotosOfDeletedTargets(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	photos, err := svc.UploadForRating(ctx, 7, 5, []Upload{{Data: testJPEG(t, 10, 10)}})
+	require.NoError(t, err)
+
+	// A soft-deleted rating no longer resolves, but its photo keeps rating_id for a restore.
+	delete(svc.ratings.(fakeRatings), 5)
+	_, _, err = svc.Open(ctx, photos[0].ID, false)
+	assert.ErrorIs(t, err, ErrPhotoNotFound)
+
+	svc.ratings.(fakeRatings)[5] = &models.Rating{ID: 5, UserID: 7, CafeListingID: 1}
+	_, body, err := svc.Open(ctx, photos[0].ID, false)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+}
+
+func TestPhotosOfHiddenContent_OnlyForAuthorAndModerators(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	ctx := context.Background()
+	uploads := []Upload{{Data: testJPEG(t, 10, 10)}}
+	cafePhotos, err := svc.UploadForCafe(ctx, 7, 1, uploads)
+	require.NoError(t, err)
+	ratingPhotos, err := svc.UploadForRating(ctx, 7, 5, uploads)
+	require.NoError(t, err)
+
+	hiddenAt := time.Now()
+	svc.cafes.(fakeCafes)[1].HiddenAt = &hiddenAt
+	svc.ratings.(fakeRatings)[5].CafeListing = svc.cafes.(fakeCafes)[1]
+
+	author := context.WithValue(ctx, auth.UserIDKey, uint(7))
+	stranger := context.WithValue(ctx, auth.UserIDKey, uint(8))
+	moderator := context.WithValue(stranger, auth.RoleKey, auth.RoleModerator)
+	for name, viewer := range map[string]context.Context{"anonymous": ctx, "stranger": stranger} {
+		_, err = svc.ListByCafe(viewer, 1)
+		assert.ErrorIs(t, err, ErrTargetNotFound, name)
+		_, err = svc.ListByRating(viewer, 5)
+		assert.ErrorIs(t, err, ErrTargetNotFound, name, "a rating on a hidden cafe is hidden too")
+		_, _, err = svc.Open(viewer, cafePhotos[0].ID, false)
+		assert.ErrorIs(t, err, ErrPhotoNotFound, name)
+		_, _, err = svc.Open(viewer, ratingPhotos[0].ID, true)
+		assert.ErrorIs(t, err, ErrPhotoNotFound, name)
+	}
+	for name, viewer := range map[string]context.Context{"author": author, "moderator": moderator} {
+		listed, err := svc.ListByCafe(viewer, 1)
+		require.NoError(t, err, name)
+		assert.Len(t, listed, 1, name)
+		_, body, err := svc.Open(viewer, ratingPhotos[0].ID, false)
+		require.NoError(t, err, name)
+		require.NoError(t, body.Close())
+	}
+
+	// A held rating on a visible cafe hides its photos the same way.
+	svc.cafes.(fakeCafes)[1].HiddenAt = nil
+	svc.ratings.(fakeRatings)[5].HeldAt = &hiddenAt
+	_, err = svc.ListByCafe(ctx, 1)
+	assert.NoError(t, err)
+	_, err = svc.ListByRating(stranger, 5)
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+	_, err = svc.ListByRating(author, 5)
+	assert.NoError(t, err)
+}
@@ -1,8 +1,20 @@
 package rating
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
+)
 
 var ErrNotOwner = errors.New("rating does not belong to this user")
 var ErrCafeNotVisited = errors.New("cafe must be marked visited before rating")
 var ErrInvalidRatingValue = errors.New("rating must be between 1 and 5")
+var ErrInvalidSubScore = errors.New("coffee_score, ambience_score, service_score, value_score and work_score must be between 1 and 5 when set")
 var ErrDuplicateRating = errors.New("you already reviewed this cafe")
+var ErrOwnRatingVote = errors.New("you cannot vote on your own review")
+var ErrAlreadyVoted = errors.New("you already marked this review helpful")
+var ErrVoteNotFound = errors.New("you have not marked this review helpful")
+var ErrReviewRejected = errors.New("review was rejected by content screening")
+var ErrParentDeleted = fmt.Errorf("%w: the rating's cafe listing or author is deleted; restore that instead", audit.ErrRestoreConflict)
+var ErrRestoreDuplicate = fmt.Errorf("%w: the author has since reviewed this cafe again", audit.ErrRestoreConflict)
@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"gorm.io/gorm"
 )
 
@@ -17,44 +18,54 @@ type Handler struct {
 	Service *Service
 }
 
-// RegisterRoutes registers rating routes. authMiddleware is required for create/update/delete and /me.
-func RegisterRoutes(r chi.Router, service *Service, authMiddleware func(http.Handler) http.Handler) {
+// VoteResponse is the helpful count of a rating after a vote is added or withdrawn.
+type VoteResponse struct {
+	RatingID     uint  `json:"rating_id"`
+	HelpfulCount int64 `json:"helpful_count"`
+}
+
+// RegisterRoutes registers rating routes. authMiddleware is required for create/update/delete and /me;
+// writeMiddleware additionally guards create/update (e.g. email verification). Personal access tokens need
+// ratings:read or ratings:write.
+func RegisterRoutes(r chi.Router, service *Service, authMiddleware, writeMiddleware func(http.Handler) http.Handler) {
 	h := &Handler{Service: service}
-	r.Group(func(r chi.Router) {
-		r.Use(authMiddleware)
-		r.Get("/me/ratings", h.ListMyHandler)
-	})
+	readAuth := auth.WithScope(auth.ScopeRatingsRead, authMiddleware)
+	writeAuth := auth.WithScope(auth.ScopeRatingsWrite, authMiddleware)
+	r.With(readAuth).Get("/me/ratings", h.ListMyHandler)
+	// Public reads take an optional login so authors still see their own hidden and held ratings.
+	viewer := auth.Optional(readAuth)
 	r.Route("/cafes/{id}/ratings", func(r chi.Router) {
-		r.Get("/", h.ListByCafeHandler)
-		r.Group(func(r chi.Router) {
-			r.Use(authMiddleware)
-			r.Post("/", h.CreateHandler)
-		})
+		r.With(viewer).Get("/", h.ListByCafeHandler)
+		r.With(writeAuth, writeMiddleware).Post("/", h.CreateHandler)
 	})
 	r.Route("/users/{userId}/ratings", func(r chi.Router) {
-		r.Use(authMiddleware)
-		r.Get("/", h.ListByUserHandler)
+		r.With(readAuth).Get("/", h.ListByUserHandler)
 	})
 	r.Route("/community/places", func(r chi.Router) {
-		r.Get("/{placeId}/ratings", h.ListByExternalPlaceHandler)
+		r.With(viewer).Get("/{placeId}/ratings", h.ListByExternalPlaceHandler)
 	})
 	r.Route("/ratings", func(r chi.Router) {
-		r.Get("/{id}", h.GetByIDHandler)
+		r.With(viewer).Get("/{id}", h.GetByIDHandler)
 		r.Group(func(r chi.Router) {
-			r.Use(authMiddleware)
-			r.Put("/{id}", h.UpdateHandler)
+			r.Use(writeAuth)
+			r.With(writeMiddleware).Put("/{id}", h.UpdateHandler)
 			r.Delete("/{id}", h.DeleteHandler)
+			r.With(writeMiddleware).Post("/{id}/votes", h.VoteHandler)
+			r.Delete("/{id}/votes", h.UnvoteHandler)
 		})
 	})
 }
 
 // ListByExternalPlaceHandler godoc
 // @Summary List ratings by external place ID
-// @Description Returns ratings associated with an external discovery place.
+// @Description Returns ratings associated with an external discovery place, each with its helpful vote count. Ratings hidden by moderators or held by screening, and ratings on a hidden cafe, are left out, except for their author when a bearer token is sent.
 // @Tags ratings
 // @Produce json
 // @Param placeId path string true "External place ID"
-// @Success 200 {array} models.Rating
+// @Param sort query string false "recent (default), helpful, highest or lowest"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.Rating}
 // @Failure 400 {string} string
 // @Failure 500 {string} string
 // @Router /community/places/{placeId}/ratings [get]
@@ -64,9 +75,17 @@ func (h *Handler) ListByExternalPlaceHandler(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "Invalid place ID", http.StatusBadRequest)
 		return
 	}
-
-	ratings, err := h.Service.GetByExternalPlaceID(placeID)
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	ratings, err := h.Service.GetByExternalPlaceID(placeID, listFilter(r), page)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to retrieve ratings", http.StatusInternalServerError)
 		return
 	}
@@ -77,7 +96,7 @@ func (h *Handler) ListByExternalPlaceHandler(w http.ResponseWriter, r *http.Requ
 
 // GetByIDHandler godoc
 // @Summary Get rating by ID
-// @Description Returns a rating by ID.
+// @Description Returns a rating by ID. A rating hidden by moderators or held by screening, or one on a hidden cafe, is 404 except for its author and moderators, who must send a bearer token.
 // @Tags ratings
 // @Produce json
 // @Param id path int true "Rating ID"
@@ -98,7 +117,7 @@ func (h *Handler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to retrieve rating", http.StatusInternalServerError)
 		return
 	}
-	if rating == nil {
+	if rating == nil || (!isPublic(rating) && !auth.CanViewHidden(r.Context(), rating.UserID)) {
 		http.Error(w, "Rating not found", http.StatusNotFound)
 		return
 	}
@@ -106,14 +125,24 @@ func (h *Handler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(rating)
 }
 
+// listFilter reads sort and the optional caller of a public rating list.
+func listFilter(r *http.Request) ListFilter {
+	viewerID, _ := auth.UserIDFromContext(r.Context())
+	return ListFilter{Sort: r.URL.Query().Get("sort"), ViewerID: viewerID}
+}
+
 // ListByCafeHandler godoc
 // @Summary List ratings by cafe
-// @Description Returns ratings for a cafe listing.
+// @Description Returns ratings for a cafe listing, each with its helpful vote count. Ratings hidden by moderators or held by screening, and ratings on a hidden cafe, are left out, except for their author when a bearer token is sent.
 // @Tags ratings
 // @Produce json
 // @Param id path int true "Cafe ID"
-// @Success 200 {array} models.Rating
+// @Param sort query string false "recent (default), helpful, highest or lowest"
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.Rating}
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500 {string} string
 // @Router /cafes/{id}/ratings/ [get]
 func (h *Handler) ListByCafeHandler(w http.ResponseWriter, r *http.Request) {
@@ -123,8 +152,21 @@ func (h *Handler) ListByCafeHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid cafe ID", http.StatusBadRequest)
 		return
 	}
-	ratings, err := h.Service.GetByCafeListingID(uint(cafeID))
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	ratings, err := h.Service.GetByCafeListingID(uint(cafeID), listFilter(r), page)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Cafe listing not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, "Failed to retrieve ratings", http.StatusInternalServerError)
 		return
 	}
@@ -138,7 +180,10 @@ func (h *Handler) ListByCafeHandler(w http.ResponseWriter, r *http.Request) {
 // @Tags ratings
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Rating
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.Rating}
+// @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 500 {string} string
 // @Router /me/ratings [get]
@@ -148,8 +193,17 @@ func (h *Handler) ListMyHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	ratings, err := h.Service.GetByUserID(userID)
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
 	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	ratings, err := h.Service.GetByUserID(userID, page)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to retrieve ratings", http.StatusInternalServerError)
 		return
 	}
@@ -164,7 +218,9 @@ func (h *Handler) ListMyHandler(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerAuth
 // @Param userId path int true "User ID"
-// @Success 200 {array} models.Rating
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.Rating}
 // @Failure 400 {string} string
 // @Failure 401 {string} string
 // @Failure 403 {string} string
@@ -186,8 +242,17 @@ func (h *Handler) ListByUserHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
-	ratings, err := h.Service.GetByUserID(userID)
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
 	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	ratings, err := h.Service.GetByUserID(userID, page)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to retrieve ratings", http.StatusInternalServerError)
 		return
 	}
@@ -197,7 +262,7 @@ func (h *Handler) ListByUserHandler(w http.ResponseWriter, r *http.Request) {
 
 // CreateHandler godoc
 // @Summary Create rating
-// @Description Creates a rating for a cafe listing.
+// @Description Creates a rating for a cafe listing. The review text is screened first: spam-like text is saved with held_at and held_reason set and stays out of public lists until a moderator approves it, and text that fails outright (too long, too many links) is rejected with 400.
 // @Tags ratings
 // @Accept json
 // @Produce json
@@ -231,7 +296,7 @@ func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 	rating.CafeListingID = uint(cafeID)
 	rating.UserID = userID
 	if err := h.Service.CreateRating(&rating); err != nil {
-		if errors.Is(err, ErrCafeNotVisited) || errors.Is(err, ErrInvalidRatingValue) {
+		if errors.Is(err, ErrCafeNotVisited) || errors.Is(err, ErrInvalidRatingValue) || errors.Is(err, ErrInvalidSubScore) || errors.Is(err, ErrReviewRejected) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -252,7 +317,7 @@ func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 
 // UpdateHandler godoc
 // @Summary Update rating
-// @Description Updates a rating by ID.
+// @Description Updates a rating by ID. The review text is screened again, so an edit can hold or release the rating, or be rejected with 400.
 // @Tags ratings
 // @Accept json
 // @Produce json
@@ -288,7 +353,7 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
 		}
-		if errors.Is(err, ErrInvalidRatingValue) {
+		if errors.Is(err, ErrInvalidRatingValue) || errors.Is(err, ErrInvalidSubScore) || errors.Is(err, ErrReviewRejected) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -342,3 +407,94 @@ func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// VoteHandler godoc
+// @Summary Mark rating helpful
+// @Description Adds the authenticated user's helpful vote to a rating. Users vote once per rating and never on their own.
+// @Tags ratings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rating ID"
+// @Success 201 {object} VoteResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /ratings/{id}/votes [post]
+func (h *Handler) VoteHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	count, err := h.Service.Vote(uint(id), userID)
+	if err != nil {
+		if errors.Is(err, ErrOwnRatingVote) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrAlreadyVoted) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Rating not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to record vote", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(VoteResponse{RatingID: uint(id), HelpfulCount: count})
+}
+
+// UnvoteHandler godoc
+// @Summary Withdraw helpful vote
+// @Description Removes the authenticated user's helpful vote from a rating.
+// @Tags ratings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rating ID"
+// @Success 200 {object} VoteResponse
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /ratings/{id}/votes [delete]
+func (h *Handler) UnvoteHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	count, err := h.Service.Unvote(uint(id), userID)
+	if err != nil {
+		if errors.Is(err, ErrVoteNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Rating not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to remove vote", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(VoteResponse{RatingID: uint(id), HelpfulCount: count})
+}
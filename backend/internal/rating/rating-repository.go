@@ -4,18 +4,78 @@ import (
 	"errors"
 
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Storage interface {
 	Create(rating *models.Rating) error
 	GetByID(id uint) (*models.Rating, error)
-	GetByCafeListingID(cafeListingID uint) ([]models.Rating, error)
-	GetByExternalPlaceID(externalPlaceID string) ([]models.Rating, error)
-	GetByUserID(userID uint) ([]models.Rating, error)
+	GetByCafeListingID(cafeListingID uint, filter ListFilter, page pagination.Request) (pagination.Page[models.Rating], error)
+	GetByExternalPlaceID(externalPlaceID string, filter ListFilter, page pagination.Request) (pagination.Page[models.Rating], error)
+	GetByUserID(userID uint, page pagination.Request) (pagination.Page[models.Rating], error)
+	StatsByExternalPlaceIDs(externalPlaceIDs []string) (map[string]PlaceStats, error)
 	FindByUserAndCafe(userID uint, cafeListingID uint) (*models.Rating, error)
 	Update(id uint, updated models.Rating) error
 	Delete(id uint) error
+	GetWithDeleted(id uint) (*models.Rating, error)
+	Restore(id uint) error
+	AddVote(userID, ratingID uint) (bool, error)
+	RemoveVote(userID, ratingID uint) (bool, error)
+	CountVotes(ratingID uint) (int64, error)
+	// Transaction runs fn with a Storage bound to tx, committing only if fn returns nil.
+	Transaction(fn func(tx *gorm.DB, store Storage) error) error
+}
+
+// ListFilter shapes the public rating lists of a cafe or place.
+type ListFilter struct {
+	Sort string
+	// ViewerID is the signed-in caller, whose own hidden and held ratings stay in the list; zero for anonymous callers.
+	ViewerID uint
+}
+
+// publicRatingSQL matches ratings that are neither hidden by a moderator nor held by screening, on a cafe that is not
+// hidden either. Queries using it must join gocafe_cafes.
+const publicRatingSQL = "gocafe_ratings.hidden_at IS NULL AND gocafe_ratings.held_at IS NULL AND gocafe_cafes.hidden_at IS NULL"
+
+// Keyset orders for rating lists. recentOrder (most recent visit first) is the default and the only order for a
+// user's own ratings; cafe and place lists can also sort by helpful votes or by score.
+var (
+	visitedDesc  = pagination.Key[models.Rating]{Column: "gocafe_ratings.visited_at", Desc: true, Value: func(r models.Rating) any { return r.VisitedAt }}
+	ratingIDDesc = pagination.Key[models.Rating]{Column: "gocafe_ratings.id", Desc: true, Value: func(r models.Rating) any { return r.ID }}
+
+	recentOrder = pagination.Order[models.Rating]{Name: "recent", Keys: []pagination.Key[models.Rating]{visitedDesc, ratingIDDesc}}
+
+	cafeRatingOrders = map[string]pagination.Order[models.Rating]{
+		"recent": recentOrder,
+		"helpful": {Name: "helpful", Keys: []pagination.Key[models.Rating]{
+			{Column: "COALESCE(votes.helpful_count, 0)", Desc: true, Value: func(r models.Rating) any { return r.HelpfulCount }},
+			visitedDesc, ratingIDDesc,
+		}},
+		"highest": {Name: "highest", Keys: []pagination.Key[models.Rating]{
+			{Column: "gocafe_ratings.rating", Desc: true, Value: func(r models.Rating) any { return r.Rating }},
+			visitedDesc, ratingIDDesc,
+		}},
+		"lowest": {Name: "lowest", Keys: []pagination.Key[models.Rating]{
+			{Column: "gocafe_ratings.rating", Value: func(r models.Rating) any { return r.Rating }},
+			visitedDesc, ratingIDDesc,
+		}},
+	}
+)
+
+func cafeRatingOrder(sort string) pagination.Order[models.Rating] {
+	if order, ok := cafeRatingOrders[sort]; ok {
+		return order
+	}
+	return recentOrder
+}
+
+// PlaceStats aggregates every rating left on listings saved from one external place.
+type PlaceStats struct {
+	ExternalPlaceID string  `json:"external_place_id"`
+	AvgRating       float64 `json:"avg_rating"`
+	ReviewCount     int64   `json:"review_count"`
 }
 
 type Repository struct {
@@ -32,62 +92,66 @@ func (r *Repository) Create(rt *models.Rating) error {
 
 func (r *Repository) GetByID(id uint) (*models.Rating, error) {
 	var rating models.Rating
-	err := r.db.Preload("User").Preload("CafeListing").First(&rating, id).Error
+	err := r.baseRatingQuery().Preload("User").Preload("CafeListing").Where("gocafe_ratings.id = ?", id).First(&rating).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &rating, err
 }
 
-func (r *Repository) GetByCafeListingID(cafeListingID uint) ([]models.Rating, error) {
-	var cafe models.CafeListing
-	if err := r.db.First(&cafe, cafeListingID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return []models.Rating{}, nil
-		}
-		return nil, err
-	}
-
-	rootID := cafe.ID
-	if cafe.SourceCafeID != nil {
-		rootID = *cafe.SourceCafeID
-	}
-
-	if cafe.ExternalPlaceID != "" {
-		return r.GetByExternalPlaceID(cafe.ExternalPlaceID)
-	}
-
-	var ratings []models.Rating
-	err := r.db.
+func (r *Repository) GetByCafeListingID(cafeListingID uint, filter ListFilter, page pagination.Request) (pagination.Page[models.Rating], error) {
+	q := r.baseRatingQuery().
 		Preload("User").
 		Preload("CafeListing").
-		Joins("JOIN gocafe_cafe_listings ON gocafe_cafe_listings.id = gocafe_ratings.cafe_listing_id").
-		Where("COALESCE(gocafe_cafe_listings.source_cafe_id, gocafe_cafe_listings.id) = ?", rootID).
-		Order("gocafe_ratings.visited_at DESC").
-		Find(&ratings).Error
-	return ratings, err
+		Joins("JOIN gocafe_cafes ON gocafe_cafes.id = gocafe_ratings.cafe_listing_id AND gocafe_cafes.deleted_at IS NULL").
+		Where("gocafe_ratings.cafe_listing_id = ?", cafeListingID).
+		Where("("+publicRatingSQL+") OR gocafe_ratings.user_id = ?", filter.ViewerID)
+	return pagination.Find(q, cafeRatingOrder(filter.Sort), page)
 }
 
-func (r *Repository) GetByExternalPlaceID(externalPlaceID string) ([]models.Rating, error) {
-	var ratings []models.Rating
-	err := r.db.
+func (r *Repository) GetByExternalPlaceID(externalPlaceID string, filter ListFilter, page pagination.Request) (pagination.Page[models.Rating], error) {
+	q := r.baseRatingQuery().
 		Preload("User").
 		Preload("CafeListing").
-		Joins("JOIN gocafe_cafe_listings ON gocafe_cafe_listings.id = gocafe_ratings.cafe_listing_id").
-		Where("gocafe_cafe_listings.external_place_id = ?", externalPlaceID).
-		Order("gocafe_ratings.visited_at DESC").
-		Find(&ratings).Error
-	return ratings, err
+		Joins("JOIN gocafe_cafes ON gocafe_cafes.id = gocafe_ratings.cafe_listing_id AND gocafe_cafes.deleted_at IS NULL").
+		Where("gocafe_cafes.external_place_id = ?", externalPlaceID).
+		Where("("+publicRatingSQL+") OR gocafe_ratings.user_id = ?", filter.ViewerID)
+	return pagination.Find(q, cafeRatingOrder(filter.Sort), page)
 }
 
-func (r *Repository) GetByUserID(userID uint) ([]models.Rating, error) {
-	var ratings []models.Rating
-	err := r.db.
+func (r *Repository) GetByUserID(userID uint, page pagination.Request) (pagination.Page[models.Rating], error) {
+	q := r.baseRatingQuery().
 		Preload("CafeListing").
-		Where("user_id = ?", userID).
-		Order("visited_at DESC").
-		Find(&ratings).Error
-	return ratings, err
+		Where("gocafe_ratings.user_id = ?", userID)
+	return pagination.Find(q, recentOrder, page)
+}
+
+// StatsByExternalPlaceIDs returns stats for the given places in one grouped query; places without visible ratings
+// are absent.
+func (r *Repository) StatsByExternalPlaceIDs(externalPlaceIDs []string) (map[string]PlaceStats, error) {
+	out := make(map[string]PlaceStats, len(externalPlaceIDs))
+	if len(externalPlaceIDs) == 0 {
+		return out, nil
+	}
+	var rows []PlaceStats
+	err := r.db.Model(&models.Rating{}).
+		Select(`
+			gocafe_cafes.external_place_id AS external_place_id,
+			ROUND(AVG(CAST(gocafe_ratings.rating AS numeric)), 2) AS avg_rating,
+			COUNT(gocafe_ratings.id) AS review_count
+		`).
+		Joins("JOIN gocafe_cafes ON gocafe_cafes.id = gocafe_ratings.cafe_listing_id AND gocafe_cafes.deleted_at IS NULL").
+		Where("gocafe_cafes.external_place_id IN ?", externalPlaceIDs).
+		Where(publicRatingSQL).
+		Group("gocafe_cafes.external_place_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.ExternalPlaceID] = row
+	}
+	return out, nil
 }
 
 func (r *Repository) FindByUserAndCafe(userID uint, cafeListingID uint) (*models.Rating, error) {
@@ -107,9 +171,18 @@ func (r *Repository) Update(id uint, updated models.Rating) error {
 	existing.VisitedAt = updated.VisitedAt
 	existing.Rating = updated.Rating
 	existing.Review = updated.Review
-	return r.db.Save(&existing).Error
+	existing.CoffeeScore = updated.CoffeeScore
+	existing.AmbienceScore = updated.AmbienceScore
+	existing.ServiceScore = updated.ServiceScore
+	existing.ValueScore = updated.ValueScore
+	existing.WorkScore = updated.WorkScore
+	existing.HeldAt = updated.HeldAt
+	existing.HeldReason = updated.HeldReason
+	// hidden_at belongs to moderators; leave it out so an edit cannot race a hide or restore.
+	return r.db.Omit("hidden_at").Save(&existing).Error
 }
 
+// Delete soft-deletes the rating; its helpful votes stay and count again if it is restored.
 func (r *Repository) Delete(id uint) error {
 	result := r.db.Delete(&models.Rating{}, id)
 	if result.RowsAffected == 0 {
@@ -117,3 +190,92 @@ func (r *Repository) Delete(id uint) error {
 	}
 	return result.Error
 }
+
+// GetWithDeleted returns the rating whether or not it is soft-deleted, without votes or preloads.
+func (r *Repository) GetWithDeleted(id uint) (*models.Rating, error) {
+	var rating models.Rating
+	err := r.db.Unscoped().First(&rating, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rating, err
+}
+
+// Restore undeletes the rating if its listing and author are live and the author has not reviewed that listing
+// again in the meantime.
+func (r *Repository) Restore(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var rating models.Rating
+		if err := tx.Unscoped().First(&rating, id).Error; err != nil {
+			return err
+		}
+		var listings, authors, duplicates int64
+		if err := tx.Model(&models.Cafe{}).Where("id = ?", rating.CafeListingID).Count(&listings).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", rating.UserID).Count(&authors).Error; err != nil {
+			return err
+		}
+		if listings == 0 || authors == 0 {
+			return ErrParentDeleted
+		}
+		if err := tx.Model(&models.Rating{}).
+			Where("user_id = ? AND cafe_listing_id = ? AND id <> ?", rating.UserID, rating.CafeListingID, id).
+			Count(&duplicates).Error; err != nil {
+			return err
+		}
+		if duplicates > 0 {
+			return ErrRestoreDuplicate
+		}
+		result := tx.Unscoped().Model(&models.Rating{}).Where("id = ? AND deleted_at IS NOT NULL", id).UpdateColumn("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *Repository) Transaction(fn func(tx *gorm.DB, store Storage) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(tx, NewRepository(tx))
+	})
+}
+
+// AddVote records userID's helpful vote on ratingID; false means the user had already voted.
+func (r *Repository) AddVote(userID, ratingID uint) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RatingVote{UserID: userID, RatingID: ratingID})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RemoveVote withdraws userID's vote on ratingID; false means there was none.
+func (r *Repository) RemoveVote(userID, ratingID uint) (bool, error) {
+	result := r.db.Where("user_id = ? AND rating_id = ?", userID, ratingID).Delete(&models.RatingVote{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// liveVotes leaves out votes by soft-deleted accounts.
+func liveVotes(q *gorm.DB) *gorm.DB {
+	return q.Joins("LEFT JOIN gocafe_users ON gocafe_users.id = gocafe_rating_votes.user_id").Where("gocafe_users.deleted_at IS NULL")
+}
+
+func (r *Repository) CountVotes(ratingID uint) (int64, error) {
+	var count int64
+	err := liveVotes(r.db.Model(&models.RatingVote{})).Where("gocafe_rating_votes.rating_id = ?", ratingID).Count(&count).Error
+	return count, err
+}
+
+// baseRatingQuery selects ratings with helpful_count from one grouped vote count joined in, rather than a count
+// per row. Votes by deleted accounts do not count.
+func (r *Repository) baseRatingQuery() *gorm.DB {
+	votesQuery := liveVotes(r.db.Table("gocafe_rating_votes")).
+		Select("gocafe_rating_votes.rating_id, COUNT(*) AS helpful_count").
+		Group("gocafe_rating_votes.rating_id")
+
+	return r.db.
+		Model(&models.Rating{}).
+		Select("gocafe_ratings.*, COALESCE(votes.helpful_count, 0) AS helpful_count").
+		Joins("LEFT JOIN (?) AS votes ON votes.rating_id = gocafe_ratings.id", votesQuery)
+}
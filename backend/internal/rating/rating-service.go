@@ -1,46 +1,93 @@
 package rating
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/khorzhenwin/go-cafe/backend/internal/screening"
+	"gorm.io/gorm"
 )
 
+// ContentScreener checks review text before it is saved. Implemented by *screening.RuleScreener; an implementation
+// backed by an external classifier returns an error when it cannot reach a verdict.
+type ContentScreener interface {
+	Screen(text string) (screening.Verdict, error)
+}
+
+// NewScreenerFromConfig builds the screener selected by REVIEW_SCREENER; nil for "none".
+func NewScreenerFromConfig(cfg appconfig.ScreeningConfig) ContentScreener {
+	if cfg.Screener == appconfig.ReviewScreenerNone {
+		return nil
+	}
+	return screening.NewRuleScreener(screening.Rules{
+		Words:            cfg.Words,
+		MaxLength:        cfg.MaxLength,
+		MaxLinks:         cfg.MaxLinks,
+		MaxRepeatedChars: cfg.MaxRepeatedChars,
+	})
+}
+
+// CafeLookup is satisfied by *cafelisting.Service.
+type CafeLookup interface {
+	GetByID(id uint) (*models.Cafe, error)
+	IsVisitedBy(id, userID uint) (bool, error)
+}
+
 type Service struct {
 	store      Storage
-	cafeLookup interface {
-		IsListingVisited(id uint) (bool, error)
-	}
+	cafeLookup CafeLookup
+	screener   ContentScreener
+	auditor    audit.Recorder
+	now        func() time.Time
 }
 
-func NewService(store Storage, cafeLookup interface {
-	IsListingVisited(id uint) (bool, error)
-}) *Service {
-	return &Service{store: store, cafeLookup: cafeLookup}
+// NewService takes a nil screener to save reviews unscreened, and a nil auditor to keep no audit trail.
+func NewService(store Storage, cafeLookup CafeLookup, screener ContentScreener, auditor audit.Recorder) *Service {
+	return &Service{store: store, cafeLookup: cafeLookup, screener: screener, auditor: auditor, now: time.Now}
 }
 
 func (s *Service) GetByID(id uint) (*models.Rating, error) {
 	return s.store.GetByID(id)
 }
 
-func (s *Service) GetByCafeListingID(cafeListingID uint) ([]models.Rating, error) {
-	return s.store.GetByCafeListingID(cafeListingID)
+// GetByCafeListingID lists the cafe's ratings; gorm.ErrRecordNotFound if the cafe does not exist.
+func (s *Service) GetByCafeListingID(cafeListingID uint, filter ListFilter, page pagination.Request) (pagination.Page[models.Rating], error) {
+	cafe, err := s.cafeLookup.GetByID(cafeListingID)
+	if err != nil {
+		return pagination.Page[models.Rating]{}, err
+	}
+	if cafe == nil {
+		return pagination.Page[models.Rating]{}, gorm.ErrRecordNotFound
+	}
+	filter.Sort = strings.TrimSpace(filter.Sort)
+	return s.store.GetByCafeListingID(cafeListingID, filter, page)
+}
+
+func (s *Service) GetByExternalPlaceID(externalPlaceID string, filter ListFilter, page pagination.Request) (pagination.Page[models.Rating], error) {
+	filter.Sort = strings.TrimSpace(filter.Sort)
+	return s.store.GetByExternalPlaceID(externalPlaceID, filter, page)
 }
 
-func (s *Service) GetByExternalPlaceID(externalPlaceID string) ([]models.Rating, error) {
-	return s.store.GetByExternalPlaceID(externalPlaceID)
+func (s *Service) GetByUserID(userID uint, page pagination.Request) (pagination.Page[models.Rating], error) {
+	return s.store.GetByUserID(userID, page)
 }
 
-func (s *Service) GetByUserID(userID uint) ([]models.Rating, error) {
-	return s.store.GetByUserID(userID)
+func (s *Service) StatsByExternalPlaceIDs(externalPlaceIDs []string) (map[string]PlaceStats, error) {
+	return s.store.StatsByExternalPlaceIDs(externalPlaceIDs)
 }
 
 func (s *Service) CreateRating(rating *models.Rating) error {
 	if err := validateRating(rating); err != nil {
 		return err
 	}
+	rating.HiddenAt, rating.HeldAt, rating.HeldReason = nil, nil, ""
 	if s.cafeLookup != nil {
-		visited, err := s.cafeLookup.IsListingVisited(rating.CafeListingID)
+		visited, err := s.cafeLookup.IsVisitedBy(rating.CafeListingID, rating.UserID)
 		if err != nil {
 			return err
 		}
@@ -55,6 +102,9 @@ func (s *Service) CreateRating(rating *models.Rating) error {
 	if existing != nil {
 		return ErrDuplicateRating
 	}
+	if err := s.screen(rating); err != nil {
+		return err
+	}
 	return s.store.Create(rating)
 }
 
@@ -69,7 +119,20 @@ func (s *Service) UpdateRating(id uint, userID uint, updated models.Rating) erro
 	if err := validateRating(&updated); err != nil {
 		return err
 	}
-	return s.store.Update(id, updated)
+	updated.HeldAt, updated.HeldReason = existing.HeldAt, existing.HeldReason
+	if err := s.screen(&updated); err != nil {
+		return err
+	}
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if err := store.Update(id, updated); err != nil {
+			return err
+		}
+		after, err := store.GetByID(id)
+		if err != nil {
+			return err
+		}
+		return s.record(tx, userID, audit.ActionUpdate, id, existing, after)
+	})
 }
 
 func (s *Service) DeleteRating(id uint, userID uint) error {
@@ -80,13 +143,143 @@ func (s *Service) DeleteRating(id uint, userID uint) error {
 	if existing.UserID != userID {
 		return ErrNotOwner
 	}
-	return s.store.Delete(id)
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if err := store.Delete(id); err != nil {
+			return err
+		}
+		return s.record(tx, userID, audit.ActionDelete, id, existing, nil)
+	})
+}
+
+// Restore brings back a soft-deleted rating (implements audit.Restorer). A rating deleted with its listing or its
+// author's account comes back only with them.
+func (s *Service) Restore(id, actorID uint, deletedSince time.Time) error {
+	deleted, err := s.store.GetWithDeleted(id)
+	if err != nil {
+		return err
+	}
+	if deleted == nil {
+		return gorm.ErrRecordNotFound
+	}
+	if !deleted.DeletedAt.Valid {
+		return audit.ErrNotDeleted
+	}
+	if deleted.DeletedAt.Time.Before(deletedSince) {
+		return audit.ErrRestoreWindowPassed
+	}
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if err := store.Restore(id); err != nil {
+			return err
+		}
+		restored, err := store.GetByID(id)
+		if err != nil {
+			return err
+		}
+		return s.record(tx, actorID, audit.ActionRestore, id, nil, restored)
+	})
+}
+
+// record audits a change to a rating through tx, the transaction making it. The snapshots leave out the preloaded
+// author and listing, which have their own events.
+func (s *Service) record(tx *gorm.DB, actorID uint, action string, id uint, before, after *models.Rating) error {
+	if s.auditor == nil {
+		return nil
+	}
+	change := audit.Change{ActorID: actorID, Action: action, EntityType: audit.EntityRating, EntityID: id}
+	if before != nil {
+		snapshot := *before
+		snapshot.User, snapshot.CafeListing = nil, nil
+		change.Before = snapshot
+	}
+	if after != nil {
+		snapshot := *after
+		snapshot.User, snapshot.CafeListing = nil, nil
+		change.After = snapshot
+	}
+	return s.auditor.Record(tx, change)
+}
+
+// Vote marks a rating helpful for userID and returns its new helpful count.
+func (s *Service) Vote(ratingID uint, userID uint) (int64, error) {
+	existing, err := s.store.GetByID(ratingID)
+	if err != nil {
+		return 0, err
+	}
+	if existing == nil || !isPublic(existing) {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if existing.UserID == userID {
+		return 0, ErrOwnRatingVote
+	}
+	added, err := s.store.AddVote(userID, ratingID)
+	if err != nil {
+		return 0, err
+	}
+	if !added {
+		return 0, ErrAlreadyVoted
+	}
+	return s.store.CountVotes(ratingID)
+}
+
+// Unvote withdraws userID's helpful vote and returns the rating's new helpful count.
+func (s *Service) Unvote(ratingID uint, userID uint) (int64, error) {
+	existing, err := s.store.GetByID(ratingID)
+	if err != nil {
+		return 0, err
+	}
+	if existing == nil {
+		return 0, gorm.ErrRecordNotFound
+	}
+	removed, err := s.store.RemoveVote(userID, ratingID)
+	if err != nil {
+		return 0, err
+	}
+	if !removed {
+		return 0, ErrVoteNotFound
+	}
+	return s.store.CountVotes(ratingID)
+}
+
+// isPublic reports whether a rating is shown to everyone: not hidden or held, and not on a hidden cafe.
+func isPublic(rating *models.Rating) bool {
+	return rating.HiddenAt == nil && rating.HeldAt == nil && (rating.CafeListing == nil || rating.CafeListing.HiddenAt == nil)
+}
+
+// screen runs the review text through the screener and sets the held state from its verdict. A rating that was held
+// keeps its held_at when it is held again, so edits do not move it to the back of the moderation queue; one that
+// now passes is released.
+func (s *Service) screen(rating *models.Rating) error {
+	if s.screener == nil {
+		return nil
+	}
+	verdict, err := s.screener.Screen(rating.Review)
+	if err != nil {
+		return err
+	}
+	switch verdict.Action {
+	case screening.ActionReject:
+		return fmt.Errorf("%w (%s)", ErrReviewRejected, verdict.Reason)
+	case screening.ActionHold:
+		if rating.HeldAt == nil {
+			now := s.now()
+			rating.HeldAt = &now
+		}
+		rating.HeldReason = verdict.Reason
+	default:
+		rating.HeldAt, rating.HeldReason = nil, ""
+	}
+	return nil
 }
 
 func validateRating(rating *models.Rating) error {
 	if rating.Rating < 1 || rating.Rating > 5 {
 		return ErrInvalidRatingValue
 	}
+	for _, score := range []*int{rating.CoffeeScore, rating.AmbienceScore, rating.ServiceScore, rating.ValueScore, rating.WorkScore} {
+		if score != nil && (*score < 1 || *score > 5) {
+			return ErrInvalidSubScore
+		}
+	}
 	rating.Review = strings.TrimSpace(rating.Review)
 	return nil
 }
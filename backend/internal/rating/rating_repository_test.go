@@ -0,0 +1,291 @@
+package rating
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func newRatingTestRepo(t *testing.T) (*Repository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "gocafe_"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Cafe{}, &models.SavedPlace{}, &models.Rating{}, &models.RatingVote{}))
+	return NewRepository(db), db
+}
+
+func ratingIDs(ratings []models.Rating) []uint {
+	ids := make([]uint, len(ratings))
+	for i, r := range ratings {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestRepository_GetByCafeListingID_Pages(t *testing.T) {
+	repo, db := newRatingTestRepo(t)
+	cafe := models.Cafe{UserID: 1, Name: "Root"}
+	require.NoError(t, db.Create(&cafe).Error)
+	other := models.Cafe{UserID: 2, Name: "Elsewhere"}
+	require.NoError(t, db.Create(&other).Error)
+
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	// Ratings 2 and 3 share a visit date, so the id tie-breaker decides their order.
+	visits := []struct {
+		listing uint
+		at      time.Time
+	}{
+		{cafe.ID, day.AddDate(0, 0, -2)},
+		{cafe.ID, day},
+		{cafe.ID, day},
+		{cafe.ID, day.AddDate(0, 0, -1)},
+		{cafe.ID, day.AddDate(0, 0, -3)},
+		{other.ID, day},
+	}
+	for i, v := range visits {
+		require.NoError(t, db.Create(&models.Rating{UserID: uint(10 + i), CafeListingID: v.listing, VisitedAt: v.at, Rating: 4}).Error)
+	}
+
+	var got []uint
+	req := pagination.Request{Limit: 2}
+	for {
+		page, err := repo.GetByCafeListingID(cafe.ID, ListFilter{}, req)
+		require.NoError(t, err)
+		got = append(got, ratingIDs(page.Items)...)
+		if page.NextCursor == nil {
+			break
+		}
+		req.Cursor = *page.NextCursor
+	}
+	assert.Equal(t, []uint{3, 2, 4, 1, 5}, got)
+}
+
+func TestRepository_GetByExternalPlaceID_Sorts(t *testing.T) {
+	repo, db := newRatingTestRepo(t)
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	// Rating 1 has two votes, rating 3 one, ratings 2 and 4 none.
+	scores := []int{3, 5, 4, 2}
+	for i, score := range scores {
+		listing := models.Cafe{UserID: uint(i + 1), Name: "Cafe", ExternalPlaceID: "place-a"}
+		require.NoError(t, db.Create(&listing).Error)
+		require.NoError(t, db.Create(&models.Rating{UserID: uint(i + 1), CafeListingID: listing.ID, VisitedAt: day.AddDate(0, 0, i), Rating: score}).Error)
+	}
+	for _, vote := range [][2]uint{{2, 1}, {3, 1}, {1, 3}} {
+		added, err := repo.AddVote(vote[0], vote[1])
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	added, err := repo.AddVote(2, 1)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	collect := func(sort string) ([]uint, []int64) {
+		var ids []uint
+		var counts []int64
+		req := pagination.Request{Limit: 3}
+		for {
+			page, err := repo.GetByExternalPlaceID("place-a", ListFilter{Sort: sort}, req)
+			require.NoError(t, err)
+			for _, r := range page.Items {
+				ids = append(ids, r.ID)
+				counts = append(counts, r.HelpfulCount)
+			}
+			if page.NextCursor == nil {
+				return ids, counts
+			}
+			req.Cursor = *page.NextCursor
+		}
+	}
+
+	ids, counts := collect("helpful")
+	assert.Equal(t, []uint{1, 3, 4, 2}, ids)
+	assert.Equal(t, []int64{2, 1, 0, 0}, counts)
+	ids, _ = collect("highest")
+	assert.Equal(t, []uint{2, 3, 1, 4}, ids)
+	ids, _ = collect("lowest")
+	assert.Equal(t, []uint{4, 1, 3, 2}, ids)
+	ids, _ = collect("")
+	assert.Equal(t, []uint{4, 3, 2, 1}, ids)
+
+	rating, err := repo.GetByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), rating.HelpfulCount)
+
+	removed, err := repo.RemoveVote(2, 1)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	count, err := repo.CountVotes(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRepository_GetByExternalPlaceID_WithheldVisibleToAuthor(t *testing.T) {
+	repo, db := newRatingTestRepo(t)
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i, state := range []string{"public", "hidden", "held", "hidden cafe"} {
+		listing := models.Cafe{UserID: uint(i + 1), Name: "Cafe", ExternalPlaceID: "place-a"}
+		if state == "hidden cafe" {
+			listing.HiddenAt = &day
+		}
+		require.NoError(t, db.Create(&listing).Error)
+		rating := models.Rating{UserID: uint(i + 1), CafeListingID: listing.ID, VisitedAt: day, Rating: 1}
+		switch state {
+		case "public":
+			rating.Rating = 5
+		case "hidden":
+			rating.HiddenAt = &day
+		case "held":
+			rating.HeldAt, rating.HeldReason = &day, "links"
+		}
+		require.NoError(t, db.Create(&rating).Error)
+	}
+
+	anonymous, err := repo.GetByExternalPlaceID("place-a", ListFilter{}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1}, ratingIDs(anonymous.Items))
+	hiddenAuthor, err := repo.GetByExternalPlaceID("place-a", ListFilter{ViewerID: 2}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2, 1}, ratingIDs(hiddenAuthor.Items))
+	heldAuthor, err := repo.GetByExternalPlaceID("place-a", ListFilter{ViewerID: 3}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3, 1}, ratingIDs(heldAuthor.Items))
+	hiddenCafeAuthor, err := repo.GetByExternalPlaceID("place-a", ListFilter{ViewerID: 4}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{4, 1}, ratingIDs(hiddenCafeAuthor.Items))
+	byHiddenCafe, err := repo.GetByCafeListingID(4, ListFilter{}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, byHiddenCafe.Items)
+
+	stats, err := repo.StatsByExternalPlaceIDs([]string{"place-a"})
+	require.NoError(t, err)
+	assert.Equal(t, PlaceStats{ExternalPlaceID: "place-a", AvgRating: 5, ReviewCount: 1}, stats["place-a"])
+}
+
+func TestRepository_GetByUserID_Pages(t *testing.T) {
+	repo, db := newRatingTestRepo(t)
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		listing := models.Cafe{UserID: 1, Name: "Cafe"}
+		require.NoError(t, db.Create(&listing).Error)
+		require.NoError(t, db.Create(&models.Rating{UserID: 7, CafeListingID: listing.ID, VisitedAt: day.AddDate(0, 0, i/2), Rating: 5}).Error)
+	}
+
+	first, err := repo.GetByUserID(7, pagination.Request{Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{5, 4, 3}, ratingIDs(first.Items))
+	require.NotNil(t, first.NextCursor)
+	require.NotNil(t, first.Items[0].CafeListing)
+
+	second, err := repo.GetByUserID(7, pagination.Request{Limit: 3, Cursor: *first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{2, 1}, ratingIDs(second.Items))
+	assert.Nil(t, second.NextCursor)
+
+	empty, err := repo.GetByUserID(99, pagination.Request{Limit: 3})
+	require.NoError(t, err)
+	assert.NotNil(t, empty.Items)
+	assert.Empty(t, empty.Items)
+}
+
+func TestRepository_StatsByExternalPlaceIDs(t *testing.T) {
+	repo, db := newRatingTestRepo(t)
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	// Two users saved the same place; their ratings are pooled.
+	for i, seed := range []struct {
+		placeID string
+		rating  int
+	}{{"place-a", 5}, {"place-a", 4}, {"place-b", 3}, {"", 1}} {
+		listing := models.Cafe{UserID: uint(i + 1), Name: "Cafe", ExternalPlaceID: seed.placeID}
+		require.NoError(t, db.Create(&listing).Error)
+		require.NoError(t, db.Create(&models.Rating{UserID: uint(i + 1), CafeListingID: listing.ID, VisitedAt: day, Rating: seed.rating}).Error)
+	}
+
+	stats, err := repo.StatsByExternalPlaceIDs([]string{"place-a", "place-b", "place-c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PlaceStats{
+		"place-a": {ExternalPlaceID: "place-a", AvgRating: 4.5, ReviewCount: 2},
+		"place-b": {ExternalPlaceID: "place-b", AvgRating: 3, ReviewCount: 1},
+	}, stats)
+
+	empty, err := repo.StatsByExternalPlaceIDs(nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestRepository_DeleteAndRestore(t *testing.T) {
+	repo, db := newRatingTestRepo(t)
+	at := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, email := range []string{"owner@example.com", "author@example.com", "voter@example.com"} {
+		require.NoError(t, db.Create(&models.User{Email: email}).Error)
+	}
+	listing := models.Cafe{UserID: 1, Name: "Bloom", ExternalPlaceID: "geo:bloom"}
+	require.NoError(t, db.Create(&listing).Error)
+	rating := models.Rating{UserID: 2, CafeListingID: listing.ID, VisitedAt: at, Rating: 5}
+	require.NoError(t, db.Create(&rating).Error)
+	require.NoError(t, db.Create(&models.RatingVote{UserID: 3, RatingID: rating.ID}).Error)
+
+	// Votes by a deleted account no longer count.
+	require.NoError(t, db.Delete(&models.User{}, 3).Error)
+	votes, err := repo.CountVotes(rating.ID)
+	require.NoError(t, err)
+	assert.Zero(t, votes)
+
+	require.NoError(t, repo.Delete(rating.ID))
+	got, err := repo.GetByID(rating.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	page, err := repo.GetByExternalPlaceID("geo:bloom", ListFilter{ViewerID: 2}, pagination.Request{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items, "deleted ratings are gone even for their author")
+	stats, err := repo.StatsByExternalPlaceIDs([]string{"geo:bloom"})
+	require.NoError(t, err)
+	assert.Empty(t, stats)
+
+	// The author reviewed the cafe again, so the old rating cannot come back beside the new one.
+	again := models.Rating{UserID: 2, CafeListingID: listing.ID, VisitedAt: at, Rating: 3}
+	require.NoError(t, db.Create(&again).Error)
+	assert.ErrorIs(t, repo.Restore(rating.ID), ErrRestoreDuplicate)
+	require.NoError(t, repo.Delete(again.ID))
+
+	require.NoError(t, db.Delete(&models.Cafe{}, listing.ID).Error)
+	assert.ErrorIs(t, repo.Restore(rating.ID), ErrParentDeleted)
+	require.NoError(t, db.Unscoped().Model(&models.Cafe{}).Where("id = ?", listing.ID).Update("deleted_at", nil).Error)
+
+	require.NoError(t, repo.Restore(rating.ID))
+	got, err = repo.GetByID(rating.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.ErrorIs(t, repo.Restore(rating.ID), gorm.ErrRecordNotFound)
+}
+
+func TestService_DeleteRating_AuditInSameTransaction(t *testing.T) {
+	repo, db := newRatingTestRepo(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditEvent{}))
+	rating := models.Rating{UserID: 2, CafeListingID: 1, VisitedAt: time.Now(), Rating: 4}
+	require.NoError(t, db.Create(&rating).Error)
+
+	failing := NewService(repo, nil, nil, &recordingAuditor{err: errors.New("audit table unavailable")})
+	require.Error(t, failing.DeleteRating(rating.ID, 2))
+	got, err := repo.GetByID(rating.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got, "a failed audit write rolls the delete back")
+
+	svc := NewService(repo, nil, nil, audit.NewService(audit.NewRepository(db), 0))
+	require.NoError(t, svc.DeleteRating(rating.ID, 2))
+	var events []models.AuditEvent
+	require.NoError(t, db.Find(&events).Error)
+	require.Len(t, events, 1)
+	assert.Equal(t, audit.ActionDelete, events[0].Action)
+	assert.Equal(t, rating.ID, events[0].EntityID)
+}
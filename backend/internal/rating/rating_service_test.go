@@ -1,19 +1,30 @@
 package rating
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/khorzhenwin/go-cafe/backend/internal/screening"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 type mockCafeLookup struct {
 	visited bool
 	err     error
+	cafe    *models.Cafe
 }
 
-func (m *mockCafeLookup) IsListingVisited(id uint) (bool, error) {
+func (m *mockCafeLookup) GetByID(id uint) (*models.Cafe, error) {
+	return m.cafe, m.err
+}
+
+func (m *mockCafeLookup) IsVisitedBy(id, userID uint) (bool, error) {
 	if m.err != nil {
 		return false, m.err
 	}
@@ -26,6 +37,11 @@ type mockRatingStorage struct {
 	createErr error
 	updateErr error
 	deleteErr error
+	updated   *models.Rating
+	votes     map[[2]uint]bool
+
+	withDeleted *models.Rating
+	restored    bool
 }
 
 func (m *mockRatingStorage) Create(r *models.Rating) error {
@@ -39,28 +55,32 @@ func (m *mockRatingStorage) Create(r *models.Rating) error {
 
 func (m *mockRatingStorage) GetByID(id uint) (*models.Rating, error) { return m.getByID, nil }
 
-func (m *mockRatingStorage) GetByCafeListingID(id uint) ([]models.Rating, error) {
-	var out []models.Rating
+func (m *mockRatingStorage) GetByCafeListingID(id uint, filter ListFilter, page pagination.Request) (pagination.Page[models.Rating], error) {
+	out := []models.Rating{}
 	for _, r := range m.ratings {
 		if r.CafeListingID == id {
 			out = append(out, r)
 		}
 	}
-	return out, nil
+	return pagination.Page[models.Rating]{Items: out}, nil
 }
 
-func (m *mockRatingStorage) GetByUserID(id uint) ([]models.Rating, error) {
-	var out []models.Rating
+func (m *mockRatingStorage) GetByUserID(id uint, page pagination.Request) (pagination.Page[models.Rating], error) {
+	out := []models.Rating{}
 	for _, r := range m.ratings {
 		if r.UserID == id {
 			out = append(out, r)
 		}
 	}
-	return out, nil
+	return pagination.Page[models.Rating]{Items: out}, nil
+}
+
+func (m *mockRatingStorage) GetByExternalPlaceID(externalPlaceID string, filter ListFilter, page pagination.Request) (pagination.Page[models.Rating], error) {
+	return pagination.Page[models.Rating]{Items: []models.Rating{}}, nil
 }
 
-func (m *mockRatingStorage) GetByExternalPlaceID(externalPlaceID string) ([]models.Rating, error) {
-	return []models.Rating{}, nil
+func (m *mockRatingStorage) StatsByExternalPlaceIDs(externalPlaceIDs []string) (map[string]PlaceStats, error) {
+	return map[string]PlaceStats{}, nil
 }
 
 func (m *mockRatingStorage) FindByUserAndCafe(userID uint, cafeListingID uint) (*models.Rating, error) {
@@ -73,13 +93,73 @@ func (m *mockRatingStorage) FindByUserAndCafe(userID uint, cafeListingID uint) (
 	return nil, nil
 }
 
-func (m *mockRatingStorage) Update(id uint, updated models.Rating) error { return m.updateErr }
+func (m *mockRatingStorage) Update(id uint, updated models.Rating) error {
+	m.updated = &updated
+	return m.updateErr
+}
 
 func (m *mockRatingStorage) Delete(id uint) error { return m.deleteErr }
 
+func (m *mockRatingStorage) GetWithDeleted(id uint) (*models.Rating, error) {
+	return m.withDeleted, nil
+}
+
+func (m *mockRatingStorage) Restore(id uint) error {
+	m.restored = true
+	return nil
+}
+
+func (m *mockRatingStorage) Transaction(fn func(tx *gorm.DB, store Storage) error) error {
+	return fn(nil, m)
+}
+
+type recordingAuditor struct {
+	changes []audit.Change
+	err     error
+}
+
+func (r *recordingAuditor) Record(tx *gorm.DB, change audit.Change) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func (m *mockRatingStorage) AddVote(userID, ratingID uint) (bool, error) {
+	if m.votes == nil {
+		m.votes = map[[2]uint]bool{}
+	}
+	key := [2]uint{userID, ratingID}
+	if m.votes[key] {
+		return false, nil
+	}
+	m.votes[key] = true
+	return true, nil
+}
+
+func (m *mockRatingStorage) RemoveVote(userID, ratingID uint) (bool, error) {
+	key := [2]uint{userID, ratingID}
+	if !m.votes[key] {
+		return false, nil
+	}
+	delete(m.votes, key)
+	return true, nil
+}
+
+func (m *mockRatingStorage) CountVotes(ratingID uint) (int64, error) {
+	var count int64
+	for key := range m.votes {
+		if key[1] == ratingID {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func TestService_CreateRating(t *testing.T) {
 	m := &mockRatingStorage{}
-	svc := NewService(m, &mockCafeLookup{visited: true})
+	svc := NewService(m, &mockCafeLookup{visited: true}, nil, nil)
 	r := &models.Rating{UserID: 1, CafeListingID: 2, Rating: 5}
 	err := svc.CreateRating(r)
 	require.NoError(t, err)
@@ -89,7 +169,7 @@ func TestService_CreateRating(t *testing.T) {
 
 func TestService_CreateRating_RequiresVisitedCafe(t *testing.T) {
 	m := &mockRatingStorage{}
-	svc := NewService(m, &mockCafeLookup{visited: false})
+	svc := NewService(m, &mockCafeLookup{visited: false}, nil, nil)
 	r := &models.Rating{UserID: 1, CafeListingID: 2, Rating: 5}
 	err := svc.CreateRating(r)
 	assert.ErrorIs(t, err, ErrCafeNotVisited)
@@ -100,7 +180,7 @@ func TestService_CreateRating_Duplicate(t *testing.T) {
 	m := &mockRatingStorage{
 		ratings: []models.Rating{{ID: 1, UserID: 1, CafeListingID: 2, Rating: 4}},
 	}
-	svc := NewService(m, &mockCafeLookup{visited: true})
+	svc := NewService(m, &mockCafeLookup{visited: true}, nil, nil)
 	r := &models.Rating{UserID: 1, CafeListingID: 2, Rating: 5}
 	err := svc.CreateRating(r)
 	assert.ErrorIs(t, err, ErrDuplicateRating)
@@ -108,22 +188,149 @@ func TestService_CreateRating_Duplicate(t *testing.T) {
 
 func TestService_CreateRating_InvalidValue(t *testing.T) {
 	m := &mockRatingStorage{}
-	svc := NewService(m, &mockCafeLookup{visited: true})
+	svc := NewService(m, &mockCafeLookup{visited: true}, nil, nil)
 	r := &models.Rating{UserID: 1, CafeListingID: 2, Rating: 6}
 	err := svc.CreateRating(r)
 	assert.ErrorIs(t, err, ErrInvalidRatingValue)
 }
 
+func TestService_CreateRating_SubScores(t *testing.T) {
+	m := &mockRatingStorage{}
+	svc := NewService(m, &mockCafeLookup{visited: true}, nil, nil)
+	score := func(n int) *int { return &n }
+
+	require.NoError(t, svc.CreateRating(&models.Rating{UserID: 1, CafeListingID: 2, Rating: 4, CoffeeScore: score(5), WorkScore: score(1)}))
+	require.Len(t, m.ratings, 1)
+	assert.Nil(t, m.ratings[0].AmbienceScore)
+
+	for _, r := range []models.Rating{
+		{UserID: 2, CafeListingID: 2, Rating: 4, CoffeeScore: score(0)},
+		{UserID: 2, CafeListingID: 2, Rating: 4, AmbienceScore: score(6)},
+		{UserID: 2, CafeListingID: 2, Rating: 4, ServiceScore: score(-1)},
+		{UserID: 2, CafeListingID: 2, Rating: 4, ValueScore: score(10)},
+		{UserID: 2, CafeListingID: 2, Rating: 4, WorkScore: score(0)},
+	} {
+		assert.ErrorIs(t, svc.CreateRating(&r), ErrInvalidSubScore)
+	}
+}
+
+func TestService_CreateRating_Screening(t *testing.T) {
+	m := &mockRatingStorage{}
+	svc := NewService(m, &mockCafeLookup{visited: true}, screening.NewRuleScreener(screening.Rules{Words: []string{"casino"}}), nil)
+
+	require.NoError(t, svc.CreateRating(&models.Rating{UserID: 1, CafeListingID: 2, Rating: 4, Review: "Lovely crema"}))
+	require.NoError(t, svc.CreateRating(&models.Rating{UserID: 2, CafeListingID: 2, Rating: 5, Review: "Win big at our casino"}))
+	require.Len(t, m.ratings, 2)
+	assert.Nil(t, m.ratings[0].HeldAt)
+	require.NotNil(t, m.ratings[1].HeldAt)
+	assert.Equal(t, screening.ReasonWordList, m.ratings[1].HeldReason)
+
+	err := svc.CreateRating(&models.Rating{UserID: 3, CafeListingID: 2, Rating: 5, Review: "a.com b.com c.com"})
+	assert.ErrorIs(t, err, ErrReviewRejected)
+	assert.Len(t, m.ratings, 2)
+}
+
+func TestService_UpdateRating_Screening(t *testing.T) {
+	heldAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	m := &mockRatingStorage{getByID: &models.Rating{ID: 1, UserID: 10, HeldAt: &heldAt, HeldReason: screening.ReasonLinks}}
+	svc := NewService(m, nil, screening.NewRuleScreener(screening.Rules{}), nil)
+
+	// Still spammy: stays held with its original place in the queue.
+	require.NoError(t, svc.UpdateRating(1, 10, models.Rating{Rating: 4, Review: "Call 9123 4567"}))
+	require.NotNil(t, m.updated.HeldAt)
+	assert.Equal(t, heldAt, *m.updated.HeldAt)
+	assert.Equal(t, screening.ReasonPhone, m.updated.HeldReason)
+
+	// Cleaned up: released.
+	require.NoError(t, svc.UpdateRating(1, 10, models.Rating{Rating: 4, Review: "Good pour-over"}))
+	assert.Nil(t, m.updated.HeldAt)
+	assert.Empty(t, m.updated.HeldReason)
+
+	assert.ErrorIs(t, svc.UpdateRating(1, 10, models.Rating{Rating: 4, Review: strings.Repeat("x ", 1500)}), ErrReviewRejected)
+}
+
 func TestService_UpdateRating_NotOwner(t *testing.T) {
 	m := &mockRatingStorage{getByID: &models.Rating{ID: 1, UserID: 10}}
-	svc := NewService(m, nil)
+	svc := NewService(m, nil, nil, nil)
 	err := svc.UpdateRating(1, 99, models.Rating{Rating: 4})
 	assert.ErrorIs(t, err, ErrNotOwner)
 }
 
 func TestService_DeleteRating_NotOwner(t *testing.T) {
 	m := &mockRatingStorage{getByID: &models.Rating{ID: 1, UserID: 10}}
-	svc := NewService(m, nil)
+	svc := NewService(m, nil, nil, nil)
 	err := svc.DeleteRating(1, 99)
 	assert.ErrorIs(t, err, ErrNotOwner)
 }
+
+func TestService_DeleteAndRestore_Audited(t *testing.T) {
+	rating := &models.Rating{ID: 1, UserID: 10, Rating: 4, User: &models.User{ID: 10}, CafeListing: &models.Cafe{ID: 3}}
+	m := &mockRatingStorage{getByID: rating}
+	auditor := &recordingAuditor{}
+	svc := NewService(m, nil, nil, auditor)
+
+	require.NoError(t, svc.DeleteRating(1, 10))
+	require.Len(t, auditor.changes, 1)
+	deleted := auditor.changes[0]
+	assert.Equal(t, audit.ActionDelete, deleted.Action)
+	assert.Equal(t, audit.EntityRating, deleted.EntityType)
+	assert.Nil(t, deleted.After)
+	before, ok := deleted.Before.(models.Rating)
+	require.True(t, ok)
+	assert.Equal(t, 4, before.Rating)
+	assert.Nil(t, before.User, "the author has its own audit events")
+	assert.Nil(t, before.CafeListing)
+
+	since := time.Now().Add(-time.Hour)
+	assert.ErrorIs(t, svc.Restore(1, 99, since), gorm.ErrRecordNotFound)
+	m.withDeleted = &models.Rating{ID: 1, UserID: 10}
+	assert.ErrorIs(t, svc.Restore(1, 99, since), audit.ErrNotDeleted)
+	m.withDeleted.DeletedAt = gorm.DeletedAt{Time: since.Add(-time.Minute), Valid: true}
+	assert.ErrorIs(t, svc.Restore(1, 99, since), audit.ErrRestoreWindowPassed)
+	assert.False(t, m.restored)
+
+	m.withDeleted.DeletedAt.Time = since.Add(time.Minute)
+	require.NoError(t, svc.Restore(1, 99, since))
+	assert.True(t, m.restored)
+	require.Len(t, auditor.changes, 2)
+	assert.Equal(t, audit.ActionRestore, auditor.changes[1].Action)
+	assert.Equal(t, uint(99), auditor.changes[1].ActorID)
+}
+
+func TestService_Vote(t *testing.T) {
+	m := &mockRatingStorage{getByID: &models.Rating{ID: 1, UserID: 10}}
+	svc := NewService(m, nil, nil, nil)
+
+	_, err := svc.Vote(1, 10)
+	assert.ErrorIs(t, err, ErrOwnRatingVote)
+
+	count, err := svc.Vote(1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	_, err = svc.Vote(1, 20)
+	assert.ErrorIs(t, err, ErrAlreadyVoted)
+
+	count, err = svc.Vote(1, 30)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = svc.Unvote(1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	_, err = svc.Unvote(1, 20)
+	assert.ErrorIs(t, err, ErrVoteNotFound)
+
+	missing := NewService(&mockRatingStorage{}, nil, nil, nil)
+	_, err = missing.Vote(1, 20)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestService_GetByCafeListingID_MissingCafe(t *testing.T) {
+	svc := NewService(&mockRatingStorage{}, &mockCafeLookup{}, nil, nil)
+	_, err := svc.GetByCafeListingID(9, ListFilter{}, pagination.Request{Limit: 20})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	svc = NewService(&mockRatingStorage{}, &mockCafeLookup{cafe: &models.Cafe{ID: 9}}, nil, nil)
+	_, err = svc.GetByCafeListingID(9, ListFilter{}, pagination.Request{Limit: 20})
+	assert.NoError(t, err)
+}
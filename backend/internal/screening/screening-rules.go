@@ -0,0 +1,177 @@
+// Package screening checks user-written review text for obvious spam and abuse before it is saved, using local
+// rules only.
+package screening
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ActionAllow  = "allow"
+	ActionHold   = "hold"   // save, but keep out of public view until a moderator approves it
+	ActionReject = "reject" // refuse to save
+)
+
+// Reasons name the rule behind a hold or reject.
+const (
+	ReasonTooLong      = "too_long"
+	ReasonWordList     = "word_list"
+	ReasonLinks        = "links"
+	ReasonPhone        = "phone_number"
+	ReasonFlooding     = "repeated_characters"
+	ReasonTooManyLinks = "too_many_links"
+)
+
+// Verdict is the outcome of screening one text. Reason is empty when Action is ActionAllow.
+type Verdict struct {
+	Action string
+	Reason string
+}
+
+// Rules configure a RuleScreener. Zero limits take the DefaultRules value.
+type Rules struct {
+	Words            []string // whole words or phrases, matched case-insensitively; a match holds the text
+	MaxLength        int      // longer texts (in characters) are rejected
+	MaxLinks         int      // any link holds the text; more than this many rejects it
+	MaxRepeatedChars int      // a longer run of one repeated character holds the text
+}
+
+var DefaultRules = Rules{
+	MaxLength:        2000,
+	MaxLinks:         2,
+	MaxRepeatedChars: 8,
+}
+
+var (
+	// linkPattern matches URLs, www. hosts and bare domains on TLDs favoured by spam.
+	linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)*\.(?:com|net|org|info|biz|io|co|me|ly|xyz|top|shop|app|link|click)\b`)
+	// phonePattern matches runs of digits with the usual separators; hasPhoneNumber decides which runs count.
+	phonePattern = regexp.MustCompile(`\+?\(?\d[\d\s().-]{6,}\d`)
+	datePattern  = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
+	// clockOrPricePattern matches times and prices such as 10.30, 7:00 or 4.50, which reviews list in runs.
+	clockOrPricePattern = regexp.MustCompile(`\b\d{1,2}[.:]\d{2}\b`)
+	digitGroupPattern   = regexp.MustCompile(`\d{3,}`)
+)
+
+const minPhoneDigits = 8
+
+// wordEdge guards a word-list entry's edge that is a letter or digit, so the entry only matches as a whole word.
+// Unlike \b it knows non-ASCII letters, and it is left off edges like "$$$" where \b would never match.
+const wordEdge = `[^\pL\pN_]`
+
+// RuleScreener is the built-in local screener. Rejects come from length and link count; holds come from the word
+// list, links, phone numbers and repeated-character flooding. It makes no network calls.
+type RuleScreener struct {
+	rules Rules
+	words *regexp.Regexp
+}
+
+func NewRuleScreener(rules Rules) *RuleScreener {
+	if rules.MaxLength <= 0 {
+		rules.MaxLength = DefaultRules.MaxLength
+	}
+	if rules.MaxLinks <= 0 {
+		rules.MaxLinks = DefaultRules.MaxLinks
+	}
+	if rules.MaxRepeatedChars <= 0 {
+		rules.MaxRepeatedChars = DefaultRules.MaxRepeatedChars
+	}
+	s := &RuleScreener{rules: rules}
+	var quoted []string
+	for _, word := range rules.Words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, wordPattern(word))
+		}
+	}
+	if len(quoted) > 0 {
+		s.words = regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+	}
+	return s
+}
+
+// Screen applies the rules to text. A reject outranks a hold, and the first matching rule in each group names the
+// reason. The error is always nil; it is there for classifiers that call out to a service.
+func (s *RuleScreener) Screen(text string) (Verdict, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Verdict{Action: ActionAllow}, nil
+	}
+	if utf8.RuneCountInString(text) > s.rules.MaxLength {
+		return Verdict{Action: ActionReject, Reason: ReasonTooLong}, nil
+	}
+	links := len(linkPattern.FindAllStringIndex(text, -1))
+	if links > s.rules.MaxLinks {
+		return Verdict{Action: ActionReject, Reason: ReasonTooManyLinks}, nil
+	}
+
+	switch {
+	case s.words != nil && s.words.MatchString(text):
+		return Verdict{Action: ActionHold, Reason: ReasonWordList}, nil
+	case links > 0:
+		return Verdict{Action: ActionHold, Reason: ReasonLinks}, nil
+	case hasPhoneNumber(text):
+		return Verdict{Action: ActionHold, Reason: ReasonPhone}, nil
+	case longestRun(text) > s.rules.MaxRepeatedChars:
+		return Verdict{Action: ActionHold, Reason: ReasonFlooding}, nil
+	}
+	return Verdict{Action: ActionAllow}, nil
+}
+
+// wordPattern matches one word-list entry, guarding each edge that is a letter or digit.
+func wordPattern(word string) string {
+	pattern := regexp.QuoteMeta(word)
+	if first, _ := utf8.DecodeRuneInString(word); isWordRune(first) {
+		pattern = `(?:^|` + wordEdge + `)` + pattern
+	}
+	if last, _ := utf8.DecodeLastRuneInString(word); isWordRune(last) {
+		pattern += `(?:` + wordEdge + `|$)`
+	}
+	return `(?:` + pattern + `)`
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// hasPhoneNumber looks for digit runs long enough to be a phone number. ISO dates, times and prices are blanked out
+// first, and a run must start with + or ( or hold a group of at least three digits, so a visit date, opening hours
+// or a price list is not mistaken for one.
+func hasPhoneNumber(text string) bool {
+	text = datePattern.ReplaceAllString(text, " ")
+	text = clockOrPricePattern.ReplaceAllString(text, " ")
+	for _, match := range phonePattern.FindAllString(text, -1) {
+		digits := 0
+		for _, c := range match {
+			if c >= '0' && c <= '9' {
+				digits++
+			}
+		}
+		if digits < minPhoneDigits {
+			continue
+		}
+		if strings.HasPrefix(match, "+") || strings.HasPrefix(match, "(") || digitGroupPattern.MatchString(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// longestRun returns the longest run of one repeated non-space character, ignoring case.
+func longestRun(text string) int {
+	longest, run := 0, 0
+	var prev rune = -1
+	for _, c := range text {
+		c = unicode.ToLower(c)
+		if c == prev && !unicode.IsSpace(c) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = c
+		longest = max(longest, run)
+	}
+	return longest
+}
@@ -0,0 +1,61 @@
+package screening
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleScreener_Screen(t *testing.T) {
+	screener := NewRuleScreener(Rules{Words: []string{"casino", "free money", "$$$", "@@", "f*ck", "crème"}, MaxLength: 200})
+
+	tests := []struct {
+		name   string
+		text   string
+		want   string
+		reason string
+	}{
+		{"empty", "   ", ActionAllow, ""},
+		{"plain review", "Great flat white, visited 2026-10-17 at 10am. Sooooo cosy!", ActionAllow, ""},
+		{"too long", strings.Repeat("good coffee ", 20), ActionReject, ReasonTooLong},
+		{"word list", "Best CASINO vibes in town", ActionHold, ReasonWordList},
+		{"phrase", "They gave me free money", ActionHold, ReasonWordList},
+		{"word inside another word", "The casinos nearby are loud", ActionAllow, ""},
+		{"entry of symbols", "Make $$$ from home", ActionHold, ReasonWordList},
+		{"entry of symbols inside a word", "DM me@@now", ActionHold, ReasonWordList},
+		{"entry with a symbol inside", "What the F*CK is this", ActionHold, ReasonWordList},
+		{"non-ASCII entry", "Too much crème on top", ActionHold, ReasonWordList},
+		{"non-ASCII entry inside a word", "The crèmes were fine", ActionAllow, ""},
+		{"url", "Menu at https://example.org/menu", ActionHold, ReasonLinks},
+		{"bare domain", "order at cheapbeans.xyz", ActionHold, ReasonLinks},
+		{"too many links", "a.com b.com c.com", ActionReject, ReasonTooManyLinks},
+		{"phone", "Call +65 9123 4567 for deals", ActionHold, ReasonPhone},
+		{"short number", "Table 12, paid 4.50", ActionAllow, ""},
+		{"phone with hyphens", "WhatsApp 555-123-4567", ActionHold, ReasonPhone},
+		{"phone in parentheses", "Ring (02) 98 76 54 32", ActionHold, ReasonPhone},
+		{"opening hours", "Open 10.30-22.30 daily, 7:00 - 9:30 on weekends", ActionAllow, ""},
+		{"price list", "Latte 4.50 3.80 4.20 and cake 5.10", ActionAllow, ""},
+		{"short digit groups", "Rated 5 4 5 3 4 5 4 5 across visits", ActionAllow, ""},
+		{"flooding", "Nice!!!!!!!!!!!!", ActionHold, ReasonFlooding},
+		{"mixed case flooding", "NOooooOOOOoo", ActionHold, ReasonFlooding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := screener.Screen(tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, verdict.Action)
+			assert.Equal(t, tt.reason, verdict.Reason)
+		})
+	}
+}
+
+func TestNewRuleScreener_Defaults(t *testing.T) {
+	screener := NewRuleScreener(Rules{})
+	assert.Equal(t, DefaultRules, screener.rules)
+
+	verdict, err := screener.Screen(strings.Repeat("a b ", 600))
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{Action: ActionReject, Reason: ReasonTooLong}, verdict)
+}
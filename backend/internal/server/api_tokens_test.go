@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/apitoken"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registerForToken(t *testing.T, handler http.Handler, email string) string {
+	t.Helper()
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", "", map[string]string{"email": email, "password": "secret123"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var tokens auth.TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	return tokens.Token
+}
+
+func createPersonalToken(t *testing.T, handler http.Handler, jwt string, scopes ...string) apitoken.CreateTokenResponse {
+	t.Helper()
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/tokens", jwt, apitoken.CreateTokenRequest{Name: "script", Scopes: scopes})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created apitoken.CreateTokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	return created
+}
+
+func TestPersonalAccessTokens_ScopesAndRevocation(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	jwt := registerForToken(t, handler, "scripter@example.com")
+
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodPost, "/api/v1/me/tokens", jwt, apitoken.CreateTokenRequest{Name: "bad", Scopes: []string{"users:admin"}}).Code)
+	created := createPersonalToken(t, handler, jwt, auth.ScopeCafesRead, auth.ScopeCafesWrite)
+	pat := created.Token
+	assert.Equal(t, []string{auth.ScopeCafesRead, auth.ScopeCafesWrite}, created.Scopes)
+
+	cafe := map[string]string{"name": "Scripted Cafe", "visit_status": "to_visit"}
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", pat, cafe)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, "/api/v1/me/cafes", pat, nil).Code)
+
+	// Out-of-scope and unscoped routes refuse the token.
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodGet, "/api/v1/me/ratings", pat, nil).Code)
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodGet, "/api/v1/me", pat, nil).Code)
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodGet, "/api/v1/me/tokens", pat, nil).Code)
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodPost, "/api/v1/auth/logout-all", pat, nil).Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/me/tokens", jwt, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed []apitoken.TokenSummary
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.NotContains(t, rec.Body.String(), pat)
+	assert.Equal(t, created.TokenPrefix, listed[0].TokenPrefix)
+	assert.NotNil(t, listed[0].LastUsedAt)
+
+	// Another user cannot revoke it.
+	otherJWT := registerForToken(t, handler, "other@example.com")
+	path := fmt.Sprintf("/api/v1/me/tokens/%d", created.ID)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodDelete, path, otherJWT, nil).Code)
+
+	assert.Equal(t, http.StatusNoContent, doJSON(t, handler, http.MethodDelete, path, jwt, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, doJSON(t, handler, http.MethodGet, "/api/v1/me/cafes", pat, nil).Code)
+}
+
+func TestPersonalAccessTokens_RevokedByPasswordReset(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	authCfg.PasswordResetURL = "http://app.test/reset-password"
+	authCfg.PasswordResetExpiry = time.Hour
+	sent := make(chanMailer, 4)
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}, Mailer: sent})
+	jwt := registerForToken(t, handler, "reset@example.com")
+	pat := createPersonalToken(t, handler, jwt, auth.ScopeRatingsRead).Token
+	require.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, "/api/v1/me/ratings", pat, nil).Code)
+
+	require.Equal(t, http.StatusAccepted, doJSON(t, handler, http.MethodPost, "/api/v1/auth/password/forgot", "", map[string]string{"email": "reset@example.com"}).Code)
+	token := awaitMailToken(t, sent, "reset@example.com", "Reset")
+	require.Equal(t, http.StatusNoContent, doJSON(t, handler, http.MethodPost, "/api/v1/auth/password/reset", "", map[string]string{"token": token, "new_password": "brand-new-pass"}).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, doJSON(t, handler, http.MethodGet, "/api/v1/me/ratings", pat, nil).Code)
+}
+
+func TestPersonalAccessTokens_RevokedByPasswordChange(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	jwt := registerForToken(t, handler, "change@example.com")
+	pat := createPersonalToken(t, handler, jwt, auth.ScopeRatingsRead).Token
+	require.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, "/api/v1/me/ratings", pat, nil).Code)
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/password", jwt, map[string]string{"current_password": "secret123", "new_password": "brand-new-pass"})
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, doJSON(t, handler, http.MethodGet, "/api/v1/me/ratings", pat, nil).Code)
+	// The session that changed the password stays signed in.
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, "/api/v1/me/ratings", jwt, nil).Code)
+}
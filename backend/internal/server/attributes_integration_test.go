@@ -0,0 +1,82 @@
+//go:build integration
+// +build integration
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/joho/godotenv"
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/db"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_DiscoveryAttributeFilters(t *testing.T) {
+	_ = godotenv.Load()
+	dbCfg, err := appconfig.LoadAWSConfig()
+	if err != nil {
+		t.Skipf("skip integration: DB not configured: %v", err)
+		return
+	}
+	authCfg, err := appconfig.LoadAuthConfig()
+	if err != nil {
+		t.Skipf("skip integration: auth not configured: %v", err)
+		return
+	}
+	conn, err := db.NewAWSClient(dbCfg)
+	require.NoError(t, err)
+
+	migrationsPath, _ := filepath.Abs("../../migrations")
+	m, err := migrate.New("file://"+filepath.ToSlash(migrationsPath), dbCfg.GetMigrationDSN())
+	require.NoError(t, err)
+	defer m.Close()
+	_ = m.Up()
+
+	handler := New(conn, authCfg, Config{BasePath: "/api/v1", Address: ":0"})
+	nonce := "attr" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	token := registerForToken(t, handler, "attr+"+nonce+"@example.com")
+
+	allDay := []map[string]string{}
+	for _, day := range []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"} {
+		allDay = append(allDay, map[string]string{"day": day, "open": "00:00", "close": "24:00"})
+	}
+	// A unique city keeps these rows apart from whatever else the shared database holds.
+	for _, cafe := range []map[string]any{
+		{"name": "Always Open", "city": nonce, "amenities": []string{"wifi", "outlets"}, "price_level": 1,
+			"opening_hours": map[string]any{"timezone": "Europe/London", "weekly": allDay}},
+		{"name": "Wifi Only", "city": nonce, "amenities": []string{"wifi"}, "price_level": 4},
+		{"name": "Plain", "city": nonce},
+	} {
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, cafe)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	names := func(params string) []string {
+		t.Helper()
+		rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes?sort=name_asc&city="+nonce+"&"+params, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page pagination.Page[models.Cafe]
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		var out []string
+		for _, listing := range page.Items {
+			out = append(out, listing.Name)
+		}
+		return out
+	}
+
+	// JSONB containment, so both amenities must be present.
+	assert.Equal(t, []string{"Always Open", "Wifi Only"}, names("amenities=wifi"))
+	assert.Equal(t, []string{"Always Open"}, names("amenities=outlets,wifi"))
+	assert.Equal(t, []string{"Wifi Only"}, names("price_level=4"))
+	assert.Equal(t, []string{"Always Open"}, names("open_now=true&amenities=wifi"))
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscovery_AttributeFilters(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	token := registerForToken(t, handler, "hours@example.com")
+
+	allDay := []map[string]string{}
+	for _, day := range []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"} {
+		allDay = append(allDay, map[string]string{"day": day, "open": "00:00", "close": "24:00"})
+	}
+	for _, cafe := range []map[string]any{
+		{"name": "Always Open", "amenities": []string{"wifi", "outlets"}, "price_level": 2,
+			"opening_hours": map[string]any{"timezone": "Asia/Singapore", "weekly": allDay}},
+		{"name": "Wifi Only", "amenities": []string{"wifi"}, "price_level": 3},
+		{"name": "Plain"},
+	} {
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, cafe)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	names := func(query string) []string {
+		t.Helper()
+		rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes?sort=name_asc&"+query, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page pagination.Page[models.Cafe]
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		out := []string{}
+		for _, listing := range page.Items {
+			out = append(out, listing.Name)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"Always Open", "Wifi Only"}, names("amenities=wifi"))
+	assert.Equal(t, []string{"Always Open"}, names("amenities=wifi,outlets"))
+	assert.Equal(t, []string{"Wifi Only"}, names("price_level=3,4"))
+	assert.Equal(t, []string{"Always Open"}, names("open_now=true"))
+	assert.Equal(t, []string{"Always Open", "Plain", "Wifi Only"}, names("open_now=false"))
+
+	for _, query := range []string{"amenities=jacuzzi", "price_level=0", "price_level=cheap", "open_now=maybe"} {
+		rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes?"+query, "", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+	for _, cafe := range []map[string]any{
+		{"name": "Bad Amenity", "amenities": []string{"jacuzzi"}},
+		{"name": "Bad Price", "price_level": 9},
+		{"name": "Bad Hours", "opening_hours": map[string]any{"timezone": "Nowhere/City", "weekly": allDay}},
+	} {
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, cafe)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, cafe["name"])
+	}
+}
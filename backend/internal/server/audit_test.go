@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit_DeleteAndAdminRestore(t *testing.T) {
+	conn := newSQLiteTestDB(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	owner := registerForToken(t, handler, "owner@example.com")
+	registerForToken(t, handler, "admin@example.com")
+	require.NoError(t, conn.Model(&models.User{}).Where("email = ?", "admin@example.com").Update("role", auth.RoleAdmin).Error)
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"email": "admin@example.com", "password": "secret123"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var tokens auth.TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	admin := tokens.Token
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", owner, map[string]string{"name": "Gone Cafe", "visit_status": "visited"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var cafe models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cafe))
+	cafeID := strconv.FormatUint(uint64(cafe.ID), 10)
+	cafePath := "/api/v1/cafes/" + cafeID
+	restorePath := "/api/v1/admin/restore/cafe_listing/" + cafeID
+
+	assert.Equal(t, http.StatusConflict, doJSON(t, handler, http.MethodPost, restorePath, admin, nil).Code, "a live listing has nothing to restore")
+
+	rec = doJSON(t, handler, http.MethodDelete, cafePath, owner, nil)
+	require.Less(t, rec.Code, 300, rec.Body.String())
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, cafePath, "", nil).Code)
+
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodGet, "/api/v1/admin/audit-events/", owner, nil).Code)
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/admin/audit-events/?entity_type=cafe_listing&entity_id="+cafeID, admin, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var events pagination.Page[models.AuditEvent]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &events))
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, "delete", events.Items[0].Action)
+	assert.Equal(t, cafe.UserID, events.Items[0].ActorID)
+	assert.Contains(t, string(events.Items[0].Before), "Gone Cafe")
+	assert.Empty(t, events.Items[0].After)
+
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodPost, restorePath, owner, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodPost, "/api/v1/admin/restore/photo/"+cafeID, admin, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodPost, "/api/v1/admin/restore/cafe_listing/999", admin, nil).Code)
+	rec = doJSON(t, handler, http.MethodPost, restorePath, admin, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, cafePath, "", nil).Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/admin/audit-events/?entity_type=cafe_listing&entity_id="+cafeID, admin, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &events))
+	require.Len(t, events.Items, 2)
+	assert.Equal(t, "restore", events.Items[0].Action)
+
+	// Past the restore window the listing stays deleted.
+	require.Less(t, doJSON(t, handler, http.MethodDelete, cafePath, owner, nil).Code, 300)
+	require.NoError(t, conn.Unscoped().Model(&models.Cafe{}).Where("id = ?", cafe.ID).Update("deleted_at", time.Now().Add(-31*24*time.Hour)).Error)
+	assert.Equal(t, http.StatusGone, doJSON(t, handler, http.MethodPost, restorePath, admin, nil).Code)
+}
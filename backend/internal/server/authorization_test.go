@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func newSQLiteTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "gocafe_"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.AutoMigrate(&models.User{}, &models.Session{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{}, &models.Cafe{}, &models.SavedPlace{}, &models.CafeRedirect{}, &models.Rating{}, &models.LoginAttempt{}, &models.LoginLockout{}, &models.UserIdentity{}, &models.PersonalAccessToken{}, &models.Photo{}, &models.RatingVote{}, &models.ContentReport{}, &models.AuditEvent{}))
+	return conn
+}
+
+func doJSON(t *testing.T, handler http.Handler, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUserRoutes_AdminOnly(t *testing.T) {
+	conn := newSQLiteTestDB(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+
+	creds := map[string]string{"email": "member@example.com", "name": "Member", "password": "secret123"}
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", "", creds)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var tokens struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodGet, "/api/v1/users/", tokens.Token, nil).Code)
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodDelete, "/api/v1/users/1", tokens.Token, nil).Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/me", tokens.Token, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"role":"user"`)
+
+	// Promotion takes effect on the next refresh.
+	require.NoError(t, conn.Model(&models.User{}).Where("email = ?", creds["email"]).Update("role", "admin").Error)
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": tokens.RefreshToken})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, "/api/v1/users/", tokens.Token, nil).Code)
+}
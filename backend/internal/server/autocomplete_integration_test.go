@@ -46,14 +46,14 @@ func TestIntegration_AutocompleteRoute_Success(t *testing.T) {
 		nil,
 		testAuthConfig(),
 		testServerConfig(),
-		mockAddressAutocompleteProvider{
+		Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{
 			results: []cafelisting.AddressSuggestion{
 				{
 					Name:      "Cafe Route Test",
 					Formatted: "10 Test Street, Singapore",
 				},
 			},
-		},
+		}},
 	)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/cafes/autocomplete?text=cafe&limit=5", nil)
@@ -72,7 +72,7 @@ func TestIntegration_AutocompleteRoute_ProviderFailure(t *testing.T) {
 		nil,
 		testAuthConfig(),
 		testServerConfig(),
-		mockAddressAutocompleteProvider{err: errors.New("upstream failure")},
+		Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{err: errors.New("upstream failure")}},
 	)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/cafes/autocomplete?text=cafe", nil)
@@ -87,7 +87,7 @@ func TestIntegration_AutocompleteRoute_BadRequest(t *testing.T) {
 		nil,
 		testAuthConfig(),
 		testServerConfig(),
-		mockAddressAutocompleteProvider{},
+		Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}},
 	)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/cafes/autocomplete", nil)
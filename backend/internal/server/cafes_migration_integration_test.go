@@ -0,0 +1,71 @@
+//go:build integration
+// +build integration
+
+package server
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/joho/godotenv"
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_FoldCopiesIntoCafes runs migration 000024 over listings seeded at version 23, in a schema of its
+// own so the shared database's data is left alone.
+func TestIntegration_FoldCopiesIntoCafes(t *testing.T) {
+	_ = godotenv.Load()
+	dbCfg, err := appconfig.LoadAWSConfig()
+	if err != nil {
+		t.Skipf("skip integration: DB not configured: %v", err)
+		return
+	}
+	conn, err := db.NewAWSClient(dbCfg)
+	require.NoError(t, err)
+
+	schema := "gocafe_fold_" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	require.NoError(t, conn.Exec("CREATE SCHEMA "+schema).Error)
+	t.Cleanup(func() { conn.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	migrationsPath, _ := filepath.Abs("../../migrations")
+	m, err := migrate.New("file://"+filepath.ToSlash(migrationsPath), dbCfg.GetMigrationDSN()+"&search_path="+url.QueryEscape(schema+",public"))
+	require.NoError(t, err)
+	defer m.Close()
+	require.NoError(t, m.Migrate(23))
+
+	exec := func(sql string, args ...any) {
+		t.Helper()
+		require.NoError(t, conn.Exec(sql, args...).Error)
+	}
+	exec("INSERT INTO " + schema + ".gocafe_users (id, email) VALUES (1, 'adder@example.com'), (3, 'reviewer@example.com')")
+	exec("INSERT INTO " + schema + ".gocafe_cafe_listings (id, user_id, name, visit_status) VALUES (1, 1, 'Brew', 'visited')")
+	exec("INSERT INTO " + schema + ".gocafe_cafe_listings (id, user_id, name, visit_status, source_cafe_id) VALUES (2, 3, 'Brew', 'visited', 1)")
+	visited := time.Now().Add(-48 * time.Hour)
+	// User 3 reviewed both the original and their copy; the copy's review is the newer one. User 1 reviewed once.
+	exec("INSERT INTO "+schema+".gocafe_ratings (id, user_id, cafe_listing_id, visited_at, rating, updated_at) VALUES (1, 3, 1, ?, 2, ?)", visited, visited)
+	exec("INSERT INTO "+schema+".gocafe_ratings (id, user_id, cafe_listing_id, visited_at, rating, updated_at) VALUES (2, 3, 2, ?, 5, ?)", visited, visited.Add(time.Hour))
+	exec("INSERT INTO "+schema+".gocafe_ratings (id, user_id, cafe_listing_id, visited_at, rating, updated_at) VALUES (3, 1, 1, ?, 4, ?)", visited, visited)
+
+	require.NoError(t, m.Migrate(24))
+
+	var live []uint
+	require.NoError(t, conn.Raw("SELECT id FROM "+schema+".gocafe_ratings WHERE cafe_listing_id = 1 AND deleted_at IS NULL ORDER BY id").Scan(&live).Error)
+	assert.Equal(t, []uint{2, 3}, live)
+	var deleted []uint
+	require.NoError(t, conn.Raw("SELECT id FROM "+schema+".gocafe_ratings WHERE deleted_at IS NOT NULL").Scan(&deleted).Error)
+	assert.Equal(t, []uint{1}, deleted)
+
+	var redirectTo uint
+	require.NoError(t, conn.Raw("SELECT cafe_id FROM "+schema+".gocafe_cafe_redirects WHERE from_id = 2").Scan(&redirectTo).Error)
+	assert.Equal(t, uint(1), redirectTo)
+	var savers []uint
+	require.NoError(t, conn.Raw("SELECT user_id FROM "+schema+".gocafe_saved_places WHERE cafe_id = 1 ORDER BY user_id").Scan(&savers).Error)
+	assert.Equal(t, []uint{1, 3}, savers)
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/discovery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryFeed_ServesCommunityWhenProviderUnavailable(t *testing.T) {
+	t.Setenv("GEOAPIFY_API_KEY", "")
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	token := registerForToken(t, handler, "feed@example.com")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, map[string]any{"name": "Apiary", "external_place_id": "geo-apiary", "source_provider": "geoapify_places"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/discovery/feed", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var feed discovery.FeedResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &feed))
+	assert.Equal(t, []string{discovery.OriginProvider}, feed.Unavailable)
+	require.Len(t, feed.Items, 1)
+	assert.Equal(t, "geo-apiary", feed.Items[0].ID)
+	assert.Equal(t, discovery.OriginCommunity, feed.Items[0].Origin)
+	require.NotNil(t, feed.Items[0].CafeListingID)
+
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodGet, "/api/v1/discovery/feed?limit=x", "", nil).Code)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/discovery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscovery_UsesConfiguredOSMProvider(t *testing.T) {
+	t.Setenv("GEOAPIFY_API_KEY", "")
+	osm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"elements":[{"type":"node","id":101,"lat":1.2765,"lon":103.8412,"tags":{"amenity":"cafe","name":"Nylon Coffee Roasters"}}]}`))
+	}))
+	defer osm.Close()
+
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	srvCfg := testServerConfig()
+	// Geoapify is listed first but has no API key, so OpenStreetMap serves alone.
+	srvCfg.Discovery = appconfig.DiscoveryConfig{
+		Providers:    []string{appconfig.DiscoveryProviderGeoapify, appconfig.DiscoveryProviderOSM},
+		OverpassURL:  osm.URL,
+		NominatimURL: osm.URL,
+		OSMUserAgent: "go-cafe-test",
+	}
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, srvCfg, Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/discovery/cafes/", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var places []discovery.Place
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &places))
+	require.Len(t, places, 1)
+	place := places[0]
+	assert.Equal(t, "osm-node-101", place.ID)
+	assert.Equal(t, discovery.SourceProviderOpenStreetMap, place.SourceProvider)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/discovery/cafes/osm-node-101", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// Saving the place keeps its provider, and the feed folds the listing back into it.
+	token := registerForToken(t, handler, "osm@example.com")
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, map[string]any{
+		"name": place.Name, "external_place_id": place.ExternalPlaceID, "source_provider": place.SourceProvider,
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"source_provider":"openstreetmap"`)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/discovery/feed", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var feed discovery.FeedResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &feed))
+	assert.Empty(t, feed.Unavailable)
+	require.Len(t, feed.Items, 1)
+	assert.Equal(t, discovery.OriginBoth, feed.Items[0].Origin)
+	assert.Equal(t, discovery.SourceProviderOpenStreetMap, feed.Items[0].SourceProvider)
+}
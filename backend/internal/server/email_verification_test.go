@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerification_GatesWrites(t *testing.T) {
+	conn := newSQLiteTestDB(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	authCfg.EmailVerificationURL = "http://app.test/verify-email"
+	authCfg.EmailVerificationExpiry = time.Hour
+	authCfg.RequireEmailVerification = true
+	sent := make(chanMailer, 4)
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{
+		AutocompleteProvider: mockAddressAutocompleteProvider{},
+		Mailer:               sent,
+	})
+
+	creds := map[string]string{"email": "new@example.com", "password": "secret123"}
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", "", creds)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var tokens struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+
+	cafe := map[string]string{"name": "Gate Cafe", "visit_status": "to_visit"}
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", tokens.Token, cafe).Code)
+	// Reads stay available while unverified.
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, "/api/v1/me/cafes", tokens.Token, nil).Code)
+
+	token := awaitMailToken(t, sent, creds["email"], "Confirm")
+
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodPost, "/api/v1/auth/verify-email", "", map[string]string{"token": "bogus"}).Code)
+	assert.Equal(t, http.StatusNoContent, doJSON(t, handler, http.MethodPost, "/api/v1/auth/verify-email", "", map[string]string{"token": token}).Code)
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", tokens.Token, cafe)
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/me", tokens.Token, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), `"email_verified_at":null`)
+}
+
+func TestRegister_RejectsInvalidEmail(t *testing.T) {
+	handler := NewWithDependencies(newSQLiteTestDB(t), testAuthConfig(), testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", "", map[string]string{"email": "not an email", "password": "secret123"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
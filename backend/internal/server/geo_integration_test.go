@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/joho/godotenv"
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/db"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_DiscoveryGeoFilters(t *testing.T) {
+	_ = godotenv.Load()
+	dbCfg, err := appconfig.LoadAWSConfig()
+	if err != nil {
+		t.Skipf("skip integration: DB not configured: %v", err)
+		return
+	}
+	authCfg, err := appconfig.LoadAuthConfig()
+	if err != nil {
+		t.Skipf("skip integration: auth not configured: %v", err)
+		return
+	}
+	conn, err := db.NewAWSClient(dbCfg)
+	require.NoError(t, err)
+
+	migrationsPath, _ := filepath.Abs("../../migrations")
+	m, err := migrate.New("file://"+filepath.ToSlash(migrationsPath), dbCfg.GetMigrationDSN())
+	require.NoError(t, err)
+	defer m.Close()
+	_ = m.Up()
+
+	handler := New(conn, authCfg, Config{BasePath: "/api/v1", Address: ":0"})
+	nonce := "geo" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	token := registerForToken(t, handler, "geo+"+nonce+"@example.com")
+
+	// A unique city keeps these rows apart from whatever else the shared database holds.
+	for _, cafe := range []map[string]any{
+		{"name": "Tanjong Pagar", "city": nonce, "latitude": 1.2764, "longitude": 103.8459},
+		{"name": "Orchard", "city": nonce, "latitude": 1.3048, "longitude": 103.8318},
+		{"name": "Johor Bahru", "city": nonce, "latitude": 1.4927, "longitude": 103.7414},
+		{"name": "No Coordinates", "city": nonce},
+	} {
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, cafe)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	list := func(params string) pagination.Page[models.Cafe] {
+		t.Helper()
+		rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes?city="+nonce+"&"+params, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page pagination.Page[models.Cafe]
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		return page
+	}
+	names := func(items []models.Cafe) []string {
+		var out []string
+		for _, listing := range items {
+			out = append(out, listing.Name)
+		}
+		return out
+	}
+
+	near := "near=" + url.QueryEscape("1.29,103.85")
+	page := list(near + "&radius_m=5000&sort=distance")
+	assert.Equal(t, []string{"Tanjong Pagar", "Orchard"}, names(page.Items))
+	require.NotNil(t, page.Items[0].DistanceM)
+	assert.InDelta(t, 1600, *page.Items[0].DistanceM, 100)
+	assert.Less(t, *page.Items[0].DistanceM, *page.Items[1].DistanceM)
+
+	// Distance keyset pages line up with the single query.
+	first := list(near + "&sort=distance&limit=2")
+	require.NotNil(t, first.NextCursor)
+	rest := list(near + "&sort=distance&limit=2&cursor=" + url.QueryEscape(*first.NextCursor))
+	assert.Equal(t, []string{"Tanjong Pagar", "Orchard", "Johor Bahru"}, append(names(first.Items), names(rest.Items)...))
+
+	assert.Equal(t, []string{"Orchard", "Tanjong Pagar"}, names(list("bbox="+url.QueryEscape("103.6,1.2,104.1,1.48")+"&sort=name_asc").Items))
+}
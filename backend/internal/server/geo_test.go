@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscovery_GeoFilters(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	token := registerForToken(t, handler, "mapper@example.com")
+
+	for _, cafe := range []map[string]any{
+		{"name": "Tanjong Pagar", "latitude": 1.2764, "longitude": 103.8459},
+		{"name": "Johor Bahru", "latitude": 1.4927, "longitude": 103.7414},
+		{"name": "No Coordinates"},
+	} {
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, cafe)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes?bbox="+url.QueryEscape("103.6,1.2,104.1,1.4"), "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page pagination.Page[models.Cafe]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Tanjong Pagar", page.Items[0].Name)
+
+	for _, query := range []string{
+		"near=1.29",
+		"near=95,103.85",
+		"radius_m=500",
+		"near=1.29,103.85&radius_m=0",
+		"near=1.29,103.85&radius_m=60000",
+		"bbox=104.1,1.4,103.6",
+		"sort=distance",
+	} {
+		rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes?"+query, "", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
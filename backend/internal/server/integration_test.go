@@ -69,10 +69,12 @@ func TestIntegration_RegisterLoginAndCafeFlow(t *testing.T) {
 	handler.ServeHTTP(rec, req)
 	require.Equal(t, http.StatusCreated, rec.Code, "register: %s", rec.Body.String())
 	var regResp struct {
-		Token string `json:"token"`
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
 	}
 	require.NoError(t, json.NewDecoder(rec.Body).Decode(&regResp))
 	require.NotEmpty(t, regResp.Token)
+	require.NotEmpty(t, regResp.RefreshToken)
 
 	token := regResp.Token
 
@@ -138,6 +140,33 @@ func TestIntegration_RegisterLoginAndCafeFlow(t *testing.T) {
 	rec = httptest.NewRecorder()
 	handler.ServeHTTP(rec, req)
 	assert.Equal(t, http.StatusCreated, rec.Code, "create rating: %s", rec.Body.String())
+
+	// 8. Refresh rotates the refresh token
+	refreshJSON, _ := json.Marshal(map[string]string{"refresh_token": regResp.RefreshToken})
+	req = httptest.NewRequest(http.MethodPost, base+"/auth/refresh", bytes.NewReader(refreshJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, "refresh: %s", rec.Body.String())
+	var refreshResp struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&refreshResp))
+	require.NotEqual(t, regResp.RefreshToken, refreshResp.RefreshToken)
+
+	// 9. Logout revokes the session, so its access tokens stop working
+	req = httptest.NewRequest(http.MethodPost, base+"/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+refreshResp.Token)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, base+"/me/cafes", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
 }
 
 func init() {
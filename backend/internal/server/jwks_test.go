@@ -0,0 +1,58 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKS_AsymmetricTokensVerifyAgainstPublishedKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	authCfg.JWTIssuer = "go-cafe"
+	authCfg.JWTAudience = "go-cafe-api"
+	authCfg.JWTKeys = []appconfig.JWTKey{{ID: "k1", Algorithm: appconfig.JWTAlgorithmRS256, Signer: key, Public: &key.PublicKey}}
+	authCfg.JWTActiveKeyID = "k1"
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", "", map[string]string{"email": "jwks@example.com", "password": "secret123"})
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var tokens auth.TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, "/api/v1/me", tokens.Token, nil).Code)
+
+	jwksRec := httptest.NewRecorder()
+	handler.ServeHTTP(jwksRec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, jwksRec.Code)
+	var set auth.JWKSResponse
+	require.NoError(t, json.Unmarshal(jwksRec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+
+	// Another service verifies the token using only the published key.
+	n, err := base64.RawURLEncoding.DecodeString(set.Keys[0].N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(set.Keys[0].E)
+	require.NoError(t, err)
+	published := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	parsed, err := jwt.Parse(tokens.Token, func(tok *jwt.Token) (any, error) {
+		require.Equal(t, set.Keys[0].KeyID, tok.Header["kid"])
+		return published, nil
+	}, jwt.WithValidMethods([]string{set.Keys[0].Algorithm}), jwt.WithIssuer("go-cafe"), jwt.WithAudience("go-cafe-api"))
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogin_LockoutAfterRepeatedFailures(t *testing.T) {
+	conn := newSQLiteTestDB(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	authCfg.LoginAttemptStore = appconfig.LoginAttemptStorePostgres
+	authCfg.LoginMaxEmailFailures = 3
+	authCfg.LoginLockoutBase = time.Minute
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+
+	creds := map[string]string{"email": "target@example.com", "password": "secret123"}
+	require.Equal(t, http.StatusCreated, doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", "", creds).Code)
+
+	wrong := map[string]string{"email": creds["email"], "password": "guess"}
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusUnauthorized, doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", "", wrong).Code)
+	}
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", "", wrong)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.Equal(t, 60, retryAfter)
+
+	// The right password is refused while the email is locked.
+	assert.Equal(t, http.StatusTooManyRequests, doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", "", creds).Code)
+
+	var lockouts []models.LoginLockout
+	require.NoError(t, conn.Find(&lockouts).Error)
+	require.Len(t, lockouts, 1)
+	assert.Equal(t, "email", lockouts[0].Scope)
+	assert.Equal(t, creds["email"], lockouts[0].Subject)
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModeration_ReportHideRestore(t *testing.T) {
+	conn := newSQLiteTestDB(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	author := registerForToken(t, handler, "author@example.com")
+	reader := registerForToken(t, handler, "reader@example.com")
+	registerForToken(t, handler, "mod@example.com")
+	require.NoError(t, conn.Model(&models.User{}).Where("email = ?", "mod@example.com").Update("role", auth.RoleModerator).Error)
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"email": "mod@example.com", "password": "secret123"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var tokens auth.TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	moderator := tokens.Token
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", author, map[string]string{"name": "Loud Cafe", "visit_status": "visited"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var cafe models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cafe))
+	cafeID := strconv.FormatUint(uint64(cafe.ID), 10)
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/cafes/"+cafeID+"/ratings/", author, map[string]any{"visited_at": time.Now().UTC().Format(time.RFC3339), "rating": 5, "review": "Buy followers now, best deal in town"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var review models.Rating
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &review))
+	ratingPath := "/api/v1/ratings/" + strconv.FormatUint(uint64(review.ID), 10)
+
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodPost, ratingPath+"/reports", author, map[string]string{"reason": "spam"}).Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodPost, ratingPath+"/reports", reader, map[string]string{"reason": "meh"}).Code)
+	rec = doJSON(t, handler, http.MethodPost, ratingPath+"/reports", reader, map[string]string{"reason": "spam", "note": "link farm"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var report models.ContentReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, http.StatusConflict, doJSON(t, handler, http.MethodPost, ratingPath+"/reports", reader, map[string]string{"reason": "spam"}).Code)
+	assert.Equal(t, http.StatusCreated, doJSON(t, handler, http.MethodPost, "/api/v1/cafes/"+cafeID+"/reports", reader, map[string]string{"reason": "off_topic"}).Code)
+
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodGet, "/api/v1/moderation/reports/", reader, nil).Code)
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/moderation/reports/?target_type=rating", moderator, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var queue pagination.Page[models.ContentReport]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &queue))
+	require.Len(t, queue.Items, 1)
+	require.NotNil(t, queue.Items[0].Rating)
+	assert.Equal(t, review.ID, queue.Items[0].Rating.ID)
+
+	reportPath := "/api/v1/moderation/reports/" + strconv.FormatUint(uint64(report.ID), 10)
+	rec = doJSON(t, handler, http.MethodPost, reportPath+"/hide", moderator, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusConflict, doJSON(t, handler, http.MethodPost, reportPath+"/hide", moderator, nil).Code)
+
+	ratingCount := func(token string) int {
+		t.Helper()
+		rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes/"+cafeID+"/ratings/", token, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page pagination.Page[models.Rating]
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		return len(page.Items)
+	}
+	assert.Equal(t, 0, ratingCount(""))
+	assert.Equal(t, 0, ratingCount(reader))
+	assert.Equal(t, 1, ratingCount(author))
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, ratingPath, "", nil).Code)
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, ratingPath, author, nil).Code)
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, ratingPath, moderator, nil).Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/cafes/"+cafeID, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var listing models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listing))
+	assert.Equal(t, int64(0), listing.ReviewCount)
+
+	rec = doJSON(t, handler, http.MethodPost, reportPath+"/restore", moderator, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "restored", report.Status)
+	assert.Equal(t, 1, ratingCount(""))
+	assert.Equal(t, http.StatusConflict, doJSON(t, handler, http.MethodPost, reportPath+"/dismiss", moderator, nil).Code)
+}
+
+func TestModeration_HeldReviews(t *testing.T) {
+	conn := newSQLiteTestDB(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	author := registerForToken(t, handler, "author@example.com")
+	registerForToken(t, handler, "mod@example.com")
+	require.NoError(t, conn.Model(&models.User{}).Where("email = ?", "mod@example.com").Update("role", auth.RoleModerator).Error)
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"email": "mod@example.com", "password": "secret123"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var tokens auth.TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	moderator := tokens.Token
+
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", author, map[string]string{"name": "Link Cafe", "visit_status": "visited"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var cafe models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cafe))
+	cafeID := strconv.FormatUint(uint64(cafe.ID), 10)
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/cafes/"+cafeID+"/ratings/", author, map[string]any{"visited_at": time.Now().UTC().Format(time.RFC3339), "rating": 5, "review": "Cheap beans at cheapbeans.xyz"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var review models.Rating
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &review))
+	require.NotNil(t, review.HeldAt)
+	assert.Equal(t, "links", review.HeldReason)
+	ratingPath := "/api/v1/ratings/" + strconv.FormatUint(uint64(review.ID), 10)
+
+	ratingCount := func(token string) int {
+		t.Helper()
+		rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes/"+cafeID+"/ratings/", token, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page pagination.Page[models.Rating]
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		return len(page.Items)
+	}
+	assert.Equal(t, 0, ratingCount(""))
+	assert.Equal(t, 1, ratingCount(author))
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, ratingPath, "", nil).Code)
+
+	rec = doJSON(t, handler, http.MethodPut, ratingPath, author, map[string]any{"visited_at": time.Now().UTC().Format(time.RFC3339), "rating": 5, "review": "a.com b.com c.com"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodGet, "/api/v1/moderation/held-ratings/", author, nil).Code)
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/moderation/held-ratings/", moderator, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var queue pagination.Page[models.Rating]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &queue))
+	require.Len(t, queue.Items, 1)
+	assert.Equal(t, review.ID, queue.Items[0].ID)
+
+	heldPath := "/api/v1/moderation/held-ratings/" + strconv.FormatUint(uint64(review.ID), 10)
+	rec = doJSON(t, handler, http.MethodPost, heldPath+"/approve", moderator, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 1, ratingCount(""))
+	assert.Equal(t, http.StatusConflict, doJSON(t, handler, http.MethodPost, heldPath+"/reject", moderator, nil).Code)
+}
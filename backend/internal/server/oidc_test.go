@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOIDCSuccessURL = "http://app.test/auth/oidc/callback"
+
+func newOIDCTestServer(t *testing.T) (http.Handler, *oidctest.Issuer) {
+	t.Helper()
+	issuer := oidctest.NewIssuer(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	authCfg.OIDCCallbackBaseURL = "http://api.test/api/v1"
+	authCfg.OIDCSuccessURL = testOIDCSuccessURL
+	authCfg.OIDCProviders = []appconfig.OIDCProviderConfig{{
+		Name:     "stub",
+		Issuer:   issuer.URL,
+		ClientID: "gocafe-test",
+		Scopes:   []string{"openid", "email"},
+	}}
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	return handler, issuer
+}
+
+func oidcGet(handler http.Handler, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// oidcResult parses the fragment of a redirect to the success URL.
+func oidcResult(t *testing.T, rec *httptest.ResponseRecorder) url.Values {
+	t.Helper()
+	require.Equal(t, http.StatusFound, rec.Code)
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(loc.String(), testOIDCSuccessURL+"#"), loc.String())
+	values, err := url.ParseQuery(loc.Fragment)
+	require.NoError(t, err)
+	return values
+}
+
+func stateCookie(t *testing.T, rec *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "gocafe_oidc_state" {
+			return c
+		}
+	}
+	t.Fatal("state cookie not set")
+	return nil
+}
+
+func TestOIDC_SignInLinksVerifiedEmailAndIssuesTokens(t *testing.T) {
+	handler, issuer := newOIDCTestServer(t)
+	creds := map[string]string{"email": "member@example.com", "password": "secret123"}
+	require.Equal(t, http.StatusCreated, doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", "", creds).Code)
+	issuer.SetIdentity(oidctest.Identity{Subject: "stub-sub", Email: "Member@Example.com", EmailVerified: true})
+
+	providers := oidcGet(handler, "/api/v1/auth/oidc/providers")
+	require.Equal(t, http.StatusOK, providers.Code)
+	assert.JSONEq(t, `{"providers":["stub"]}`, providers.Body.String())
+
+	start := oidcGet(handler, "/api/v1/auth/oidc/stub/start")
+	require.Equal(t, http.StatusFound, start.Code)
+	cookie := stateCookie(t, start)
+	assert.Equal(t, "/api/v1/auth/oidc/stub", cookie.Path)
+	assert.True(t, cookie.HttpOnly)
+
+	callback := issuer.Authorize(t, start.Header().Get("Location"))
+	require.Equal(t, "api.test", callback.Host)
+	result := oidcResult(t, oidcGet(handler, callback.RequestURI(), cookie))
+	require.Empty(t, result.Get("error"))
+	require.NotEmpty(t, result.Get("token"))
+	require.NotEmpty(t, result.Get("refresh_token"))
+
+	me := doJSON(t, handler, http.MethodGet, "/api/v1/me", result.Get("token"), nil)
+	require.Equal(t, http.StatusOK, me.Code)
+	var profile models.User
+	require.NoError(t, json.Unmarshal(me.Body.Bytes(), &profile))
+	assert.Equal(t, creds["email"], profile.Email)
+	assert.NotNil(t, profile.EmailVerifiedAt)
+
+	// Replaying the callback without the consumed state cookie fails.
+	assert.Equal(t, "invalid_state", oidcResult(t, oidcGet(handler, callback.RequestURI())).Get("error"))
+}
+
+func TestOIDC_CallbackRejectsBadState(t *testing.T) {
+	handler, issuer := newOIDCTestServer(t)
+	issuer.SetIdentity(oidctest.Identity{Subject: "stub-sub", Email: "someone@example.com", EmailVerified: true})
+
+	start := oidcGet(handler, "/api/v1/auth/oidc/stub/start")
+	require.Equal(t, http.StatusFound, start.Code)
+	callback := issuer.Authorize(t, start.Header().Get("Location"))
+	query := callback.Query()
+	query.Set("state", "forged")
+
+	rec := oidcGet(handler, callback.Path+"?"+query.Encode(), stateCookie(t, start))
+	assert.Equal(t, "invalid_state", oidcResult(t, rec).Get("error"))
+}
+
+func TestOIDC_UnverifiedEmailIsRefused(t *testing.T) {
+	handler, issuer := newOIDCTestServer(t)
+	issuer.SetIdentity(oidctest.Identity{Subject: "stub-sub", Email: "someone@example.com", EmailVerified: false})
+
+	start := oidcGet(handler, "/api/v1/auth/oidc/stub/start")
+	callback := issuer.Authorize(t, start.Header().Get("Location"))
+	rec := oidcGet(handler, callback.RequestURI(), stateCookie(t, start))
+	assert.Equal(t, "email_not_verified", oidcResult(t, rec).Get("error"))
+}
+
+func TestOIDC_UnknownProvider(t *testing.T) {
+	handler, _ := newOIDCTestServer(t)
+
+	assert.Equal(t, http.StatusNotFound, oidcGet(handler, "/api/v1/auth/oidc/nope/start").Code)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEndpoints_CursorPagination(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	token := registerForToken(t, handler, "collector@example.com")
+
+	for i := 0; i < 5; i++ {
+		cafe := map[string]string{"name": fmt.Sprintf("Cafe %d", i), "visit_status": "to_visit"}
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, cafe)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var names []string
+	path := "/api/v1/me/cafes?sort=name_asc&limit=2"
+	for pages := 0; pages < 5; pages++ {
+		rec := doJSON(t, handler, http.MethodGet, path, token, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page pagination.Page[models.Cafe]
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		for _, listing := range page.Items {
+			names = append(names, listing.Name)
+		}
+		if page.NextCursor == nil {
+			assert.Contains(t, rec.Body.String(), `"next_cursor":null`)
+			break
+		}
+		path = "/api/v1/me/cafes?sort=name_asc&limit=2&cursor=" + url.QueryEscape(*page.NextCursor)
+	}
+	assert.Equal(t, []string{"Cafe 0", "Cafe 1", "Cafe 2", "Cafe 3", "Cafe 4"}, names)
+
+	// A cursor is only valid for the sort that produced it.
+	rec := doJSON(t, handler, http.MethodGet, "/api/v1/me/cafes?sort=name_asc&limit=2", token, nil)
+	var page pagination.Page[models.Cafe]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.NotNil(t, page.NextCursor)
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodGet, "/api/v1/me/cafes?sort=created_desc&cursor="+url.QueryEscape(*page.NextCursor), token, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodGet, "/api/v1/me/cafes?limit=many", token, nil).Code)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/cafes?limit=3", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 3)
+	assert.NotNil(t, page.NextCursor)
+
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/me/ratings", token, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"items":[],"next_cursor":null}`, rec.Body.String())
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/mail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chanMailer hands sent messages to the test; delivery happens off the request goroutine.
+type chanMailer chan mail.Message
+
+func (c chanMailer) Send(ctx context.Context, msg mail.Message) error {
+	c <- msg
+	return nil
+}
+
+var mailTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// awaitMailToken waits for a message whose subject contains subject and returns the token from its link.
+func awaitMailToken(t *testing.T, sent chanMailer, to, subject string) string {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-sent:
+			if msg.To != to || !strings.Contains(msg.Subject, subject) {
+				continue
+			}
+			m := mailTokenPattern.FindStringSubmatch(msg.Body)
+			require.Len(t, m, 2, "link not found in %q", msg.Body)
+			return m[1]
+		case <-timeout:
+			t.Fatalf("no %q email to %s was sent", subject, to)
+		}
+	}
+}
+
+func TestPasswordReset_EndToEnd(t *testing.T) {
+	conn := newSQLiteTestDB(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	authCfg.PasswordResetURL = "http://app.test/reset-password"
+	authCfg.PasswordResetExpiry = time.Hour
+	sent := make(chanMailer, 4)
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{Mailer: sent})
+
+	creds := map[string]string{"email": "forgetful@example.com", "password": "secret123"}
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/auth/register", "", creds)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	unknown := doJSON(t, handler, http.MethodPost, "/api/v1/auth/password/forgot", "", map[string]string{"email": "nobody@example.com"})
+	known := doJSON(t, handler, http.MethodPost, "/api/v1/auth/password/forgot", "", map[string]string{"email": creds["email"]})
+	require.Equal(t, http.StatusAccepted, known.Code)
+	assert.Equal(t, unknown.Code, known.Code)
+	assert.Equal(t, unknown.Body.String(), known.Body.String())
+
+	token := awaitMailToken(t, sent, creds["email"], "Reset")
+
+	reset := map[string]string{"token": token, "new_password": "brand-new-pass"}
+	assert.Equal(t, http.StatusNoContent, doJSON(t, handler, http.MethodPost, "/api/v1/auth/password/reset", "", reset).Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodPost, "/api/v1/auth/password/reset", "", reset).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", "", creds).Code)
+	creds["password"] = "brand-new-pass"
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodPost, "/api/v1/auth/login", "", creds).Code)
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/blob"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func multipartPhotos(t *testing.T, files map[string][]byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, data := range files {
+		part, err := mw.CreateFormFile("photos", name)
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+	return &body, mw.FormDataContentType()
+}
+
+func doUpload(t *testing.T, handler http.Handler, path, token string, files map[string][]byte) *httptest.ResponseRecorder {
+	t.Helper()
+	body, contentType := multipartPhotos(t, files)
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPhotos_UploadServeAndDelete(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	store, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srvCfg := testServerConfig()
+	srvCfg.Photos.MaxBytes = 64 << 10
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, srvCfg, Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}, PhotoStore: store})
+	owner := registerForToken(t, handler, "photographer@example.com")
+	other := registerForToken(t, handler, "other@example.com")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", owner, map[string]string{"name": "Photo Cafe"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var cafe models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cafe))
+
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 600, 300))))
+	photosPath := "/api/v1/cafes/" + fmt.Sprint(cafe.ID) + "/photos/"
+
+	assert.Equal(t, http.StatusForbidden, doUpload(t, handler, photosPath, other, map[string][]byte{"a.png": pngData.Bytes()}).Code)
+	assert.Equal(t, http.StatusUnsupportedMediaType, doUpload(t, handler, photosPath, owner, map[string][]byte{"a.png": []byte("renamed text file")}).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, doUpload(t, handler, photosPath, owner, map[string][]byte{"big.png": make([]byte, 65<<10)}).Code)
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodPost, photosPath, owner, map[string]string{}).Code)
+
+	rec = doUpload(t, handler, photosPath, owner, map[string][]byte{"a.png": pngData.Bytes()})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var uploaded []models.Photo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &uploaded))
+	require.Len(t, uploaded, 1)
+	assert.Equal(t, "/api/v1/photos/"+fmt.Sprint(uploaded[0].ID)+"/thumbnail", uploaded[0].ThumbnailURL)
+	assert.Equal(t, 400, uploaded[0].ThumbnailWidth)
+	assert.NotContains(t, rec.Body.String(), "storage_key")
+
+	rec = doJSON(t, handler, http.MethodGet, photosPath, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), uploaded[0].URL)
+
+	rec = doJSON(t, handler, http.MethodGet, uploaded[0].ThumbnailURL, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "immutable")
+	thumb, err := png.DecodeConfig(rec.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 400, thumb.Width)
+
+	req := httptest.NewRequest(http.MethodGet, uploaded[0].URL, nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	notModified := httptest.NewRecorder()
+	handler.ServeHTTP(notModified, req)
+	assert.Equal(t, http.StatusOK, notModified.Code, "full image and thumbnail have different ETags")
+
+	deletePath := photosPath + fmt.Sprint(uploaded[0].ID)
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodDelete, deletePath, other, nil).Code)
+	assert.Equal(t, http.StatusNoContent, doJSON(t, handler, http.MethodDelete, deletePath, owner, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, uploaded[0].URL, "", nil).Code)
+}
+
+func TestPhotos_StorageNotConfigured(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	token := registerForToken(t, handler, "nostore@example.com")
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, map[string]string{"name": "Photo Cafe"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	rec = doUpload(t, handler, "/api/v1/cafes/1/photos/", token, map[string][]byte{"a.png": pngData.Bytes()})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestPhotos_HiddenCafeOnlyForAuthor(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	store, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	conn := newSQLiteTestDB(t)
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}, PhotoStore: store})
+	owner := registerForToken(t, handler, "hidden-photos@example.com")
+	other := registerForToken(t, handler, "onlooker@example.com")
+	pat := createPersonalToken(t, handler, owner, auth.ScopeCafesRead, auth.ScopeRatingsRead).Token
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", owner, map[string]string{"name": "Hidden Cafe", "visit_status": "visited"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var cafe models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cafe))
+	rec = doJSON(t, handler, http.MethodPost, fmt.Sprintf("/api/v1/cafes/%d/ratings/", cafe.ID), owner, map[string]any{"visited_at": time.Now().UTC().Format(time.RFC3339), "rating": 4})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var review models.Rating
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &review))
+
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	photosPath := fmt.Sprintf("/api/v1/cafes/%d/photos/", cafe.ID)
+	reviewPhotosPath := fmt.Sprintf("/api/v1/ratings/%d/photos/", review.ID)
+	rec = doUpload(t, handler, photosPath, owner, map[string][]byte{"a.png": pngData.Bytes()})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var uploaded []models.Photo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &uploaded))
+	rec = doUpload(t, handler, reviewPhotosPath, owner, map[string][]byte{"b.png": pngData.Bytes()})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var reviewPhotos []models.Photo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reviewPhotos))
+
+	require.NoError(t, conn.Model(&models.Cafe{}).Where("id = ?", cafe.ID).Update("hidden_at", time.Now()).Error)
+
+	for _, token := range []string{"", other} {
+		assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, photosPath, token, nil).Code)
+		assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, reviewPhotosPath, token, nil).Code)
+		assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, uploaded[0].URL, token, nil).Code)
+		assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, reviewPhotos[0].ThumbnailURL, token, nil).Code)
+		assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, fmt.Sprintf("/api/v1/ratings/%d", review.ID), token, nil).Code)
+	}
+	// Personal access tokens fetch photo files anonymously.
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodGet, uploaded[0].URL, pat, nil).Code)
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, photosPath, pat, nil).Code)
+
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, photosPath, owner, nil).Code)
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, reviewPhotosPath, owner, nil).Code)
+	rec = doJSON(t, handler, http.MethodGet, uploaded[0].URL, owner, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "private, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedPlaces_SharedCafeAndRedirects(t *testing.T) {
+	conn := newSQLiteTestDB(t)
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(conn, authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	adder := registerForToken(t, handler, "adder@example.com")
+	saver := registerForToken(t, handler, "saver@example.com")
+
+	place := map[string]string{"name": "Shared Cafe", "source_provider": "geoapify", "external_place_id": "place-1", "visit_status": "visited"}
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", adder, place)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var cafe models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cafe))
+	cafePath := "/api/v1/cafes/" + strconv.FormatUint(uint64(cafe.ID), 10)
+
+	// Saving the same provider place lands on the same cafe, with the saver's own status and notes.
+	place["name"], place["visit_status"], place["notes"] = "My name for it", "to_visit", "ask for the window seat"
+	rec = doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", saver, place)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var saved models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &saved))
+	assert.Equal(t, cafe.ID, saved.ID)
+	assert.Equal(t, "Shared Cafe", saved.Name)
+	assert.Equal(t, "to_visit", saved.VisitStatus)
+	assert.Equal(t, "ask for the window seat", saved.Notes)
+
+	// The saver cannot rate before visiting, and cannot rename the cafe; both can change their own status.
+	review := map[string]any{"visited_at": time.Now().UTC().Format(time.RFC3339), "rating": 5, "review": "Lovely"}
+	assert.Equal(t, http.StatusBadRequest, doJSON(t, handler, http.MethodPost, cafePath+"/ratings/", saver, review).Code)
+	rec = doJSON(t, handler, http.MethodPut, cafePath, saver, map[string]string{"name": "Renamed", "visit_status": "visited"})
+	assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+	rec = doJSON(t, handler, http.MethodPut, cafePath, saver, map[string]string{"name": "Shared Cafe", "visit_status": "visited"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = doJSON(t, handler, http.MethodPost, cafePath+"/ratings/", saver, review)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	for token, status := range map[string]string{adder: "visited", saver: "visited", "": ""} {
+		rec = doJSON(t, handler, http.MethodGet, cafePath, token, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var got models.Cafe
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, "Shared Cafe", got.Name)
+		assert.Equal(t, status, got.VisitStatus)
+		assert.Equal(t, int64(1), got.ReviewCount)
+	}
+
+	// Discovery lists the place once.
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/cafes/", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var discovered pagination.Page[models.Cafe]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &discovered))
+	assert.Len(t, discovered.Items, 1)
+
+	// A listing id folded into the cafe by the migration redirects there, keeping the rest of the path.
+	require.NoError(t, conn.Create(&models.CafeRedirect{FromID: 99, CafeID: cafe.ID}).Error)
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/cafes/99/ratings/?sort=helpful", "", nil)
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+	assert.Equal(t, cafePath+"/ratings/?sort=helpful", rec.Header().Get("Location"))
+
+	// The adder removing the cafe only unsaves it while the saver still uses it.
+	rec = doJSON(t, handler, http.MethodDelete, cafePath, adder, nil)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusOK, doJSON(t, handler, http.MethodGet, cafePath, "", nil).Code)
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/me/cafes", adder, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var mine pagination.Page[models.Cafe]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &mine))
+	assert.Empty(t, mine.Items)
+	rec = doJSON(t, handler, http.MethodGet, "/api/v1/me/cafes", saver, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &mine))
+	require.Len(t, mine.Items, 1)
+	assert.Equal(t, cafe.ID, mine.Items[0].ID)
+}
@@ -0,0 +1,79 @@
+//go:build integration
+// +build integration
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/joho/godotenv"
+	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
+	"github.com/khorzhenwin/go-cafe/backend/internal/db"
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_DiscoveryFullTextSearch(t *testing.T) {
+	_ = godotenv.Load()
+	dbCfg, err := appconfig.LoadAWSConfig()
+	if err != nil {
+		t.Skipf("skip integration: DB not configured: %v", err)
+		return
+	}
+	authCfg, err := appconfig.LoadAuthConfig()
+	if err != nil {
+		t.Skipf("skip integration: auth not configured: %v", err)
+		return
+	}
+	conn, err := db.NewAWSClient(dbCfg)
+	require.NoError(t, err)
+
+	migrationsPath, _ := filepath.Abs("../../migrations")
+	m, err := migrate.New("file://"+filepath.ToSlash(migrationsPath), dbCfg.GetMigrationDSN())
+	require.NoError(t, err)
+	defer m.Close()
+	_ = m.Up()
+
+	handler := New(conn, authCfg, Config{BasePath: "/api/v1", Address: ":0"})
+	nonce := "zq" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	token := registerForToken(t, handler, "search+"+nonce+"@example.com")
+
+	// A unique city keeps these rows apart from whatever else the shared database holds.
+	for _, cafe := range []map[string]string{
+		{"name": "Nimbus Roasters", "city": nonce},
+		{"name": "Quiet Corner", "city": nonce, "description": "Single-origin roasters on rotation"},
+		{"name": "Tea House", "city": nonce},
+	} {
+		rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", token, cafe)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		rec := doJSON(t, handler, http.MethodGet, "/api/v1/cafes?city="+nonce+"&query="+url.QueryEscape(query), "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page pagination.Page[models.Cafe]
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		var names []string
+		for _, listing := range page.Items {
+			names = append(names, listing.Name)
+		}
+		return names
+	}
+
+	// Stemming matches "roaster" to "Roasters", and a name hit (weight A) outranks a description hit (weight D).
+	assert.Equal(t, []string{"Nimbus Roasters", "Quiet Corner"}, search("roaster"))
+	// websearch syntax: exclusion.
+	assert.Equal(t, []string{"Nimbus Roasters"}, search("roasters -single"))
+	// Trigram fallback tolerates a typo in the name.
+	assert.Equal(t, []string{"Nimbus Roasters"}, search("Nimbos Roasters"))
+}
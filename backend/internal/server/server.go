@@ -1,15 +1,28 @@
 package server
 
 import (
+	"context"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/apitoken"
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
 	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
+	"github.com/khorzhenwin/go-cafe/backend/internal/cache"
 	"github.com/khorzhenwin/go-cafe/backend/internal/cafelisting"
 	appconfig "github.com/khorzhenwin/go-cafe/backend/internal/config"
 	"github.com/khorzhenwin/go-cafe/backend/internal/discovery"
+	"github.com/khorzhenwin/go-cafe/backend/internal/emailverification"
+	"github.com/khorzhenwin/go-cafe/backend/internal/loginguard"
+	"github.com/khorzhenwin/go-cafe/backend/internal/mail"
+	"github.com/khorzhenwin/go-cafe/backend/internal/moderation"
+	"github.com/khorzhenwin/go-cafe/backend/internal/oidc"
+	"github.com/khorzhenwin/go-cafe/backend/internal/passwordreset"
+	"github.com/khorzhenwin/go-cafe/backend/internal/photo"
 	"github.com/khorzhenwin/go-cafe/backend/internal/rating"
+	"github.com/khorzhenwin/go-cafe/backend/internal/session"
 	"github.com/khorzhenwin/go-cafe/backend/internal/user"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"gorm.io/gorm"
@@ -21,11 +34,26 @@ type Config struct {
 	Address      string
 	WriteTimeout time.Duration
 	ReadTimeout  time.Duration
+	Cache        appconfig.CacheConfig     // zero value disables response caching
+	Discovery    appconfig.DiscoveryConfig // zero value uses Geoapify alone
+	Photos       appconfig.PhotoConfig     // only MaxBytes is read here; zero value allows 10 MB per file
+	Screening    appconfig.ScreeningConfig // zero value screens reviews with the default rules
+	Audit        appconfig.AuditConfig     // zero value allows restores for 30 days after deletion
+}
+
+// Dependencies are external integrations injected into the handler. Nil fields fall back to defaults.
+type Dependencies struct {
+	AutocompleteProvider cafelisting.AddressAutocompleteProvider
+	PlacesProvider       discovery.Provider          // defaults to the Config.Discovery provider chain
+	StaticMaps           discovery.StaticMapRenderer // defaults to Geoapify Static Maps when GEOAPIFY_API_KEY is set
+	Mailer               mail.Mailer                 // defaults to a log mailer on stderr
+	PhotoStore           photo.BlobStore             // nil disables photo uploads and downloads
+	ReviewScreener       rating.ContentScreener      // defaults to the Config.Screening screener
 }
 
 // New builds the HTTP handler from DB connection and configs. Caller must run migrations separately.
 func New(dbConn *gorm.DB, authCfg *appconfig.AuthConfig, srvCfg Config) http.Handler {
-	return NewWithDependencies(dbConn, authCfg, srvCfg, nil)
+	return NewWithDependencies(dbConn, authCfg, srvCfg, Dependencies{})
 }
 
 // NewWithDependencies builds the HTTP handler with explicit injected dependencies.
@@ -33,30 +61,161 @@ func NewWithDependencies(
 	dbConn *gorm.DB,
 	authCfg *appconfig.AuthConfig,
 	srvCfg Config,
-	autocompleteProvider cafelisting.AddressAutocompleteProvider,
+	deps Dependencies,
 ) http.Handler {
+	mailer := deps.Mailer
+	if mailer == nil {
+		mailer = mail.NewLogMailer(os.Stderr, "no-reply@go-cafe.local")
+	}
+
+	auditRepo := audit.NewRepository(dbConn)
+	auditSvc := audit.NewService(auditRepo, srvCfg.Audit.RestoreWindow)
 	userRepo := user.NewRepository(dbConn)
-	userSvc := user.NewService(userRepo)
+	userSvc := user.NewService(userRepo, auditSvc)
 	cafeRepo := cafelisting.NewRepository(dbConn)
-	cafeSvc := cafelisting.NewService(cafeRepo)
+	cafeSvc := cafelisting.NewService(cafeRepo, auditSvc)
 	ratingRepo := rating.NewRepository(dbConn)
-	ratingSvc := rating.NewService(ratingRepo, cafeSvc)
+	reviewScreener := deps.ReviewScreener
+	if reviewScreener == nil {
+		reviewScreener = rating.NewScreenerFromConfig(srvCfg.Screening)
+	}
+	ratingSvc := rating.NewService(ratingRepo, cafeSvc, reviewScreener, auditSvc)
+	photoRepo := photo.NewRepository(dbConn)
+	photoSvc := photo.NewService(photoRepo, deps.PhotoStore, cafeSvc, ratingSvc)
+	if deps.PhotoStore != nil && srvCfg.Photos.OrphanCleanupInterval > 0 {
+		go photoSvc.RunOrphanCleanup(context.Background(), srvCfg.Photos.OrphanCleanupInterval)
+	}
+	moderationRepo := moderation.NewRepository(dbConn)
+	moderationSvc := moderation.NewService(moderationRepo, cafeSvc, ratingSvc, auditSvc)
+	sessionRepo := session.NewRepository(dbConn)
+	sessionSvc := session.NewService(sessionRepo)
+	tokenRepo := apitoken.NewRepository(dbConn)
+	tokenSvc := apitoken.NewService(tokenRepo, userSvc)
+	resetRepo := passwordreset.NewRepository(dbConn)
+	credentials := credentialRevoker{sessions: sessionSvc, tokens: tokenSvc}
+	resetSvc := passwordreset.NewService(resetRepo, userSvc, credentials, mailer, authCfg.PasswordResetURL, authCfg.PasswordResetExpiry)
+	verificationRepo := emailverification.NewRepository(dbConn)
+	verificationSvc := emailverification.NewService(verificationRepo, userSvc, mailer, authCfg.EmailVerificationURL, authCfg.EmailVerificationExpiry)
+	loginGuardRepo := loginguard.NewRepository(dbConn)
+	var loginAttempts loginguard.Storage = loginguard.NewMemoryStore()
+	if authCfg.LoginAttemptStore == appconfig.LoginAttemptStorePostgres {
+		loginAttempts = loginGuardRepo
+	}
+	loginGuardSvc := loginguard.NewService(loginAttempts, loginGuardRepo, loginguard.Policy{
+		MaxEmailFailures: authCfg.LoginMaxEmailFailures,
+		MaxIPFailures:    authCfg.LoginMaxIPFailures,
+		Window:           authCfg.LoginFailureWindow,
+		LockoutBase:      authCfg.LoginLockoutBase,
+		LockoutMax:       authCfg.LoginLockoutMax,
+	})
+
+	caches := newResponseCaches(dbConn, srvCfg.Cache)
+	chainedPlaces, chainedAutocomplete := newDiscoveryProviders(srvCfg.Discovery, caches.geocode)
+	autocomplete := deps.AutocompleteProvider
+	if autocomplete == nil {
+		autocomplete = chainedAutocomplete
+	}
+	if autocomplete != nil {
+		autocomplete = cafelisting.NewCachedAutocompleteProvider(autocomplete, caches.autocomplete)
+	}
+	places := deps.PlacesProvider
+	if places == nil {
+		places = chainedPlaces
+	}
+	if places != nil {
+		places = discovery.NewCachedProvider(places, caches.places)
+	}
+	staticMaps := deps.StaticMaps
+	if staticMaps == nil {
+		if client := discovery.NewStaticMapClientFromEnv(); client != nil {
+			staticMaps = client
+		}
+	}
 
-	authMiddleware := auth.Middleware(authCfg)
-	authHandler := &auth.Handler{AuthCfg: authCfg, Finder: userSvc, Creator: userSvc}
+	authMiddleware := auth.Middleware(authCfg, sessionSvc, tokenSvc)
+	oidcRepo := oidc.NewRepository(dbConn)
+	oidcSvc := oidc.NewService(authCfg.OIDCProviders, authCfg.OIDCCallbackBaseURL, oidcRepo, userSvc, credentials)
+	authHandler := &auth.Handler{AuthCfg: authCfg, Finder: userSvc, Creator: userSvc, Roles: userSvc, Sessions: sessionSvc, Resets: resetSvc, Verifier: verificationSvc, Guard: loginGuardSvc, OIDC: oidcSvc}
+	writeMiddleware := passthrough
+	if authCfg.RequireEmailVerification {
+		writeMiddleware = auth.RequireVerifiedEmail(userSvc)
+	}
 
 	r := chi.NewRouter()
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
+	r.Get("/.well-known/jwks.json", auth.JWKSHandler(authCfg))
 	r.Route(srvCfg.BasePath, func(r chi.Router) {
-		auth.RegisterRoutes(r, authHandler)
-		user.RegisterRoutes(r, userSvc, authMiddleware)
-		cafelisting.RegisterRoutes(r, cafeSvc, authMiddleware, autocompleteProvider)
-		discovery.RegisterRoutes(r, nil)
-		rating.RegisterRoutes(r, ratingSvc, authMiddleware)
+		r.Use(cafelisting.FollowRedirects(cafeSvc, srvCfg.BasePath))
+		auth.RegisterRoutes(r, authHandler, authMiddleware)
+		user.RegisterRoutes(r, userSvc, sessionSvc, credentials, authMiddleware)
+		apitoken.RegisterRoutes(r, tokenSvc, authMiddleware)
+		cafelisting.RegisterRoutes(r, cafeSvc, authMiddleware, writeMiddleware, autocomplete)
+		discovery.RegisterRoutes(r, places, cafeSvc, ratingSvc, staticMaps)
+		cache.RegisterRoutes(r, authMiddleware, caches.places, caches.geocode, caches.autocomplete)
+		rating.RegisterRoutes(r, ratingSvc, authMiddleware, writeMiddleware)
+		photo.RegisterRoutes(r, photoSvc, srvCfg.BasePath, srvCfg.Photos.MaxBytes, authMiddleware, writeMiddleware)
+		moderation.RegisterRoutes(r, moderationSvc, authMiddleware, writeMiddleware)
+		audit.RegisterRoutes(r, auditSvc, map[string]audit.Restorer{
+			audit.EntityUser:        userSvc,
+			audit.EntityCafeListing: cafeSvc,
+			audit.EntityRating:      ratingSvc,
+		}, authMiddleware)
 	})
 	return r
 }
 
+func passthrough(next http.Handler) http.Handler { return next }
+
+// responseCaches hold Geoapify responses; a nil cache (store "none" or a zero TTL) passes calls straight through.
+type responseCaches struct {
+	places, geocode, autocomplete *cache.Cache
+}
+
+func newResponseCaches(dbConn *gorm.DB, cfg appconfig.CacheConfig) responseCaches {
+	var store cache.Storage
+	switch cfg.Store {
+	case appconfig.CacheStoreMemory:
+		store = cache.NewMemoryStore(cfg.MaxEntries)
+	case appconfig.CacheStorePostgres:
+		store = cache.NewRepository(dbConn)
+	default:
+		return responseCaches{}
+	}
+	newCache := func(name string, ttl time.Duration) *cache.Cache {
+		if ttl <= 0 {
+			return nil
+		}
+		return cache.New(name, store, cache.Config{TTL: ttl, StaleTTL: cfg.StaleTTL})
+	}
+	return responseCaches{
+		places:       newCache("places", cfg.PlacesTTL),
+		geocode:      newCache("geocode", cfg.GeocodeTTL),
+		autocomplete: newCache("autocomplete", cfg.AutocompleteTTL),
+	}
+}
+
+// credentialRevoker revokes the credentials of a user (sessions and API tokens) after a password reset or change,
+// or when an OIDC sign-in claims an account with an unverified email.
+type credentialRevoker struct {
+	sessions *session.Service
+	tokens   *apitoken.Service
+}
+
+func (c credentialRevoker) RevokeAllForUser(userID uint) error {
+	if err := c.sessions.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	return c.tokens.RevokeAllForUser(userID)
+}
+
+// RevokeAllExceptSession is RevokeAllForUser but keeps keepSessionID signed in; zero keeps none.
+func (c credentialRevoker) RevokeAllExceptSession(userID, keepSessionID uint) error {
+	if err := c.sessions.RevokeOtherSessions(userID, keepSessionID); err != nil {
+		return err
+	}
+	return c.tokens.RevokeAllForUser(userID)
+}
+
 // NewServer returns an http.Server using the same handler (for ListenAndServe).
 func NewServer(handler http.Handler, cfg Config) *http.Server {
 	return &http.Server{
@@ -66,3 +225,39 @@ func NewServer(handler http.Handler, cfg Config) *http.Server {
 		ReadTimeout:  cfg.ReadTimeout,
 	}
 }
+
+// newDiscoveryProviders builds the places and autocomplete failover chains in cfg.Providers order. Geoapify is left
+// out when GEOAPIFY_API_KEY is unset; both results are nil when no provider is left.
+func newDiscoveryProviders(cfg appconfig.DiscoveryConfig, geocodeCache *cache.Cache) (discovery.Provider, cafelisting.AddressAutocompleteProvider) {
+	names := cfg.Providers
+	if len(names) == 0 {
+		names = []string{appconfig.DiscoveryProviderGeoapify}
+	}
+
+	var places []discovery.Provider
+	var autocomplete []cafelisting.AddressAutocompleteProvider
+	for _, name := range names {
+		switch name {
+		case appconfig.DiscoveryProviderGeoapify:
+			if client := discovery.NewGeoapifyPlacesClientFromEnv(); client != nil {
+				places = append(places, client.WithGeocodeCache(geocodeCache))
+			}
+			if client := cafelisting.NewGeoapifyClientFromEnv(); client != nil {
+				autocomplete = append(autocomplete, client)
+			}
+		case appconfig.DiscoveryProviderOSM:
+			places = append(places, discovery.NewOSMPlacesClient(cfg.OverpassURL, cfg.NominatimURL, cfg.OSMUserAgent).WithGeocodeCache(geocodeCache))
+			autocomplete = append(autocomplete, cafelisting.NewNominatimClient(cfg.NominatimURL, cfg.OSMUserAgent))
+		}
+	}
+
+	var placesChain discovery.Provider
+	if len(places) > 0 {
+		placesChain = discovery.NewProviderChain(places...)
+	}
+	var autocompleteChain cafelisting.AddressAutocompleteProvider
+	if len(autocomplete) > 0 {
+		autocompleteChain = cafelisting.NewAutocompleteChain(autocomplete...)
+	}
+	return placesChain, autocompleteChain
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"github.com/khorzhenwin/go-cafe/backend/internal/rating"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatings_HelpfulVotes(t *testing.T) {
+	authCfg := testAuthConfig()
+	authCfg.JWTExpiry = time.Minute
+	authCfg.RefreshExpiry = time.Hour
+	handler := NewWithDependencies(newSQLiteTestDB(t), authCfg, testServerConfig(), Dependencies{AutocompleteProvider: mockAddressAutocompleteProvider{}})
+	author := registerForToken(t, handler, "author@example.com")
+	voter := registerForToken(t, handler, "voter@example.com")
+
+	rec := doJSON(t, handler, http.MethodPost, "/api/v1/me/cafes", author, map[string]string{"name": "Voted Cafe", "visit_status": "visited"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var cafe models.Cafe
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cafe))
+	cafePath := "/api/v1/cafes/" + strconv.FormatUint(uint64(cafe.ID), 10) + "/ratings/"
+	rec = doJSON(t, handler, http.MethodPost, cafePath, author, map[string]any{"visited_at": time.Now().UTC().Format(time.RFC3339), "rating": 4, "review": "Solid"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var review models.Rating
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &review))
+	votesPath := "/api/v1/ratings/" + strconv.FormatUint(uint64(review.ID), 10) + "/votes"
+
+	assert.Equal(t, http.StatusUnauthorized, doJSON(t, handler, http.MethodPost, votesPath, "", nil).Code)
+	assert.Equal(t, http.StatusForbidden, doJSON(t, handler, http.MethodPost, votesPath, author, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodPost, "/api/v1/ratings/999/votes", voter, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, handler, http.MethodDelete, votesPath, voter, nil).Code)
+
+	rec = doJSON(t, handler, http.MethodPost, votesPath, voter, nil)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var vote rating.VoteResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &vote))
+	assert.Equal(t, rating.VoteResponse{RatingID: review.ID, HelpfulCount: 1}, vote)
+	assert.Equal(t, http.StatusConflict, doJSON(t, handler, http.MethodPost, votesPath, voter, nil).Code)
+
+	rec = doJSON(t, handler, http.MethodGet, cafePath+"?sort=helpful", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page pagination.Page[models.Rating]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, int64(1), page.Items[0].HelpfulCount)
+
+	rec = doJSON(t, handler, http.MethodDelete, votesPath, voter, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &vote))
+	assert.Equal(t, int64(0), vote.HelpfulCount)
+}
@@ -0,0 +1,8 @@
+package session
+
+import "errors"
+
+var ErrSessionNotFound = errors.New("session not found")
+var ErrSessionRevoked = errors.New("session has been revoked")
+var ErrSessionExpired = errors.New("session has expired")
+var ErrRefreshTokenReused = errors.New("refresh token was already used; session revoked")
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"errors"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type Storage interface {
+	Create(session *models.Session) error
+	GetByID(id uint) (*models.Session, error)
+	GetByRefreshTokenHash(hash string) (*models.Session, error)
+	GetByPreviousTokenHash(hash string) (*models.Session, error)
+	Rotate(id uint, currentHash, newHash string, expiresAt, usedAt time.Time) error
+	Revoke(id uint, at time.Time) error
+	RevokeAllByUserID(userID uint, at time.Time) error
+	RevokeAllByUserIDExcept(userID, keepID uint, at time.Time) error
+}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(s *models.Session) error {
+	return r.db.Create(s).Error
+}
+
+func (r *Repository) GetByID(id uint) (*models.Session, error) {
+	var s models.Session
+	err := r.db.First(&s, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &s, err
+}
+
+func (r *Repository) GetByRefreshTokenHash(hash string) (*models.Session, error) {
+	var s models.Session
+	err := r.db.Where("refresh_token_hash = ?", hash).First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &s, err
+}
+
+func (r *Repository) GetByPreviousTokenHash(hash string) (*models.Session, error) {
+	var s models.Session
+	err := r.db.Where("previous_token_hash = ?", hash).First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &s, err
+}
+
+// Rotate swaps the refresh token hash only if currentHash is still the active one,
+// so two concurrent refreshes with the same token cannot both succeed.
+func (r *Repository) Rotate(id uint, currentHash, newHash string, expiresAt, usedAt time.Time) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, currentHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  newHash,
+			"previous_token_hash": currentHash,
+			"expires_at":          expiresAt,
+			"last_used_at":        usedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *Repository) Revoke(id uint, at time.Time) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.Error
+}
+
+func (r *Repository) RevokeAllByUserID(userID uint, at time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *Repository) RevokeAllByUserIDExcept(userID, keepID uint, at time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", at).Error
+}
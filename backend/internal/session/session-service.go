@@ -0,0 +1,148 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+const refreshTokenBytes = 32
+
+type Service struct {
+	store Storage
+	now   func() time.Time
+}
+
+func NewService(store Storage) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// CreateSession starts a new session and returns its ID with a fresh refresh token (implements auth.SessionManager).
+func (s *Service) CreateSession(userID uint, userAgent, ipAddress string, expiresAt time.Time) (sessionID uint, refreshToken string, err error) {
+	refreshToken, err = generateRefreshToken()
+	if err != nil {
+		return 0, "", err
+	}
+	session := &models.Session{
+		UserID:           userID,
+		RefreshTokenHash: HashToken(refreshToken),
+		UserAgent:        truncate(userAgent, 255),
+		IPAddress:        truncate(ipAddress, 64),
+		ExpiresAt:        expiresAt,
+	}
+	if err := s.store.Create(session); err != nil {
+		return 0, "", err
+	}
+	return session.ID, refreshToken, nil
+}
+
+// RotateSession exchanges a refresh token for a new one on the same session (implements auth.SessionManager).
+// Presenting an already-rotated token revokes the session, since it indicates the token leaked.
+func (s *Service) RotateSession(refreshToken string, expiresAt time.Time) (sessionID, userID uint, newRefreshToken string, err error) {
+	now := s.now()
+	hash := HashToken(refreshToken)
+
+	session, err := s.store.GetByRefreshTokenHash(hash)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if session == nil {
+		reused, err := s.store.GetByPreviousTokenHash(hash)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		if reused == nil {
+			return 0, 0, "", ErrSessionNotFound
+		}
+		if err := s.store.Revoke(reused.ID, now); err != nil {
+			return 0, 0, "", err
+		}
+		return 0, 0, "", ErrRefreshTokenReused
+	}
+	if session.RevokedAt != nil {
+		return 0, 0, "", ErrSessionRevoked
+	}
+	if !session.ExpiresAt.After(now) {
+		return 0, 0, "", ErrSessionExpired
+	}
+
+	newRefreshToken, err = generateRefreshToken()
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if err := s.store.Rotate(session.ID, hash, HashToken(newRefreshToken), expiresAt, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, 0, "", ErrRefreshTokenReused
+		}
+		return 0, 0, "", err
+	}
+	return session.ID, session.UserID, newRefreshToken, nil
+}
+
+// RevokeSession ends a single session (implements auth.SessionManager).
+func (s *Service) RevokeSession(sessionID uint) error {
+	return s.store.Revoke(sessionID, s.now())
+}
+
+// RevokeAllForUser ends every active session of a user (implements auth.SessionManager).
+func (s *Service) RevokeAllForUser(userID uint) error {
+	return s.store.RevokeAllByUserID(userID, s.now())
+}
+
+// RevokeOtherSessions ends every active session of a user except keepSessionID, e.g. after a password change.
+func (s *Service) RevokeOtherSessions(userID, keepSessionID uint) error {
+	return s.store.RevokeAllByUserIDExcept(userID, keepSessionID, s.now())
+}
+
+// SignedInAt returns when the session was created by a sign-in; refreshing keeps the same session, so this is the
+// time the user last proved who they are on that device. Unknown sessions return gorm.ErrRecordNotFound.
+func (s *Service) SignedInAt(sessionID uint) (time.Time, error) {
+	session, err := s.store.GetByID(sessionID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if session == nil {
+		return time.Time{}, gorm.ErrRecordNotFound
+	}
+	return session.CreatedAt, nil
+}
+
+// IsSessionActive reports whether the session exists, is not revoked and has not expired (implements auth.SessionValidator).
+func (s *Service) IsSessionActive(sessionID uint) (bool, error) {
+	session, err := s.store.GetByID(sessionID)
+	if err != nil {
+		return false, err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return false, nil
+	}
+	return session.ExpiresAt.After(s.now()), nil
+}
+
+// HashToken returns the hex SHA-256 digest stored in place of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
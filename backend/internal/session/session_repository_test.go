@@ -0,0 +1,45 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRepository_RotateRequiresCurrentHash(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Session{}))
+	repo := NewRepository(db)
+
+	s := &models.Session{UserID: 1, RefreshTokenHash: "a", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, repo.Create(s))
+
+	require.NoError(t, repo.Rotate(s.ID, "a", "b", time.Now().Add(time.Hour), time.Now()))
+	assert.ErrorIs(t, repo.Rotate(s.ID, "a", "c", time.Now().Add(time.Hour), time.Now()), gorm.ErrRecordNotFound)
+
+	got, err := repo.GetByPreviousTokenHash("a")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "b", got.RefreshTokenHash)
+	assert.NotNil(t, got.LastUsedAt)
+}
+
+func TestRepository_RevokeAllByUserID(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	_ = db.AutoMigrate(&models.Session{})
+	repo := NewRepository(db)
+	_ = repo.Create(&models.Session{UserID: 1, RefreshTokenHash: "a", ExpiresAt: time.Now().Add(time.Hour)})
+	_ = repo.Create(&models.Session{UserID: 1, RefreshTokenHash: "b", ExpiresAt: time.Now().Add(time.Hour)})
+
+	require.NoError(t, repo.RevokeAllByUserID(1, time.Now()))
+
+	got, _ := repo.GetByRefreshTokenHash("b")
+	require.NotNil(t, got)
+	assert.NotNil(t, got.RevokedAt)
+}
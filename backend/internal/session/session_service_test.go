@@ -0,0 +1,201 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockSessionStorage struct {
+	sessions map[uint]*models.Session
+	nextID   uint
+}
+
+func newMockSessionStorage() *mockSessionStorage {
+	return &mockSessionStorage{sessions: map[uint]*models.Session{}}
+}
+
+func (m *mockSessionStorage) Create(s *models.Session) error {
+	m.nextID++
+	s.ID = m.nextID
+	copy := *s
+	m.sessions[s.ID] = &copy
+	return nil
+}
+
+func (m *mockSessionStorage) GetByID(id uint) (*models.Session, error) {
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copy := *s
+	return &copy, nil
+}
+
+func (m *mockSessionStorage) GetByRefreshTokenHash(hash string) (*models.Session, error) {
+	for _, s := range m.sessions {
+		if s.RefreshTokenHash == hash {
+			copy := *s
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockSessionStorage) GetByPreviousTokenHash(hash string) (*models.Session, error) {
+	for _, s := range m.sessions {
+		if s.PreviousTokenHash == hash {
+			copy := *s
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockSessionStorage) Rotate(id uint, currentHash, newHash string, expiresAt, usedAt time.Time) error {
+	s, ok := m.sessions[id]
+	if !ok || s.RefreshTokenHash != currentHash || s.RevokedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	s.PreviousTokenHash = currentHash
+	s.RefreshTokenHash = newHash
+	s.ExpiresAt = expiresAt
+	s.LastUsedAt = &usedAt
+	return nil
+}
+
+func (m *mockSessionStorage) Revoke(id uint, at time.Time) error {
+	if s, ok := m.sessions[id]; ok && s.RevokedAt == nil {
+		s.RevokedAt = &at
+	}
+	return nil
+}
+
+func (m *mockSessionStorage) RevokeAllByUserID(userID uint, at time.Time) error {
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *mockSessionStorage) RevokeAllByUserIDExcept(userID, keepID uint, at time.Time) error {
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.ID != keepID && s.RevokedAt == nil {
+			s.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestService_CreateSession_StoresHashOnly(t *testing.T) {
+	m := newMockSessionStorage()
+	svc := NewService(m)
+	id, token, err := svc.CreateSession(7, "ua", "127.0.0.1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.NotZero(t, id)
+	require.NotEmpty(t, token)
+	assert.Equal(t, HashToken(token), m.sessions[id].RefreshTokenHash)
+	assert.NotEqual(t, token, m.sessions[id].RefreshTokenHash)
+
+	active, err := svc.IsSessionActive(id)
+	require.NoError(t, err)
+	assert.True(t, active)
+}
+
+func TestService_RotateSession(t *testing.T) {
+	m := newMockSessionStorage()
+	svc := NewService(m)
+	id, token, _ := svc.CreateSession(7, "", "", time.Now().Add(time.Hour))
+
+	sid, uid, next, err := svc.RotateSession(token, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, id, sid)
+	assert.Equal(t, uint(7), uid)
+	assert.NotEqual(t, token, next)
+
+	_, _, next2, err := svc.RotateSession(next, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.NotEmpty(t, next2)
+}
+
+func TestService_SignedInAt_SurvivesRotation(t *testing.T) {
+	m := newMockSessionStorage()
+	svc := NewService(m)
+	id, token, _ := svc.CreateSession(7, "", "", time.Now().Add(time.Hour))
+	signedIn := time.Now().Add(-time.Hour)
+	m.sessions[id].CreatedAt = signedIn
+
+	_, _, _, err := svc.RotateSession(token, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	at, err := svc.SignedInAt(id)
+	require.NoError(t, err)
+	assert.Equal(t, signedIn, at)
+
+	_, err = svc.SignedInAt(id + 1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestService_RotateSession_ReuseRevokesSession(t *testing.T) {
+	m := newMockSessionStorage()
+	svc := NewService(m)
+	id, token, _ := svc.CreateSession(7, "", "", time.Now().Add(time.Hour))
+	_, _, next, err := svc.RotateSession(token, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	_, _, _, err = svc.RotateSession(token, time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	active, _ := svc.IsSessionActive(id)
+	assert.False(t, active)
+	_, _, _, err = svc.RotateSession(next, time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+func TestService_RotateSession_Expired(t *testing.T) {
+	m := newMockSessionStorage()
+	svc := NewService(m)
+	_, token, _ := svc.CreateSession(7, "", "", time.Now().Add(-time.Minute))
+	_, _, _, err := svc.RotateSession(token, time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, ErrSessionExpired)
+}
+
+func TestService_RotateSession_Unknown(t *testing.T) {
+	svc := NewService(newMockSessionStorage())
+	_, _, _, err := svc.RotateSession("nope", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestService_RevokeAllForUser(t *testing.T) {
+	m := newMockSessionStorage()
+	svc := NewService(m)
+	a, _, _ := svc.CreateSession(7, "", "", time.Now().Add(time.Hour))
+	b, _, _ := svc.CreateSession(7, "", "", time.Now().Add(time.Hour))
+	other, _, _ := svc.CreateSession(8, "", "", time.Now().Add(time.Hour))
+
+	require.NoError(t, svc.RevokeAllForUser(7))
+	for _, id := range []uint{a, b} {
+		active, _ := svc.IsSessionActive(id)
+		assert.False(t, active)
+	}
+	active, _ := svc.IsSessionActive(other)
+	assert.True(t, active)
+}
+
+func TestService_RevokeOtherSessions(t *testing.T) {
+	m := newMockSessionStorage()
+	svc := NewService(m)
+	keep, _, _ := svc.CreateSession(7, "", "", time.Now().Add(time.Hour))
+	drop, _, _ := svc.CreateSession(7, "", "", time.Now().Add(time.Hour))
+
+	require.NoError(t, svc.RevokeOtherSessions(7, keep))
+	active, _ := svc.IsSessionActive(keep)
+	assert.True(t, active)
+	active, _ = svc.IsSessionActive(drop)
+	assert.False(t, active)
+}
@@ -0,0 +1,274 @@
+package upstream
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Upstream names. API clients that call the same upstream share its Client through Shared.
+const (
+	Geoapify  = "geoapify"
+	Overpass  = "overpass"
+	Nominatim = "nominatim"
+	Tiles     = "tiles"
+	S3        = "s3"
+)
+
+// Policy tunes one upstream's client. Zero fields take the DefaultPolicy value.
+type Policy struct {
+	Timeout          time.Duration // per attempt
+	MaxAttempts      int           // including the first
+	BaseBackoff      time.Duration // backoff before the second attempt; doubles after each further attempt
+	MaxBackoff       time.Duration // cap on computed backoff
+	MaxRetryAfter    time.Duration // longest Retry-After worth waiting for; longer ones end the call
+	MaxConcurrent    int           // requests in flight at once, counted until the response body is closed
+	FailureThreshold int           // consecutive failed attempts that open the circuit breaker
+	OpenFor          time.Duration // how long an open breaker rejects calls before letting one probe through
+}
+
+var DefaultPolicy = Policy{
+	Timeout:          10 * time.Second,
+	MaxAttempts:      3,
+	BaseBackoff:      200 * time.Millisecond,
+	MaxBackoff:       2 * time.Second,
+	MaxRetryAfter:    5 * time.Second,
+	MaxConcurrent:    16,
+	FailureThreshold: 5,
+	OpenFor:          30 * time.Second,
+}
+
+func (p Policy) withDefaults() Policy {
+	d := DefaultPolicy
+	if p.Timeout > 0 {
+		d.Timeout = p.Timeout
+	}
+	if p.MaxAttempts > 0 {
+		d.MaxAttempts = p.MaxAttempts
+	}
+	if p.BaseBackoff > 0 {
+		d.BaseBackoff = p.BaseBackoff
+	}
+	if p.MaxBackoff > 0 {
+		d.MaxBackoff = p.MaxBackoff
+	}
+	if p.MaxRetryAfter > 0 {
+		d.MaxRetryAfter = p.MaxRetryAfter
+	}
+	if p.MaxConcurrent > 0 {
+		d.MaxConcurrent = p.MaxConcurrent
+	}
+	if p.FailureThreshold > 0 {
+		d.FailureThreshold = p.FailureThreshold
+	}
+	if p.OpenFor > 0 {
+		d.OpenFor = p.OpenFor
+	}
+	return d
+}
+
+// Client sends requests to one upstream. It retries 429, 5xx and transport failures with jittered exponential
+// backoff (or the upstream's Retry-After), limits concurrent requests, and stops calling the upstream for a while
+// after repeated failures. Like http.Client it returns any response it gets, including a final 429 or 5xx; check
+// the status and build a StatusError with NewStatusError.
+type Client struct {
+	name   string
+	policy Policy
+	http   *http.Client
+	slots  chan struct{}
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func New(name string, policy Policy) *Client {
+	policy = policy.withDefaults()
+	return &Client{
+		name:   name,
+		policy: policy,
+		http:   &http.Client{Timeout: policy.Timeout},
+		slots:  make(chan struct{}, policy.MaxConcurrent),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+var (
+	sharedMu sync.Mutex
+	shared   = map[string]*Client{}
+)
+
+// Shared returns the process-wide client for the named upstream, creating it with policy on first use, so every
+// API client calling that upstream shares one circuit breaker and one concurrency limit.
+func Shared(name string, policy Policy) *Client {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if c, ok := shared[name]; ok {
+		return c
+	}
+	c := New(name, policy)
+	shared[name] = c
+	return c
+}
+
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Do sends req, retrying when that may help. Errors are *CircuitOpenError, *TransportError or the context's error.
+// A request with a body is retried only if it has GetBody, which http.NewRequest sets for in-memory readers.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := c.allow(); err != nil {
+		return nil, err
+	}
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		c.abandonProbe()
+		return nil, ctx.Err()
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.http.Do(c.attemptRequest(req, attempt))
+		if err == nil && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			c.record(true)
+			return c.holdSlot(resp), nil
+		}
+		if ctx.Err() != nil {
+			// The caller gave up; that says nothing about the upstream.
+			c.abandonProbe()
+			<-c.slots
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		c.record(false)
+
+		delay, retry := c.retryDelay(req, resp, attempt)
+		if !retry || c.allow() != nil {
+			if err != nil {
+				<-c.slots
+				return nil, &TransportError{Service: c.name, Timeout: isTimeout(err), err: err}
+			}
+			return c.holdSlot(resp), nil
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			<-c.slots
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) attemptRequest(req *http.Request, attempt int) *http.Request {
+	if attempt == 1 || req.Body == nil || req.GetBody == nil {
+		return req
+	}
+	clone := req.Clone(req.Context())
+	clone.Body, _ = req.GetBody()
+	return clone
+}
+
+// retryDelay reports whether another attempt is allowed after a failed one, and how long to wait first.
+func (c *Client) retryDelay(req *http.Request, resp *http.Response, attempt int) (time.Duration, bool) {
+	if attempt >= c.policy.MaxAttempts || (req.Body != nil && req.GetBody == nil) {
+		return 0, false
+	}
+	var delay time.Duration
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), c.now()); ok {
+			if retryAfter > c.policy.MaxRetryAfter {
+				return 0, false
+			}
+			delay = retryAfter
+		}
+	}
+	if delay == 0 {
+		// Full jitter: spread retries over [0, backoff) so callers that failed together do not retry together.
+		backoff := min(c.policy.BaseBackoff<<(attempt-1), c.policy.MaxBackoff)
+		delay = rand.N(backoff) + 1
+	}
+	if deadline, ok := req.Context().Deadline(); ok && c.now().Add(delay).After(deadline) {
+		return 0, false
+	}
+	return delay, true
+}
+
+// allow reports whether the breaker lets a call through. Once OpenFor has passed, a single probe call is let
+// through; its outcome closes the breaker or opens it again.
+func (c *Client) allow() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.openUntil.IsZero() {
+		return nil
+	}
+	if c.now().Before(c.openUntil) || c.probing {
+		return &CircuitOpenError{Service: c.name, RetryAt: c.openUntil}
+	}
+	c.probing = true
+	return nil
+}
+
+func (c *Client) record(success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if success {
+		c.failures = 0
+		c.openUntil = time.Time{}
+		c.probing = false
+		return
+	}
+	c.failures++
+	if c.probing || c.failures >= c.policy.FailureThreshold {
+		c.openUntil = c.now().Add(c.policy.OpenFor)
+		c.probing = false
+	}
+}
+
+func (c *Client) abandonProbe() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probing = false
+}
+
+// holdSlot keeps the request's concurrency slot until the caller closes the response body.
+func (c *Client) holdSlot(resp *http.Response) *http.Response {
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-c.slots }}
+	return resp
+}
+
+// releasingBody frees the concurrency slot when the caller closes the response body.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
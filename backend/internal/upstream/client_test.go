@@ -0,0 +1,203 @@
+package upstream
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client whose sleeps are recorded instead of waited out, on a clock tests can move.
+func newTestClient(t *testing.T, policy Policy) (*Client, *[]time.Duration, *time.Time) {
+	t.Helper()
+	c := New("test", policy)
+	clock := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	c.now = func() time.Time { return clock }
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	return c, &slept, &clock
+}
+
+func get(t *testing.T, c *Client, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+	return c.Do(req)
+}
+
+func TestClient_RetriesServerErrorsWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "upstream stack trace", http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c, slept, _ := newTestClient(t, Policy{MaxAttempts: 3, BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second})
+	resp, err := get(t, c, srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	require.Len(t, *slept, 2)
+	assert.LessOrEqual(t, (*slept)[0], 100*time.Millisecond)
+	assert.LessOrEqual(t, (*slept)[1], 200*time.Millisecond)
+}
+
+func TestClient_HonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, slept, _ := newTestClient(t, Policy{})
+	resp, err := get(t, c, srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []time.Duration{2 * time.Second}, *slept)
+
+	// A Retry-After beyond MaxRetryAfter ends the call with the 429 for the caller to report.
+	calls.Store(0)
+	*slept = nil
+	c.policy.MaxRetryAfter = time.Second
+	resp, err = get(t, c, srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	statusErr := NewStatusError("test search", resp)
+	assert.Equal(t, 2*time.Second, statusErr.RetryAfter)
+	assert.Empty(t, *slept)
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c, _, _ := newTestClient(t, Policy{})
+	resp, err := get(t, c, srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_ReplaysRequestBodyOnRetry(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c, _, _ := newTestClient(t, Policy{})
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("data=query"))
+	require.NoError(t, err)
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"data=query", "data=query"}, bodies)
+}
+
+func TestClient_TransportErrorHidesRequestURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	c, _, _ := newTestClient(t, Policy{MaxAttempts: 2})
+	_, err := get(t, c, url+"/v2/places?apiKey=secret")
+	var transportErr *TransportError
+	require.ErrorAs(t, err, &transportErr)
+	assert.NotContains(t, err.Error(), "secret")
+	assert.True(t, Retryable(context.Background(), err))
+}
+
+func TestClient_CircuitBreakerOpensAndProbes(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	c, _, clock := newTestClient(t, Policy{MaxAttempts: 1, FailureThreshold: 2, OpenFor: time.Minute})
+	for i := 0; i < 2; i++ {
+		resp, err := get(t, c, srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	_, err := get(t, c, srv.URL)
+	var circuitErr *CircuitOpenError
+	require.ErrorAs(t, err, &circuitErr)
+	assert.Equal(t, int32(2), calls.Load(), "an open breaker does not call the upstream")
+
+	// After OpenFor one probe goes through; it fails, so the breaker opens again.
+	*clock = clock.Add(time.Minute)
+	resp, err := get(t, c, srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	_, err = get(t, c, srv.URL)
+	require.ErrorAs(t, err, &circuitErr)
+
+	// A successful probe closes it.
+	healthy.Store(true)
+	*clock = clock.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		resp, err = get(t, c, srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestClient_LimitsConcurrentRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c, _, _ := newTestClient(t, Policy{MaxConcurrent: 1})
+	held, err := get(t, c, srv.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	_, err = c.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the slot is held until the first body is closed")
+
+	require.NoError(t, held.Body.Close())
+	resp, err := get(t, c, srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
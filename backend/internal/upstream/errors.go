@@ -0,0 +1,135 @@
+// Package upstream is the shared outbound HTTP client for third-party APIs (places, geocoding, autocomplete,
+// static maps) and the errors it reports.
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusError is a non-200 response from a third-party API. Service names the call, e.g. "geoapify places search".
+// The response body is deliberately not kept: it can echo request details and must not reach API clients.
+type StatusError struct {
+	Service    string
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header, when the upstream sent one
+}
+
+// NewStatusError builds a StatusError from resp and drains a little of its body so the connection can be reused.
+// The caller still closes the body.
+func NewStatusError(service string, resp *http.Response) *StatusError {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	return &StatusError{Service: service, StatusCode: resp.StatusCode, RetryAfter: retryAfter}
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed: status=%d", e.Service, e.StatusCode)
+}
+
+// TransportError is a call that got no response: the upstream was unreachable or did not answer in time. Error
+// leaves out the underlying *url.Error, whose message includes the request URL and with it any API key.
+type TransportError struct {
+	Service string
+	Timeout bool
+	err     error
+}
+
+func (e *TransportError) Error() string {
+	if e.Timeout {
+		return e.Service + " timed out"
+	}
+	return e.Service + " is unreachable"
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.err
+}
+
+// CircuitOpenError is a call refused without contacting the upstream because recent calls kept failing.
+type CircuitOpenError struct {
+	Service string
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return e.Service + " is temporarily unavailable"
+}
+
+// Retryable reports whether another provider could succeed where err failed: the upstream rate limited the call
+// (429), failed (5xx), could not be reached, or is cut off by its circuit breaker. Nothing is retryable once the
+// caller's ctx is done.
+func Retryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var transportErr *TransportError
+	var circuitErr *CircuitOpenError
+	return errors.As(err, &transportErr) || errors.As(err, &circuitErr)
+}
+
+// WriteError answers an API client for a failed upstream call. The message names the kind of failure only; upstream
+// bodies, URLs and keys never reach the client. Errors that did not come from this package get a plain 502.
+func WriteError(w http.ResponseWriter, err error) {
+	var (
+		statusErr    *StatusError
+		transportErr *TransportError
+		circuitErr   *CircuitOpenError
+	)
+	switch {
+	case errors.As(err, &circuitErr):
+		setRetryAfter(w, time.Until(circuitErr.RetryAt))
+		http.Error(w, "Upstream service is temporarily unavailable", http.StatusServiceUnavailable)
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests:
+		setRetryAfter(w, statusErr.RetryAfter)
+		http.Error(w, "Upstream service is rate limited", http.StatusServiceUnavailable)
+	case errors.As(err, &transportErr) && transportErr.Timeout,
+		errors.Is(err, context.DeadlineExceeded):
+		http.Error(w, "Upstream service timed out", http.StatusGatewayTimeout)
+	case errors.As(err, &transportErr):
+		http.Error(w, "Upstream service is unreachable", http.StatusBadGateway)
+	default:
+		http.Error(w, "Upstream service failed", http.StatusBadGateway)
+	}
+}
+
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	if d > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((d+time.Second-1)/time.Second)))
+	}
+}
+
+// parseRetryAfter reads a Retry-After header in either form: delay seconds or an HTTP date.
+func parseRetryAfter(raw string, now time.Time) (time.Duration, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(raw); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(raw)
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
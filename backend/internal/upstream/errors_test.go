@@ -0,0 +1,64 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryable(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, Retryable(ctx, &StatusError{Service: "geoapify places search", StatusCode: 429}))
+	assert.True(t, Retryable(ctx, fmt.Errorf("search: %w", &StatusError{StatusCode: 503})))
+	assert.True(t, Retryable(ctx, &TransportError{Service: Geoapify, err: errors.New("connection refused")}))
+	assert.True(t, Retryable(ctx, &CircuitOpenError{Service: Geoapify}))
+	assert.False(t, Retryable(ctx, &StatusError{StatusCode: 400}))
+	assert.False(t, Retryable(ctx, errors.New("could not resolve city")))
+	assert.False(t, Retryable(ctx, nil))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, Retryable(cancelled, &StatusError{StatusCode: 503}), "the caller gave up")
+}
+
+func TestWriteError(t *testing.T) {
+	cases := []struct {
+		err        error
+		status     int
+		retryAfter string
+	}{
+		{&CircuitOpenError{Service: Geoapify, RetryAt: time.Now().Add(10 * time.Second)}, http.StatusServiceUnavailable, "10"},
+		{&StatusError{Service: Geoapify, StatusCode: 429, RetryAfter: 3 * time.Second}, http.StatusServiceUnavailable, "3"},
+		{&StatusError{Service: Geoapify, StatusCode: 500}, http.StatusBadGateway, ""},
+		{&TransportError{Service: Geoapify, Timeout: true}, http.StatusGatewayTimeout, ""},
+		{&TransportError{Service: Geoapify}, http.StatusBadGateway, ""},
+		{errors.New(`decode "apiKey=secret"`), http.StatusBadGateway, ""},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		WriteError(rec, tc.err)
+		assert.Equal(t, tc.status, rec.Code, tc.err.Error())
+		assert.Equal(t, tc.retryAfter, rec.Header().Get("Retry-After"), tc.err.Error())
+		assert.NotContains(t, rec.Body.String(), "secret")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	d, ok := parseRetryAfter("7", now)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, d)
+
+	d, ok = parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Second, d)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
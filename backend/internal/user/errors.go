@@ -0,0 +1,15 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
+)
+
+var ErrInvalidRole = errors.New("invalid role: must be user, moderator or admin")
+var ErrInvalidEmail = errors.New("a valid email is required")
+var ErrEmailTaken = errors.New("email is already registered")
+var ErrIncorrectPassword = errors.New("current password is incorrect")
+var ErrReauthenticationRequired = errors.New("sign in again to confirm it is you")
+var ErrRestoreEmailTaken = fmt.Errorf("%w: another account has registered this email since", audit.ErrRestoreConflict)
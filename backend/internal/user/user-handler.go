@@ -3,22 +3,48 @@ package user
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"gorm.io/gorm"
 )
 
+// SessionLookup is implemented by session service to tell when the caller signed in.
+type SessionLookup interface {
+	SignedInAt(sessionID uint) (time.Time, error)
+}
+
+// CredentialRevoker signs a user out of every session and API token except the caller's session, after a password
+// change; implemented by the server's session and API token revoker.
+type CredentialRevoker interface {
+	RevokeAllExceptSession(userID, keepSessionID uint) error
+}
+
 type Handler struct {
-	Service *Service
+	Service     *Service
+	Sessions    SessionLookup
+	Credentials CredentialRevoker
 }
 
-func RegisterRoutes(r chi.Router, service *Service, authMiddleware func(http.Handler) http.Handler) {
-	h := &Handler{Service: service}
+// RegisterRoutes registers user routes. /users is admin-only; self-service account routes live under /me.
+func RegisterRoutes(r chi.Router, service *Service, sessions SessionLookup, credentials CredentialRevoker, authMiddleware func(http.Handler) http.Handler) {
+	h := &Handler{Service: service, Sessions: sessions, Credentials: credentials}
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Get("/me", h.GetMeHandler)
+		r.Patch("/me", h.UpdateMeHandler)
+		r.Delete("/me", h.DeleteMeHandler)
+		r.Post("/me/password", h.ChangePasswordHandler)
+	})
 	r.Route("/users", func(r chi.Router) {
 		r.Use(authMiddleware)
+		r.Use(auth.RequireRole(auth.RoleAdmin))
 		r.Get("/", h.GetAllHandler)
 		r.Post("/", h.CreateHandler)
 		r.Get("/{id}", h.GetByIDHandler)
@@ -29,15 +55,30 @@ func RegisterRoutes(r chi.Router, service *Service, authMiddleware func(http.Han
 
 // GetAllHandler godoc
 // @Summary List users
-// @Description Returns all users.
+// @Description Returns users in sign-up order, one page at a time. Admin only.
 // @Tags users
 // @Produce json
-// @Success 200 {array} models.User
+// @Security BearerAuth
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} pagination.PageDoc{items=[]models.User}
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
 // @Failure 500 {string} string
 // @Router /users/ [get]
 func (h *Handler) GetAllHandler(w http.ResponseWriter, r *http.Request) {
-	users, err := h.Service.FindAll()
+	page, err := pagination.FromQuery(r.URL.Query(), pagination.DefaultLimit, pagination.MaxLimit)
+	if err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	users, err := h.Service.FindAll(page)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to retrieve users", http.StatusInternalServerError)
 		return
 	}
@@ -47,12 +88,15 @@ func (h *Handler) GetAllHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetByIDHandler godoc
 // @Summary Get user by ID
-// @Description Returns a single user by ID.
+// @Description Returns a single user by ID. Admin only.
 // @Tags users
 // @Produce json
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Success 200 {object} models.User
 // @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
 // @Failure 404 {string} string
 // @Failure 500 {string} string
 // @Router /users/{id} [get]
@@ -80,20 +124,49 @@ type CreateUserRequest struct {
 	Email    string `json:"email"`
 	Name     string `json:"name"`
 	Password string `json:"password"`
+	Role     string `json:"role,omitempty"`
+}
+
+type UpdateUserRequest struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Role  string `json:"role,omitempty"`
+}
+
+type UpdateMeRequest struct {
+	Email *string `json:"email,omitempty"`
+	Name  *string `json:"name,omitempty"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type DeleteMeRequest struct {
+	Password string `json:"password"`
 }
 
 // CreateHandler godoc
 // @Summary Create user
-// @Description Creates a new user.
+// @Description Creates a new user, optionally with a role. Admin only.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param body body CreateUserRequest true "Create user payload"
 // @Success 201 {object} models.User
 // @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
 // @Failure 500 {string} string
 // @Router /users/ [post]
 func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	var req CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -104,11 +177,21 @@ func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "email and password required", http.StatusBadRequest)
 		return
 	}
+	if req.Role != "" && !auth.IsValidRole(req.Role) {
+		http.Error(w, ErrInvalidRole.Error(), http.StatusBadRequest)
+		return
+	}
 	id, err := h.Service.CreateWithPassword(req.Email, req.Name, req.Password)
 	if err != nil {
 		http.Error(w, "Failed to create user", http.StatusInternalServerError)
 		return
 	}
+	if req.Role != "" {
+		if err := h.Service.SetRole(id, adminID, req.Role); err != nil {
+			http.Error(w, "Failed to set user role", http.StatusInternalServerError)
+			return
+		}
+	}
 	u, _ := h.Service.GetByID(id)
 	w.WriteHeader(http.StatusCreated)
 	if u != nil {
@@ -120,54 +203,85 @@ func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 
 // UpdateHandler godoc
 // @Summary Update user
-// @Description Updates an existing user by ID.
+// @Description Updates an existing user by ID, including their role. Admin only.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path int true "User ID"
-// @Param body body models.User true "Update user payload"
+// @Param body body UpdateUserRequest true "Update user payload"
 // @Success 200 {object} map[string]string
 // @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
 // @Failure 500 {string} string
 // @Router /users/{id} [put]
 func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		http.Error(w, "Invalid ID", http.StatusBadRequest)
 		return
 	}
-	var u models.User
-	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+	var req UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
-	if err := h.Service.UpdateUser(uint(id), u); err != nil {
+	if req.Role != "" && !auth.IsValidRole(req.Role) {
+		http.Error(w, ErrInvalidRole.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.Service.UpdateUser(uint(id), adminID, models.User{Email: req.Email, Name: req.Name}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, "Failed to update user", http.StatusInternalServerError)
 		return
 	}
+	if req.Role != "" {
+		if err := h.Service.SetRole(uint(id), adminID, req.Role); err != nil {
+			http.Error(w, "Failed to set user role", http.StatusInternalServerError)
+			return
+		}
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{"message": "updated"})
 }
 
 // DeleteHandler godoc
 // @Summary Delete user
-// @Description Deletes a user by ID.
+// @Description Soft-deletes a user by ID together with their cafe listings and ratings, and signs them out everywhere. An admin can restore the account within the restore window. Admin only.
 // @Tags users
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Success 204 {string} string
 // @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
 // @Failure 404 {string} string
 // @Failure 500 {string} string
 // @Router /users/{id} [delete]
 func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		http.Error(w, "Invalid ID", http.StatusBadRequest)
 		return
 	}
-	if err := h.Service.DeleteUser(uint(id)); err != nil {
+	if err := h.Service.DeleteUser(uint(id), adminID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			http.Error(w, "User not found", http.StatusNotFound)
 			return
@@ -177,3 +291,190 @@ func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// GetMeHandler godoc
+// @Summary Get my profile
+// @Description Returns the authenticated user's profile.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.User
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /me [get]
+func (h *Handler) GetMeHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	u, err := h.Service.GetByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve user", http.StatusInternalServerError)
+		return
+	}
+	if u == nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(u)
+}
+
+// UpdateMeHandler godoc
+// @Summary Update my profile
+// @Description Updates the authenticated user's name and/or email. Omitted fields are left unchanged.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body UpdateMeRequest true "Profile changes"
+// @Success 200 {object} models.User
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /me [patch]
+func (h *Handler) UpdateMeHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req UpdateMeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	u, err := h.Service.UpdateProfile(userID, req.Name, req.Email)
+	if err != nil {
+		if errors.Is(err, ErrInvalidEmail) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrEmailTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(u)
+}
+
+// ChangePasswordHandler godoc
+// @Summary Change my password
+// @Description Verifies the current password, stores the new one, and signs out every other session and revokes every personal access token. An account created through an external sign-in has no password: it omits current_password to set one, which needs a session signed in within the last 10 minutes (403 otherwise, including for API tokens).
+// @Tags me
+// @Accept json
+// @Security BearerAuth
+// @Param body body ChangePasswordRequest true "Password change payload"
+// @Success 204 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /me/password [post]
+func (h *Handler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.NewPassword == "" {
+		http.Error(w, "new_password required", http.StatusBadRequest)
+		return
+	}
+	signedInAt, err := h.signedInAt(r)
+	if err != nil {
+		http.Error(w, "Failed to change password", http.StatusInternalServerError)
+		return
+	}
+	if err := h.Service.ChangePassword(userID, req.CurrentPassword, req.NewPassword, signedInAt); err != nil {
+		if errors.Is(err, ErrIncorrectPassword) || errors.Is(err, ErrReauthenticationRequired) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, auth.ErrWeakPassword) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to change password", http.StatusInternalServerError)
+		return
+	}
+	if h.Credentials != nil {
+		// A caller using an API token has no session to keep, and loses that token with the rest.
+		sessionID, _ := auth.SessionIDFromContext(r.Context())
+		if err := h.Credentials.RevokeAllExceptSession(userID, sessionID); err != nil {
+			http.Error(w, "Password changed but failed to sign out other sessions", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteMeHandler godoc
+// @Summary Delete my account
+// @Description Re-checks the password (or, for an account without one, requires a session signed in within the last 10 minutes), then deletes the account with its cafe listings and ratings and signs out every session. The data is soft-deleted, so an admin can restore it within the restore window.
+// @Tags me
+// @Accept json
+// @Security BearerAuth
+// @Param body body DeleteMeRequest false "Password confirmation"
+// @Success 204 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /me [delete]
+func (h *Handler) DeleteMeHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req DeleteMeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	signedInAt, err := h.signedInAt(r)
+	if err != nil {
+		http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+	if err := h.Service.DeleteAccount(userID, req.Password, signedInAt); err != nil {
+		if errors.Is(err, ErrIncorrectPassword) || errors.Is(err, ErrReauthenticationRequired) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// signedInAt returns when the caller's session signed in, or the zero time for API tokens, which have no session.
+func (h *Handler) signedInAt(r *http.Request) (time.Time, error) {
+	sessionID, ok := auth.SessionIDFromContext(r.Context())
+	if !ok || h.Sessions == nil {
+		return time.Time{}, nil
+	}
+	return h.Sessions.SignedInAt(sessionID)
+}
@@ -2,20 +2,36 @@ package user
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"gorm.io/gorm"
 )
 
 type Storage interface {
 	Create(user *models.User) error
-	GetAll() ([]models.User, error)
+	GetAll(page pagination.Request) (pagination.Page[models.User], error)
 	GetByID(id uint) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
+	GetByEmailFold(email string) (*models.User, error)
 	Update(id uint, updated models.User) error
-	Delete(id uint) error
+	UpdateRole(id uint, role string) error
+	UpdatePasswordHash(id uint, passwordHash string) error
+	MarkEmailVerified(id uint, email string, at time.Time) error
+	DeleteWithContent(id uint) error
+	GetWithDeleted(id uint) (*models.User, error)
+	Restore(id uint, deletedAt time.Time) error
+	// Transaction runs fn with a Storage bound to tx, committing only if fn returns nil.
+	Transaction(fn func(tx *gorm.DB, store Storage) error) error
 }
 
+// idOrder pages users in sign-up order.
+var idOrder = pagination.Order[models.User]{Name: "id", Keys: []pagination.Key[models.User]{
+	{Column: "id", Value: func(u models.User) any { return u.ID }},
+}}
+
 type Repository struct {
 	db *gorm.DB
 }
@@ -24,14 +40,18 @@ func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{db: db}
 }
 
+func (r *Repository) Transaction(fn func(tx *gorm.DB, store Storage) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(tx, NewRepository(tx))
+	})
+}
+
 func (r *Repository) Create(u *models.User) error {
 	return r.db.Create(u).Error
 }
 
-func (r *Repository) GetAll() ([]models.User, error) {
-	var users []models.User
-	err := r.db.Find(&users).Error
-	return users, err
+func (r *Repository) GetAll(page pagination.Request) (pagination.Page[models.User], error) {
+	return pagination.Find(r.db.Model(&models.User{}), idOrder, page)
 }
 
 func (r *Repository) GetByID(id uint) (*models.User, error) {
@@ -52,20 +72,148 @@ func (r *Repository) GetByEmail(email string) (*models.User, error) {
 	return &u, err
 }
 
+// GetByEmailFold matches email case-insensitively, oldest account first.
+func (r *Repository) GetByEmailFold(email string) (*models.User, error) {
+	var u models.User
+	err := r.db.Where("LOWER(email) = LOWER(?)", email).Order("id").First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &u, err
+}
+
 func (r *Repository) Update(id uint, updated models.User) error {
 	var existing models.User
 	if err := r.db.First(&existing, id).Error; err != nil {
 		return err
 	}
+	if !strings.EqualFold(existing.Email, updated.Email) {
+		// A new address has not been verified yet.
+		existing.EmailVerifiedAt = nil
+	}
 	existing.Email = updated.Email
 	existing.Name = updated.Name
 	return r.db.Save(&existing).Error
 }
 
-func (r *Repository) Delete(id uint) error {
-	result := r.db.Delete(&models.User{}, id)
+func (r *Repository) UpdateRole(id uint, role string) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *Repository) UpdatePasswordHash(id uint, passwordHash string) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Update("password_hash", passwordHash)
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
 		return gorm.ErrRecordNotFound
 	}
-	return result.Error
+	return nil
+}
+
+// MarkEmailVerified sets email_verified_at only while the account still has the given email;
+// returns gorm.ErrRecordNotFound when the address changed after the link was sent.
+func (r *Repository) MarkEmailVerified(id uint, email string, at time.Time) error {
+	result := r.db.Model(&models.User{}).Where("id = ? AND email = ?", id, email).Update("email_verified_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteWithContent soft-deletes a user together with their saved places, their ratings and the cafes they added
+// that no one else has saved or rated, all with one deleted_at so Restore can bring back exactly that set. Cafes
+// other users still keep or review stay up. Sessions, reset and verification tokens, linked identities and API
+// tokens are removed outright: a restored account signs in again. Votes and reports stay; queries leave out the
+// ones by or about deleted accounts and content.
+func (r *Repository) DeleteWithContent(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.User{}).Where("id = ?", id).UpdateColumn("deleted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		for _, content := range []any{&models.SavedPlace{}, &models.Rating{}} {
+			if err := tx.Model(content).Where("user_id = ?", id).UpdateColumn("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		// With the user's own rows gone, a cafe they added is unused when no live saved place or rating points at it.
+		if err := tx.Model(&models.Cafe{}).Where("user_id = ?", id).
+			Where("id NOT IN (?)", tx.Model(&models.SavedPlace{}).Select("cafe_id")).
+			Where("id NOT IN (?)", tx.Model(&models.Rating{}).Select("cafe_listing_id")).
+			UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		for _, credential := range []any{&models.Session{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{}, &models.UserIdentity{}, &models.PersonalAccessToken{}} {
+			if err := tx.Where("user_id = ?", id).Delete(credential).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetWithDeleted returns the user whether or not the account is soft-deleted.
+func (r *Repository) GetWithDeleted(id uint) (*models.User, error) {
+	var u models.User
+	err := r.db.Unscoped().First(&u, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &u, err
+}
+
+// Restore undeletes the user and the cafes, saved places and ratings deleted with the account at deletedAt. Saved
+// places and ratings whose other side (author or cafe) has been deleted separately since stay deleted. Fails with
+// ErrRestoreEmailTaken when a live account has registered the email in the meantime.
+func (r *Repository) Restore(id uint, deletedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var u models.User
+		if err := tx.Unscoped().First(&u, id).Error; err != nil {
+			return err
+		}
+		var taken int64
+		if err := tx.Model(&models.User{}).Where("email = ?", u.Email).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrRestoreEmailTaken
+		}
+		result := tx.Unscoped().Model(&models.User{}).Where("id = ? AND deleted_at = ?", id, deletedAt).UpdateColumn("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Unscoped().Model(&models.Cafe{}).
+			Where("user_id = ? AND deleted_at = ?", id, deletedAt).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.SavedPlace{}).
+			Where("user_id = ? AND deleted_at = ?", id, deletedAt).
+			Where("cafe_id IN (?)", tx.Model(&models.Cafe{}).Select("id")).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Rating{}).
+			Where("user_id = ? AND deleted_at = ?", id, deletedAt).
+			Where("cafe_listing_id IN (?)", tx.Model(&models.Cafe{}).Select("id")).
+			UpdateColumn("deleted_at", nil).Error
+	})
 }
@@ -2,21 +2,32 @@ package user
 
 import (
 	"fmt"
+	"net/mail"
+	"strings"
+	"time"
 
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
 	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
+	"gorm.io/gorm"
 )
 
+// RecentSignInWindow is how long after signing in a password-less account may set a password or delete itself,
+// standing in for the current password it does not have.
+const RecentSignInWindow = 10 * time.Minute
+
 type Service struct {
-	store Storage
+	store   Storage
+	auditor audit.Recorder // nil records nothing
 }
 
-func NewService(store Storage) *Service {
-	return &Service{store: store}
+func NewService(store Storage, auditor audit.Recorder) *Service {
+	return &Service{store: store, auditor: auditor}
 }
 
-func (s *Service) FindAll() ([]models.User, error) {
-	return s.store.GetAll()
+func (s *Service) FindAll(page pagination.Request) (pagination.Page[models.User], error) {
+	return s.store.GetAll(page)
 }
 
 func (s *Service) GetByID(id uint) (*models.User, error) {
@@ -27,6 +38,11 @@ func (s *Service) GetByEmail(email string) (*models.User, error) {
 	return s.store.GetByEmail(email)
 }
 
+// GetByEmailFold returns the user whose email matches ignoring case (implements oidc.UserAccounts).
+func (s *Service) GetByEmailFold(email string) (*models.User, error) {
+	return s.store.GetByEmailFold(email)
+}
+
 // GetByEmailForAuth returns user id and password hash for auth (implements auth.LoginFinder).
 func (s *Service) GetByEmailForAuth(email string) (id uint, passwordHash string, err error) {
 	u, err := s.store.GetByEmail(email)
@@ -36,27 +52,273 @@ func (s *Service) GetByEmailForAuth(email string) (id uint, passwordHash string,
 	return u.ID, u.PasswordHash, nil
 }
 
+// GetRole returns the user's role for token issuance (implements auth.RoleLookup).
+func (s *Service) GetRole(userID uint) (string, error) {
+	u, err := s.store.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if u == nil {
+		return "", gorm.ErrRecordNotFound
+	}
+	if u.Role == "" {
+		return auth.RoleUser, nil
+	}
+	return u.Role, nil
+}
+
 // CreateWithPassword creates a user with hashed password (implements auth.RegisterCreator).
 func (s *Service) CreateWithPassword(email, name, password string) (id uint, err error) {
+	email, err = normalizeEmail(email)
+	if err != nil {
+		return 0, err
+	}
 	hash, err := auth.HashPassword(password)
 	if err != nil {
 		return 0, fmt.Errorf("hash password: %w", err)
 	}
-	u := &models.User{Email: email, Name: name, PasswordHash: hash}
+	u := &models.User{Email: email, Name: name, PasswordHash: hash, Role: auth.RoleUser}
 	if err := s.store.Create(u); err != nil {
 		return 0, err
 	}
 	return u.ID, nil
 }
 
+// CreateExternal creates a password-less account for an identity whose email the provider has verified
+// (implements oidc.UserAccounts).
+func (s *Service) CreateExternal(email, name string) (*models.User, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	u := &models.User{Email: email, Name: strings.TrimSpace(name), Role: auth.RoleUser, EmailVerifiedAt: &now}
+	if err := s.store.Create(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
 func (s *Service) CreateUser(user *models.User) error {
 	return s.store.Create(user)
 }
 
-func (s *Service) UpdateUser(id uint, updated models.User) error {
-	return s.store.Update(id, updated)
+// UpdateUser applies an admin's changes to a user's name and email.
+func (s *Service) UpdateUser(id, actorID uint, updated models.User) error {
+	return s.audited(id, actorID, func(store Storage) error { return store.Update(id, updated) })
 }
 
-func (s *Service) DeleteUser(id uint) error {
-	return s.store.Delete(id)
+// IsEmailVerified reports whether the user has confirmed their email (implements auth.VerificationChecker).
+func (s *Service) IsEmailVerified(userID uint) (bool, error) {
+	u, err := s.store.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if u == nil {
+		return false, gorm.ErrRecordNotFound
+	}
+	return u.EmailVerifiedAt != nil, nil
+}
+
+// MarkEmailVerified records that email was confirmed; fails with gorm.ErrRecordNotFound if the account's email has since changed.
+func (s *Service) MarkEmailVerified(id uint, email string, at time.Time) error {
+	return s.store.MarkEmailVerified(id, email, at)
+}
+
+// SetRole changes a user's role on actorID's behalf. The new role applies to access tokens issued from the next
+// login or refresh.
+func (s *Service) SetRole(id, actorID uint, role string) error {
+	role = strings.TrimSpace(strings.ToLower(role))
+	if !auth.IsValidRole(role) {
+		return ErrInvalidRole
+	}
+	return s.audited(id, actorID, func(store Storage) error { return store.UpdateRole(id, role) })
+}
+
+// UpdateProfile applies self-service name/email changes; nil fields are left unchanged.
+func (s *Service) UpdateProfile(id uint, name, email *string) (*models.User, error) {
+	existing, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	updated := *existing
+	if name != nil {
+		updated.Name = strings.TrimSpace(*name)
+	}
+	if email != nil {
+		normalized, err := normalizeEmail(*email)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(normalized, existing.Email) {
+			taken, err := s.store.GetByEmail(normalized)
+			if err != nil {
+				return nil, err
+			}
+			if taken != nil && taken.ID != id {
+				return nil, ErrEmailTaken
+			}
+		}
+		updated.Email = normalized
+	}
+	err = s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if err := store.Update(id, updated); err != nil {
+			return err
+		}
+		return s.record(tx, id, audit.ActionUpdate, id, existing, &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteUser soft-deletes a user with their listings and ratings on an admin's behalf.
+func (s *Service) DeleteUser(id, actorID uint) error {
+	existing, err := s.store.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return gorm.ErrRecordNotFound
+	}
+	return s.deleteWithContent(id, actorID, existing)
+}
+
+// Restore brings back a soft-deleted account with the listings and ratings deleted along with it (implements
+// audit.Restorer). Its sessions and tokens are not restored.
+func (s *Service) Restore(id, actorID uint, deletedSince time.Time) error {
+	deleted, err := s.store.GetWithDeleted(id)
+	if err != nil {
+		return err
+	}
+	if deleted == nil {
+		return gorm.ErrRecordNotFound
+	}
+	if !deleted.DeletedAt.Valid {
+		return audit.ErrNotDeleted
+	}
+	if deleted.DeletedAt.Time.Before(deletedSince) {
+		return audit.ErrRestoreWindowPassed
+	}
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if err := store.Restore(id, deleted.DeletedAt.Time); err != nil {
+			return err
+		}
+		restored, err := store.GetByID(id)
+		if err != nil {
+			return err
+		}
+		return s.record(tx, actorID, audit.ActionRestore, id, nil, restored)
+	})
+}
+
+// ChangePassword verifies the current password before storing a hash of the new one. A password-less account
+// (signed up through a provider) sets its first password instead, which needs a sign-in at signedInAt within
+// RecentSignInWindow; the zero time means the caller did not sign in with a session, e.g. an API token.
+func (s *Service) ChangePassword(id uint, currentPassword, newPassword string, signedInAt time.Time) error {
+	if _, err := s.confirmIdentity(id, currentPassword, signedInAt); err != nil {
+		return err
+	}
+	return s.SetPassword(id, newPassword)
+}
+
+// SetPassword stores a hash of newPassword without checking the old one (used by password reset).
+func (s *Service) SetPassword(id uint, newPassword string) error {
+	if err := auth.ValidatePasswordStrength(newPassword); err != nil {
+		return err
+	}
+	hash, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	return s.store.UpdatePasswordHash(id, hash)
+}
+
+// ClearPassword removes the user's password so only their linked sign-in providers can log them in
+// (implements oidc.UserAccounts).
+func (s *Service) ClearPassword(id uint) error {
+	return s.store.UpdatePasswordHash(id, "")
+}
+
+// DeleteAccount re-checks the password, or for a password-less account a recent sign-in as in ChangePassword,
+// then soft-deletes the user with their listings and ratings and signs them out everywhere.
+func (s *Service) DeleteAccount(id uint, password string, signedInAt time.Time) error {
+	existing, err := s.confirmIdentity(id, password, signedInAt)
+	if err != nil {
+		return err
+	}
+	return s.deleteWithContent(id, id, existing)
+}
+
+// deleteWithContent soft-deletes user id with their listings and ratings, and records it in the same transaction.
+func (s *Service) deleteWithContent(id, actorID uint, existing *models.User) error {
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if err := store.DeleteWithContent(id); err != nil {
+			return err
+		}
+		return s.record(tx, actorID, audit.ActionDelete, id, existing, nil)
+	})
+}
+
+// audited runs an update of user id and records it with the user as it was before and after, in one transaction.
+func (s *Service) audited(id, actorID uint, update func(store Storage) error) error {
+	before, err := s.store.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return gorm.ErrRecordNotFound
+	}
+	return s.store.Transaction(func(tx *gorm.DB, store Storage) error {
+		if err := update(store); err != nil {
+			return err
+		}
+		after, err := store.GetByID(id)
+		if err != nil {
+			return err
+		}
+		return s.record(tx, actorID, audit.ActionUpdate, id, before, after)
+	})
+}
+
+// record audits a change to a user through tx, the transaction making it.
+func (s *Service) record(tx *gorm.DB, actorID uint, action string, id uint, before, after *models.User) error {
+	if s.auditor == nil {
+		return nil
+	}
+	return s.auditor.Record(tx, audit.Change{ActorID: actorID, Action: action, EntityType: audit.EntityUser, EntityID: id, Before: before, After: after})
+}
+
+// confirmIdentity checks the user's password, or a recent sign-in when the account has no password.
+func (s *Service) confirmIdentity(id uint, password string, signedInAt time.Time) (*models.User, error) {
+	u, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if u.PasswordHash == "" {
+		if signedInAt.IsZero() || time.Since(signedInAt) > RecentSignInWindow {
+			return nil, ErrReauthenticationRequired
+		}
+		return u, nil
+	}
+	if !auth.CheckPassword(password, u.PasswordHash) {
+		return nil, ErrIncorrectPassword
+	}
+	return u, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	trimmed := strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(trimmed)
+	if err != nil || addr.Address != trimmed {
+		return "", ErrInvalidEmail
+	}
+	return trimmed, nil
 }
@@ -2,8 +2,10 @@ package user
 
 import (
 	"testing"
+	"time"
 
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
@@ -48,6 +50,32 @@ func TestRepository_GetByEmail(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, got)
 	assert.Equal(t, "x@y.com", got.Email)
+
+	folded, err := repo.GetByEmailFold("X@Y.com")
+	require.NoError(t, err)
+	require.NotNil(t, folded)
+	assert.Equal(t, got.ID, folded.ID)
+}
+
+func TestRepository_GetAll_Pages(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	_ = db.AutoMigrate(&models.User{})
+	repo := NewRepository(db)
+	for _, email := range []string{"a@b.com", "b@b.com", "c@b.com"} {
+		require.NoError(t, repo.Create(&models.User{Email: email}))
+	}
+
+	first, err := repo.GetAll(pagination.Request{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Items, 2)
+	assert.Equal(t, "a@b.com", first.Items[0].Email)
+	require.NotNil(t, first.NextCursor)
+
+	second, err := repo.GetAll(pagination.Request{Limit: 2, Cursor: *first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Items, 1)
+	assert.Equal(t, "c@b.com", second.Items[0].Email)
+	assert.Nil(t, second.NextCursor)
 }
 
 func TestRepository_Update(t *testing.T) {
@@ -66,16 +94,144 @@ func TestRepository_Update(t *testing.T) {
 	assert.Equal(t, "New", got.Name)
 }
 
-func TestRepository_Delete(t *testing.T) {
+func TestRepository_DeletedEmailCanRegisterAgain(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	_ = db.AutoMigrate(&models.User{})
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Cafe{}, &models.SavedPlace{}, &models.Rating{}, &models.Session{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{}, &models.UserIdentity{}, &models.PersonalAccessToken{}))
 	repo := NewRepository(db)
 	u := &models.User{Email: "d@d.com", Name: "D", PasswordHash: "h"}
-	_ = repo.Create(u)
+	require.NoError(t, repo.Create(u))
+	assert.Error(t, repo.Create(&models.User{Email: "d@d.com"}), "live accounts keep unique emails")
+
+	require.NoError(t, repo.DeleteWithContent(u.ID))
+	got, _ := repo.GetByID(u.ID)
+	assert.Nil(t, got)
+	got, _ = repo.GetByEmail("d@d.com")
+	assert.Nil(t, got)
 
-	err := repo.Delete(u.ID)
+	again := &models.User{Email: "d@d.com", Name: "D2", PasswordHash: "h"}
+	require.NoError(t, repo.Create(again))
+	deleted, err := repo.GetWithDeleted(u.ID)
 	require.NoError(t, err)
+	assert.ErrorIs(t, repo.Restore(u.ID, deleted.DeletedAt.Time), ErrRestoreEmailTaken)
+}
+
+func TestRepository_EmailVerification(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	_ = db.AutoMigrate(&models.User{})
+	repo := NewRepository(db)
+	u := &models.User{Email: "v@v.com", Name: "V", PasswordHash: "h"}
+	_ = repo.Create(u)
 
+	assert.ErrorIs(t, repo.MarkEmailVerified(u.ID, "other@v.com", time.Now()), gorm.ErrRecordNotFound)
+	require.NoError(t, repo.MarkEmailVerified(u.ID, "v@v.com", time.Now()))
 	got, _ := repo.GetByID(u.ID)
+	require.NotNil(t, got.EmailVerifiedAt)
+
+	// Renaming keeps verification, changing the address clears it.
+	require.NoError(t, repo.Update(u.ID, models.User{Email: "v@v.com", Name: "Vee"}))
+	got, _ = repo.GetByID(u.ID)
+	assert.NotNil(t, got.EmailVerifiedAt)
+	require.NoError(t, repo.Update(u.ID, models.User{Email: "new@v.com", Name: "Vee"}))
+	got, _ = repo.GetByID(u.ID)
+	assert.Nil(t, got.EmailVerifiedAt)
+}
+
+func TestRepository_UpdateRole(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	_ = db.AutoMigrate(&models.User{})
+	repo := NewRepository(db)
+	u := &models.User{Email: "r@r.com", Name: "R", PasswordHash: "h"}
+	_ = repo.Create(u)
+
+	got, _ := repo.GetByID(u.ID)
+	require.NotNil(t, got)
+	assert.Equal(t, "user", got.Role)
+
+	require.NoError(t, repo.UpdateRole(u.ID, "admin"))
+	got, _ = repo.GetByID(u.ID)
+	assert.Equal(t, "admin", got.Role)
+
+	assert.ErrorIs(t, repo.UpdateRole(999, "admin"), gorm.ErrRecordNotFound)
+}
+
+func TestRepository_DeleteWithContent(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Cafe{}, &models.SavedPlace{}, &models.Rating{}, &models.Session{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{}, &models.UserIdentity{}, &models.PersonalAccessToken{}, &models.RatingVote{}, &models.ContentReport{}))
+	repo := NewRepository(db)
+	owner := &models.User{Email: "o@o.com", PasswordHash: "h"}
+	other := &models.User{Email: "x@x.com", PasswordHash: "h"}
+	require.NoError(t, repo.Create(owner))
+	require.NoError(t, repo.Create(other))
+
+	ownListing := &models.Cafe{UserID: owner.ID, Name: "Mine"}
+	otherListing := &models.Cafe{UserID: other.ID, Name: "Theirs"}
+	unusedListing := &models.Cafe{UserID: owner.ID, Name: "Only mine"}
+	require.NoError(t, db.Create(ownListing).Error)
+	require.NoError(t, db.Create(otherListing).Error)
+	require.NoError(t, db.Create(unusedListing).Error)
+	for _, place := range []models.SavedPlace{
+		{UserID: owner.ID, CafeID: ownListing.ID},
+		{UserID: owner.ID, CafeID: unusedListing.ID},
+		{UserID: other.ID, CafeID: otherListing.ID},
+	} {
+		require.NoError(t, db.Create(&place).Error)
+	}
+	require.NoError(t, db.Create(&models.Rating{UserID: owner.ID, CafeListingID: otherListing.ID, Rating: 4}).Error)
+	require.NoError(t, db.Create(&models.Rating{UserID: other.ID, CafeListingID: ownListing.ID, Rating: 5}).Error)
+	require.NoError(t, db.Create(&models.Rating{UserID: other.ID, CafeListingID: otherListing.ID, Rating: 3}).Error)
+	// Votes by and on the owner are kept.
+	require.NoError(t, db.Create(&models.RatingVote{UserID: owner.ID, RatingID: 3}).Error)
+	require.NoError(t, db.Create(&models.RatingVote{UserID: other.ID, RatingID: 1}).Error)
+	// Reports by the owner and on the owner's content are kept too; the moderation queue skips deleted content.
+	require.NoError(t, db.Create(&models.ContentReport{ReporterID: owner.ID, TargetType: "rating", TargetID: 3, Reason: "spam", Status: "open"}).Error)
+	require.NoError(t, db.Create(&models.ContentReport{ReporterID: other.ID, TargetType: "cafe_listing", TargetID: ownListing.ID, Reason: "spam", Status: "open"}).Error)
+	require.NoError(t, db.Create(&models.ContentReport{ReporterID: other.ID, TargetType: "cafe_listing", TargetID: otherListing.ID, Reason: "spam", Status: "dismissed", ResolvedByID: &owner.ID}).Error)
+
+	require.NoError(t, db.Create(&models.Session{UserID: owner.ID}).Error)
+
+	require.NoError(t, repo.DeleteWithContent(owner.ID))
+
+	// The owner's saved places and rating are soft-deleted, and so is the cafe only they used. The cafe the other
+	// user rated stays up for them. Everything deleted is kept for a restore.
+	var listings, ratings, places, allListings, allRatings, allPlaces, votes, sessions int64
+	db.Model(&models.Cafe{}).Count(&listings)
+	db.Model(&models.Rating{}).Count(&ratings)
+	db.Model(&models.SavedPlace{}).Count(&places)
+	db.Unscoped().Model(&models.Cafe{}).Count(&allListings)
+	db.Unscoped().Model(&models.Rating{}).Count(&allRatings)
+	db.Unscoped().Model(&models.SavedPlace{}).Count(&allPlaces)
+	db.Model(&models.RatingVote{}).Count(&votes)
+	db.Model(&models.Session{}).Count(&sessions)
+	assert.Equal(t, int64(2), listings)
+	assert.Equal(t, int64(2), ratings)
+	assert.Equal(t, int64(1), places)
+	assert.Equal(t, int64(3), allListings)
+	assert.Equal(t, int64(3), allRatings)
+	assert.Equal(t, int64(3), allPlaces)
+	var unused models.Cafe
+	require.NoError(t, db.Unscoped().First(&unused, unusedListing.ID).Error)
+	assert.True(t, unused.DeletedAt.Valid)
+	assert.Equal(t, int64(2), votes, "votes stay; vote counts skip deleted accounts and ratings")
+	assert.Zero(t, sessions, "credentials are removed outright")
+	var reports int64
+	db.Model(&models.ContentReport{}).Count(&reports)
+	assert.Equal(t, int64(3), reports)
+	got, _ := repo.GetByID(owner.ID)
 	assert.Nil(t, got)
+
+	// Deleting again finds no live account.
+	assert.ErrorIs(t, repo.DeleteWithContent(owner.ID), gorm.ErrRecordNotFound)
+
+	deleted, err := repo.GetWithDeleted(owner.ID)
+	require.NoError(t, err)
+	require.True(t, deleted.DeletedAt.Valid)
+	require.NoError(t, repo.Restore(owner.ID, deleted.DeletedAt.Time))
+	db.Model(&models.Cafe{}).Count(&listings)
+	db.Model(&models.Rating{}).Count(&ratings)
+	db.Model(&models.SavedPlace{}).Count(&places)
+	assert.Equal(t, int64(3), listings)
+	assert.Equal(t, int64(3), ratings)
+	assert.Equal(t, int64(3), places)
+	got, _ = repo.GetByID(owner.ID)
+	assert.NotNil(t, got)
 }
@@ -3,10 +3,15 @@ package user
 import (
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/khorzhenwin/go-cafe/backend/internal/audit"
+	"github.com/khorzhenwin/go-cafe/backend/internal/auth"
 	"github.com/khorzhenwin/go-cafe/backend/internal/models"
+	"github.com/khorzhenwin/go-cafe/backend/internal/pagination"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 type mockStorage struct {
@@ -18,6 +23,9 @@ type mockStorage struct {
 	getByEmailErr error
 	updateErr   error
 	deleteErr   error
+
+	withDeleted *models.User
+	restoredAt  *time.Time
 }
 
 func (m *mockStorage) Create(u *models.User) error {
@@ -31,7 +39,9 @@ func (m *mockStorage) Create(u *models.User) error {
 	return nil
 }
 
-func (m *mockStorage) GetAll() ([]models.User, error) { return m.users, nil }
+func (m *mockStorage) GetAll(page pagination.Request) (pagination.Page[models.User], error) {
+	return pagination.Page[models.User]{Items: m.users}, nil
+}
 
 func (m *mockStorage) GetByID(id uint) (*models.User, error) {
 	if m.getByIDErr != nil {
@@ -47,13 +57,58 @@ func (m *mockStorage) GetByEmail(email string) (*models.User, error) {
 	return m.getByEmail, nil
 }
 
+func (m *mockStorage) GetByEmailFold(email string) (*models.User, error) {
+	return m.GetByEmail(email)
+}
+
 func (m *mockStorage) Update(id uint, updated models.User) error { return m.updateErr }
 
-func (m *mockStorage) Delete(id uint) error { return m.deleteErr }
+func (m *mockStorage) UpdateRole(id uint, role string) error { return m.updateErr }
+
+func (m *mockStorage) UpdatePasswordHash(id uint, passwordHash string) error {
+	if m.getByID != nil {
+		m.getByID.PasswordHash = passwordHash
+	}
+	return m.updateErr
+}
+
+func (m *mockStorage) DeleteWithContent(id uint) error { return m.deleteErr }
+
+func (m *mockStorage) GetWithDeleted(id uint) (*models.User, error) { return m.withDeleted, nil }
+
+func (m *mockStorage) Restore(id uint, deletedAt time.Time) error {
+	m.restoredAt = &deletedAt
+	return nil
+}
+
+func (m *mockStorage) Transaction(fn func(tx *gorm.DB, store Storage) error) error {
+	return fn(nil, m)
+}
+
+type recordingAuditor struct {
+	changes []audit.Change
+	err     error
+}
+
+func (r *recordingAuditor) Record(tx *gorm.DB, change audit.Change) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func (m *mockStorage) MarkEmailVerified(id uint, email string, at time.Time) error {
+	if m.getByID == nil || m.getByID.Email != email {
+		return gorm.ErrRecordNotFound
+	}
+	m.getByID.EmailVerifiedAt = &at
+	return nil
+}
 
 func TestService_CreateWithPassword(t *testing.T) {
 	m := &mockStorage{}
-	svc := NewService(m)
+	svc := NewService(m, nil)
 	id, err := svc.CreateWithPassword("a@b.com", "Alice", "pass123")
 	require.NoError(t, err)
 	assert.NotZero(t, id)
@@ -64,9 +119,46 @@ func TestService_CreateWithPassword(t *testing.T) {
 	assert.NotEqual(t, "pass123", m.users[0].PasswordHash)
 }
 
+func TestService_CreateExternal(t *testing.T) {
+	m := &mockStorage{}
+	svc := NewService(m, nil)
+	u, err := svc.CreateExternal("sso@b.com", " SSO User ")
+	require.NoError(t, err)
+	require.Len(t, m.users, 1)
+	assert.Equal(t, "SSO User", u.Name)
+	assert.Empty(t, m.users[0].PasswordHash)
+	assert.NotNil(t, m.users[0].EmailVerifiedAt)
+
+	_, err = svc.CreateExternal("nope", "")
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+}
+
+func TestService_CreateWithPassword_InvalidEmail(t *testing.T) {
+	m := &mockStorage{}
+	svc := NewService(m, nil)
+	_, err := svc.CreateWithPassword("not-an-email", "A", "pass123")
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+	assert.Empty(t, m.users)
+}
+
+func TestService_IsEmailVerified(t *testing.T) {
+	m := &mockStorage{getByID: &models.User{ID: 1, Email: "a@b.com"}}
+	svc := NewService(m, nil)
+
+	verified, err := svc.IsEmailVerified(1)
+	require.NoError(t, err)
+	assert.False(t, verified)
+
+	assert.ErrorIs(t, svc.MarkEmailVerified(1, "old@b.com", time.Now()), gorm.ErrRecordNotFound)
+	require.NoError(t, svc.MarkEmailVerified(1, "a@b.com", time.Now()))
+	verified, err = svc.IsEmailVerified(1)
+	require.NoError(t, err)
+	assert.True(t, verified)
+}
+
 func TestService_GetByEmailForAuth_NotFound(t *testing.T) {
 	m := &mockStorage{getByEmail: nil}
-	svc := NewService(m)
+	svc := NewService(m, nil)
 	id, hash, err := svc.GetByEmailForAuth("x@y.com")
 	require.NoError(t, err)
 	assert.Zero(t, id)
@@ -75,7 +167,7 @@ func TestService_GetByEmailForAuth_NotFound(t *testing.T) {
 
 func TestService_GetByEmailForAuth_Found(t *testing.T) {
 	m := &mockStorage{getByEmail: &models.User{ID: 3, Email: "u@v.com", PasswordHash: "hashed"}}
-	svc := NewService(m)
+	svc := NewService(m, nil)
 	id, hash, err := svc.GetByEmailForAuth("u@v.com")
 	require.NoError(t, err)
 	assert.Equal(t, uint(3), id)
@@ -84,7 +176,121 @@ func TestService_GetByEmailForAuth_Found(t *testing.T) {
 
 func TestService_CreateWithPassword_PropagatesError(t *testing.T) {
 	m := &mockStorage{createErr: errors.New("db error")}
-	svc := NewService(m)
+	svc := NewService(m, nil)
 	_, err := svc.CreateWithPassword("a@b.com", "A", "p")
 	assert.Error(t, err)
 }
+
+func TestService_GetRole_DefaultsToUser(t *testing.T) {
+	m := &mockStorage{getByID: &models.User{ID: 1}}
+	svc := NewService(m, nil)
+	role, err := svc.GetRole(1)
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleUser, role)
+}
+
+func TestService_SetRole_Invalid(t *testing.T) {
+	svc := NewService(&mockStorage{getByID: &models.User{ID: 1, Role: auth.RoleUser}}, nil)
+	assert.ErrorIs(t, svc.SetRole(1, 2, "root"), ErrInvalidRole)
+	assert.NoError(t, svc.SetRole(1, 2, " Admin "))
+	assert.ErrorIs(t, NewService(&mockStorage{}, nil).SetRole(1, 2, "admin"), gorm.ErrRecordNotFound)
+}
+
+func TestService_SetRole_FailsWithAuditWrite(t *testing.T) {
+	auditErr := errors.New("audit table unavailable")
+	svc := NewService(&mockStorage{getByID: &models.User{ID: 1, Role: auth.RoleUser}}, &recordingAuditor{err: auditErr})
+	assert.ErrorIs(t, svc.SetRole(1, 2, "admin"), auditErr)
+}
+
+func TestService_UpdateProfile(t *testing.T) {
+	m := &mockStorage{getByID: &models.User{ID: 1, Email: "old@x.com", Name: "Old"}}
+	svc := NewService(m, nil)
+	name := " New "
+	u, err := svc.UpdateProfile(1, &name, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "New", u.Name)
+	assert.Equal(t, "old@x.com", u.Email)
+}
+
+func TestService_UpdateProfile_EmailRules(t *testing.T) {
+	m := &mockStorage{
+		getByID:    &models.User{ID: 1, Email: "old@x.com"},
+		getByEmail: &models.User{ID: 2, Email: "taken@x.com"},
+	}
+	svc := NewService(m, nil)
+	bad := "not-an-email"
+	_, err := svc.UpdateProfile(1, nil, &bad)
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+
+	taken := "taken@x.com"
+	_, err = svc.UpdateProfile(1, nil, &taken)
+	assert.ErrorIs(t, err, ErrEmailTaken)
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	hash, err := auth.HashPassword("oldpass123")
+	require.NoError(t, err)
+	m := &mockStorage{getByID: &models.User{ID: 1, PasswordHash: hash}}
+	svc := NewService(m, nil)
+
+	assert.ErrorIs(t, svc.ChangePassword(1, "wrong", "newpass123", time.Time{}), ErrIncorrectPassword)
+	assert.ErrorIs(t, svc.ChangePassword(1, "oldpass123", "short", time.Time{}), auth.ErrWeakPassword)
+	// A recent sign-in does not replace the password of an account that has one.
+	assert.ErrorIs(t, svc.ChangePassword(1, "", "newpass123", time.Now()), ErrIncorrectPassword)
+
+	require.NoError(t, svc.ChangePassword(1, "oldpass123", "newpass123", time.Time{}))
+	assert.True(t, auth.CheckPassword("newpass123", m.getByID.PasswordHash))
+}
+
+func TestService_ChangePassword_PasswordlessAccountNeedsRecentSignIn(t *testing.T) {
+	m := &mockStorage{getByID: &models.User{ID: 1, Email: "oidc@x.com"}}
+	svc := NewService(m, nil)
+
+	assert.ErrorIs(t, svc.ChangePassword(1, "", "newpass123", time.Time{}), ErrReauthenticationRequired)
+	assert.ErrorIs(t, svc.ChangePassword(1, "", "newpass123", time.Now().Add(-RecentSignInWindow-time.Minute)), ErrReauthenticationRequired)
+	assert.Empty(t, m.getByID.PasswordHash)
+
+	require.NoError(t, svc.ChangePassword(1, "", "newpass123", time.Now().Add(-time.Minute)))
+	assert.True(t, auth.CheckPassword("newpass123", m.getByID.PasswordHash))
+}
+
+func TestService_DeleteAccount_RequiresPassword(t *testing.T) {
+	hash, _ := auth.HashPassword("secret123")
+	m := &mockStorage{getByID: &models.User{ID: 1, PasswordHash: hash}}
+	svc := NewService(m, nil)
+
+	assert.ErrorIs(t, svc.DeleteAccount(1, "nope", time.Time{}), ErrIncorrectPassword)
+	assert.NoError(t, svc.DeleteAccount(1, "secret123", time.Time{}))
+}
+
+func TestService_DeleteAccount_PasswordlessAccountNeedsRecentSignIn(t *testing.T) {
+	m := &mockStorage{getByID: &models.User{ID: 1, Email: "oidc@x.com"}}
+	svc := NewService(m, nil)
+
+	assert.ErrorIs(t, svc.DeleteAccount(1, "", time.Time{}), ErrReauthenticationRequired)
+	assert.ErrorIs(t, svc.DeleteAccount(1, "anything", time.Now().Add(-time.Hour)), ErrReauthenticationRequired)
+	assert.NoError(t, svc.DeleteAccount(1, "", time.Now()))
+}
+
+func TestService_DeleteAndRestoreUser_Audited(t *testing.T) {
+	m := &mockStorage{getByID: &models.User{ID: 1, Email: "gone@x.com"}}
+	auditor := &recordingAuditor{}
+	svc := NewService(m, auditor)
+
+	require.NoError(t, svc.DeleteUser(1, 7))
+	require.Len(t, auditor.changes, 1)
+	assert.Equal(t, audit.Change{ActorID: 7, Action: audit.ActionDelete, EntityType: audit.EntityUser, EntityID: 1, Before: m.getByID, After: (*models.User)(nil)}, auditor.changes[0])
+
+	since := time.Now().Add(-time.Hour)
+	assert.ErrorIs(t, svc.Restore(1, 7, since), gorm.ErrRecordNotFound)
+	m.withDeleted = &models.User{ID: 1, Email: "gone@x.com", DeletedAt: gorm.DeletedAt{Time: since.Add(-time.Second), Valid: true}}
+	assert.ErrorIs(t, svc.Restore(1, 7, since), audit.ErrRestoreWindowPassed)
+	assert.Nil(t, m.restoredAt)
+
+	m.withDeleted.DeletedAt.Time = since.Add(time.Minute)
+	require.NoError(t, svc.Restore(1, 7, since))
+	require.NotNil(t, m.restoredAt)
+	require.Len(t, auditor.changes, 2)
+	assert.Equal(t, audit.ActionRestore, auditor.changes[1].Action)
+	assert.Nil(t, auditor.changes[1].Before)
+}
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS gocafe_sessions (
+    id                  SERIAL PRIMARY KEY,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    user_id             BIGINT NOT NULL,
+    refresh_token_hash  VARCHAR(64) NOT NULL UNIQUE,
+    previous_token_hash VARCHAR(64) DEFAULT '',
+    user_agent          VARCHAR(255) DEFAULT '',
+    ip_address          VARCHAR(64) DEFAULT '',
+    expires_at          TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at        TIMESTAMP WITH TIME ZONE,
+    revoked_at          TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_gocafe_sessions_user FOREIGN KEY (user_id) REFERENCES gocafe_users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_sessions_user_id ON gocafe_sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_sessions_previous_token_hash ON gocafe_sessions (previous_token_hash);
+CREATE INDEX IF NOT EXISTS idx_gocafe_sessions_revoked_at ON gocafe_sessions (revoked_at);
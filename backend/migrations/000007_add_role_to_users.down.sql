@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_gocafe_users_role;
+
+ALTER TABLE gocafe_users
+DROP CONSTRAINT IF EXISTS chk_gocafe_users_role;
+
+ALTER TABLE gocafe_users
+DROP COLUMN IF EXISTS role;
@@ -0,0 +1,11 @@
+ALTER TABLE gocafe_users
+ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'user';
+
+ALTER TABLE gocafe_users
+DROP CONSTRAINT IF EXISTS chk_gocafe_users_role;
+
+ALTER TABLE gocafe_users
+ADD CONSTRAINT chk_gocafe_users_role
+CHECK (role IN ('user', 'moderator', 'admin'));
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_users_role ON gocafe_users (role);
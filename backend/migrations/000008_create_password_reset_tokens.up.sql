@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS gocafe_password_reset_tokens (
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    user_id    BIGINT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_gocafe_password_reset_tokens_user FOREIGN KEY (user_id) REFERENCES gocafe_users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_password_reset_tokens_user_id ON gocafe_password_reset_tokens (user_id);
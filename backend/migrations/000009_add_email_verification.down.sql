@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS gocafe_email_verification_tokens;
+
+ALTER TABLE gocafe_users DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,14 @@
+ALTER TABLE gocafe_users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS gocafe_email_verification_tokens (
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    user_id    BIGINT NOT NULL,
+    email      VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_gocafe_email_verification_tokens_user FOREIGN KEY (user_id) REFERENCES gocafe_users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_email_verification_tokens_user_id ON gocafe_email_verification_tokens (user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS gocafe_login_lockouts;
+
+DROP TABLE IF EXISTS gocafe_login_attempts;
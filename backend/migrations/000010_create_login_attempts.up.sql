@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS gocafe_login_attempts (
+    key            VARCHAR(320) PRIMARY KEY,
+    failures       INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS gocafe_login_lockouts (
+    id           SERIAL PRIMARY KEY,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    scope        VARCHAR(16) NOT NULL,
+    subject      VARCHAR(320) NOT NULL,
+    ip_address   VARCHAR(64) DEFAULT '',
+    failures     INTEGER NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_login_lockouts_created_at ON gocafe_login_lockouts (created_at);
+CREATE INDEX IF NOT EXISTS idx_gocafe_login_lockouts_subject ON gocafe_login_lockouts (subject);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS gocafe_user_identities (
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    user_id    BIGINT NOT NULL,
+    provider   VARCHAR(64) NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    email      VARCHAR(255) DEFAULT '',
+    CONSTRAINT fk_gocafe_user_identities_user FOREIGN KEY (user_id) REFERENCES gocafe_users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gocafe_user_identities_provider_subject ON gocafe_user_identities (provider, subject);
+CREATE INDEX IF NOT EXISTS idx_gocafe_user_identities_user_id ON gocafe_user_identities (user_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS gocafe_personal_access_tokens;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS gocafe_personal_access_tokens (
+    id           SERIAL PRIMARY KEY,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    user_id      BIGINT NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    token_hash   VARCHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(32) NOT NULL,
+    scopes       TEXT NOT NULL,
+    expires_at   TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at   TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_gocafe_personal_access_tokens_user FOREIGN KEY (user_id) REFERENCES gocafe_users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_personal_access_tokens_user_id ON gocafe_personal_access_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_personal_access_tokens_revoked_at ON gocafe_personal_access_tokens (revoked_at);
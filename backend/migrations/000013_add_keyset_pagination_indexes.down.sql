@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_gocafe_ratings_cafe_listing_id_visited_at;
+DROP INDEX IF EXISTS idx_gocafe_ratings_user_id_visited_at;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_user_id_name;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_user_id_created_at;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_user_id_updated_at;
@@ -0,0 +1,6 @@
+-- Composite indexes backing the keyset (cursor) orders of the paged list endpoints.
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_user_id_updated_at ON gocafe_cafe_listings (user_id, updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_user_id_created_at ON gocafe_cafe_listings (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_user_id_name ON gocafe_cafe_listings (user_id, name, id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_ratings_user_id_visited_at ON gocafe_ratings (user_id, visited_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_gocafe_ratings_cafe_listing_id_visited_at ON gocafe_ratings (cafe_listing_id, visited_at DESC, id DESC);
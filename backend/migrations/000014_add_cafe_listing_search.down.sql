@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_name_trgm;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_search_vector;
+
+ALTER TABLE gocafe_cafe_listings
+DROP COLUMN IF EXISTS search_vector;
+
+-- pg_trgm is left installed; other objects in the database may depend on it.
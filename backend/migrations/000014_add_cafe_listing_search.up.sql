@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Weighted full-text document: name (A), neighborhood and city (B), address (C), description (D).
+-- A generated column keeps it current on every insert and update.
+ALTER TABLE gocafe_cafe_listings
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(neighborhood, '') || ' ' || coalesce(city, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(address, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'D')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_search_vector ON gocafe_cafe_listings USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_name_trgm ON gocafe_cafe_listings USING GIN (name gin_trgm_ops);
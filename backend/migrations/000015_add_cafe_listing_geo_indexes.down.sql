@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_latitude_longitude;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_earth;
+
+-- cube and earthdistance are left installed; other objects in the database may depend on them.
@@ -0,0 +1,11 @@
+-- earthdistance (on top of cube) provides ll_to_earth, earth_box and earth_distance for radius and distance queries.
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+-- GiST index over each listing's point on the Earth sphere; earth_box(...) @> ll_to_earth(...) uses it for near/radius_m.
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_earth ON gocafe_cafe_listings
+USING GIST (ll_to_earth(latitude, longitude))
+WHERE latitude IS NOT NULL AND longitude IS NOT NULL;
+
+-- B-tree for bbox range filters.
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_latitude_longitude ON gocafe_cafe_listings (latitude, longitude);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS gocafe_cache_entries (
+    key         VARCHAR(512) PRIMARY KEY,
+    value       BYTEA NOT NULL,
+    expires_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    stale_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_cache_entries_stale_until ON gocafe_cache_entries (stale_until);
@@ -0,0 +1,26 @@
+-- Photos attached to a cafe listing or a rating. Deleting the target nulls its ID instead of deleting the row,
+-- so the photo service can still find and delete the stored blobs.
+CREATE TABLE IF NOT EXISTS gocafe_photos (
+    id               SERIAL PRIMARY KEY,
+    created_at       TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    user_id          BIGINT NOT NULL,
+    cafe_listing_id  BIGINT,
+    rating_id        BIGINT,
+    content_type     VARCHAR(32) NOT NULL,
+    width            INTEGER NOT NULL,
+    height           INTEGER NOT NULL,
+    size_bytes       BIGINT NOT NULL,
+    storage_key      TEXT NOT NULL,
+    thumbnail_key    TEXT NOT NULL,
+    thumbnail_width  INTEGER NOT NULL,
+    thumbnail_height INTEGER NOT NULL,
+    CONSTRAINT fk_gocafe_photos_user FOREIGN KEY (user_id) REFERENCES gocafe_users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_gocafe_photos_cafe_listing FOREIGN KEY (cafe_listing_id) REFERENCES gocafe_cafe_listings (id) ON DELETE SET NULL,
+    CONSTRAINT fk_gocafe_photos_rating FOREIGN KEY (rating_id) REFERENCES gocafe_ratings (id) ON DELETE SET NULL,
+    CONSTRAINT chk_gocafe_photos_single_target CHECK (cafe_listing_id IS NULL OR rating_id IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_photos_user_id ON gocafe_photos (user_id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_photos_cafe_listing_id ON gocafe_photos (cafe_listing_id, id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_photos_rating_id ON gocafe_photos (rating_id, id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_photos_orphaned ON gocafe_photos (id) WHERE cafe_listing_id IS NULL AND rating_id IS NULL;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_price_level;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_amenities;
+
+ALTER TABLE gocafe_cafe_listings
+DROP CONSTRAINT IF EXISTS chk_gocafe_cafe_listings_price_level;
+
+ALTER TABLE gocafe_cafe_listings
+DROP COLUMN IF EXISTS price_level,
+DROP COLUMN IF EXISTS amenities,
+DROP COLUMN IF EXISTS opening_hours;
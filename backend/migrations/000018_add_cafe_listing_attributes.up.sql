@@ -0,0 +1,22 @@
+-- Structured attributes: opening hours (weekly schedule, time zone and dated exceptions) and amenities are JSON
+-- documents; price_level is 1 (inexpensive) to 4 (very expensive).
+ALTER TABLE gocafe_cafe_listings
+ADD COLUMN IF NOT EXISTS opening_hours JSONB,
+ADD COLUMN IF NOT EXISTS amenities JSONB,
+ADD COLUMN IF NOT EXISTS price_level SMALLINT;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'chk_gocafe_cafe_listings_price_level'
+    ) THEN
+        ALTER TABLE gocafe_cafe_listings
+        ADD CONSTRAINT chk_gocafe_cafe_listings_price_level CHECK (price_level BETWEEN 1 AND 4);
+    END IF;
+END $$;
+
+-- amenities @> '["wifi","outlets"]' filters use this index.
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_amenities ON gocafe_cafe_listings USING GIN (amenities jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_price_level ON gocafe_cafe_listings (price_level);
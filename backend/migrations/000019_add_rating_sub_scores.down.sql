@@ -0,0 +1,8 @@
+ALTER TABLE gocafe_ratings DROP CONSTRAINT IF EXISTS chk_gocafe_ratings_sub_score_range;
+
+ALTER TABLE gocafe_ratings
+DROP COLUMN IF EXISTS work_score,
+DROP COLUMN IF EXISTS value_score,
+DROP COLUMN IF EXISTS service_score,
+DROP COLUMN IF EXISTS ambience_score,
+DROP COLUMN IF EXISTS coffee_score;
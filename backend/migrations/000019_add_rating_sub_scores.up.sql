@@ -0,0 +1,25 @@
+-- Optional 1-5 sub-scores per dimension; rating stays the overall score.
+ALTER TABLE gocafe_ratings
+ADD COLUMN IF NOT EXISTS coffee_score SMALLINT,
+ADD COLUMN IF NOT EXISTS ambience_score SMALLINT,
+ADD COLUMN IF NOT EXISTS service_score SMALLINT,
+ADD COLUMN IF NOT EXISTS value_score SMALLINT,
+ADD COLUMN IF NOT EXISTS work_score SMALLINT;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'chk_gocafe_ratings_sub_score_range'
+    ) THEN
+        ALTER TABLE gocafe_ratings
+        ADD CONSTRAINT chk_gocafe_ratings_sub_score_range CHECK (
+            (coffee_score IS NULL OR coffee_score BETWEEN 1 AND 5)
+            AND (ambience_score IS NULL OR ambience_score BETWEEN 1 AND 5)
+            AND (service_score IS NULL OR service_score BETWEEN 1 AND 5)
+            AND (value_score IS NULL OR value_score BETWEEN 1 AND 5)
+            AND (work_score IS NULL OR work_score BETWEEN 1 AND 5)
+        );
+    END IF;
+END $$;
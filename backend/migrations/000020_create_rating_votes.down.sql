@@ -0,0 +1 @@
+DROP TABLE IF EXISTS gocafe_rating_votes;
@@ -0,0 +1,12 @@
+-- "Helpful" votes on ratings: one per user per rating. Counts are aggregated at read time.
+CREATE TABLE IF NOT EXISTS gocafe_rating_votes (
+    id         SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    user_id    BIGINT NOT NULL,
+    rating_id  BIGINT NOT NULL,
+    CONSTRAINT fk_gocafe_rating_votes_user FOREIGN KEY (user_id) REFERENCES gocafe_users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_gocafe_rating_votes_rating FOREIGN KEY (rating_id) REFERENCES gocafe_ratings (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gocafe_rating_votes_user_rating ON gocafe_rating_votes (user_id, rating_id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_rating_votes_rating_id ON gocafe_rating_votes (rating_id);
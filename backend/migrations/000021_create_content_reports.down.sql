@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS gocafe_content_reports;
+ALTER TABLE gocafe_cafe_listings DROP COLUMN IF EXISTS hidden_at;
+ALTER TABLE gocafe_ratings DROP COLUMN IF EXISTS hidden_at;
@@ -0,0 +1,29 @@
+-- Moderators hide reported ratings and cafe listings by setting hidden_at; hidden rows stay visible to their author.
+ALTER TABLE gocafe_ratings ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE gocafe_cafe_listings ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+
+-- Reports point at a rating or a cafe listing by (target_type, target_id), so they have no FK to the target.
+CREATE TABLE IF NOT EXISTS gocafe_content_reports (
+    id             SERIAL PRIMARY KEY,
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at     TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    reporter_id    BIGINT NOT NULL,
+    target_type    VARCHAR(16) NOT NULL,
+    target_id      BIGINT NOT NULL,
+    reason         VARCHAR(32) NOT NULL,
+    note           TEXT,
+    status         VARCHAR(16) NOT NULL DEFAULT 'open',
+    resolved_by_id BIGINT,
+    resolved_at    TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_gocafe_content_reports_reporter FOREIGN KEY (reporter_id) REFERENCES gocafe_users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_gocafe_content_reports_resolved_by FOREIGN KEY (resolved_by_id) REFERENCES gocafe_users (id) ON DELETE SET NULL,
+    CONSTRAINT chk_gocafe_content_reports_target_type CHECK (target_type IN ('rating', 'cafe_listing')),
+    CONSTRAINT chk_gocafe_content_reports_reason CHECK (reason IN ('spam', 'offensive', 'harassment', 'off_topic', 'misleading', 'other')),
+    CONSTRAINT chk_gocafe_content_reports_status CHECK (status IN ('open', 'hidden', 'restored', 'dismissed'))
+);
+
+-- One open report per user per target; a user may report again after a moderator has dealt with it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gocafe_content_reports_open_reporter
+    ON gocafe_content_reports (reporter_id, target_type, target_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_gocafe_content_reports_target ON gocafe_content_reports (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_content_reports_queue ON gocafe_content_reports (status, created_at, id);
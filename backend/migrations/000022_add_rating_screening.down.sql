@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_gocafe_ratings_held;
+ALTER TABLE gocafe_ratings DROP COLUMN IF EXISTS held_reason;
+ALTER TABLE gocafe_ratings DROP COLUMN IF EXISTS held_at;
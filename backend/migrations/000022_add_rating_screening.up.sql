@@ -0,0 +1,6 @@
+-- Reviews the screener holds for moderator review get held_at and the rule that held them. Held rows stay visible
+-- to their author but are left out of public lists and stats until a moderator approves them.
+ALTER TABLE gocafe_ratings ADD COLUMN IF NOT EXISTS held_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE gocafe_ratings ADD COLUMN IF NOT EXISTS held_reason VARCHAR(32);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_ratings_held ON gocafe_ratings (held_at, id) WHERE held_at IS NOT NULL;
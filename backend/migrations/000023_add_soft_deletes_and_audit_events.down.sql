@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS gocafe_audit_events;
+DROP FUNCTION IF EXISTS gocafe_audit_events_append_only();
+
+-- Without deleted_at, soft-deleted rows would come back to life; remove them as a hard delete would have.
+DELETE FROM gocafe_ratings WHERE deleted_at IS NOT NULL;
+DELETE FROM gocafe_cafe_listings WHERE deleted_at IS NOT NULL;
+DELETE FROM gocafe_users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_gocafe_users_email_active;
+ALTER TABLE gocafe_users ADD CONSTRAINT gocafe_users_email_key UNIQUE (email);
+
+ALTER TABLE gocafe_ratings DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE gocafe_cafe_listings DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE gocafe_users DROP COLUMN IF EXISTS deleted_at;
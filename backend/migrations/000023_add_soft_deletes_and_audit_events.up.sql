@@ -0,0 +1,41 @@
+-- Users, cafe listings and ratings are soft-deleted: deleted_at is set instead of removing the row, so the FK
+-- cascades no longer wipe reviews and an admin can restore them. Rows deleted together share one deleted_at.
+ALTER TABLE gocafe_users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE gocafe_cafe_listings ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE gocafe_ratings ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_users_deleted_at ON gocafe_users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_deleted_at ON gocafe_cafe_listings (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_gocafe_ratings_deleted_at ON gocafe_ratings (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A deleted account keeps its row, so only live accounts need a unique email; the address can be registered again.
+ALTER TABLE gocafe_users DROP CONSTRAINT IF EXISTS gocafe_users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gocafe_users_email_active ON gocafe_users (email) WHERE deleted_at IS NULL;
+
+-- The audit trail is append-only. actor_id has no FK so events outlive the account that made them.
+CREATE TABLE IF NOT EXISTS gocafe_audit_events (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    actor_id    BIGINT NOT NULL,
+    action      VARCHAR(16) NOT NULL,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id   BIGINT NOT NULL,
+    before      JSONB,
+    after       JSONB,
+    CONSTRAINT chk_gocafe_audit_events_action CHECK (action IN ('update', 'delete', 'restore')),
+    CONSTRAINT chk_gocafe_audit_events_entity_type CHECK (entity_type IN ('user', 'cafe_listing', 'rating'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_audit_events_entity ON gocafe_audit_events (entity_type, entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_audit_events_actor ON gocafe_audit_events (actor_id, id);
+
+CREATE OR REPLACE FUNCTION gocafe_audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'gocafe_audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_gocafe_audit_events_append_only ON gocafe_audit_events;
+CREATE TRIGGER trg_gocafe_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON gocafe_audit_events
+    FOR EACH ROW EXECUTE FUNCTION gocafe_audit_events_append_only();
@@ -0,0 +1,40 @@
+-- Back to per-user listings: the cafe stays with the user who added it, and every other saved place becomes a copy
+-- pointing at it through source_cafe_id. Notes and the redirects of folded ids are lost; folded listings are not
+-- split out again, so ratings, photos and reports stay on the cafe they were folded into, and duplicate reviews the
+-- fold soft-deleted stay deleted.
+DROP INDEX IF EXISTS idx_gocafe_cafes_external_place_id_active;
+ALTER TABLE gocafe_cafes RENAME TO gocafe_cafe_listings;
+
+ALTER TABLE gocafe_cafe_listings
+ADD COLUMN IF NOT EXISTS visit_status VARCHAR(32) NOT NULL DEFAULT 'to_visit',
+ADD COLUMN IF NOT EXISTS source_cafe_id BIGINT;
+
+UPDATE gocafe_cafe_listings c
+SET visit_status = s.visit_status
+FROM gocafe_saved_places s
+WHERE s.cafe_id = c.id AND s.user_id = c.user_id AND s.deleted_at IS NULL;
+
+INSERT INTO gocafe_cafe_listings (
+    created_at, updated_at, user_id, name, address, city, neighborhood, description, image_url, latitude, longitude,
+    source_provider, external_place_id, opening_hours, amenities, price_level, visit_status, source_cafe_id, deleted_at
+)
+SELECT
+    s.created_at, s.updated_at, s.user_id, c.name, c.address, c.city, c.neighborhood, c.description, c.image_url,
+    c.latitude, c.longitude, c.source_provider, c.external_place_id, c.opening_hours, c.amenities, c.price_level,
+    s.visit_status, c.id, s.deleted_at
+FROM gocafe_saved_places s
+JOIN gocafe_cafe_listings c ON c.id = s.cafe_id
+WHERE s.user_id <> c.user_id;
+
+ALTER TABLE gocafe_cafe_listings
+ADD CONSTRAINT chk_gocafe_cafe_listings_visit_status CHECK (visit_status IN ('to_visit', 'visited')),
+ADD CONSTRAINT fk_gocafe_cafe_listings_source_cafe FOREIGN KEY (source_cafe_id) REFERENCES gocafe_cafe_listings (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_visit_status ON gocafe_cafe_listings (visit_status);
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_source_cafe_id ON gocafe_cafe_listings (source_cafe_id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_user_id_updated_at ON gocafe_cafe_listings (user_id, updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_user_id_created_at ON gocafe_cafe_listings (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_listings_user_id_name ON gocafe_cafe_listings (user_id, name, id);
+
+DROP TABLE IF EXISTS gocafe_cafe_redirects;
+DROP TABLE IF EXISTS gocafe_saved_places;
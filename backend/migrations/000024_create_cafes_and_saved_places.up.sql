@@ -0,0 +1,126 @@
+-- Cafes become canonical: one row per place, with each user's visit status and notes in gocafe_saved_places
+-- instead of a per-user copy of the listing pointing back through source_cafe_id.
+
+-- Fold every listing into one canonical cafe: listings of the same provider place share a cafe, and so do a
+-- saved copy and the listing it was copied from. The canonical row is the oldest live original of the group.
+CREATE TEMP TABLE gocafe_cafe_folds AS
+WITH keyed AS (
+    SELECT
+        id,
+        source_cafe_id,
+        deleted_at,
+        CASE
+            WHEN COALESCE(external_place_id, '') <> '' THEN 'place:' || external_place_id
+            ELSE 'cafe:' || COALESCE(source_cafe_id, id)
+        END AS group_key
+    FROM gocafe_cafe_listings
+),
+canonical AS (
+    SELECT DISTINCT ON (group_key) group_key, id AS cafe_id
+    FROM keyed
+    ORDER BY group_key, deleted_at IS NOT NULL, source_cafe_id IS NOT NULL, id
+)
+SELECT keyed.id AS listing_id, canonical.cafe_id
+FROM keyed
+JOIN canonical USING (group_key);
+
+-- gocafe_saved_places
+CREATE TABLE IF NOT EXISTS gocafe_saved_places (
+    id           SERIAL PRIMARY KEY,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at   TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    user_id      BIGINT NOT NULL,
+    cafe_id      BIGINT NOT NULL,
+    visit_status VARCHAR(32) NOT NULL DEFAULT 'to_visit',
+    notes        TEXT NOT NULL DEFAULT '',
+    deleted_at   TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_gocafe_saved_places_user FOREIGN KEY (user_id) REFERENCES gocafe_users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_gocafe_saved_places_cafe FOREIGN KEY (cafe_id) REFERENCES gocafe_cafe_listings (id) ON DELETE CASCADE,
+    CONSTRAINT chk_gocafe_saved_places_visit_status CHECK (visit_status IN ('to_visit', 'visited'))
+);
+
+-- Each listing becomes its owner's saved place on the canonical cafe. A user holding several listings of one
+-- place keeps the live one, else the most recently updated; deleted listings stay deleted with their deleted_at.
+INSERT INTO gocafe_saved_places (created_at, updated_at, user_id, cafe_id, visit_status, deleted_at)
+SELECT DISTINCT ON (l.user_id, f.cafe_id) l.created_at, l.updated_at, l.user_id, f.cafe_id, l.visit_status, l.deleted_at
+FROM gocafe_cafe_listings l
+JOIN gocafe_cafe_folds f ON f.listing_id = l.id
+ORDER BY l.user_id, f.cafe_id, l.deleted_at IS NOT NULL, l.updated_at DESC, l.id DESC;
+
+-- One live saved place per user and cafe, and keyset indexes for the /me list orders.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gocafe_saved_places_user_cafe ON gocafe_saved_places (user_id, cafe_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_gocafe_saved_places_cafe_id ON gocafe_saved_places (cafe_id);
+CREATE INDEX IF NOT EXISTS idx_gocafe_saved_places_user_id_updated_at ON gocafe_saved_places (user_id, updated_at DESC, cafe_id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_gocafe_saved_places_user_id_created_at ON gocafe_saved_places (user_id, created_at DESC, cafe_id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_gocafe_saved_places_deleted_at ON gocafe_saved_places (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Ratings, photos and reports move to the canonical cafe. A second open report by the same user on the same cafe is
+-- dropped, as the open-report index requires.
+UPDATE gocafe_ratings r
+SET cafe_listing_id = f.cafe_id
+FROM gocafe_cafe_folds f
+WHERE r.cafe_listing_id = f.listing_id AND f.listing_id <> f.cafe_id;
+
+-- A user reviews a cafe once: where someone reviewed both a listing and a copy folded into the same cafe, their most
+-- recently updated review stays and the others are soft-deleted, so stats count each reviewer once.
+UPDATE gocafe_ratings r
+SET deleted_at = now()
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, cafe_listing_id ORDER BY updated_at DESC, id DESC) AS recency
+    FROM gocafe_ratings
+    WHERE deleted_at IS NULL
+        AND cafe_listing_id IN (SELECT cafe_id FROM gocafe_cafe_folds WHERE listing_id <> cafe_id)
+) ranked
+WHERE r.id = ranked.id AND ranked.recency > 1;
+
+UPDATE gocafe_photos p
+SET cafe_listing_id = f.cafe_id
+FROM gocafe_cafe_folds f
+WHERE p.cafe_listing_id = f.listing_id AND f.listing_id <> f.cafe_id;
+
+DELETE FROM gocafe_content_reports r
+USING gocafe_cafe_folds f, gocafe_content_reports kept
+JOIN gocafe_cafe_folds kept_fold ON kept_fold.listing_id = kept.target_id
+WHERE r.target_type = 'cafe_listing' AND r.status = 'open' AND f.listing_id = r.target_id
+    AND kept.target_type = 'cafe_listing' AND kept.status = 'open' AND kept.reporter_id = r.reporter_id
+    AND kept_fold.cafe_id = f.cafe_id AND kept.id < r.id;
+
+UPDATE gocafe_content_reports r
+SET target_id = f.cafe_id
+FROM gocafe_cafe_folds f
+WHERE r.target_type = 'cafe_listing' AND r.target_id = f.listing_id AND f.listing_id <> f.cafe_id;
+
+-- Folded listing ids keep resolving: the API answers them with a redirect to the canonical cafe.
+CREATE TABLE IF NOT EXISTS gocafe_cafe_redirects (
+    from_id    BIGINT PRIMARY KEY,
+    cafe_id    BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CONSTRAINT fk_gocafe_cafe_redirects_cafe FOREIGN KEY (cafe_id) REFERENCES gocafe_cafe_listings (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_gocafe_cafe_redirects_cafe_id ON gocafe_cafe_redirects (cafe_id);
+
+INSERT INTO gocafe_cafe_redirects (from_id, cafe_id)
+SELECT listing_id, cafe_id FROM gocafe_cafe_folds WHERE listing_id <> cafe_id;
+
+-- Nothing points at the folded listings any more.
+ALTER TABLE gocafe_cafe_listings DROP CONSTRAINT IF EXISTS fk_gocafe_cafe_listings_source_cafe;
+DELETE FROM gocafe_cafe_listings l
+USING gocafe_cafe_folds f
+WHERE l.id = f.listing_id AND f.listing_id <> f.cafe_id;
+
+DROP TABLE gocafe_cafe_folds;
+
+-- Visit status lives on saved places now; dropping the columns drops their check constraint and indexes.
+ALTER TABLE gocafe_cafe_listings DROP COLUMN IF EXISTS source_cafe_id;
+ALTER TABLE gocafe_cafe_listings DROP COLUMN IF EXISTS visit_status;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_user_id_updated_at;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_user_id_created_at;
+DROP INDEX IF EXISTS idx_gocafe_cafe_listings_user_id_name;
+
+-- Existing indexes, constraints and the id sequence keep their gocafe_cafe_listings names.
+ALTER TABLE gocafe_cafe_listings RENAME TO gocafe_cafes;
+
+-- A provider place has at most one live cafe.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gocafe_cafes_external_place_id_active ON gocafe_cafes (external_place_id)
+    WHERE external_place_id <> '' AND deleted_at IS NULL;
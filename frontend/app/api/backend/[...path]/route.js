@@ -11,6 +11,16 @@ async function forward(request, paramsPromise) {
     headers.set("authorization", auth);
   }
   headers.set("content-type", request.headers.get("content-type") || "application/json");
+  // Backend login throttling is keyed on the client IP, so pass it through instead of the proxy's own.
+  // Photos are served with ETags, so conditional requests can be answered with 304 upstream.
+  const ifNoneMatch = request.headers.get("if-none-match");
+  if (ifNoneMatch) {
+    headers.set("if-none-match", ifNoneMatch);
+  }
+  const forwardedFor = request.headers.get("x-forwarded-for") || request.headers.get("x-real-ip");
+  if (forwardedFor) {
+    headers.set("x-forwarded-for", forwardedFor);
+  }
 
   const method = request.method.toUpperCase();
   const hasBody = !["GET", "HEAD"].includes(method);
@@ -39,12 +49,20 @@ async function forward(request, paramsPromise) {
     );
   }
 
-  const text = await upstream.text();
-  return new Response(text, {
-    status: upstream.status,
-    headers: {
-      "content-type": upstream.headers.get("content-type") || "text/plain; charset=utf-8"
+  // Read bytes, not text, so photo downloads pass through intact.
+  const responseBody = upstream.status === 304 ? null : await upstream.arrayBuffer();
+  const responseHeaders = {
+    "content-type": upstream.headers.get("content-type") || "text/plain; charset=utf-8"
+  };
+  for (const name of ["retry-after", "etag", "cache-control", "x-content-type-options"]) {
+    const value = upstream.headers.get(name);
+    if (value) {
+      responseHeaders[name] = value;
     }
+  }
+  return new Response(responseBody, {
+    status: upstream.status,
+    headers: responseHeaders
   });
 }
 
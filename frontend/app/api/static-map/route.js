@@ -2,23 +2,39 @@ const BACKEND_BASE_URL = process.env.API_BASE_URL || process.env.NEXT_PUBLIC_API
 
 export async function GET(request) {
   const url = `${BACKEND_BASE_URL}/api/v1/discovery/cafes/static-map${request.nextUrl.search || ""}`;
+  const ifNoneMatch = request.headers.get("if-none-match");
 
   let upstream;
   try {
-    upstream = await fetch(url, { cache: "no-store" });
-  } catch (error) {
-    return new Response(error instanceof Error ? error.message : "Static map upstream failed", {
+    upstream = await fetch(url, {
+      cache: "no-store",
+      headers: ifNoneMatch ? { "if-none-match": ifNoneMatch } : undefined
+    });
+  } catch {
+    return new Response("Static map upstream failed", {
       status: 502,
       headers: { "content-type": "text/plain; charset=utf-8" }
     });
   }
 
+  const headers = {
+    "cache-control": upstream.headers.get("cache-control") || "public, max-age=300"
+  };
+  const etag = upstream.headers.get("etag");
+  if (etag) {
+    headers.etag = etag;
+  }
+
+  if (upstream.status === 304) {
+    return new Response(null, { status: 304, headers });
+  }
+
   const body = await upstream.arrayBuffer();
   return new Response(body, {
     status: upstream.status,
     headers: {
-      "content-type": upstream.headers.get("content-type") || "image/png",
-      "cache-control": upstream.headers.get("cache-control") || "public, max-age=300"
+      ...headers,
+      "content-type": upstream.headers.get("content-type") || "image/png"
     }
   });
 }
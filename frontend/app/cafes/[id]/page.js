@@ -11,11 +11,26 @@ import {
   createMyCafe,
   getCafeById,
   getDiscoveryCafeById,
-  listCafeRatings,
-  listCommunityRatingsByPlaceId,
-  listMyCafes
+  listAllCafeRatings,
+  listAllCommunityRatingsByPlaceId,
+  listAllMyCafes,
+  reportCafe,
+  reportRating,
+  unvoteRating,
+  voteRating
 } from "@/lib/api";
-import { formatCount, formatRating, formatVisitStatus, getCafeSummary } from "@/lib/presentation";
+import {
+  formatAmenities,
+  formatCount,
+  formatOpeningHours,
+  formatPriceLevel,
+  formatRating,
+  formatVisitStatus,
+  getCafeSummary,
+  RATING_DIMENSIONS,
+  REPORT_REASONS,
+  REVIEW_SORTS
+} from "@/lib/presentation";
 
 export default function CafeDetailPage() {
   const params = useParams();
@@ -24,6 +39,9 @@ export default function CafeDetailPage() {
   const { token, ready, isAuthed } = useAuth();
   const [cafe, setCafe] = useState(null);
   const [ratings, setRatings] = useState([]);
+  const [reviewSort, setReviewSort] = useState("recent");
+  const [votedIds, setVotedIds] = useState([]);
+  const [reportReason, setReportReason] = useState("spam");
   const [myCafes, setMyCafes] = useState([]);
   const [loading, setLoading] = useState(true);
   const [saving, setSaving] = useState(false);
@@ -42,8 +60,8 @@ export default function CafeDetailPage() {
       try {
         const [detail, communityRatings] = await Promise.all(
           isPersonalCafe
-            ? [getCafeById(cafeId), listCafeRatings(cafeId)]
-            : [getDiscoveryCafeById(cafeId), listCommunityRatingsByPlaceId(cafeId)]
+            ? [getCafeById(cafeId), listAllCafeRatings(cafeId, { sort: reviewSort })]
+            : [getDiscoveryCafeById(cafeId), listAllCommunityRatingsByPlaceId(cafeId, { sort: reviewSort })]
         );
 
         if (!cancelled) {
@@ -66,7 +84,7 @@ export default function CafeDetailPage() {
     return () => {
       cancelled = true;
     };
-  }, [cafeId, isPersonalCafe]);
+  }, [cafeId, isPersonalCafe, reviewSort]);
 
   useEffect(() => {
     if (!ready || !isAuthed) {
@@ -78,7 +96,7 @@ export default function CafeDetailPage() {
 
     async function loadMyCafes() {
       try {
-        const payload = await listMyCafes(token, { sort: "updated_desc" });
+        const payload = await listAllMyCafes(token, { sort: "updated_desc" });
         if (!cancelled) {
           setMyCafes(payload || []);
         }
@@ -102,11 +120,51 @@ export default function CafeDetailPage() {
     return (
       myCafes.find((item) => String(item.id) === String(cafe.id)) ||
       myCafes.find((item) => String(item.external_place_id) === String(cafe.external_place_id || cafe.id)) ||
-      myCafes.find((item) => String(item.source_cafe_id) === String(cafe.source_cafe_id || cafe.id)) ||
       null
     );
   }, [cafe, myCafes]);
 
+  async function handleVote(ratingId) {
+    if (!isAuthed) {
+      setError("Log in to mark reviews as helpful.");
+      return;
+    }
+
+    const voted = votedIds.includes(ratingId);
+    setError("");
+
+    try {
+      const result = voted ? await unvoteRating(token, ratingId) : await voteRating(token, ratingId);
+      setRatings((current) =>
+        current.map((rating) => (rating.id === ratingId ? { ...rating, helpful_count: result.helpful_count } : rating))
+      );
+      setVotedIds((current) => (voted ? current.filter((id) => id !== ratingId) : [...current, ratingId]));
+    } catch (voteError) {
+      setError(voteError.message);
+    }
+  }
+
+  async function handleReport(ratingId) {
+    if (!isAuthed) {
+      setError("Log in to report content.");
+      return;
+    }
+
+    setError("");
+    setMessage("");
+
+    try {
+      if (ratingId) {
+        await reportRating(token, ratingId, { reason: reportReason });
+      } else {
+        await reportCafe(token, cafe.id, { reason: reportReason });
+      }
+      setMessage("Thanks, a moderator will take a look.");
+    } catch (reportError) {
+      setError(reportError.message);
+    }
+  }
+
   async function handleSave() {
     if (!cafe) return;
 
@@ -130,9 +188,13 @@ export default function CafeDetailPage() {
         image_url: cafe.image_url,
         latitude: cafe.latitude,
         longitude: cafe.longitude,
+        opening_hours: cafe.opening_hours,
+        amenities: cafe.amenities,
+        price_level: cafe.price_level,
         visit_status: "to_visit",
         source_provider: cafe.source_provider,
-        external_place_id: cafe.external_place_id || cafe.id
+        external_place_id: cafe.external_place_id || (isPersonalCafe ? "" : cafe.id),
+        source_cafe_id: isPersonalCafe ? cafe.id : undefined
       });
 
       setMyCafes((current) => [created, ...current]);
@@ -209,6 +271,24 @@ export default function CafeDetailPage() {
                   <span className="meta-label">Neighborhood</span>
                   <strong>{cafe.neighborhood || "Unknown"}</strong>
                 </div>
+                {RATING_DIMENSIONS.filter((dimension) => cafe[dimension.average]).map((dimension) => (
+                  <div key={dimension.key}>
+                    <span className="meta-label">{dimension.label}</span>
+                    <strong>{formatRating(cafe[dimension.average])}</strong>
+                  </div>
+                ))}
+                <div>
+                  <span className="meta-label">Price</span>
+                  <strong>{formatPriceLevel(cafe.price_level)}</strong>
+                </div>
+                <div>
+                  <span className="meta-label">Amenities</span>
+                  <strong>{formatAmenities(cafe.amenities)}</strong>
+                </div>
+                <div>
+                  <span className="meta-label">Hours{cafe.opening_hours?.timezone ? ` (${cafe.opening_hours.timezone})` : ""}</span>
+                  <strong>{formatOpeningHours(cafe.opening_hours)}</strong>
+                </div>
               </div>
 
               {message ? <p className="feedback success">{message}</p> : null}
@@ -240,6 +320,11 @@ export default function CafeDetailPage() {
                 <Link href="/reviews" className="button button-secondary">
                   Review workflow
                 </Link>
+                {isPersonalCafe ? (
+                  <button type="button" className="button button-ghost" onClick={() => handleReport()}>
+                    Report listing
+                  </button>
+                ) : null}
               </div>
             </div>
           </section>
@@ -250,8 +335,34 @@ export default function CafeDetailPage() {
                 <p className="eyebrow">Community reviews</p>
                 <h2>How people described this cafe</h2>
               </div>
+              <label>
+                <span>Sort reviews</span>
+                <select value={reviewSort} onChange={(event) => setReviewSort(event.target.value)}>
+                  {REVIEW_SORTS.map((option) => (
+                    <option key={option.value} value={option.value}>
+                      {option.label}
+                    </option>
+                  ))}
+                </select>
+              </label>
+              <label>
+                <span>Report reason</span>
+                <select value={reportReason} onChange={(event) => setReportReason(event.target.value)}>
+                  {REPORT_REASONS.map((option) => (
+                    <option key={option.value} value={option.value}>
+                      {option.label}
+                    </option>
+                  ))}
+                </select>
+              </label>
             </div>
-            <ReviewList ratings={ratings} emptyMessage="No community notes yet for this cafe." />
+            <ReviewList
+              ratings={ratings}
+              emptyMessage="No community notes yet for this cafe."
+              onVote={handleVote}
+              votedIds={votedIds}
+              onReport={handleReport}
+            />
           </section>
         </>
       ) : null}
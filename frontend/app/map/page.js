@@ -6,7 +6,7 @@ import AppShell from "@/components/app-shell";
 import CafeCard from "@/components/cafe-card";
 import CafeMap from "@/components/cafe-map";
 import { useAuth } from "@/components/providers/auth-provider";
-import { createMyCafe, listDiscoveryCafes, listMyCafes } from "@/lib/api";
+import { createMyCafe, listAllMyCafes, listDiscoveryCafes } from "@/lib/api";
 
 export default function MapPage() {
   const { token, isAuthed, ready } = useAuth();
@@ -61,7 +61,7 @@ export default function MapPage() {
 
     async function loadMyCafes() {
       try {
-        const payload = await listMyCafes(token, { sort: "updated_desc" });
+        const payload = await listAllMyCafes(token, { sort: "updated_desc" });
         if (!cancelled) {
           setMyCafes(payload || []);
         }
@@ -82,7 +82,7 @@ export default function MapPage() {
   const savedMap = useMemo(() => {
     return new Map(
       myCafes.map((cafe) => {
-        const key = cafe.external_place_id || cafe.source_cafe_id || cafe.id;
+        const key = cafe.external_place_id || cafe.id;
         return [String(key), cafe];
       })
     );
@@ -109,6 +109,9 @@ export default function MapPage() {
         image_url: cafe.image_url,
         latitude: cafe.latitude,
         longitude: cafe.longitude,
+        opening_hours: cafe.opening_hours,
+        amenities: cafe.amenities,
+        price_level: cafe.price_level,
         source_provider: cafe.source_provider,
         external_place_id: cafe.external_place_id || cafe.id,
         visit_status: "to_visit"
@@ -167,7 +170,7 @@ export default function MapPage() {
           {selectedCafe ? (
             <>
               <h2>{selectedCafe.name}</h2>
-              <p className="muted">{selectedCafe.address || "Geoapify discovery result"}</p>
+              <p className="muted">{selectedCafe.address || "Discovery result"}</p>
               <p className="body-copy">
                 {selectedCafe.description || "Open the detail page to understand the context behind this recommendation."}
               </p>
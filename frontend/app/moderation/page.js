@@ -0,0 +1,197 @@
+"use client";
+
+import Link from "next/link";
+import { useCallback, useEffect, useState } from "react";
+import AppShell from "@/components/app-shell";
+import RequireAuth from "@/components/require-auth";
+import { useAuth } from "@/components/providers/auth-provider";
+import {
+  approveHeldRating,
+  collectPages,
+  dismissReport,
+  hideReport,
+  listHeldRatings,
+  listModerationReports,
+  rejectHeldRating,
+  restoreReport
+} from "@/lib/api";
+import { formatDate, formatHeldReason, formatReportReason } from "@/lib/presentation";
+
+const STATUSES = [
+  { value: "open", label: "Open" },
+  { value: "hidden", label: "Hidden" },
+  { value: "restored", label: "Restored" },
+  { value: "dismissed", label: "Dismissed" },
+  { value: "all", label: "All" }
+];
+
+function describeTarget(report) {
+  if (report.target_type === "rating") {
+    if (!report.rating) return `Review #${report.target_id} (deleted)`;
+    return `${report.rating.rating}/5 by ${report.rating.user?.name || "Cafe Explorer"}: ${report.rating.review || "No written note."}`;
+  }
+  if (!report.cafe_listing) return `Listing #${report.target_id} (deleted)`;
+  return `${report.cafe_listing.name} by ${report.cafe_listing.user?.name || "Cafe Explorer"}`;
+}
+
+function isHidden(report) {
+  return Boolean((report.rating || report.cafe_listing)?.hidden_at);
+}
+
+export default function ModerationPage() {
+  const { token, isAuthed, ready } = useAuth();
+  const [status, setStatus] = useState("open");
+  const [reports, setReports] = useState([]);
+  const [heldRatings, setHeldRatings] = useState([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState("");
+
+  const loadReports = useCallback(async () => {
+    if (!ready || !isAuthed) {
+      setReports([]);
+      setHeldRatings([]);
+      setLoading(false);
+      return;
+    }
+
+    setLoading(true);
+    setError("");
+
+    try {
+      const [payload, held] = await Promise.all([
+        collectPages((cursor) => listModerationReports(token, { status, limit: 100, cursor })),
+        collectPages((cursor) => listHeldRatings(token, { limit: 100, cursor }))
+      ]);
+      setReports(payload || []);
+      setHeldRatings(held || []);
+    } catch (loadError) {
+      setError(loadError.status === 403 ? "Only moderators and admins can open the queue." : loadError.message);
+    } finally {
+      setLoading(false);
+    }
+  }, [isAuthed, ready, status, token]);
+
+  useEffect(() => {
+    loadReports();
+  }, [loadReports]);
+
+  async function handleAction(action, id) {
+    setError("");
+
+    try {
+      await action(token, id);
+      await loadReports();
+    } catch (actionError) {
+      setError(actionError.message);
+    }
+  }
+
+  return (
+    <AppShell
+      title="Moderation queue"
+      subtitle="Reported reviews and listings, oldest first. Hiding takes content out of public lists and stats; its author can still see it."
+    >
+      <RequireAuth>
+        {!loading && heldRatings.length ? (
+          <section className="section-stack">
+            <div className="section-heading">
+              <div>
+                <p className="eyebrow">Automatic screening</p>
+                <h2>Reviews waiting for approval</h2>
+              </div>
+            </div>
+
+            {heldRatings.map((rating) => (
+              <article key={rating.id} className="surface review-card">
+                <div className="cluster-sm">
+                  <div>
+                    <p className="eyebrow">{rating.cafe_listing?.name || `Cafe #${rating.cafe_listing_id}`}</p>
+                    <h3>{rating.rating}/5</h3>
+                  </div>
+                  <div className="review-card-meta">
+                    <span>{rating.user?.name || "Cafe Explorer"}</span>
+                    <span>{formatDate(rating.held_at)}</span>
+                  </div>
+                </div>
+
+                <p className="muted">Held because {formatHeldReason(rating.held_reason)}.</p>
+                <p className="body-copy">{rating.review}</p>
+
+                <div className="card-actions">
+                  <button type="button" className="button" onClick={() => handleAction(approveHeldRating, rating.id)}>
+                    Approve
+                  </button>
+                  <button type="button" className="button button-ghost" onClick={() => handleAction(rejectHeldRating, rating.id)}>
+                    Reject
+                  </button>
+                </div>
+              </article>
+            ))}
+          </section>
+        ) : null}
+
+        <section className="section-stack">
+          <div className="section-heading">
+            <div>
+              <p className="eyebrow">Reports</p>
+              <h2>{STATUSES.find((option) => option.value === status)?.label} reports</h2>
+            </div>
+            <label>
+              <span>Status</span>
+              <select value={status} onChange={(event) => setStatus(event.target.value)}>
+                {STATUSES.map((option) => (
+                  <option key={option.value} value={option.value}>
+                    {option.label}
+                  </option>
+                ))}
+              </select>
+            </label>
+          </div>
+
+          {error ? <p className="feedback error">{error}</p> : null}
+          {loading ? <section className="surface empty-state">Loading reports...</section> : null}
+          {!loading && !error && !reports.length ? <section className="surface empty-state">Nothing to review.</section> : null}
+
+          {!loading
+            ? reports.map((report) => (
+                <article key={report.id} className="surface review-card">
+                  <div className="cluster-sm">
+                    <div>
+                      <p className="eyebrow">{formatReportReason(report.reason)}</p>
+                      <h3>{report.target_type === "rating" ? "Review" : "Listing"}</h3>
+                    </div>
+                    <div className="review-card-meta">
+                      <span>{report.status}</span>
+                      <span>{formatDate(report.created_at)}</span>
+                    </div>
+                  </div>
+
+                  <p className="body-copy">{describeTarget(report)}</p>
+                  {report.note ? <p className="muted">Reporter note: {report.note}</p> : null}
+                  {report.cafe_listing ? <Link href={`/cafes/${report.cafe_listing.id}`}>Open listing</Link> : null}
+
+                  <div className="card-actions">
+                    {report.status === "open" && !isHidden(report) ? (
+                      <button type="button" className="button" onClick={() => handleAction(hideReport, report.id)}>
+                        Hide
+                      </button>
+                    ) : null}
+                    {(report.status === "open" || report.status === "hidden") && isHidden(report) ? (
+                      <button type="button" className="button button-secondary" onClick={() => handleAction(restoreReport, report.id)}>
+                        Restore
+                      </button>
+                    ) : null}
+                    {report.status === "open" ? (
+                      <button type="button" className="button button-ghost" onClick={() => handleAction(dismissReport, report.id)}>
+                        Dismiss
+                      </button>
+                    ) : null}
+                  </div>
+                </article>
+              ))
+            : null}
+        </section>
+      </RequireAuth>
+    </AppShell>
+  );
+}
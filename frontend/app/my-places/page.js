@@ -9,9 +9,20 @@ import { useAuth } from "@/components/providers/auth-provider";
 import { createMyCafe, deleteCafe, listMyCafes, updateCafe } from "@/lib/api";
 import { formatCount, formatVisitStatus } from "@/lib/presentation";
 
+const PAGE_SIZE = 50;
+
+function indexStatuses(cafes, initial = {}) {
+  return cafes.reduce((accumulator, cafe) => {
+    accumulator[cafe.id] = cafe.visit_status;
+    return accumulator;
+  }, initial);
+}
+
 export default function MyPlacesPage() {
   const { token, isAuthed, ready } = useAuth();
   const [cafes, setCafes] = useState([]);
+  const [nextCursor, setNextCursor] = useState("");
+  const [loadingMore, setLoadingMore] = useState(false);
   const [pendingStatusById, setPendingStatusById] = useState({});
   const [loading, setLoading] = useState(true);
   const [submitting, setSubmitting] = useState(false);
@@ -21,6 +32,7 @@ export default function MyPlacesPage() {
   const loadMyCafes = useCallback(async () => {
     if (!ready || !isAuthed) {
       setCafes([]);
+      setNextCursor("");
       setPendingStatusById({});
       setLoading(false);
       return;
@@ -30,15 +42,11 @@ export default function MyPlacesPage() {
     setError("");
 
     try {
-      const payload = await listMyCafes(token, { sort: "updated_desc" });
-      const nextCafes = payload || [];
+      const payload = await listMyCafes(token, { sort: "updated_desc", limit: PAGE_SIZE });
+      const nextCafes = payload?.items || [];
       setCafes(nextCafes);
-      setPendingStatusById(
-        nextCafes.reduce((accumulator, cafe) => {
-          accumulator[cafe.id] = cafe.visit_status;
-          return accumulator;
-        }, {})
-      );
+      setNextCursor(payload?.next_cursor || "");
+      setPendingStatusById(indexStatuses(nextCafes));
     } catch (loadError) {
       setError(loadError.message);
     } finally {
@@ -50,6 +58,27 @@ export default function MyPlacesPage() {
     loadMyCafes();
   }, [loadMyCafes]);
 
+  async function handleLoadMore() {
+    if (!nextCursor) return;
+
+    setLoadingMore(true);
+    setError("");
+
+    try {
+      const payload = await listMyCafes(token, { sort: "updated_desc", limit: PAGE_SIZE, cursor: nextCursor });
+      const moreCafes = payload?.items || [];
+      setCafes((current) => [...current, ...moreCafes]);
+      setNextCursor(payload?.next_cursor || "");
+      setPendingStatusById((current) => indexStatuses(moreCafes, { ...current }));
+    } catch (loadError) {
+      setError(loadError.message);
+    } finally {
+      setLoadingMore(false);
+    }
+  }
+
+  const countSuffix = nextCursor ? "+" : "";
+
   const savedCafes = useMemo(() => cafes.filter((cafe) => cafe.visit_status === "to_visit"), [cafes]);
   const visitedCafes = useMemo(() => cafes.filter((cafe) => cafe.visit_status === "visited"), [cafes]);
 
@@ -86,7 +115,11 @@ export default function MyPlacesPage() {
         image_url: cafe.image_url,
         latitude: cafe.latitude,
         longitude: cafe.longitude,
-        visit_status: pendingStatusById[cafe.id] || cafe.visit_status
+        opening_hours: cafe.opening_hours,
+        amenities: cafe.amenities,
+        price_level: cafe.price_level,
+        visit_status: pendingStatusById[cafe.id] || cafe.visit_status,
+        notes: cafe.notes
       });
 
       setMessage(`${cafe.name} is now marked as ${formatVisitStatus(pendingStatusById[cafe.id])}.`);
@@ -132,15 +165,24 @@ export default function MyPlacesPage() {
       <RequireAuth>
         <section className="stats-grid">
           <article className="surface stat-card">
-            <strong>{cafes.length}</strong>
+            <strong>
+              {cafes.length}
+              {countSuffix}
+            </strong>
             <span>{formatCount(cafes.length, "place", "places")}</span>
           </article>
           <article className="surface stat-card">
-            <strong>{savedCafes.length}</strong>
+            <strong>
+              {savedCafes.length}
+              {countSuffix}
+            </strong>
             <span>Saved for later</span>
           </article>
           <article className="surface stat-card">
-            <strong>{visitedCafes.length}</strong>
+            <strong>
+              {visitedCafes.length}
+              {countSuffix}
+            </strong>
             <span>Visited</span>
           </article>
         </section>
@@ -255,6 +297,12 @@ export default function MyPlacesPage() {
                 <p className="muted">No visited cafes yet.</p>
               )}
             </div>
+
+            {nextCursor ? (
+              <button type="button" className="button button-secondary" onClick={handleLoadMore} disabled={loadingMore}>
+                {loadingMore ? "Loading more places..." : "Load more places"}
+              </button>
+            ) : null}
           </section>
         ) : null}
       </RequireAuth>
@@ -6,7 +6,7 @@ import AppShell from "@/components/app-shell";
 import CafeCard from "@/components/cafe-card";
 import CafeMap from "@/components/cafe-map";
 import { useAuth } from "@/components/providers/auth-provider";
-import { listDiscoveryCafes } from "@/lib/api";
+import { listDiscoveryFeed } from "@/lib/api";
 
 const BENEFITS = [
   "Browse real cafes from Geoapify instead of relying on seeded shared database content.",
@@ -14,6 +14,11 @@ const BENEFITS = [
   "Turn visits into reviews without losing the discovery context."
 ];
 
+// Community-only feed results have no place ID; their detail page is the listing itself.
+function feedCafeHref(cafe) {
+  return `/cafes/${encodeURIComponent(cafe.external_place_id || cafe.cafe_listing_id || cafe.id)}`;
+}
+
 export default function HomePage() {
   const { isAuthed } = useAuth();
   const [cafes, setCafes] = useState([]);
@@ -29,10 +34,10 @@ export default function HomePage() {
       setError("");
 
       try {
-        const payload = await listDiscoveryCafes({ city: "Singapore", limit: 6 });
+        const payload = await listDiscoveryFeed({ city: "Singapore", limit: 6 });
         if (!cancelled) {
-          setCafes(payload || []);
-          setSelectedCafe(payload?.[0] || null);
+          setCafes(payload?.items || []);
+          setSelectedCafe(payload?.items?.[0] || null);
         }
       } catch (loadError) {
         if (!cancelled) {
@@ -122,12 +127,12 @@ export default function HomePage() {
           {selectedCafe ? (
             <>
               <h2>{selectedCafe.name}</h2>
-              <p className="muted">{selectedCafe.address || selectedCafe.city || "Geoapify discovery result"}</p>
+              <p className="muted">{selectedCafe.address || selectedCafe.city || "Discovery result"}</p>
               <p className="body-copy">
                 {selectedCafe.description || "Open this cafe detail to see the full context and community notes."}
               </p>
               <div className="card-actions">
-                <Link href={`/cafes/${encodeURIComponent(selectedCafe.id)}`} className="button">
+                <Link href={feedCafeHref(selectedCafe)} className="button">
                   Open cafe detail
                 </Link>
                 <Link href="/map" className="button button-secondary">
@@ -159,14 +164,14 @@ export default function HomePage() {
 
         {!loading && !cafes.length ? (
           <section className="surface empty-state">
-            Geoapify discovery is not available right now. Check the configured Geoapify key to populate this view with live cafes.
+            No cafes matched yet. Geoapify results and community listings both appear here once available.
           </section>
         ) : null}
 
         {!loading ? (
           <div className="card-grid">
             {cafes.map((cafe) => (
-              <CafeCard key={cafe.id} cafe={cafe} />
+              <CafeCard key={cafe.id} cafe={cafe} href={feedCafeHref(cafe)} />
             ))}
           </div>
         ) : null}
@@ -5,7 +5,8 @@ import AppShell from "@/components/app-shell";
 import RequireAuth from "@/components/require-auth";
 import ReviewList from "@/components/review-list";
 import { useAuth } from "@/components/providers/auth-provider";
-import { createCafeRating, deleteRating, listMyCafes, listMyRatings } from "@/lib/api";
+import { createCafeRating, deleteRating, listAllMyCafes, listAllMyRatings } from "@/lib/api";
+import { RATING_DIMENSIONS } from "@/lib/presentation";
 
 export default function ReviewsPage() {
   const { token, isAuthed, ready } = useAuth();
@@ -13,6 +14,7 @@ export default function ReviewsPage() {
   const [ratings, setRatings] = useState([]);
   const [selectedCafeId, setSelectedCafeId] = useState("");
   const [score, setScore] = useState("5");
+  const [subScores, setSubScores] = useState({});
   const [review, setReview] = useState("");
   const [loading, setLoading] = useState(true);
   const [submitting, setSubmitting] = useState(false);
@@ -33,8 +35,8 @@ export default function ReviewsPage() {
 
     try {
       const [nextCafes, nextRatings] = await Promise.all([
-        listMyCafes(token, { sort: "updated_desc" }),
-        listMyRatings(token)
+        listAllMyCafes(token, { sort: "updated_desc" }),
+        listAllMyRatings(token)
       ]);
 
       const cafesPayload = nextCafes || [];
@@ -63,15 +65,21 @@ export default function ReviewsPage() {
     setMessage("");
 
     try {
-      await createCafeRating(token, selectedCafeId, {
+      const created = await createCafeRating(token, selectedCafeId, {
         visited_at: new Date().toISOString(),
         rating: Number(score),
+        ...Object.fromEntries(
+          Object.entries(subScores)
+            .filter(([, value]) => value)
+            .map(([key, value]) => [key, Number(value)])
+        ),
         review
       });
 
       setReview("");
       setScore("5");
-      setMessage("Review saved.");
+      setSubScores({});
+      setMessage(created?.held_at ? "Review saved. It will appear publicly once a moderator approves it." : "Review saved.");
       await loadData();
     } catch (submitError) {
       setError(submitError.message);
@@ -133,6 +141,23 @@ export default function ReviewsPage() {
                 </select>
               </label>
 
+              {RATING_DIMENSIONS.map((dimension) => (
+                <label key={dimension.key}>
+                  {dimension.label} (optional)
+                  <select
+                    value={subScores[dimension.key] || ""}
+                    onChange={(event) => setSubScores((current) => ({ ...current, [dimension.key]: event.target.value }))}
+                  >
+                    <option value="">Not scored</option>
+                    {[5, 4, 3, 2, 1].map((value) => (
+                      <option key={value} value={value}>
+                        {value}/5
+                      </option>
+                    ))}
+                  </select>
+                </label>
+              ))}
+
               <label>
                 Tasting note
                 <textarea
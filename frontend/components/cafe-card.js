@@ -40,6 +40,11 @@ export default function CafeCard({
         </div>
 
         <p className="body-copy">{cafe.description || "A real-world cafe result worth keeping on your radar."}</p>
+        {cafe.source_provider === "openstreetmap" ? (
+          <p className="muted">
+            Data &copy; <a href="https://www.openstreetmap.org/copyright">OpenStreetMap contributors</a>
+          </p>
+        ) : null}
 
         <div className="card-actions">
           <Link href={href} className="button button-secondary">
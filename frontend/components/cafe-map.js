@@ -42,7 +42,7 @@ export default function CafeMap({ cafes, selectedCafeId, onSelect, title = "Jour
         <p className="eyebrow">Map view</p>
         <h2>{title}</h2>
         <p className="muted">
-          Explore the selected place on a Geoapify static map while using the list below to move between cafes.
+          Explore the selected place on a static map while using the list below to move between cafes.
         </p>
       </div>
 
@@ -59,7 +59,7 @@ export default function CafeMap({ cafes, selectedCafeId, onSelect, title = "Jour
           </div>
           <div className="static-map-overlay" aria-hidden="true">
             <span className="static-map-badge">{selectedCafe ? selectedCafe.name : "Discovery view"}</span>
-            <span className="static-map-credit">Static map by Geoapify</span>
+            <span className="static-map-credit">Map data © OpenStreetMap contributors</span>
           </div>
         </div>
       ) : (
@@ -1,8 +1,16 @@
 "use client";
 
-import { formatDate } from "@/lib/presentation";
+import { formatDate, formatHeldReason, formatHelpfulCount, formatSubScores } from "@/lib/presentation";
 
-export default function ReviewList({ ratings, emptyMessage = "No reviews yet.", onDelete, canDelete = false }) {
+export default function ReviewList({
+  ratings,
+  emptyMessage = "No reviews yet.",
+  onDelete,
+  canDelete = false,
+  onVote,
+  votedIds = [],
+  onReport
+}) {
   if (!ratings.length) {
     return <section className="surface empty-state">{emptyMessage}</section>;
   }
@@ -22,8 +30,29 @@ export default function ReviewList({ ratings, emptyMessage = "No reviews yet.",
             </div>
           </div>
 
+          {formatSubScores(rating) ? <p className="muted">{formatSubScores(rating)}</p> : null}
+
+          {rating.hidden_at ? <p className="feedback error">Hidden by moderators. Only you can see this review.</p> : null}
+          {rating.held_at && !rating.hidden_at ? (
+            <p className="feedback">Waiting for moderator review because {formatHeldReason(rating.held_reason)}. Only you can see it for now.</p>
+          ) : null}
+
           <p className="body-copy">{rating.review || "No written tasting note yet."}</p>
 
+          {formatHelpfulCount(rating.helpful_count) ? <p className="muted">{formatHelpfulCount(rating.helpful_count)}</p> : null}
+
+          {onVote ? (
+            <button type="button" className="button button-ghost" onClick={() => onVote(rating.id)}>
+              {votedIds.includes(rating.id) ? "Undo helpful" : "Helpful"}
+            </button>
+          ) : null}
+
+          {onReport ? (
+            <button type="button" className="button button-ghost" onClick={() => onReport(rating.id)}>
+              Report
+            </button>
+          ) : null}
+
           {canDelete && onDelete ? (
             <button type="button" className="button button-ghost" onClick={() => onDelete(rating.id)}>
               Delete review
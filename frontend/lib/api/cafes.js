@@ -1,4 +1,4 @@
-import { authHeaders, request } from "@/lib/api/client";
+import { authHeaders, collectPages, photosFormData, request } from "@/lib/api/client";
 
 function toQuery(params = {}) {
   const searchParams = new URLSearchParams();
@@ -22,6 +22,10 @@ export function listMyCafes(token, query = {}) {
   });
 }
 
+export function listAllMyCafes(token, query = {}) {
+  return collectPages((cursor) => listMyCafes(token, { ...query, limit: 100, cursor }));
+}
+
 export function createMyCafe(token, body) {
   return request("/me/cafes", {
     method: "POST",
@@ -48,3 +52,22 @@ export function deleteCafe(token, cafeId) {
 export function searchCafeAddresses(query, limit = 5) {
   return request(`/cafes/autocomplete${toQuery({ text: query, limit })}`);
 }
+
+export function listCafePhotos(cafeId) {
+  return request(`/cafes/${cafeId}/photos/`);
+}
+
+export function uploadCafePhotos(token, cafeId, files) {
+  return request(`/cafes/${cafeId}/photos/`, {
+    method: "POST",
+    headers: authHeaders(token),
+    body: photosFormData(files)
+  });
+}
+
+export function deleteCafePhoto(token, cafeId, photoId) {
+  return request(`/cafes/${cafeId}/photos/${photoId}`, {
+    method: "DELETE",
+    headers: authHeaders(token)
+  });
+}
@@ -9,10 +9,12 @@ export class ApiError extends Error {
 }
 
 export async function request(path, options = {}) {
+  // Let the browser set the multipart boundary for FormData uploads.
+  const isFormData = typeof FormData !== "undefined" && options.body instanceof FormData;
   const response = await fetch(`${API_BASE_URL}${path}`, {
     ...options,
     headers: {
-      "Content-Type": "application/json",
+      ...(isFormData ? {} : { "Content-Type": "application/json" }),
       ...(options.headers || {})
     },
     cache: "no-store"
@@ -43,6 +45,31 @@ export async function request(path, options = {}) {
   return payload;
 }
 
+// collectPages follows next_cursor until the last page of a paged list endpoint and returns every item.
+export async function collectPages(fetchPage) {
+  const items = [];
+  let cursor = "";
+
+  do {
+    const page = await fetchPage(cursor);
+    items.push(...(page?.items || []));
+    cursor = page?.next_cursor || "";
+  } while (cursor);
+
+  return items;
+}
+
 export function authHeaders(token) {
   return token ? { Authorization: `Bearer ${token}` } : {};
 }
+
+// photoSrc maps a photo url from the API (e.g. /api/v1/photos/1/thumbnail) onto the backend proxy.
+export function photoSrc(url) {
+  return url ? url.replace(/^\/api\/v1(?=\/)/, API_BASE_URL) : "";
+}
+
+export function photosFormData(files) {
+  const formData = new FormData();
+  Array.from(files || []).forEach((file) => formData.append("photos", file));
+  return formData;
+}
@@ -1,4 +1,4 @@
-import { request } from "@/lib/api/client";
+import { collectPages, request } from "@/lib/api/client";
 
 function toQuery(params = {}) {
   const searchParams = new URLSearchParams();
@@ -16,10 +16,25 @@ export function listDiscoveryCafes(query = {}) {
   return request(`/discovery/cafes${toQuery(query)}`);
 }
 
+// Geoapify places merged with community listings; returns { items, unavailable }. Each item has an origin
+// (provider, community or both) and, when a community listing matched, its cafe_listing_id.
+export function listDiscoveryFeed(query = {}) {
+  return request(`/discovery/feed${toQuery(query)}`);
+}
+
+// Community listings; supports query, city, near ("lat,lon"), radius_m, bbox ("minLon,minLat,maxLon,maxLat"), sort, limit, cursor.
+export function listCommunityCafes(query = {}) {
+  return request(`/cafes${toQuery(query)}`);
+}
+
 export function getDiscoveryCafeById(placeId) {
   return request(`/discovery/cafes/${encodeURIComponent(placeId)}`);
 }
 
-export function listCommunityRatingsByPlaceId(placeId) {
-  return request(`/community/places/${encodeURIComponent(placeId)}/ratings`);
+export function listCommunityRatingsByPlaceId(placeId, query = {}) {
+  return request(`/community/places/${encodeURIComponent(placeId)}/ratings${toQuery(query)}`);
+}
+
+export function listAllCommunityRatingsByPlaceId(placeId, query = {}) {
+  return collectPages((cursor) => listCommunityRatingsByPlaceId(placeId, { ...query, limit: 100, cursor }));
 }
@@ -1,5 +1,6 @@
 export * from "@/lib/api/auth";
 export * from "@/lib/api/cafes";
 export * from "@/lib/api/discovery";
+export * from "@/lib/api/moderation";
 export * from "@/lib/api/reviews";
 export * from "@/lib/api/client";
@@ -0,0 +1,79 @@
+import { authHeaders, request } from "@/lib/api/client";
+
+function toQuery(params = {}) {
+  const searchParams = new URLSearchParams();
+
+  Object.entries(params).forEach(([key, value]) => {
+    if (value === undefined || value === null || value === "") return;
+    searchParams.set(key, String(value));
+  });
+
+  const query = searchParams.toString();
+  return query ? `?${query}` : "";
+}
+
+// reason is one of spam, offensive, harassment, off_topic, misleading or other.
+export function reportRating(token, ratingId, body) {
+  return request(`/ratings/${ratingId}/reports`, {
+    method: "POST",
+    headers: authHeaders(token),
+    body: JSON.stringify(body)
+  });
+}
+
+export function reportCafe(token, cafeId, body) {
+  return request(`/cafes/${cafeId}/reports`, {
+    method: "POST",
+    headers: authHeaders(token),
+    body: JSON.stringify(body)
+  });
+}
+
+// status is open (default), hidden, restored, dismissed or all. Moderators and admins only.
+export function listModerationReports(token, query = {}) {
+  return request(`/moderation/reports/${toQuery(query)}`, {
+    headers: authHeaders(token)
+  });
+}
+
+export function hideReport(token, reportId) {
+  return request(`/moderation/reports/${reportId}/hide`, {
+    method: "POST",
+    headers: authHeaders(token)
+  });
+}
+
+export function restoreReport(token, reportId) {
+  return request(`/moderation/reports/${reportId}/restore`, {
+    method: "POST",
+    headers: authHeaders(token)
+  });
+}
+
+export function dismissReport(token, reportId) {
+  return request(`/moderation/reports/${reportId}/dismiss`, {
+    method: "POST",
+    headers: authHeaders(token)
+  });
+}
+
+// Reviews held back by automatic screening until a moderator decides. Moderators and admins only.
+export function listHeldRatings(token, query = {}) {
+  return request(`/moderation/held-ratings/${toQuery(query)}`, {
+    headers: authHeaders(token)
+  });
+}
+
+export function approveHeldRating(token, ratingId) {
+  return request(`/moderation/held-ratings/${ratingId}/approve`, {
+    method: "POST",
+    headers: authHeaders(token)
+  });
+}
+
+export function rejectHeldRating(token, ratingId) {
+  return request(`/moderation/held-ratings/${ratingId}/reject`, {
+    method: "POST",
+    headers: authHeaders(token)
+  });
+}
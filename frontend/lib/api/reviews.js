@@ -1,13 +1,34 @@
-import { authHeaders, request } from "@/lib/api/client";
+import { authHeaders, collectPages, photosFormData, request } from "@/lib/api/client";
 
-export function listMyRatings(token) {
-  return request("/me/ratings", {
+function toQuery(params = {}) {
+  const searchParams = new URLSearchParams();
+
+  Object.entries(params).forEach(([key, value]) => {
+    if (value === undefined || value === null || value === "") return;
+    searchParams.set(key, String(value));
+  });
+
+  const query = searchParams.toString();
+  return query ? `?${query}` : "";
+}
+
+export function listMyRatings(token, query = {}) {
+  return request(`/me/ratings${toQuery(query)}`, {
     headers: authHeaders(token)
   });
 }
 
-export function listCafeRatings(cafeId) {
-  return request(`/cafes/${cafeId}/ratings/`);
+export function listAllMyRatings(token) {
+  return collectPages((cursor) => listMyRatings(token, { limit: 100, cursor }));
+}
+
+export function listCafeRatings(cafeId, query = {}) {
+  return request(`/cafes/${cafeId}/ratings/${toQuery(query)}`);
+}
+
+// sort is recent (default), helpful, highest or lowest.
+export function listAllCafeRatings(cafeId, query = {}) {
+  return collectPages((cursor) => listCafeRatings(cafeId, { ...query, limit: 100, cursor }));
 }
 
 export function createCafeRating(token, cafeId, body) {
@@ -32,3 +53,36 @@ export function deleteRating(token, ratingId) {
     headers: authHeaders(token)
   });
 }
+
+export function voteRating(token, ratingId) {
+  return request(`/ratings/${ratingId}/votes`, {
+    method: "POST",
+    headers: authHeaders(token)
+  });
+}
+
+export function unvoteRating(token, ratingId) {
+  return request(`/ratings/${ratingId}/votes`, {
+    method: "DELETE",
+    headers: authHeaders(token)
+  });
+}
+
+export function listRatingPhotos(ratingId) {
+  return request(`/ratings/${ratingId}/photos/`);
+}
+
+export function uploadRatingPhotos(token, ratingId, files) {
+  return request(`/ratings/${ratingId}/photos/`, {
+    method: "POST",
+    headers: authHeaders(token),
+    body: photosFormData(files)
+  });
+}
+
+export function deleteRatingPhoto(token, ratingId, photoId) {
+  return request(`/ratings/${ratingId}/photos/${photoId}`, {
+    method: "DELETE",
+    headers: authHeaders(token)
+  });
+}
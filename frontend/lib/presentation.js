@@ -34,3 +34,75 @@ export function getCafeSummary(cafe) {
 export function hasCoordinates(cafe) {
   return typeof cafe?.latitude === "number" && typeof cafe?.longitude === "number";
 }
+
+export function formatPriceLevel(level) {
+  if (!level) return "Unknown";
+  return "$".repeat(level);
+}
+
+export function formatAmenities(amenities) {
+  if (!amenities?.length) return "Not listed";
+  return amenities.map((amenity) => amenity.replaceAll("_", " ")).join(", ");
+}
+
+const WEEKDAY_LABELS = { mon: "Mon", tue: "Tue", wed: "Wed", thu: "Thu", fri: "Fri", sat: "Sat", sun: "Sun" };
+
+export function formatOpeningHours(hours) {
+  if (!hours?.weekly?.length) return "Not listed";
+  const byDay = new Map();
+  hours.weekly.forEach((period) => {
+    const label = WEEKDAY_LABELS[period.day] || period.day;
+    byDay.set(label, [...(byDay.get(label) || []), `${period.open}-${period.close}`]);
+  });
+  return [...byDay.entries()].map(([day, ranges]) => `${day} ${ranges.join(", ")}`).join(" · ");
+}
+
+export const RATING_DIMENSIONS = [
+  { key: "coffee_score", average: "avg_coffee", label: "Coffee" },
+  { key: "ambience_score", average: "avg_ambience", label: "Ambience" },
+  { key: "service_score", average: "avg_service", label: "Service" },
+  { key: "value_score", average: "avg_value", label: "Value" },
+  { key: "work_score", average: "avg_work", label: "Work-friendly" }
+];
+
+export const REVIEW_SORTS = [
+  { value: "recent", label: "Most recent" },
+  { value: "helpful", label: "Most helpful" },
+  { value: "highest", label: "Highest rated" },
+  { value: "lowest", label: "Lowest rated" }
+];
+
+export const REPORT_REASONS = [
+  { value: "spam", label: "Spam or advertising" },
+  { value: "offensive", label: "Offensive" },
+  { value: "harassment", label: "Harassment" },
+  { value: "off_topic", label: "Off topic" },
+  { value: "misleading", label: "Misleading" },
+  { value: "other", label: "Something else" }
+];
+
+export function formatReportReason(reason) {
+  return REPORT_REASONS.find((option) => option.value === reason)?.label || reason || "Unknown";
+}
+
+const HELD_REASONS = {
+  word_list: "it uses a blocked word",
+  links: "it contains a link",
+  phone_number: "it contains a phone number",
+  repeated_characters: "it repeats characters"
+};
+
+export function formatHeldReason(reason) {
+  return HELD_REASONS[reason] || "it needs a second look";
+}
+
+export function formatHelpfulCount(count) {
+  if (!count) return "";
+  return count === 1 ? "1 person found this helpful" : `${count} people found this helpful`;
+}
+
+export function formatSubScores(rating) {
+  return RATING_DIMENSIONS.filter((dimension) => rating?.[dimension.key])
+    .map((dimension) => `${dimension.label} ${rating[dimension.key]}/5`)
+    .join(" · ");
+}